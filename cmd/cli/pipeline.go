@@ -54,6 +54,9 @@ type BuildReport struct {
 	ThroughputGain string    `json:"throughput_gain"`
 	BuildFlags     []string  `json:"build_flags"`
 	Compressed     bool      `json:"compressed"`
+	BaselineSize   string    `json:"baseline_size,omitempty"`    // Same build of --baseline
+	SizeDelta      string    `json:"size_delta,omitempty"`       // e.g. "-412.0KB (-21.3%)"
+	SizeDeltaBytes int64     `json:"size_delta_bytes,omitempty"` // Optimized minus baseline, uncompressed
 }
 
 // validateCmd creates the validate command for Stage 2
@@ -163,7 +166,8 @@ Stage 4 of the GASType Premium Pipeline:
 
 Examples:
   gastype build --source ./out_obfuscated --final --compress
-  gastype build --source ./validated_code -o ./dist/myapp`,
+  gastype build --source ./validated_code -o ./dist/myapp
+  gastype build --source ./out_nofmt --baseline ./original --final`,
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runBuildCommand(&config)
@@ -181,6 +185,8 @@ Examples:
 		"Apply maximum optimizations for production")
 	cmd.Flags().BoolVar(&config.Compress, "compress", false,
 		"Compress final binary with UPX")
+	cmd.Flags().StringVar(&config.BaselinePath, "baseline", "",
+		"Original source to build with the same flags and compare binary size against")
 	cmd.Flags().BoolVarP(&config.Verbose, "verbose", "v", false,
 		"Show detailed build logs")
 
@@ -350,6 +356,15 @@ func runBuildCommand(config *PipelineConfig) error {
 		return fmt.Errorf("build failed: %w", err)
 	}
 
+	// Step 1b: Compare with the baseline build, before compression
+	if config.BaselinePath != "" {
+		gl.Log("info", "📏 Building baseline for size comparison...")
+		if err := compareBinarySize(config.BaselinePath, binaryPath, config.Final, report); err != nil {
+			gl.Log("error", fmt.Sprintf("Size comparison failed: %v", err))
+			// Continue without the comparison
+		}
+	}
+
 	// Step 2: Compress if requested
 	if config.Compress {
 		gl.Log("info", "📦 Compressing binary...")
@@ -700,12 +715,7 @@ func collectBuildMetrics(binaryPath string, report *BuildReport) error {
 
 	// Get binary size
 	if stat, err := os.Stat(binaryPath); err == nil {
-		size := stat.Size()
-		if size > 1024*1024 {
-			report.BinarySize = fmt.Sprintf("%.1fMB", float64(size)/1024/1024)
-		} else {
-			report.BinarySize = fmt.Sprintf("%.1fKB", float64(size)/1024)
-		}
+		report.BinarySize = formatSize(stat.Size())
 	}
 
 	// Simulate other metrics (would need actual benchmarking)
@@ -717,6 +727,49 @@ func collectBuildMetrics(binaryPath string, report *BuildReport) error {
 	return nil
 }
 
+// formatSize renders a byte count as KB or MB
+func formatSize(size int64) string {
+	if size > 1024*1024 || size < -1024*1024 {
+		return fmt.Sprintf("%.1fMB", float64(size)/1024/1024)
+	}
+	return fmt.Sprintf("%.1fKB", float64(size)/1024)
+}
+
+// compareBinarySize builds the baseline source with the same flags in a
+// temporary directory and records the size difference of binaryPath
+func compareBinarySize(baselinePath, binaryPath string, final bool, report *BuildReport) error {
+	tmpDir, err := os.MkdirTemp("", "gastype-baseline-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	baselineBinary, err := buildOptimizedBinary(baselinePath, tmpDir, final, &BuildReport{})
+	if err != nil {
+		return fmt.Errorf("baseline build failed: %w", err)
+	}
+	baseline, err := os.Stat(baselineBinary)
+	if err != nil {
+		return fmt.Errorf("failed to stat baseline binary: %w", err)
+	}
+	optimized, err := os.Stat(binaryPath)
+	if err != nil {
+		return fmt.Errorf("failed to stat binary: %w", err)
+	}
+
+	delta := optimized.Size() - baseline.Size()
+	sign := ""
+	if delta > 0 {
+		sign = "+"
+	}
+	report.BaselineSize = formatSize(baseline.Size())
+	report.SizeDeltaBytes = delta
+	report.SizeDelta = fmt.Sprintf("%s%s (%s%.1f%%)", sign, formatSize(delta), sign, float64(delta)*100/float64(baseline.Size()))
+
+	gl.Log("info", fmt.Sprintf("  📉 Binary size: %s → %s (%s)\n", report.BaselineSize, formatSize(optimized.Size()), report.SizeDelta))
+	return nil
+}
+
 // saveBuildReport saves the build report to JSON
 func saveBuildReport(reportPath string, report *BuildReport) error {
 	data, err := json.MarshalIndent(report, "", "  ")
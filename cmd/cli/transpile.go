@@ -2,11 +2,14 @@
 package cli
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -30,6 +33,17 @@ type TranspileConfig struct {
 	BackupOriginal bool   `json:"backup_original"`
 	NoObfuscate    bool   `json:"no_obfuscate"` // Stage 1: transpile without obfuscation
 	MapFile        string `json:"map_file"`     // Path to context mapping file
+	Seed           string `json:"seed"`         // Build secret for string encryption (random when empty)
+	Profile        string `json:"profile"`      // pprof CPU profile marking hot code
+
+	// String obfuscation policy
+	StringsAllow      string  `json:"strings_allow"`       // Regex of literals always encrypted
+	StringsDeny       string  `json:"strings_deny"`        // Regex of literals never encrypted
+	StringsMinLen     int     `json:"strings_min_len"`     // Shorter literals stay in plain text
+	StringsMinEntropy float64 `json:"strings_min_entropy"` // Literals with lower entropy (bits/byte) stay in plain text
+
+	// Call stripping (strip-calls pass)
+	StripCalls []string `json:"strip_calls"` // Functions/methods removed, with optional levels: "gl.Log=debug|info"
 
 	// Engine-specific configurations
 	DryRun       bool     `json:"dry_run"`       // Only analyze, don't save files
@@ -190,6 +204,20 @@ Examples:
 		"Stage 1: Transpile without obfuscation (readable optimized code)")
 	cmd.Flags().StringVar(&config.MapFile, "map", "",
 		"Generate context mapping JSON file for transpilation tracking")
+	cmd.Flags().StringVar(&config.Seed, "seed", "",
+		"Secret seed for string encryption (random per build when empty, recorded in the map file)")
+	cmd.Flags().StringVar(&config.Profile, "profile", "",
+		"pprof CPU profile of the program; obfuscation stays out of the hot loops it marks")
+	cmd.Flags().StringVar(&config.StringsAllow, "strings-allow", "",
+		"Regex of string literals always encrypted, even when the automatic rules would skip them")
+	cmd.Flags().StringVar(&config.StringsDeny, "strings-deny", "",
+		"Regex of string literals never encrypted")
+	cmd.Flags().IntVar(&config.StringsMinLen, "strings-min-len", 4,
+		"Minimum length (bytes) of an encrypted string literal")
+	cmd.Flags().Float64Var(&config.StringsMinEntropy, "strings-min-entropy", 1.0,
+		"Minimum Shannon entropy (bits per byte) of an encrypted string literal")
+	cmd.Flags().StringSliceVar(&config.StripCalls, "strip-calls", nil,
+		"Calls removed by the strip-calls pass, as func or pkg.Func[=level|level] (default: logz debug logs)")
 
 	// Engine flags
 	cmd.Flags().BoolVar(&config.DryRun, "dry-run", false,
@@ -1555,6 +1583,39 @@ func runEngineTranspilation(config *TranspileConfig) error {
 	// Create transpile context using our REVOLUTIONARY constructor
 	context := astutil.NewContext(config.InputPath, config.OutputPath, !config.NoObfuscate, config.MapFile)
 	context.DryRun = config.DryRun // Set dry run after construction
+	context.Seed = config.Seed
+	context.SecurityLevel = config.SecurityLevel
+	if config.Profile != "" {
+		profile, err := astutil.LoadProfile(config.Profile)
+		if err != nil {
+			return fmt.Errorf("invalid --profile: %w", err)
+		}
+		context.Profile = profile
+	}
+	context.StringPolicy.MinLength = config.StringsMinLen
+	context.StringPolicy.MinEntropy = config.StringsMinEntropy
+	if config.StringsAllow != "" {
+		allow, err := regexp.Compile(config.StringsAllow)
+		if err != nil {
+			return fmt.Errorf("invalid --strings-allow: %w", err)
+		}
+		context.StringPolicy.Allow = allow
+	}
+	if config.StringsDeny != "" {
+		deny, err := regexp.Compile(config.StringsDeny)
+		if err != nil {
+			return fmt.Errorf("invalid --strings-deny: %w", err)
+		}
+		context.StringPolicy.Deny = deny
+	}
+
+	for _, spec := range config.StripCalls {
+		rule, err := astutil.ParseStripRule(spec)
+		if err != nil {
+			return fmt.Errorf("invalid --strip-calls: %w", err)
+		}
+		context.StripRules = append(context.StripRules, rule)
+	}
 
 	// Create engine
 	engine := transpiler.NewEngine(context)
@@ -1570,18 +1631,67 @@ func runEngineTranspilation(config *TranspileConfig) error {
 			engine.AddPass(pass.NewAssignToBitwisePass())
 		case "field-to-bitwise", "field2bitwise":
 			engine.AddPass(pass.NewFieldAccessToBitwisePass())
+		case "map-set", "mapset":
+			engine.AddPass(pass.NewMapSetPass())
+		case "bool-bitset", "bitset":
+			engine.AddPass(pass.NewBoolBitsetPass())
+		case "bool-params", "boolparams":
+			engine.AddPass(pass.NewBoolParamsPass())
+		case "bool-results", "boolresults":
+			engine.AddPass(pass.NewBoolResultsPass())
+		case "fmt-to-strconv", "fmtstrconv":
+			engine.AddPass(pass.NewFmtStrconvPass())
+		case "loop-alloc", "loopalloc":
+			engine.AddPass(pass.NewLoopAllocPass())
+		case "hoist-compile", "hoist":
+			engine.AddPass(pass.NewHoistCompilePass())
+		case "readonly-map", "romap":
+			engine.AddPass(pass.NewReadOnlyMapPass())
+		case "strip-calls", "strip":
+			engine.AddPass(pass.NewStripCallsPass())
+		case "fmt-eliminate", "fmtelim":
+			engine.AddPass(pass.NewFmtEliminatePass())
 		case "string-obfuscate", "stringobf":
 			engine.AddPass(pass.NewStringObfuscatePass())
 		case "jump-table", "jumptable":
 			engine.AddPass(pass.NewJumpTablePass())
+		case "perfect-hash", "perfecthash":
+			engine.AddPass(pass.NewPerfectHashPass())
+		case "bitfield-pack", "bitfieldpack":
+			engine.AddPass(pass.NewBitfieldPackPass())
+		case "struct-layout", "structlayout":
+			engine.AddPass(pass.NewStructLayoutPass())
+		case "mixed-bool-arith", "mba":
+			engine.AddPass(pass.NewMixedBoolArithPass())
+		case "opaque-predicates", "opaque":
+			engine.AddPass(pass.NewOpaquePredicatePass())
+		case "control-flow-flatten", "flatten":
+			engine.AddPass(pass.NewFlattenPass())
+		case "rename-idents", "rename":
+			engine.AddPass(pass.NewRenamePass())
 		case "revolution":
 			// Add ALL passes for maximum revolution!
 			engine.AddPass(pass.NewBoolToFlagsPass())
+			engine.AddPass(pass.NewBitfieldPackPass())
+			engine.AddPass(pass.NewStructLayoutPass())
 			engine.AddPass(pass.NewIfToBitwisePass())
 			engine.AddPass(pass.NewAssignToBitwisePass())
 			engine.AddPass(pass.NewFieldAccessToBitwisePass()) // 🚀 REVOLUTIONARY!
+			engine.AddPass(pass.NewMapSetPass())
+			engine.AddPass(pass.NewBoolBitsetPass())
+			engine.AddPass(pass.NewBoolParamsPass())
+			engine.AddPass(pass.NewBoolResultsPass())
+			engine.AddPass(pass.NewFmtStrconvPass())
+			engine.AddPass(pass.NewLoopAllocPass())
+			engine.AddPass(pass.NewHoistCompilePass())
+			engine.AddPass(pass.NewReadOnlyMapPass())
 			engine.AddPass(pass.NewStringObfuscatePass())
+			engine.AddPass(pass.NewPerfectHashPass())
 			engine.AddPass(pass.NewJumpTablePass())
+			engine.AddPass(pass.NewMixedBoolArithPass())
+			engine.AddPass(pass.NewOpaquePredicatePass())
+			engine.AddPass(pass.NewFlattenPass())
+			engine.AddPass(pass.NewRenamePass())
 		default:
 			if config.Verbose {
 				gl.Log("info", fmt.Sprintf("⚠️ Unknown pass: %s", passName))
@@ -1589,15 +1699,6 @@ func runEngineTranspilation(config *TranspileConfig) error {
 		}
 	}
 
-	// Performance estimation
-	if config.EstimatePerf {
-		if config.Verbose {
-			gl.Log("info", "📊 Estimating performance impact...")
-		}
-		context.EstimatePerformance()
-		gl.Log("info", "🚀 Performance estimation completed")
-	}
-
 	// Run engine
 	if config.Verbose {
 		gl.Log("info", fmt.Sprintf("🔧 Running transpilation with %d passes", len(engine.Passes)))
@@ -1609,6 +1710,15 @@ func runEngineTranspilation(config *TranspileConfig) error {
 		return fmt.Errorf("engine transpilation failed: %w", err)
 	}
 
+	// Performance estimation
+	if config.EstimatePerf {
+		if config.Verbose {
+			gl.Log("info", "📊 Estimating performance impact...")
+		}
+		context.EstimatePerformance()
+		gl.Log("info", "🚀 Performance estimation completed")
+	}
+
 	// 🚀 REVOLUTIONARY OUTPUT MANAGER - PRODUCTION-READY SOLUTION!
 	if !config.DryRun {
 		if config.Verbose {
@@ -1632,6 +1742,13 @@ func runEngineTranspilation(config *TranspileConfig) error {
 			return fmt.Errorf("OutputManager failed: %w", err)
 		}
 
+		// 🔐 Os literais cifrados não podem sobrar em texto puro no binário
+		if len(context.ObfuscatedStrings) > 0 {
+			if err := verifyObfuscatedStrings(config.OutputPath, context.ObfuscatedStrings); err != nil {
+				gl.Log("warn", fmt.Sprintf("⚠️ String obfuscation check: %v", err))
+			}
+		}
+
 		gl.Log("info", "✅ Complete project output generated - PRODUCTION-READY!")
 		gl.Log("info", fmt.Sprintf("🎯 Output completo gerado em: %s", config.OutputPath))
 		gl.Log("info", fmt.Sprintf("🚀 Projeto pronto para build: cd %s && go build", config.OutputPath))
@@ -1646,6 +1763,56 @@ func runEngineTranspilation(config *TranspileConfig) error {
 	return nil
 }
 
+// verifyObfuscatedStrings builds the output and checks that none of the
+// plaintexts encrypted by StringObfuscate survived in the resulting binaries
+func verifyObfuscatedStrings(outputPath string, plaintexts []string) error {
+	binDir, err := os.MkdirTemp("", "gastype_verify")
+	if err != nil {
+		return fmt.Errorf("failed to create build directory: %w", err)
+	}
+	defer os.RemoveAll(binDir)
+
+	cmd := exec.Command("go", "build", "-o", binDir+string(os.PathSeparator), "./...")
+	cmd.Dir = outputPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("build failed, strings not verified: %s", strings.TrimSpace(string(output)))
+	}
+
+	binaries, err := os.ReadDir(binDir)
+	if err != nil {
+		return fmt.Errorf("failed to read build directory: %w", err)
+	}
+	if len(binaries) == 0 {
+		gl.Log("info", "🔐 No main package in output, string obfuscation not verified")
+		return nil
+	}
+
+	leaked := make(map[string]bool)
+	for _, bin := range binaries {
+		data, err := os.ReadFile(filepath.Join(binDir, bin.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read binary: %w", err)
+		}
+		for _, s := range plaintexts {
+			// Poucos bytes aparecem por acaso em qualquer binário
+			if len(s) < 4 {
+				continue
+			}
+			if bytes.Contains(data, []byte(s)) {
+				leaked[s] = true
+			}
+		}
+	}
+	if len(leaked) == 0 {
+		gl.Log("info", fmt.Sprintf("🔐 Verified: none of the %d obfuscated strings appear in the built binary", len(plaintexts)))
+		return nil
+	}
+	for s := range leaked {
+		gl.Log("warn", fmt.Sprintf("  plaintext still present in binary: %q (may also come from a dependency)", s))
+	}
+	return fmt.Errorf("%d of %d obfuscated strings still appear in the built binary", len(leaked), len(plaintexts))
+}
+
 // TranspileCmds returns all transpilation-related commands
 func TranspileCmds() []*cobra.Command {
 	return []*cobra.Command{
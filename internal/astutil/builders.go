@@ -2,6 +2,7 @@ package astutil
 
 import (
 	"go/ast"
+	"go/parser"
 	"go/token"
 )
 
@@ -31,3 +32,14 @@ func NewConst(name, typ, value string) *ast.GenDecl {
 		},
 	}
 }
+
+// ParseDecls parses Go source containing only top-level declarations (no
+// package clause) and returns them ready to be appended to an existing file.
+// The source is registered in fset so the printer can resolve positions.
+func ParseDecls(fset *token.FileSet, src string) ([]ast.Decl, error) {
+	f, err := parser.ParseFile(fset, "gastype_generated.go", "package p\n\n"+src, 0)
+	if err != nil {
+		return nil, err
+	}
+	return f.Decls, nil
+}
@@ -1,11 +1,17 @@
 package astutil
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
 	"os"
+	"regexp"
+	"runtime"
 	"strings"
 
 	gl "github.com/kubex-ecosystem/logz/logger"
@@ -18,16 +24,62 @@ type AssignTransformation struct {
 	Value    string `json:"value"`
 }
 
+// LedgerEntry records a single transformation, or a refusal to transform, made by a pass
+type LedgerEntry struct {
+	Pass   string `json:"pass"`
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Target string `json:"target"`           // Expression or symbol involved
+	Action string `json:"action"`           // What happened (e.g. "rewritten", "rejected")
+	Detail string `json:"detail,omitempty"` // Replacement or reason
+}
+
+// RenameEntry records an identifier renamed by the Rename pass
+type RenameEntry struct {
+	Package string `json:"package"`
+	Kind    string `json:"kind"`            // "func", "type", "var", "const", "method", "field" or "local"
+	Scope   string `json:"scope,omitempty"` // Owner of a method/field, or function of a local
+	Old     string `json:"old"`
+	New     string `json:"new"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+}
+
+// FmtRewrite records a fmt formatting call replaced by strconv, concatenation
+// or a strings.Builder helper. Allocations are estimated per execution.
+type FmtRewrite struct {
+	File         string `json:"file"`
+	Line         int    `json:"line"`
+	Call         string `json:"call"`        // fmt.Sprintf, fmt.Sprint...
+	Replacement  string `json:"replacement"` // "strconv", "concatenation" or the generated helper
+	AllocsBefore int    `json:"allocs_before"`
+	AllocsAfter  int    `json:"allocs_after"`
+}
+
+// StrippedCall records a logging call removed by StripCalls
+type StrippedCall struct {
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	Call        string `json:"call"`            // gl.Log, log.Printf, logger.Debugf...
+	Level       string `json:"level,omitempty"` // Level argument that matched the rule
+	StringBytes int    `json:"string_bytes"`    // Bytes of string literals that went with the call
+}
+
 // TranspileContext tracks all information about a transpilation operation
 type TranspileContext struct {
 	*Info
 
 	// General configuration
-	Ofuscate  bool   `json:"ofuscate"`   // If true, names and structure will be obfuscated
-	MapFile   string `json:"map_file"`   // Path to output .map.json file
-	InputFile string `json:"input_file"` // Input file path
-	OutputDir string `json:"output_dir"` // Output directory
-	DryRun    bool   `json:"dry_run"`    // If true, only analyze without saving files
+	Ofuscate  bool   `json:"ofuscate"`       // If true, names and structure will be obfuscated
+	MapFile   string `json:"map_file"`       // Path to output .map.json file
+	InputFile string `json:"input_file"`     // Input file path
+	OutputDir string `json:"output_dir"`     // Output directory
+	DryRun    bool   `json:"dry_run"`        // If true, only analyze without saving files
+	GOARCH    string `json:"goarch"`         // Target architecture used for size/alignment computations
+	Seed      string `json:"seed,omitempty"` // Per-build secret that keys string encryption (random unless --seed)
+
+	SecurityLevel int         `json:"security_level"` // Obfuscation density: 1=low, 2=medium, 3=high
+	Profile       *HotProfile `json:"-"`              // CPU profile marking hot code (--profile), nil when absent
 
 	// Analysis results
 	Structs map[string]*StructInfo `json:"structs"` // Original struct → detailed info
@@ -36,11 +88,85 @@ type TranspileContext struct {
 	// 🚀 REVOLUTIONARY FIELDS for OutputManager
 	GeneratedFiles map[string]*ast.File `json:"-"` // File path → transpiled AST
 	Fset           *token.FileSet       `json:"-"` // Token file set for all files
+	Package        *types.Package       `json:"-"` // Package currently being transpiled (set by the engine)
 
 	// 🔥 PACKAGE-SCOPED CONSTANTS TRACKING
 	PackageConstantsAdded map[string]bool `json:"-"` // Package → constants added (prevents duplicates)
 
 	AssignTransformations []AssignTransformation `json:"assign_transformations"` // Track all assign transformations
+
+	Ledger []LedgerEntry `json:"ledger,omitempty"` // Per-site record of what each pass did
+
+	StructLayouts map[string]*StructLayout `json:"struct_layouts,omitempty"` // Struct → field reordering report
+	Bitsets       []BoolBitset             `json:"bitsets,omitempty"`        // []bool/[N]bool packed into bitsets
+	FmtRewrites   []FmtRewrite             `json:"fmt_rewrites,omitempty"`   // fmt calls replaced by strconv/concatenation
+
+	Renames []RenameEntry `json:"renames,omitempty"` // Identifier renames, to map obfuscated names back
+
+	StringPolicy      StringPolicy `json:"string_policy"` // Which literals StringObfuscate encrypts
+	ObfuscatedStrings []string     `json:"-"`             // Plaintexts encrypted by StringObfuscate, checked against the built binary
+
+	StripRules    []StripRule    `json:"strip_rules,omitempty"`    // Calls removed by StripCalls
+	StrippedCalls []StrippedCall `json:"stripped_calls,omitempty"` // Call sites removed by StripCalls
+}
+
+// StringPolicy controls which string literals StringObfuscate encrypts. A
+// //gastype:keep comment beats Deny, Deny beats Allow, and Allow beats the
+// automatic skips (format strings, errors.Is sentinels, length and entropy).
+type StringPolicy struct {
+	Allow      *regexp.Regexp `json:"-"`           // Literals always encrypted
+	Deny       *regexp.Regexp `json:"-"`           // Literals never encrypted
+	MinLength  int            `json:"min_length"`  // Shorter literals are kept
+	MinEntropy float64        `json:"min_entropy"` // Literals below this Shannon entropy (bits per byte) are kept
+}
+
+// StripRule names a function or method whose calls StripCalls removes, as
+// written in --strip-calls: "gl.Log=debug|info", "log.Printf",
+// "(*Logger).Debugf" or "github.com/kubex-ecosystem/logz/logger.Log=debug".
+// The qualifier matches an import name, a package name or path, or the
+// receiver type of a method. With Levels, only calls whose first argument is
+// one of them (a constant's value or name) are removed.
+type StripRule struct {
+	Qualifier string   `json:"qualifier,omitempty"`
+	Name      string   `json:"name"`
+	Levels    []string `json:"levels,omitempty"`
+}
+
+// ParseStripRule parses one --strip-calls entry
+func ParseStripRule(spec string) (StripRule, error) {
+	var rule StripRule
+	target, levels, hasLevels := strings.Cut(strings.TrimSpace(spec), "=")
+	if hasLevels {
+		for _, level := range strings.Split(levels, "|") {
+			if level = strings.TrimSpace(level); level != "" {
+				rule.Levels = append(rule.Levels, level)
+			}
+		}
+		if len(rule.Levels) == 0 {
+			return rule, fmt.Errorf("no levels after '=' in %q", spec)
+		}
+	}
+	if i := strings.LastIndex(target, "."); i >= 0 {
+		rule.Qualifier = strings.Trim(target[:i], "(*)")
+		target = target[i+1:]
+	}
+	if !token.IsIdentifier(target) {
+		return rule, fmt.Errorf("invalid function name in %q", spec)
+	}
+	rule.Name = target
+	return rule, nil
+}
+
+// String renders the rule back in the --strip-calls syntax
+func (r StripRule) String() string {
+	s := r.Name
+	if r.Qualifier != "" {
+		s = r.Qualifier + "." + s
+	}
+	if len(r.Levels) > 0 {
+		s += "=" + strings.Join(r.Levels, "|")
+	}
+	return s
 }
 
 // StructInfo contains detailed information about each detected struct
@@ -51,6 +177,22 @@ type StructInfo struct {
 	FlagMapping     map[string]string   `json:"flag_mapping"`    // BoolField → FlagName
 	Transformations map[string]string   `json:"transformations"` // Track applied transformations
 	DefaultValues   map[string]ast.Expr `json:"default_values"`  // Default values for bool fields
+
+	FlagsType string               `json:"flags_type,omitempty"` // Integer type of the shared flags word
+	UsedBits  int                  `json:"used_bits,omitempty"`  // Bits already allocated in the flags word
+	BitFields map[string]*BitField `json:"bit_fields,omitempty"` // Field → multi-bit range in the flags word
+}
+
+// BitField describes a field packed into a bit range of the shared flags word
+type BitField struct {
+	Field  string `json:"field"`  // Original field name
+	Kind   string `json:"kind"`   // "enum", "range" or "tristate"
+	Offset int    `json:"offset"` // First bit of the range
+	Width  int    `json:"width"`  // Number of bits
+	Min    int64  `json:"min"`    // Value stored as zero
+	Max    int64  `json:"max"`    // Largest representable value
+	Getter string `json:"getter"` // Generated getter method
+	Setter string `json:"setter"` // Generated setter method
 }
 
 // NewContext creates a new transpilation context
@@ -60,6 +202,9 @@ func NewContext(inputFile, outputDir string, ofuscate bool, mapFile string) *Tra
 		MapFile:        mapFile,
 		InputFile:      inputFile,
 		OutputDir:      outputDir,
+		GOARCH:         TargetArch(),
+		SecurityLevel:  2,
+		StringPolicy:   StringPolicy{MinLength: 4, MinEntropy: 1},
 		Structs:        make(map[string]*StructInfo),
 		Flags:          make(map[string][]string),
 		GeneratedFiles: make(map[string]*ast.File), // 🚀 REVOLUTIONARY: Store transpiled files
@@ -67,6 +212,53 @@ func NewContext(inputFile, outputDir string, ofuscate bool, mapFile string) *Tra
 	}
 }
 
+// TargetArch returns $GOARCH when set, otherwise the host architecture
+func TargetArch() string {
+	if arch := os.Getenv("GOARCH"); arch != "" {
+		return arch
+	}
+	return runtime.GOARCH
+}
+
+// DeriveKey returns a key for scope derived from the build seed, drawing a
+// random seed on first use so every build encrypts differently
+func (ctx *TranspileContext) DeriveKey(scope string) [32]byte {
+	if ctx.Seed == "" {
+		var seed [16]byte
+		_, _ = rand.Read(seed[:])
+		ctx.Seed = hex.EncodeToString(seed[:])
+	}
+	return sha256.Sum256([]byte(ctx.Seed + "\x00" + scope))
+}
+
+// Sizes returns the gc size/alignment model for the target architecture
+func (ctx *TranspileContext) Sizes() types.Sizes {
+	return SizesFor(ctx.GOARCH)
+}
+
+// RegisterStructLayout records the layout analysis of a struct
+func (ctx *TranspileContext) RegisterStructLayout(layout *StructLayout) {
+	if ctx.StructLayouts == nil {
+		ctx.StructLayouts = make(map[string]*StructLayout)
+	}
+	ctx.StructLayouts[layout.Struct] = layout
+}
+
+// RegisterBitset records a []bool/[N]bool packed into a bitset
+func (ctx *TranspileContext) RegisterBitset(bitset BoolBitset) {
+	ctx.Bitsets = append(ctx.Bitsets, bitset)
+}
+
+// RegisterFmtRewrite records a fmt call replaced by FmtStrconv
+func (ctx *TranspileContext) RegisterFmtRewrite(rewrite FmtRewrite) {
+	ctx.FmtRewrites = append(ctx.FmtRewrites, rewrite)
+}
+
+// RegisterStrippedCall records a call site removed by StripCalls
+func (ctx *TranspileContext) RegisterStrippedCall(call StrippedCall) {
+	ctx.StrippedCalls = append(ctx.StrippedCalls, call)
+}
+
 // AddStruct registers a struct transformation in the context
 func (ctx *TranspileContext) AddStruct(packageName, originalName, newName string, boolFields []string, defaultValues map[string]ast.Expr) {
 	mapping := make(map[string]string)
@@ -110,6 +302,27 @@ func (ctx *TranspileContext) AddFlagMapping(structName, fieldName, flagName stri
 	ctx.Structs[structName].FlagMapping[fieldName] = flagName
 }
 
+// AllocateBits reserves width consecutive bits in the flags word of a struct
+// and returns the offset of the first one. It fails when the word would
+// exceed 64 bits.
+func (ctx *TranspileContext) AllocateBits(structName string, width int) (int, bool) {
+	info := ctx.Structs[structName]
+	if info == nil {
+		info = &StructInfo{
+			OriginalName: structName,
+			BoolFields:   []string{},
+			FlagMapping:  map[string]string{},
+		}
+		ctx.Structs[structName] = info
+	}
+	if width <= 0 || info.UsedBits+width > 64 {
+		return 0, false
+	}
+	offset := info.UsedBits
+	info.UsedBits += width
+	return offset, true
+}
+
 // GetFlagName returns the flag name for a given struct and field
 func (ctx *TranspileContext) GetFlagName(packageName, structName, fieldName string) string {
 	if structInfo, exists := ctx.Structs[structName]; exists {
@@ -174,6 +387,23 @@ func (ctx *TranspileContext) RegisterAssignToBitwise(file, field, flagName, valu
 	ctx.LogVerbose(nil, "    ⚡ AssignToBitwise: %s → %s (%s = %s)", field, flagName, field, value)
 }
 
+// RecordLedger registra no ledger o que um pass fez (ou recusou fazer) em pos
+func (ctx *TranspileContext) RecordLedger(pass string, pos token.Position, target, action, detail string) {
+	ctx.Ledger = append(ctx.Ledger, LedgerEntry{
+		Pass:   pass,
+		File:   pos.Filename,
+		Line:   pos.Line,
+		Target: target,
+		Action: action,
+		Detail: detail,
+	})
+}
+
+// RecordRename registra no map file um identificador renomeado
+func (ctx *TranspileContext) RecordRename(entry RenameEntry) {
+	ctx.Renames = append(ctx.Renames, entry)
+}
+
 // GetAssignTransformations retorna todas as transformações registradas
 func (ctx *TranspileContext) GetAssignTransformations() []AssignTransformation {
 	return ctx.AssignTransformations
@@ -208,6 +438,51 @@ func (ctx *TranspileContext) EstimatePerformance() {
 		totalFlagsGenerated += len(structInfo.BoolFields)
 	}
 
+	// Reordenação de campos (StructLayout)
+	reordered := 0
+	var bytesSaved int64
+	for _, layout := range ctx.StructLayouts {
+		if layout.BytesSaved > 0 {
+			reordered++
+			bytesSaved += layout.BytesSaved
+		}
+	}
+	if reordered > 0 {
+		gl.Log("info", fmt.Sprintf("  📐 Structs reordered: %d (-%d bytes of padding, %s)\n", reordered, bytesSaved, ctx.GOARCH))
+	}
+
+	// Arrays e slices de bool empacotados (BoolBitset)
+	if len(ctx.Bitsets) > 0 {
+		var arraysSaved int64
+		slices := 0
+		for _, b := range ctx.Bitsets {
+			if strings.HasPrefix(b.Original, "[]") {
+				slices++
+			} else {
+				arraysSaved += b.OriginalBytes - b.PackedBytes
+			}
+		}
+		gl.Log("info", fmt.Sprintf("  🧮 Bool arrays/slices packed: %d (-%d bytes in arrays, %d slices with 8x smaller storage)\n", len(ctx.Bitsets), arraysSaved, slices))
+	}
+
+	// Chamadas fmt trocadas por strconv/concatenação (FmtStrconv)
+	if len(ctx.FmtRewrites) > 0 {
+		removed := 0
+		for _, r := range ctx.FmtRewrites {
+			removed += r.AllocsBefore - r.AllocsAfter
+		}
+		gl.Log("info", fmt.Sprintf("  🧵 fmt calls rewritten: %d (~%d allocations removed per execution of all of them)\n", len(ctx.FmtRewrites), removed))
+	}
+
+	// Chamadas de log removidas (StripCalls)
+	if len(ctx.StrippedCalls) > 0 {
+		stringBytes := 0
+		for _, c := range ctx.StrippedCalls {
+			stringBytes += c.StringBytes
+		}
+		gl.Log("info", fmt.Sprintf("  🔇 Log calls stripped: %d (-%d bytes of string literals)\n", len(ctx.StrippedCalls), stringBytes))
+	}
+
 	if totalStructs == 0 {
 		gl.Log("info", "  ℹ️  No transformations found - no performance impact")
 		return
@@ -6,15 +6,15 @@ import (
 )
 
 type Info struct {
-	Types        map[ast.Expr]types.TypeAndValue
-	Instances    map[*ast.Ident]types.Object
-	Defs         map[*ast.Ident]types.Object
-	Uses         map[*ast.Ident]types.Object
-	Implicits    map[ast.Node]types.Object
-	Selections   map[*ast.SelectorExpr]*types.Selection
-	Scopes       map[ast.Node]*types.Scope
-	InitOrder    []*types.Initializer
-	FileVersions map[*ast.File]string
+	Types        map[ast.Expr]types.TypeAndValue        `json:"-"`
+	Instances    map[*ast.Ident]types.Object            `json:"-"`
+	Defs         map[*ast.Ident]types.Object            `json:"-"`
+	Uses         map[*ast.Ident]types.Object            `json:"-"`
+	Implicits    map[ast.Node]types.Object              `json:"-"`
+	Selections   map[*ast.SelectorExpr]*types.Selection `json:"-"`
+	Scopes       map[ast.Node]*types.Scope              `json:"-"`
+	InitOrder    []*types.Initializer                   `json:"-"`
+	FileVersions map[*ast.File]string                   `json:"-"`
 }
 
 func NewInfo() *Info {
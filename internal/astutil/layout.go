@@ -0,0 +1,117 @@
+package astutil
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+)
+
+// StructLayout reports the memory layout of a struct before and after field reordering
+type StructLayout struct {
+	Struct        string   `json:"struct"`
+	File          string   `json:"file"`
+	Arch          string   `json:"arch"`
+	OriginalSize  int64    `json:"original_size"`
+	OptimizedSize int64    `json:"optimized_size"`
+	BytesSaved    int64    `json:"bytes_saved"`
+	OriginalOrder []string `json:"original_order"`
+	NewOrder      []string `json:"new_order,omitempty"`
+	Skipped       string   `json:"skipped,omitempty"` // Why the struct was left untouched
+}
+
+// BoolBitset reports a []bool or [N]bool packed into a bitset by BoolBitset.
+// Arrays report the size of the value; slices report the backing storage of
+// 64 elements, since their length is only known at run time.
+type BoolBitset struct {
+	Target        string `json:"target"` // Local variable or Struct.Field
+	File          string `json:"file"`
+	Line          int    `json:"line"`
+	Original      string `json:"original"` // []bool or [N]bool
+	Packed        string `json:"packed"`   // Generated bitset type
+	OriginalBytes int64  `json:"original_bytes"`
+	PackedBytes   int64  `json:"packed_bytes"`
+}
+
+// SizesFor returns the gc sizes for arch, falling back to amd64 for unknown values
+func SizesFor(arch string) types.Sizes {
+	if sizes := types.SizesFor("gc", arch); sizes != nil {
+		return sizes
+	}
+	return types.SizesFor("gc", "amd64")
+}
+
+// OptimalFieldOrder returns the permutation of fieldTypes with the least padding.
+// Zero-sized fields go first (a trailing zero-sized field forces padding),
+// then fields are ordered by decreasing alignment and size. The sort is
+// stable, so already optimal structs keep their order.
+func OptimalFieldOrder(fieldTypes []types.Type, sizes types.Sizes) []int {
+	order := make([]int, len(fieldTypes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ta, tb := fieldTypes[order[a]], fieldTypes[order[b]]
+		za, zb := sizes.Sizeof(ta) == 0, sizes.Sizeof(tb) == 0
+		if za != zb {
+			return za
+		}
+		if aa, ab := sizes.Alignof(ta), sizes.Alignof(tb); aa != ab {
+			return aa > ab
+		}
+		return sizes.Sizeof(ta) > sizes.Sizeof(tb)
+	})
+	return order
+}
+
+// StructSize returns the size of a struct made of fieldTypes in the given order
+func StructSize(fieldTypes []types.Type, sizes types.Sizes) int64 {
+	vars := make([]*types.Var, len(fieldTypes))
+	for i, t := range fieldTypes {
+		vars[i] = types.NewField(token.NoPos, nil, fmt.Sprintf("f%d", i), t, false)
+	}
+	return sizes.Sizeof(types.NewStruct(vars, nil))
+}
+
+// ShiftPositions moves every position inside node by delta. It is used to
+// keep positions monotonic after nodes are reordered, so the printer still
+// attaches comments to the right node.
+func ShiftPositions(node ast.Node, delta int) {
+	if node == nil || delta == 0 {
+		return
+	}
+	shiftValue(reflect.ValueOf(node), token.Pos(delta))
+}
+
+var (
+	posType    = reflect.TypeOf(token.NoPos)
+	objectType = reflect.TypeOf((*ast.Object)(nil))
+	scopeType  = reflect.TypeOf((*ast.Scope)(nil))
+)
+
+func shiftValue(v reflect.Value, delta token.Pos) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() || v.Type() == objectType || v.Type() == scopeType {
+			return
+		}
+		shiftValue(v.Elem(), delta)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Field(i)
+			if f.Type() == posType {
+				if p := token.Pos(f.Int()); p.IsValid() && f.CanSet() {
+					f.SetInt(int64(p + delta))
+				}
+				continue
+			}
+			shiftValue(f, delta)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			shiftValue(v.Index(i), delta)
+		}
+	}
+}
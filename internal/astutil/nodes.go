@@ -1,6 +1,9 @@
 package astutil
 
-import "go/ast"
+import (
+	"go/ast"
+	"go/token"
+)
 
 // ReplaceNode replaces a target AST node with a new one.
 // Returns true if the replacement was applied.
@@ -21,3 +24,55 @@ func ReplaceNode(file *ast.File, target ast.Node, replacement ast.Node) bool {
 	})
 	return replaced
 }
+
+// IsSideEffectFree reports whether evaluating expr more than once (or not at
+// all) is indistinguishable from evaluating it exactly once. Run-time panics
+// (nil dereference, index out of range) are not treated as side effects.
+// Calls are only accepted when they are type conversions or constant
+// expressions; info may be nil, in which case every call is rejected.
+func IsSideEffectFree(expr ast.Expr, info *Info) bool {
+	switch v := expr.(type) {
+	case nil:
+		return true
+	case *ast.Ident, *ast.BasicLit:
+		return true
+	case *ast.ParenExpr:
+		return IsSideEffectFree(v.X, info)
+	case *ast.SelectorExpr:
+		return IsSideEffectFree(v.X, info)
+	case *ast.StarExpr:
+		return IsSideEffectFree(v.X, info)
+	case *ast.IndexExpr:
+		return IsSideEffectFree(v.X, info) && IsSideEffectFree(v.Index, info)
+	case *ast.SliceExpr:
+		return IsSideEffectFree(v.X, info) && IsSideEffectFree(v.Low, info) &&
+			IsSideEffectFree(v.High, info) && IsSideEffectFree(v.Max, info)
+	case *ast.UnaryExpr:
+		return v.Op != token.ARROW && IsSideEffectFree(v.X, info)
+	case *ast.BinaryExpr:
+		return IsSideEffectFree(v.X, info) && IsSideEffectFree(v.Y, info)
+	case *ast.KeyValueExpr:
+		return IsSideEffectFree(v.Key, info) && IsSideEffectFree(v.Value, info)
+	case *ast.CompositeLit:
+		for _, elt := range v.Elts {
+			if !IsSideEffectFree(elt, info) {
+				return false
+			}
+		}
+		return true
+	case *ast.FuncLit:
+		return true
+	case *ast.CallExpr:
+		if info == nil {
+			return false
+		}
+		if tv, ok := info.GetTypes()[v]; ok && tv.Value != nil {
+			return true
+		}
+		if tv, ok := info.GetTypes()[v.Fun]; ok && tv.IsType() && len(v.Args) == 1 {
+			return IsSideEffectFree(v.Args[0], info)
+		}
+		return false
+	}
+	return false
+}
@@ -0,0 +1,277 @@
+package astutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// HotProfile holds the per-line sample counts of a pprof CPU profile, so
+// passes can keep expensive rewrites out of hot code
+type HotProfile struct {
+	Total int64                    // Soma dos valores de todas as amostras
+	lines map[string]map[int]*hits // arquivo ("dir/base.go") → linha → contagens
+}
+
+// hits counts the samples of a source line: flat (the line was executing) and
+// cum (the line was on the stack)
+type hits struct {
+	flat, cum int64
+}
+
+// HotThreshold is the share of the total samples that makes a range hot
+const HotThreshold = 0.01
+
+// LoadProfile reads a pprof profile (gzipped or not), attributing the last
+// sample value (cpu time for CPU profiles) to the source lines of each stack
+func LoadProfile(file string) (*HotProfile, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", file, err)
+		}
+		if data, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("profile %s: %w", file, err)
+		}
+	}
+	p, err := decodeProfile(data)
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %w", file, err)
+	}
+	return p, nil
+}
+
+// Hot reports whether the lines from..to of file account for at least
+// HotThreshold of the profile, either executing or on the stack
+func (p *HotProfile) Hot(file string, from, to int) bool {
+	if p == nil || p.Total == 0 {
+		return false
+	}
+	lines := p.lines[profileKey(file)]
+	if len(lines) == 0 {
+		return false
+	}
+	limit := int64(float64(p.Total) * HotThreshold)
+	var flat, cum int64
+	for line, h := range lines {
+		if line < from || line > to {
+			continue
+		}
+		flat += h.flat
+		cum = max(cum, h.cum)
+	}
+	return flat > 0 && flat >= limit || cum > 0 && cum >= limit
+}
+
+// profileKey keeps the last directory and the base name of a path: profiles
+// carry the absolute paths of the build that produced them
+func profileKey(file string) string {
+	file = filepath.ToSlash(file)
+	return path.Join(path.Base(path.Dir(file)), path.Base(file))
+}
+
+// Campos de perftools.profiles.Profile usados aqui
+type (
+	profSample struct {
+		locations []uint64
+		values    []int64
+	}
+	profLine struct {
+		function uint64
+		line     int64
+	}
+)
+
+func decodeProfile(data []byte) (*HotProfile, error) {
+	var (
+		samples   []profSample
+		locations = make(map[uint64][]profLine)
+		functions = make(map[uint64]int64) // id → índice do nome do arquivo
+		strs      []string
+	)
+	err := protoFields(data, func(field int, v uint64, b []byte) error {
+		switch field {
+		case 2: // sample
+			var s profSample
+			err := protoFields(b, func(field int, v uint64, b []byte) error {
+				switch field {
+				case 1:
+					return protoPacked(v, b, func(x uint64) { s.locations = append(s.locations, x) })
+				case 2:
+					return protoPacked(v, b, func(x uint64) { s.values = append(s.values, int64(x)) })
+				}
+				return nil
+			})
+			samples = append(samples, s)
+			return err
+		case 4: // location
+			var id uint64
+			var lines []profLine
+			err := protoFields(b, func(field int, v uint64, b []byte) error {
+				switch field {
+				case 1:
+					id = v
+				case 4:
+					lines = append(lines, profLine{})
+					return protoFields(b, func(field int, v uint64, _ []byte) error {
+						switch field {
+						case 1:
+							lines[len(lines)-1].function = v
+						case 2:
+							lines[len(lines)-1].line = int64(v)
+						}
+						return nil
+					})
+				}
+				return nil
+			})
+			locations[id] = lines
+			return err
+		case 5: // function
+			var id uint64
+			var filename int64
+			err := protoFields(b, func(field int, v uint64, _ []byte) error {
+				switch field {
+				case 1:
+					id = v
+				case 4:
+					filename = int64(v)
+				}
+				return nil
+			})
+			functions[id] = filename
+			return err
+		case 6: // string_table
+			strs = append(strs, string(b))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p := &HotProfile{lines: make(map[string]map[int]*hits)}
+	lineHits := func(l profLine) *hits {
+		idx, ok := functions[l.function]
+		if !ok || idx <= 0 || idx >= int64(len(strs)) {
+			return nil
+		}
+		key := profileKey(strs[idx])
+		if p.lines[key] == nil {
+			p.lines[key] = make(map[int]*hits)
+		}
+		h := p.lines[key][int(l.line)]
+		if h == nil {
+			h = &hits{}
+			p.lines[key][int(l.line)] = h
+		}
+		return h
+	}
+	for _, s := range samples {
+		if len(s.values) == 0 {
+			continue
+		}
+		value := s.values[len(s.values)-1]
+		p.Total += value
+		seen := make(map[*hits]bool) // recursão conta uma vez por amostra
+		for i, loc := range s.locations {
+			for j, l := range locations[loc] {
+				h := lineHits(l)
+				if h == nil {
+					continue
+				}
+				// A primeira linha do primeiro local é a folha (as seguintes são inlining)
+				if i == 0 && j == 0 {
+					h.flat += value
+				}
+				if !seen[h] {
+					seen[h] = true
+					h.cum += value
+				}
+			}
+		}
+	}
+	return p, nil
+}
+
+// protoFields walks the fields of a protobuf message, passing the value of
+// varint/fixed fields in v and the payload of length-delimited fields in b
+func protoFields(data []byte, fn func(field int, v uint64, b []byte) error) error {
+	for len(data) > 0 {
+		tag, n := protoVarint(data)
+		if n == 0 {
+			return errors.New("malformed protobuf tag")
+		}
+		data = data[n:]
+		field := int(tag >> 3)
+		var v uint64
+		var b []byte
+		switch tag & 7 {
+		case 0:
+			if v, n = protoVarint(data); n == 0 {
+				return errors.New("malformed protobuf varint")
+			}
+		case 1:
+			if len(data) < 8 {
+				return errors.New("truncated protobuf fixed64")
+			}
+			n = 8
+		case 2:
+			size, m := protoVarint(data)
+			if m == 0 || uint64(len(data)-m) < size {
+				return errors.New("truncated protobuf field")
+			}
+			b, n = data[m:m+int(size)], m+int(size)
+		case 5:
+			if len(data) < 4 {
+				return errors.New("truncated protobuf fixed32")
+			}
+			n = 4
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", tag&7)
+		}
+		data = data[n:]
+		if err := fn(field, v, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// protoPacked decodes a repeated varint field, packed (b) or not (v)
+func protoPacked(v uint64, b []byte, fn func(uint64)) error {
+	if b == nil {
+		fn(v)
+		return nil
+	}
+	for len(b) > 0 {
+		x, n := protoVarint(b)
+		if n == 0 {
+			return errors.New("malformed packed protobuf field")
+		}
+		fn(x)
+		b = b[n:]
+	}
+	return nil
+}
+
+// protoVarint decodes a varint, returning 0 bytes read when data is malformed
+func protoVarint(data []byte) (uint64, int) {
+	var x uint64
+	for i := 0; i < len(data) && i < 10; i++ {
+		x |= uint64(data[i]&0x7f) << (7 * i)
+		if data[i] < 0x80 {
+			return x, i + 1
+		}
+	}
+	return 0, 0
+}
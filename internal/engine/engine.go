@@ -27,6 +27,15 @@ type TranspilePass interface {
 	Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error
 }
 
+// PackagePass is implemented by passes that need to inspect every file of a
+// package before any of them is rewritten (e.g. to prove a field is never
+// address-taken). Prepare runs once per package, right before Apply is called
+// on each of its files.
+type PackagePass interface {
+	TranspilePass
+	Prepare(files []*ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error
+}
+
 // DiscoverGoFiles discovers all Go files recursively
 func DiscoverGoFiles(root string) ([]string, error) {
 	var files []string
@@ -55,11 +64,14 @@ func (e *Engine) AddPass(pass TranspilePass) {
 	e.Passes = append(e.Passes, pass)
 }
 
-// Run executes the engine on the specified root path
+// Run executes the engine on the specified root path.
+// Files are grouped by package and type-checked together, then every pass
+// runs over the whole package before the next one starts, so a pass always
+// sees the package in the state left by the previous pass.
 func (e *Engine) Run(root string) error {
 	files, err := DiscoverGoFiles(root)
 	if err != nil {
-		gl.Log("error", fmt.Sprintf("failed to discover Go files: %w", err))
+		gl.Log("error", fmt.Sprintf("failed to discover Go files: %v", err))
 		return fmt.Errorf("failed to discover Go files: %w", err)
 	}
 
@@ -68,34 +80,35 @@ func (e *Engine) Run(root string) error {
 	processedFiles := 0
 	transformedFiles := 0
 
-	for _, filePath := range files {
-		gl.Log("info", fmt.Sprintf("🔍 Processing %s\n", filePath))
-		// 🚀 REVOLUTIONARY: Use shared FileSet from context
-		astFile, err := parser.ParseFile(e.Ctx.Fset, filePath, nil, parser.ParseComments)
-		if err != nil {
-			gl.Log("error", fmt.Sprintf("  ⚠️  Failed to parse %s: %v\n", filePath, err))
-			continue
-		}
+	for _, pkg := range e.parsePackages(files) {
+		_ = e.typeCheckPackage(pkg.Files)
 
-		fileTransformed := false
 		for _, pass := range e.Passes {
-			gl.Log("info", fmt.Sprintf("  ⚙️  Applying pass: %s\n", pass.Name()))
-			// 🚀 REVOLUTIONARY: Use shared FileSet in passes
-			if err := pass.Apply(astFile, e.Ctx.Fset, e.Ctx); err != nil {
-				gl.Log("error", fmt.Sprintf("  ⚠️  Pass %s failed on %s: %v\n", pass.Name(), filePath, err))
-				return fmt.Errorf("pass %s failed on %s: %w", pass.Name(), filePath, err)
+			gl.Log("info", fmt.Sprintf("  ⚙️  Applying pass: %s (%s)\n", pass.Name(), pkg.Dir))
+			if pp, ok := pass.(PackagePass); ok {
+				if err := pp.Prepare(pkg.Files, e.Ctx.Fset, e.Ctx); err != nil {
+					gl.Log("error", fmt.Sprintf("  ⚠️  Pass %s failed to prepare %s: %v\n", pass.Name(), pkg.Dir, err))
+					return fmt.Errorf("pass %s failed to prepare %s: %w", pass.Name(), pkg.Dir, err)
+				}
+			}
+			for i, astFile := range pkg.Files {
+				// 🚀 REVOLUTIONARY: Use shared FileSet in passes
+				if err := pass.Apply(astFile, e.Ctx.Fset, e.Ctx); err != nil {
+					gl.Log("error", fmt.Sprintf("  ⚠️  Pass %s failed on %s: %v\n", pass.Name(), pkg.Paths[i], err))
+					return fmt.Errorf("pass %s failed on %s: %w", pass.Name(), pkg.Paths[i], err)
+				}
 			}
-			fileTransformed = true
 		}
 
-		if fileTransformed {
-			transformedFiles++
-			// 🚀 REVOLUTIONARY: Store transformed files for OutputManager
-			e.Ctx.GeneratedFiles[filePath] = astFile
-			gl.Log("info", fmt.Sprintf("  ✅ File transformed and stored: %s\n", filePath))
+		for i, astFile := range pkg.Files {
+			if len(e.Passes) > 0 {
+				transformedFiles++
+				// 🚀 REVOLUTIONARY: Store transformed files for OutputManager
+				e.Ctx.GeneratedFiles[pkg.Paths[i]] = astFile
+				gl.Log("info", fmt.Sprintf("  ✅ File transformed and stored: %s\n", pkg.Paths[i]))
+			}
+			processedFiles++
 		}
-
-		processedFiles++
 	}
 
 	gl.Log("info", fmt.Sprintf("📊 Engine summary: %d files processed, %d transformed\n", processedFiles, transformedFiles))
@@ -104,7 +117,7 @@ func (e *Engine) Run(root string) error {
 	// Save context map if configured
 	if e.Ctx.MapFile != "" {
 		if err := e.Ctx.SaveMap(); err != nil {
-			gl.Log("error", fmt.Sprintf("failed to save context map: %w", err))
+			gl.Log("error", fmt.Sprintf("failed to save context map: %v", err))
 			return fmt.Errorf("failed to save context map: %w", err)
 		}
 		gl.Log("info", fmt.Sprintf("📋 Context map saved: %s\n", e.Ctx.MapFile))
@@ -113,6 +126,44 @@ func (e *Engine) Run(root string) error {
 	return nil
 }
 
+// sourcePackage groups the parsed files that belong to the same package
+type sourcePackage struct {
+	Dir   string
+	Name  string
+	Paths []string
+	Files []*ast.File
+}
+
+// parsePackages parses every file and groups them by directory and package name,
+// preserving discovery order.
+func (e *Engine) parsePackages(files []string) []*sourcePackage {
+	var pkgs []*sourcePackage
+	index := make(map[string]*sourcePackage)
+
+	for _, filePath := range files {
+		gl.Log("info", fmt.Sprintf("🔍 Processing %s\n", filePath))
+		// 🚀 REVOLUTIONARY: Use shared FileSet from context
+		astFile, err := parser.ParseFile(e.Ctx.Fset, filePath, nil, parser.ParseComments)
+		if err != nil {
+			gl.Log("error", fmt.Sprintf("  ⚠️  Failed to parse %s: %v\n", filePath, err))
+			continue
+		}
+
+		dir := filepath.Dir(filePath)
+		key := dir + "|" + astFile.Name.Name
+		pkg, ok := index[key]
+		if !ok {
+			pkg = &sourcePackage{Dir: dir, Name: astFile.Name.Name}
+			index[key] = pkg
+			pkgs = append(pkgs, pkg)
+		}
+		pkg.Paths = append(pkg.Paths, filePath)
+		pkg.Files = append(pkg.Files, astFile)
+	}
+
+	return pkgs
+}
+
 // GetPassByName returns a pass by its name
 func (e *Engine) GetPassByName(name string) TranspilePass {
 	for _, pass := range e.Passes {
@@ -15,6 +15,14 @@ import (
 // typeCheckFile popula ctx.Info (types/selections/scopes) para o arquivo atual.
 // Chame isso logo após parser.ParseFile(...) dentro do loop do Engine.Run.
 func (e *Engine) typeCheckFile(astFile *ast.File) error {
+	return e.typeCheckPackage([]*ast.File{astFile})
+}
+
+// typeCheckPackage popula ctx.Info para todos os arquivos de um mesmo pacote
+// e registra o *types.Package resultante em ctx.Package.
+// Erros de tipo não interrompem o pipeline: o checker continua e os passes
+// trabalham com a informação parcial disponível.
+func (e *Engine) typeCheckPackage(files []*ast.File) error {
 	if e.Ctx.Info == nil {
 		e.Ctx.Info = astutil.NewInfo()
 	}
@@ -22,14 +30,20 @@ func (e *Engine) typeCheckFile(astFile *ast.File) error {
 		Types:      e.Ctx.GetTypes(),
 		Defs:       e.Ctx.GetDefs(),
 		Uses:       e.Ctx.GetUses(),
+		Implicits:  e.Ctx.GetImplicits(),
 		Selections: e.Ctx.GetSelections(),
 		Scopes:     e.Ctx.GetScopes(),
 	}
-	conf := types.Config{Importer: importer.Default()}
-	_, err := conf.Check("", e.Ctx.Fset, []*ast.File{astFile}, info)
-	if err != nil {
+	var typeErrs []error
+	conf := types.Config{
+		Importer: importer.Default(),
+		Error:    func(err error) { typeErrs = append(typeErrs, err) },
+	}
+	pkg, _ := conf.Check("", e.Ctx.Fset, files, info)
+	e.Ctx.Package = pkg
+	if len(typeErrs) > 0 {
 		// não quebra pipeline; só loga o warning
-		gl.Log("warn", fmt.Sprintf("type-check warnings: %v", err))
+		gl.Log("warn", fmt.Sprintf("type-check warnings: %v", typeErrs[0]))
 	}
 	return nil
 }
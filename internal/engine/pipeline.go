@@ -21,10 +21,44 @@ func DefaultPipeline(passes string) []TranspilePass {
 			selected = append(selected, pass.NewAssignToBitwisePass())
 		case "field2bitwise", "field-to-bitwise":
 			selected = append(selected, pass.NewFieldAccessToBitwisePass())
+		case "mapset", "map-set":
+			selected = append(selected, pass.NewMapSetPass())
+		case "bitset", "bool-bitset":
+			selected = append(selected, pass.NewBoolBitsetPass())
+		case "boolparams", "bool-params":
+			selected = append(selected, pass.NewBoolParamsPass())
+		case "boolresults", "bool-results":
+			selected = append(selected, pass.NewBoolResultsPass())
+		case "fmtstrconv", "fmt-to-strconv":
+			selected = append(selected, pass.NewFmtStrconvPass())
+		case "loopalloc", "loop-alloc":
+			selected = append(selected, pass.NewLoopAllocPass())
+		case "hoist", "hoist-compile":
+			selected = append(selected, pass.NewHoistCompilePass())
+		case "romap", "readonly-map":
+			selected = append(selected, pass.NewReadOnlyMapPass())
+		case "strip", "strip-calls":
+			selected = append(selected, pass.NewStripCallsPass())
+		case "fmtelim", "fmt-eliminate":
+			selected = append(selected, pass.NewFmtEliminatePass())
 		case "stringobf", "string-obfuscate":
 			selected = append(selected, pass.NewStringObfuscatePass())
 		case "jumptable", "jump-table":
 			selected = append(selected, pass.NewJumpTablePass())
+		case "bitfieldpack", "bitfield-pack":
+			selected = append(selected, pass.NewBitfieldPackPass())
+		case "perfecthash", "perfect-hash":
+			selected = append(selected, pass.NewPerfectHashPass())
+		case "structlayout", "struct-layout":
+			selected = append(selected, pass.NewStructLayoutPass())
+		case "mba", "mixed-bool-arith":
+			selected = append(selected, pass.NewMixedBoolArithPass())
+		case "opaque", "opaque-predicates":
+			selected = append(selected, pass.NewOpaquePredicatePass())
+		case "flatten", "control-flow-flatten":
+			selected = append(selected, pass.NewFlattenPass())
+		case "rename", "rename-idents":
+			selected = append(selected, pass.NewRenamePass())
 		}
 	}
 
@@ -35,11 +69,26 @@ func DefaultPipeline(passes string) []TranspilePass {
 func ProcessPipeline() []TranspilePass {
 	return []TranspilePass{
 		pass.NewBoolToFlagsPass(),          // Convert bool fields to bitwise flags
+		pass.NewBitfieldPackPass(),         // Pack small enums/ranges/tri-states into the flags word
+		pass.NewStructLayoutPass(),         // Reorder struct fields to minimize padding
 		pass.NewIfToBitwisePass(),          // Convert bool conditions to bitwise checks
 		pass.NewAssignToBitwisePass(),      // Convert bool assignments to bitwise operations
 		pass.NewFieldAccessToBitwisePass(), // 🚀 REVOLUTIONARY: Convert field access to bitwise checks
+		pass.NewMapSetPass(),               // Turn constant-key map[string]bool sets into bitsets
+		pass.NewBoolBitsetPass(),           // Pack []bool/[N]bool into []uint64-backed bitsets
+		pass.NewBoolParamsPass(),           // Merge bool parameter lists into one flag parameter
+		pass.NewBoolResultsPass(),          // Return a single flag mask instead of several bools
+		pass.NewFmtStrconvPass(),           // Replace simple fmt.Sprintf/Sprint with strconv and concatenation
+		pass.NewLoopAllocPass(),            // strings.Builder for s += x in loops, preallocate appends in range loops
+		pass.NewHoistCompilePass(),         // Hoist constant regexp/template/replacer constructors to package vars
+		pass.NewReadOnlyMapPass(),          // Turn read-only literal lookup maps into switch functions or arrays
 		pass.NewStringObfuscatePass(),      // Obfuscate string literals
+		pass.NewPerfectHashPass(),          // Perfect-hash dispatch for large string switches
 		pass.NewJumpTablePass(),            // Optimize if-chains to jump tables
+		pass.NewMixedBoolArithPass(),       // Hide integer constants and arithmetic behind MBA identities
+		pass.NewOpaquePredicatePass(),      // Insert opaque predicates guarding decoy blocks
+		pass.NewFlattenPass(),              // Flatten the control flow of //gastype:flatten functions
+		pass.NewRenamePass(),               // Rename identifiers (last: the other passes match original names)
 	}
 }
 
@@ -50,7 +99,24 @@ func GetAvailablePasses() []string {
 		"if2bitwise",
 		"assign2bitwise",
 		"field2bitwise",
+		"mapset",
+		"bitset",
+		"boolparams",
+		"boolresults",
+		"fmtstrconv",
+		"fmtelim",
+		"strip",
+		"loopalloc",
+		"hoist",
+		"romap",
 		"stringobf",
 		"jumptable",
+		"perfecthash",
+		"bitfieldpack",
+		"structlayout",
+		"mba",
+		"opaque",
+		"flatten",
+		"rename",
 	}
 }
@@ -4,12 +4,16 @@ package transpiler
 import (
 	"fmt"
 	"go/ast"
+	"go/importer"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+
 	gl "github.com/kubex-ecosystem/logz/logger"
 )
 
@@ -90,9 +94,76 @@ func (bt *BitwiseTranspiler) AnalyzeFile(filename string) (*TranspilationResult,
 		result.SecurityFeatures = append(result.SecurityFeatures, security)
 	}
 
+	// Structs com padding evitável
+	for _, layout := range bt.analyzeFileLayouts(filename) {
+		result.Optimizations = append(result.Optimizations, Optimization{
+			Type:          "FieldReordering",
+			Description:   fmt.Sprintf("Reorder fields of struct %s: %d → %d bytes on %s", layout.Struct, layout.OriginalSize, layout.OptimizedSize, layout.Arch),
+			Location:      layout.File,
+			BytesSaved:    int(layout.BytesSaved),
+			SpeedupFactor: 1.0,
+		})
+	}
+
 	return result, nil
 }
 
+// analyzeFileLayouts retorna os structs do arquivo cuja ordem de campos desperdiça padding
+func (bt *BitwiseTranspiler) analyzeFileLayouts(filename string) []*astutil.StructLayout {
+	node, err := parser.ParseFile(bt.fset, filename, nil, parser.ParseComments)
+	if err != nil {
+		return nil
+	}
+
+	info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+	conf := types.Config{Importer: importer.Default(), Error: func(error) {}}
+	_, _ = conf.Check("", bt.fset, []*ast.File{node}, info)
+
+	arch := astutil.TargetArch()
+	sizes := astutil.SizesFor(arch)
+	var layouts []*astutil.StructLayout
+	ast.Inspect(node, func(n ast.Node) bool {
+		ts, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		st, ok := ts.Type.(*ast.StructType)
+		if !ok {
+			return true
+		}
+
+		var fieldTypes []types.Type
+		for _, field := range st.Fields.List {
+			tv, ok := info.Types[field.Type]
+			if !ok || tv.Type == nil {
+				return true
+			}
+			for i := 0; i < max(len(field.Names), 1); i++ {
+				fieldTypes = append(fieldTypes, tv.Type)
+			}
+		}
+
+		order := astutil.OptimalFieldOrder(fieldTypes, sizes)
+		optimized := make([]types.Type, len(order))
+		for i, idx := range order {
+			optimized[i] = fieldTypes[idx]
+		}
+		original, best := astutil.StructSize(fieldTypes, sizes), astutil.StructSize(optimized, sizes)
+		if best < original {
+			layouts = append(layouts, &astutil.StructLayout{
+				Struct:        ts.Name.Name,
+				File:          fmt.Sprintf("%s:%d", filename, bt.fset.Position(ts.Pos()).Line),
+				Arch:          arch,
+				OriginalSize:  original,
+				OptimizedSize: best,
+				BytesSaved:    original - best,
+			})
+		}
+		return true
+	})
+	return layouts
+}
+
 // AnalyzeProject analisa um projeto inteiro (diretório)
 func (bt *BitwiseTranspiler) AnalyzeProject(projectDir string) ([]TranspilationResult, error) {
 	var results []TranspilationResult
@@ -2,18 +2,34 @@
 package pass
 
 import (
+	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
 
 	"github.com/kubex-ecosystem/gastype/internal/astutil"
+	stdastutil "golang.org/x/tools/go/ast/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
 )
 
 // AssignToBitwisePass converts bool field assignments to bitwise flag operations.
+// Fields are resolved through the type-checked selection, so only structs
+// converted by BoolToFlags are touched, whatever the receiver looks like.
 // Examples:
 //
-//	cfg.Debug = true  →  cfg.flags |= FlagDebug
-//	cfg.Debug = false →  cfg.flags &^= FlagDebug
-type AssignToBitwisePass struct{}
+//	cfg.Debug = true          →  cfg.flags |= FlagConfig_Debug
+//	cfg.Debug = false         →  cfg.flags &^= FlagConfig_Debug
+//	cfg.Debug = !cfg.Debug    →  cfg.flags ^= FlagConfig_Debug
+//	s.cfgs[i].Debug = enabled →  s.cfgs[i].flags ^= (-uint8(boolBit(enabled)) ^ s.cfgs[i].flags) & FlagConfig_Debug
+//
+// Non-constant values use the branch-free conditional set/clear
+// `w ^= (-b ^ w) & mask`. Multi-assignments are split after evaluating
+// every right-hand side into temporaries, preserving Go's semantics.
+type AssignToBitwisePass struct {
+	bitHelper string // nome do helper bool → 0/1 para o pacote atual
+	emitted   bool
+}
 
 func NewAssignToBitwisePass() *AssignToBitwisePass {
 	return &AssignToBitwisePass{}
@@ -23,67 +39,173 @@ func (p *AssignToBitwisePass) Name() string {
 	return "AssignToBitwise"
 }
 
+// Prepare picks a package-unique name for the bool → bit helper
+func (p *AssignToBitwisePass) Prepare(_ []*ast.File, _ *token.FileSet, ctx *astutil.TranspileContext) error {
+	p.bitHelper = freshPackageName(ctx.Package, "boolBit")
+	p.emitted = false
+	return nil
+}
+
 func (p *AssignToBitwisePass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	if p.bitHelper == "" {
+		p.bitHelper = freshPackageName(ctx.Package, "boolBit")
+	}
 	transformations := 0
+	needsHelper := false
+	filename := fset.Position(file.Pos()).Filename
 
-	ast.Inspect(file, func(n ast.Node) bool {
-		as, ok := n.(*ast.AssignStmt)
-		if !ok || len(as.Lhs) != 1 || len(as.Rhs) != 1 {
+	stdastutil.Apply(file, nil, func(c *stdastutil.Cursor) bool {
+		as, ok := c.Node().(*ast.AssignStmt)
+		if !ok || as.Tok != token.ASSIGN {
 			return true
 		}
 
-		// Checa se LHS é algo como cfg.Debug
-		sel, ok := as.Lhs[0].(*ast.SelectorExpr)
-		if !ok {
-			return true
+		refs := make([]*flagRef, len(as.Lhs))
+		found := false
+		for i, lhs := range as.Lhs {
+			if sel, ok := lhs.(*ast.SelectorExpr); ok {
+				refs[i] = resolveFlagRef(sel, ctx)
+				found = found || refs[i] != nil
+			}
 		}
-		ident, ok := sel.X.(*ast.Ident)
-		if !ok {
+		if !found {
 			return true
 		}
 
-		// Checa se RHS é literal true/false
-		valIdent, ok := as.Rhs[0].(*ast.Ident)
-		if !ok {
+		// === 1️⃣ Atribuição simples: cfg.X = v ===
+		if len(as.Lhs) == 1 && len(as.Rhs) == 1 {
+			stmt, kind := p.flagWrite(refs[0], as.Rhs[0], ctx)
+			if stmt == nil {
+				gl.Log("info", fmt.Sprintf("AssignToBitwise: skipping %s (receiver has side effects)", types.ExprString(as.Lhs[0])))
+				return true
+			}
+			needsHelper = needsHelper || kind == "value"
+			c.Replace(stmt)
+			ctx.RegisterAssignToBitwise(filename, refs[0].Field, refs[0].Flag, kind)
+			transformations++
 			return true
 		}
 
-		// Procura se o campo é um mapeado para flag
-		for _, info := range ctx.Structs {
-			if flagName, exists := info.FlagMapping[sel.Sel.Name]; exists {
-				// Substitui o campo pelo campo "flags"
-				as.Lhs[0] = &ast.SelectorExpr{
-					X:   ident,
-					Sel: ast.NewIdent("flags"),
-				}
-
-				switch valIdent.Name {
-				case "true":
-					as.Tok = token.OR_ASSIGN
-					as.Rhs[0] = ast.NewIdent(flagName)
-				case "false":
-					as.Tok = token.AND_NOT_ASSIGN
-					as.Rhs[0] = ast.NewIdent(flagName)
-				default:
-					return true // não é booleano simples
-				}
-
-				transformations++
-
-				// Registra no contexto para relatórios
-				pos := fset.Position(as.Pos())
-				ctx.RegisterAssignToBitwise(pos.Filename, sel.Sel.Name, flagName, valIdent.Name)
-
-				break
+		// === 2️⃣ Multi-atribuição: avalia os RHS em temporários e atribui em ordem ===
+		if c.Index() < 0 || !p.canSplit(as, refs, ctx) {
+			gl.Log("info", fmt.Sprintf("AssignToBitwise: skipping multi-assignment at %s", fset.Position(as.Pos())))
+			return true
+		}
+		temps := freshLocalNames(file, "bv", len(as.Lhs))
+		lhsTemps := make([]ast.Expr, len(temps))
+		for i, name := range temps {
+			lhsTemps[i] = ast.NewIdent(name)
+		}
+		block := &ast.BlockStmt{List: []ast.Stmt{
+			&ast.AssignStmt{Lhs: lhsTemps, Tok: token.DEFINE, Rhs: as.Rhs},
+		}}
+		for i, lhs := range as.Lhs {
+			value := ast.NewIdent(temps[i])
+			if refs[i] == nil {
+				block.List = append(block.List, &ast.AssignStmt{Lhs: []ast.Expr{lhs}, Tok: token.ASSIGN, Rhs: []ast.Expr{value}})
+				continue
 			}
+			stmt, _ := p.flagWrite(refs[i], value, ctx)
+			block.List = append(block.List, stmt)
+			ctx.RegisterAssignToBitwise(filename, refs[i].Field, refs[i].Flag, "value")
+			needsHelper = true
+			transformations++
 		}
-
+		c.Replace(block)
 		return true
 	})
 
+	if needsHelper && !p.emitted {
+		decls, err := astutil.ParseDecls(fset, fmt.Sprintf(boolBitHelper, p.bitHelper))
+		if err != nil {
+			return fmt.Errorf("AssignToBitwise: %w", err)
+		}
+		file.Decls = append(file.Decls, decls...)
+		p.emitted = true
+	}
+
 	if transformations > 0 {
-		ctx.LogVerbose(nil, "🔄 AssignToBitwisePass: %d assignments converted", transformations)
+		ctx.LogVerbose(fset, "🔄 AssignToBitwisePass: %d assignments converted", transformations)
 	}
 
 	return nil
 }
+
+// flagWrite builds the bitwise statement for ref = value. kind is "true",
+// "false", "toggle" or "value". It returns nil when the receiver would be
+// evaluated twice and has side effects.
+func (p *AssignToBitwisePass) flagWrite(ref *flagRef, value ast.Expr, ctx *astutil.TranspileContext) (ast.Stmt, string) {
+	op := func(tok token.Token, rhs ast.Expr) ast.Stmt {
+		return &ast.AssignStmt{Lhs: []ast.Expr{ref.Flags}, Tok: tok, Rhs: []ast.Expr{rhs}}
+	}
+
+	if v, ok := boolConst(value, ctx); ok {
+		if v {
+			return op(token.OR_ASSIGN, ast.NewIdent(ref.Flag)), "true"
+		}
+		return op(token.AND_NOT_ASSIGN, ast.NewIdent(ref.Flag)), "false"
+	}
+
+	// cfg.X = !cfg.X → toggle
+	if not, ok := ast.Unparen(value).(*ast.UnaryExpr); ok && not.Op == token.NOT {
+		if flags, flag, ok := flagTestOf(not.X, ctx); ok && flag == ref.Flag && sameExpr(flags, ref.Flags, ctx) {
+			return op(token.XOR_ASSIGN, ast.NewIdent(ref.Flag)), "toggle"
+		}
+	}
+
+	// w ^= (-b ^ w) & mask, sem desvios
+	if !astutil.IsSideEffectFree(ref.Flags, ctx.Info) {
+		return nil, ""
+	}
+	bit := &ast.UnaryExpr{Op: token.SUB, X: &ast.CallExpr{
+		Fun:  ast.NewIdent(ref.FlagsType),
+		Args: []ast.Expr{&ast.CallExpr{Fun: ast.NewIdent(p.bitHelper), Args: []ast.Expr{value}}},
+	}}
+	return op(token.XOR_ASSIGN, &ast.BinaryExpr{
+		X:  &ast.ParenExpr{X: &ast.BinaryExpr{X: bit, Op: token.XOR, Y: ref.Flags}},
+		Op: token.AND,
+		Y:  ast.NewIdent(ref.Flag),
+	}), "value"
+}
+
+// canSplit reports whether a multi-assignment can be rewritten as a sequence
+// of single assignments. The left-hand operands (receivers, indexes) must not
+// have side effects nor read a variable assigned by an earlier element.
+func (p *AssignToBitwisePass) canSplit(as *ast.AssignStmt, refs []*flagRef, ctx *astutil.TranspileContext) bool {
+	assigned := make(map[types.Object]bool)
+	for i, lhs := range as.Lhs {
+		if id, ok := lhs.(*ast.Ident); ok {
+			if obj := ctx.GetUses()[id]; obj != nil {
+				assigned[obj] = true
+			}
+			continue
+		}
+		if !astutil.IsSideEffectFree(lhs, ctx.Info) {
+			return false
+		}
+		if refs[i] != nil && !astutil.IsSideEffectFree(refs[i].Flags, ctx.Info) {
+			return false
+		}
+		conflict := false
+		ast.Inspect(lhs, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && assigned[ctx.GetUses()[id]] {
+				conflict = true
+			}
+			return !conflict
+		})
+		if conflict {
+			return false
+		}
+	}
+	return true
+}
+
+const boolBitHelper = `// %[1]s converte b em 0 ou 1 sem desvio (o compilador gera SETcc/CSET)
+func %[1]s(b bool) uint64 {
+	var n uint64
+	if b {
+		n = 1
+	}
+	return n
+}
+`
@@ -0,0 +1,275 @@
+package pass
+
+import (
+	"flag"
+	"fmt"
+	"go/format"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+)
+
+//go:generate go test -run TestBenchFixtures -update
+
+var update = flag.Bool("update", false, "rewrite the generated benchmark fixtures")
+
+// benchFixtures maps each generated benchmark file to the function that
+// writes it. The files are committed so `go test -bench` needs no setup;
+// TestBenchFixtures keeps them in sync with the generators.
+var benchFixtures = map[string]func() string{
+	"jump_table_bench_test.go":   jumpTableBenchSource,
+	"perfect_hash_bench_test.go": perfectHashBenchSource,
+}
+
+func TestBenchFixtures(t *testing.T) {
+	for name, gen := range benchFixtures {
+		src, err := format.Source([]byte(gen()))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if *update {
+			if err := os.WriteFile(name, src, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(src) {
+			t.Errorf("%s is stale; run go generate ./internal/pass", name)
+		}
+	}
+}
+
+// benchHeader opens a generated benchmark file
+func benchHeader(doc string) *strings.Builder {
+	var b strings.Builder
+	b.WriteString("// Code generated by TestBenchFixtures; DO NOT EDIT.\n\npackage pass\n\nimport \"testing\"\n\n")
+	b.WriteString(doc)
+	return &b
+}
+
+// benchWords returns n distinct random keys of minLen to maxLen bytes from alphabet
+func benchWords(rnd *rand.Rand, n, minLen, maxLen int, alphabet string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for len(keys) < n {
+		b := make([]byte, minLen+rnd.Intn(maxLen-minLen+1))
+		for i := range b {
+			b[i] = alphabet[rnd.Intn(len(alphabet))]
+		}
+		if k := string(b); !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// benchIf writes `func name(k T) int` as an if-chain returning 3i+1 for the
+// i-th key, counting from 1
+func benchIf(b *strings.Builder, name, typ string, keys []string) {
+	fmt.Fprintf(b, "func %s(k %s) int {\n\t", name, typ)
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString(" else ")
+		}
+		fmt.Fprintf(b, "if k == %s {\n\t\treturn %d\n\t}", k, 3*i+4)
+	}
+	b.WriteString("\n\treturn 0\n}\n\n")
+}
+
+// benchSwitch writes the same lookup as benchIf as a switch on tag
+func benchSwitch(b *strings.Builder, name, typ, tag string, keys []string) {
+	fmt.Fprintf(b, "func %s(k %s) int {\n\tswitch %s {\n", name, typ, tag)
+	for i, k := range keys {
+		fmt.Fprintf(b, "\tcase %s:\n\t\treturn %d\n", k, 3*i+4)
+	}
+	b.WriteString("\t}\n\treturn 0\n}\n\n")
+}
+
+// branchNumbers returns "1", "2", ... "n", the cases of a dispatch switch
+func branchNumbers(n int) []string {
+	cases := make([]string, n)
+	for i := range cases {
+		cases[i] = fmt.Sprint(i + 1)
+	}
+	return cases
+}
+
+func quoted(keys []string) []string {
+	q := make([]string, len(keys))
+	for i, k := range keys {
+		q[i] = fmt.Sprintf("%q", k)
+	}
+	return q
+}
+
+// jumpTableBenchSource writes jump_table_bench_test.go: each lookup in the
+// forms JumpTablePass starts from (if-chain, switch) and emits (array or map
+// index feeding a switch over branch numbers)
+func jumpTableBenchSource() string {
+	b := benchHeader(`// Benchmarks behind the jumpTableMinDenseKeys and jumpTableMinMapKeys
+// thresholds: each form is the code JumpTablePass starts from (if-chain,
+// switch) or emits (array or map index feeding a switch over branch numbers).
+//
+//	go test -run '^$' -bench JumpTable ./internal/pass
+
+`)
+	dense := []int{4, 8, 16, 32}
+	for _, n := range dense {
+		keys := branchNumbers(n)
+		benchIf(b, fmt.Sprintf("jtIfInt%d", n), "int", keys)
+		benchSwitch(b, fmt.Sprintf("jtSwitchInt%d", n), "int", "k", keys)
+		var elts []string
+		for _, k := range keys {
+			elts = append(elts, k+": "+k)
+		}
+		fmt.Fprintf(b, "var jtMapInt%d = map[int]int{%s}\n\n", n, strings.Join(elts, ", "))
+		benchSwitch(b, fmt.Sprintf("jtMapIndexInt%d", n), "int", fmt.Sprintf("jtMapInt%d[k]", n), keys)
+		fmt.Fprintf(b, "var jtArrayInt%d = [%d]uint8{%s}\n\n", n, n, strings.Join(keys, ", "))
+		fmt.Fprintf(b, "func jtArrayIndexInt%d(k int) int {\n\tvar idx int\n", n)
+		fmt.Fprintf(b, "\tif i := uint64(k - 1); i < uint64(len(jtArrayInt%[1]d)) {\n\t\tidx = int(jtArrayInt%[1]d[i])\n\t}\n", n)
+		b.WriteString("\tswitch idx {\n")
+		for i, k := range keys {
+			fmt.Fprintf(b, "\tcase %s:\n\t\treturn %d\n", k, 3*i+4)
+		}
+		b.WriteString("\t}\n\treturn 0\n}\n\n")
+	}
+
+	rnd := rand.New(rand.NewSource(7))
+	sizes := []int{16, 32, 48, 64}
+	for _, n := range sizes {
+		keys := quoted(benchWords(rnd, n, 3, 10, "abcdefghijklmnopqrstuvwxyz"))
+		fmt.Fprintf(b, "var jtStringKeys%d = []string{%s}\n\n", n, strings.Join(keys, ", "))
+		benchIf(b, fmt.Sprintf("jtIfString%d", n), "string", keys)
+		benchSwitch(b, fmt.Sprintf("jtSwitchString%d", n), "string", "k", keys)
+		var elts []string
+		for i, k := range keys {
+			elts = append(elts, fmt.Sprintf("%s: %d", k, i+1))
+		}
+		fmt.Fprintf(b, "var jtMapString%d = map[string]int{%s}\n\n", n, strings.Join(elts, ", "))
+		benchSwitch(b, fmt.Sprintf("jtMapIndexString%d", n), "string", fmt.Sprintf("jtMapString%d[k]", n), branchNumbers(n))
+	}
+
+	b.WriteString("func BenchmarkJumpTableInt(b *testing.B) {\n")
+	for _, n := range dense {
+		for _, form := range []string{"if/jtIf", "switch/jtSwitch", "map/jtMapIndex", "array/jtArrayIndex"} {
+			label, fn, _ := strings.Cut(form, "/")
+			fmt.Fprintf(b, "\tb.Run(\"dense%[1]d/%[2]s\", func(b *testing.B) { benchJumpInt(b, %[1]d, %[3]sInt%[1]d) })\n", n, label, fn)
+		}
+	}
+	b.WriteString("}\n\nfunc BenchmarkJumpTableString(b *testing.B) {\n")
+	for _, n := range sizes {
+		for _, form := range []string{"if/jtIf", "switch/jtSwitch", "map/jtMapIndex"} {
+			label, fn, _ := strings.Cut(form, "/")
+			fmt.Fprintf(b, "\tb.Run(\"keys%[1]d/%[2]s\", func(b *testing.B) { benchJumpString(b, jtStringKeys%[1]d, %[3]sString%[1]d) })\n", n, label, fn)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// perfectHashBenchSource writes perfect_hash_bench_test.go: if-chain, switch
+// and the lookup perfectHash.source emits for the same keys
+func perfectHashBenchSource() string {
+	b := benchHeader(`// Benchmarks behind perfectHashMinKeys: string keys of 3 to 14 bytes, looked up
+// in shuffled order with 10% absent inputs. The phLookup functions below are
+// perfectHash.source output for the same keys.
+//
+//	go test -run '^$' -bench PerfectHash ./internal/pass
+
+`)
+	rnd := rand.New(rand.NewSource(33))
+	sizes := []int{8, 16, 32, 64}
+	var lookups []string
+	for _, n := range sizes {
+		words := benchWords(rnd, n, 3, 14, "abcdefghijklmnopqrstuvwxyz_")
+		keys := quoted(words)
+		fmt.Fprintf(b, "var phBenchKeys%d = []string{%s}\n\n", n, strings.Join(keys, ", "))
+		benchIf(b, fmt.Sprintf("phIf%d", n), "string", keys)
+		benchSwitch(b, fmt.Sprintf("phSwitch%d", n), "string", "k", keys)
+		benchSwitch(b, fmt.Sprintf("phHash%d", n), "string", fmt.Sprintf("phLookup_bench%d(k)", n), branchNumbers(n))
+
+		ph, ok := buildPerfectHash(words)
+		if !ok {
+			panic(fmt.Sprintf("no perfect hash for the %d benchmark keys", n))
+		}
+		index := make([]int, n)
+		for i := range index {
+			index[i] = i + 1
+		}
+		lookups = append(lookups, ph.source(fmt.Sprintf("bench%d", n), words, index))
+	}
+
+	b.WriteString("func BenchmarkPerfectHash(b *testing.B) {\n")
+	for _, n := range sizes {
+		for _, form := range []string{"if/phIf", "switch/phSwitch", "hash/phHash"} {
+			label, fn, _ := strings.Cut(form, "/")
+			fmt.Fprintf(b, "\tb.Run(\"keys%[1]d/%[2]s\", func(b *testing.B) { benchJumpString(b, phBenchKeys%[1]d, %[3]s%[1]d) })\n", n, label, fn)
+		}
+	}
+	b.WriteString("}\n\n")
+	b.WriteString(strings.Join(lookups, "\n"))
+	return b.String()
+}
+
+// jtIntInputs cycles over the keys plus a quarter of misses, shuffled
+func jtIntInputs(n int) []int {
+	in := make([]int, 0, 256)
+	for len(in) < cap(in) {
+		for k := 1; k <= n+n/4 && len(in) < cap(in); k++ {
+			in = append(in, k)
+		}
+	}
+	jtShuffle(len(in), func(i, j int) { in[i], in[j] = in[j], in[i] })
+	return in
+}
+
+// jtStringInputs cycles over the keys plus 10% of absent strings, shuffled
+func jtStringInputs(keys []string) []string {
+	in := make([]string, 0, 256)
+	for len(in) < cap(in) {
+		for i, k := range keys {
+			if i%10 == 9 {
+				k += "x"
+			}
+			in = append(in, k)
+		}
+	}
+	in = in[:cap(in)]
+	jtShuffle(len(in), func(i, j int) { in[i], in[j] = in[j], in[i] })
+	return in
+}
+
+// jtShuffle is a fixed xorshift shuffle, so every run sees the same inputs
+func jtShuffle(n int, swap func(i, j int)) {
+	x := uint32(2463534242)
+	for i := n - 1; i > 0; i-- {
+		x ^= x << 13
+		x ^= x >> 17
+		x ^= x << 5
+		swap(i, int(x%uint32(i+1)))
+	}
+}
+
+var jtSink int
+
+func benchJumpInt(b *testing.B, n int, f func(int) int) {
+	in := jtIntInputs(n)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		jtSink += f(in[i&255])
+	}
+}
+
+func benchJumpString(b *testing.B, keys []string, f func(string) int) {
+	in := jtStringInputs(keys)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		jtSink += f(in[i&255])
+	}
+}
@@ -0,0 +1,922 @@
+package pass
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"math/bits"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+	stdastutil "golang.org/x/tools/go/ast/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// BitfieldPackPass packs small enum-like fields into bit ranges of the shared
+// flags word (the same `flags` field used by BoolToFlagsPass).
+//
+// Candidates:
+//
+//	Level LogLevel           // named integer type with constants (iota) → range of the const set
+//	Retries uint8 //gastype:range 0..7
+//	Cache *bool              // tri-state: nil / false / true → 2 bits
+//
+// Reads become getter calls and writes become setter calls:
+//
+//	cfg.Level = LogLevelInfo  →  cfg.SetLevel(LogLevelInfo)
+//	if cfg.Level > x          →  if cfg.GetLevel() > x
+//
+// An enum field is packed only when every value stored into it is one of its
+// constants or a copy of another packed field of the same type; computed
+// values such as bump(c.Level) keep it unpacked. Structs that are observed at
+// run time (interfaces, tags, conversions) are never packed.
+type BitfieldPackPass struct {
+	plans map[*types.Var]*bitfieldPlan
+}
+
+// bitfieldPlan describes how a single field is packed
+type bitfieldPlan struct {
+	structName string
+	field      *types.Var
+	kind       string // enum, range, tristate
+	typeExpr   string // field type as written in the source
+	min, max   int64
+	width      int
+	offset     int
+	getter     string
+	setter     string
+	encoder    string
+	maskName   string
+	shiftName  string
+}
+
+var rangeDirective = regexp.MustCompile(`gastype:range\s+(-?\d+)\.\.(-?\d+)`)
+
+func NewBitfieldPackPass() *BitfieldPackPass {
+	return &BitfieldPackPass{}
+}
+
+func (p *BitfieldPackPass) Name() string {
+	return "BitfieldPack"
+}
+
+// Prepare collects candidate fields of every struct in the package, rejects
+// the ones whose uses cannot be rewritten safely and allocates their bits.
+func (p *BitfieldPackPass) Prepare(files []*ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	p.plans = make(map[*types.Var]*bitfieldPlan)
+	if ctx.Package == nil {
+		return nil
+	}
+
+	rejected := make(map[*types.Var]string)
+	byStruct := make(map[string][]*bitfieldPlan)
+	var structOrder []string
+
+	// === 1️⃣ Coleta candidatos ===
+	// Structs que viram interface (fmt, json, reflect) mostram os campos: ficam como estão
+	observed := observedStructs(files, fset, ctx)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok || ts.TypeParams != nil {
+					continue
+				}
+				if obj, ok := ctx.GetDefs()[ts.Name].(*types.TypeName); ok && observed[obj] != "" {
+					gl.Log("info", fmt.Sprintf("BitfieldPack: skipping %s (%s)", ts.Name.Name, observed[obj]))
+					continue
+				}
+				for _, plan := range p.structCandidates(ts, st, ctx) {
+					if _, seen := byStruct[plan.structName]; !seen {
+						structOrder = append(structOrder, plan.structName)
+					}
+					byStruct[plan.structName] = append(byStruct[plan.structName], plan)
+					p.plans[plan.field] = plan
+				}
+			}
+		}
+	}
+	if len(p.plans) == 0 {
+		return nil
+	}
+
+	// === 2️⃣ Rejeita campos com usos que não podem virar getter/setter ===
+	// Repete até estabilizar: um enum copiado de outro campo só é fechado
+	// enquanto o campo de origem também continua empacotado.
+	for changed := true; changed; {
+		changed = false
+		for _, file := range files {
+			p.scanUses(file, ctx, rejected)
+		}
+		for field, reason := range rejected {
+			if plan, ok := p.plans[field]; ok {
+				gl.Log("info", fmt.Sprintf("BitfieldPack: skipping %s.%s (%s)", plan.structName, field.Name(), reason))
+				delete(p.plans, field)
+				changed = true
+			}
+		}
+	}
+
+	// === 3️⃣ Aloca ranges no flags word, na ordem de declaração ===
+	for _, structName := range structOrder {
+		for _, plan := range byStruct[structName] {
+			if _, ok := p.plans[plan.field]; !ok {
+				continue
+			}
+			offset, ok := ctx.AllocateBits(structName, plan.width)
+			if !ok {
+				gl.Log("info", fmt.Sprintf("BitfieldPack: skipping %s.%s (flags word full)", structName, plan.field.Name()))
+				delete(p.plans, plan.field)
+				continue
+			}
+			plan.offset = offset
+		}
+	}
+
+	return nil
+}
+
+// structCandidates returns the packable fields of a struct declaration
+func (p *BitfieldPackPass) structCandidates(ts *ast.TypeSpec, st *ast.StructType, ctx *astutil.TranspileContext) []*bitfieldPlan {
+	obj, ok := ctx.GetDefs()[ts.Name].(*types.TypeName)
+	if !ok || obj.Parent() != ctx.Package.Scope() {
+		return nil
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil
+	}
+	structName := ts.Name.Name
+
+	// Um campo "flags" declarado pelo usuário ocupa o nome do flags word
+	if orig, ok := named.Underlying().(*types.Struct); ok {
+		for i := 0; i < orig.NumFields(); i++ {
+			if orig.Field(i).Name() == "flags" {
+				return nil
+			}
+		}
+	}
+
+	var plans []*bitfieldPlan
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 0 || field.Tag != nil {
+			continue
+		}
+		for _, name := range field.Names {
+			v, ok := ctx.GetDefs()[name].(*types.Var)
+			if !ok || name.Name == "_" {
+				continue
+			}
+			if ctx.Package.Name() != "main" && ast.IsExported(structName) && ast.IsExported(name.Name) {
+				continue // pode ser usado fora do pacote
+			}
+			plan := p.classifyField(field, v, ctx)
+			if plan == nil {
+				continue
+			}
+			plan.structName = structName
+			plan.field = v
+			plan.typeExpr = types.ExprString(field.Type)
+			p.nameHelpers(plan, named, ctx)
+			if plan.getter == "" {
+				continue
+			}
+			plans = append(plans, plan)
+		}
+	}
+	return plans
+}
+
+// classifyField decides whether a field is an enum, an annotated range or a tri-state bool
+func (p *BitfieldPackPass) classifyField(field *ast.Field, v *types.Var, ctx *astutil.TranspileContext) *bitfieldPlan {
+	if m := rangeDirective.FindStringSubmatch(fieldDirectives(field)); m != nil {
+		if !isIntegerType(v.Type()) {
+			return nil
+		}
+		lo, err1 := strconv.ParseInt(m[1], 10, 64)
+		hi, err2 := strconv.ParseInt(m[2], 10, 64)
+		if err1 != nil || err2 != nil || hi <= lo {
+			return nil
+		}
+		return newRangePlan("range", lo, hi, v.Type())
+	}
+
+	if ptr, ok := v.Type().(*types.Pointer); ok {
+		if b, ok := ptr.Elem().(*types.Basic); ok && b.Kind() == types.Bool {
+			return &bitfieldPlan{kind: "tristate", min: 0, max: 3, width: 2}
+		}
+		return nil
+	}
+
+	named, ok := v.Type().(*types.Named)
+	if !ok || !isIntegerType(named) {
+		return nil
+	}
+	lo, hi, count := constRange(named, ctx.Package)
+	if count < 2 {
+		return nil
+	}
+	return newRangePlan("enum", lo, hi, named)
+}
+
+// newRangePlan builds a plan for [lo, hi] unless packing would not save space
+func newRangePlan(kind string, lo, hi int64, typ types.Type) *bitfieldPlan {
+	width := bits.Len64(uint64(hi - lo))
+	if width == 0 {
+		width = 1
+	}
+	if width >= basicBits(typ) {
+		return nil
+	}
+	return &bitfieldPlan{kind: kind, min: lo, max: hi, width: width}
+}
+
+// nameHelpers picks the generated identifiers, leaving getter empty on conflicts
+func (p *BitfieldPackPass) nameHelpers(plan *bitfieldPlan, named *types.Named, ctx *astutil.TranspileContext) {
+	fieldName := plan.field.Name()
+	title := strings.ToUpper(fieldName[:1]) + fieldName[1:]
+	getter, setter := "Get"+title, "Set"+title
+	if !ast.IsExported(fieldName) {
+		getter, setter = "get"+title, "set"+title
+	}
+	for _, m := range []string{getter, setter} {
+		if obj, _, _ := types.LookupFieldOrMethod(named, true, ctx.Package, m); obj != nil {
+			return
+		}
+	}
+	suffix := plan.structName + "_" + fieldName
+	plan.maskName = "Mask" + suffix
+	plan.shiftName = "Shift" + suffix
+	plan.encoder = "encode" + suffix
+	for _, n := range []string{plan.maskName, plan.shiftName, plan.encoder} {
+		if ctx.Package.Scope().Lookup(n) != nil {
+			return
+		}
+	}
+	plan.getter, plan.setter = getter, setter
+}
+
+// scanUses marks candidate fields whose uses cannot be expressed with getter/setter
+func (p *BitfieldPackPass) scanUses(file *ast.File, ctx *astutil.TranspileContext, rejected map[*types.Var]string) {
+	writes := assignedStars(file)
+
+	// Um enum só é fechado se nenhum valor fora do conjunto de constantes
+	// (ex: CustomLevelTest LogLevel = 42) ou conversão dinâmica aparece no pacote.
+	ast.Inspect(file, func(n ast.Node) bool {
+		expr, ok := n.(ast.Expr)
+		if !ok {
+			return true
+		}
+		tv, ok := ctx.GetTypes()[expr]
+		if !ok || tv.Type == nil || tv.IsType() {
+			return true
+		}
+		for _, plan := range p.plans {
+			if plan.kind != "enum" || !types.Identical(tv.Type, plan.field.Type()) {
+				continue
+			}
+			if tv.Value != nil {
+				if v, exact := constant.Int64Val(constant.ToInt(tv.Value)); !exact || v < plan.min || v > plan.max {
+					rejected[plan.field] = "value outside the constant set"
+				}
+			} else if call, ok := expr.(*ast.CallExpr); ok {
+				if ftv, ok := ctx.GetTypes()[call.Fun]; ok && ftv.IsType() {
+					rejected[plan.field] = "conversion into enum type"
+				}
+			}
+		}
+		return true
+	})
+
+	stdastutil.Apply(file, func(c *stdastutil.Cursor) bool {
+		switch node := c.Node().(type) {
+		case *ast.CompositeLit:
+			st := structOf(ctx.GetTypes()[node].Type)
+			if st == nil || len(node.Elts) == 0 {
+				return true
+			}
+			if _, keyed := node.Elts[0].(*ast.KeyValueExpr); !keyed {
+				for _, plan := range p.plans {
+					if structHasField(st, plan.field) {
+						rejected[plan.field] = "unkeyed composite literal"
+					}
+				}
+				return true
+			}
+			for _, elt := range node.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				key, ok := kv.Key.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				for _, plan := range p.plans {
+					if plan.field.Name() != key.Name || !structHasField(st, plan.field) {
+						continue
+					}
+					if plan.kind == "enum" && !p.closedValue(plan, kv.Value, ctx) {
+						rejected[plan.field] = "non-constant value in composite literal"
+					}
+					if plan.kind == "tristate" && !p.freshPointer(plan, kv.Value, ctx) {
+						rejected[plan.field] = "pointer stored from " + types.ExprString(kv.Value)
+					}
+				}
+			}
+		case *ast.SelectorExpr:
+			plan := p.planFor(node, ctx)
+			if plan == nil {
+				return true
+			}
+			if reason := p.checkUse(plan, node, c, writes, ctx); reason != "" {
+				rejected[plan.field] = reason
+			}
+		}
+		return true
+	}, nil)
+}
+
+// checkUse validates one selector of a candidate field against its parent node
+func (p *BitfieldPackPass) checkUse(plan *bitfieldPlan, sel *ast.SelectorExpr, c *stdastutil.Cursor, writes map[*ast.StarExpr]bool, ctx *astutil.TranspileContext) string {
+	switch parent := c.Parent().(type) {
+	case *ast.UnaryExpr:
+		if parent.Op == token.AND {
+			return "address taken"
+		}
+	case *ast.AssignStmt:
+		if c.Name() != "Lhs" {
+			break
+		}
+		if len(parent.Lhs) != 1 || len(parent.Rhs) != 1 {
+			return "multi-value assignment"
+		}
+		if parent.Tok != token.ASSIGN {
+			if plan.kind != "range" {
+				return "arithmetic on " + plan.kind + " field"
+			}
+			if !astutil.IsSideEffectFree(sel.X, ctx.Info) {
+				return "compound assignment with side effects"
+			}
+			return ""
+		}
+		if tv, ok := ctx.GetTypes()[parent.Rhs[0]]; ok && tv.Value != nil && tv.Value.Kind() == constant.Int {
+			if v, exact := constant.Int64Val(tv.Value); !exact || v < plan.min || v > plan.max {
+				return "constant out of range"
+			}
+		}
+		if plan.kind == "enum" && !p.closedValue(plan, parent.Rhs[0], ctx) {
+			return "non-constant value assigned"
+		}
+		if plan.kind == "tristate" && !p.freshPointer(plan, parent.Rhs[0], ctx) {
+			return "pointer stored from " + types.ExprString(parent.Rhs[0])
+		}
+		return ""
+	case *ast.IncDecStmt:
+		if plan.kind != "range" {
+			return "arithmetic on " + plan.kind + " field"
+		}
+		if !astutil.IsSideEffectFree(sel.X, ctx.Info) {
+			return "increment with side effects"
+		}
+		return ""
+	case *ast.RangeStmt:
+		if c.Name() == "Key" || c.Name() == "Value" {
+			return "range assignment"
+		}
+	case *ast.SelectorExpr:
+		// cfg.Level.Method(): métodos com receiver ponteiro exigem campo endereçável
+		if s := ctx.GetSelections()[parent]; s != nil {
+			if fn, ok := s.Obj().(*types.Func); ok {
+				if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+					if _, ptr := sig.Recv().Type().(*types.Pointer); ptr {
+						return "pointer method call"
+					}
+				}
+			}
+		}
+	}
+
+	if plan.kind != "tristate" {
+		return ""
+	}
+	// Tri-state: o getter devolve uma cópia, então só leituras que não
+	// dependem da identidade do ponteiro são aceitas.
+	switch parent := c.Parent().(type) {
+	case *ast.BinaryExpr:
+		if (parent.Op == token.EQL || parent.Op == token.NEQ) && (isNilIdent(parent.X) || isNilIdent(parent.Y)) {
+			return ""
+		}
+	case *ast.StarExpr:
+		if writes[parent] {
+			return "write through pointer"
+		}
+		return ""
+	case *ast.AssignStmt:
+		if c.Name() == "Lhs" {
+			return ""
+		}
+	}
+	return "pointer escapes"
+}
+
+// closedValue reports whether expr is known to stay inside the constant set of
+// an enum plan: an in-range constant or a read of a field packed with the same
+// type. Computed values (bump(l), l+1) may fall outside it and would be masked
+// by the setter, so they reject the field.
+func (p *BitfieldPackPass) closedValue(plan *bitfieldPlan, expr ast.Expr, ctx *astutil.TranspileContext) bool {
+	expr = ast.Unparen(expr)
+	if tv, ok := ctx.GetTypes()[expr]; ok && tv.Value != nil {
+		v, exact := constant.Int64Val(constant.ToInt(tv.Value))
+		return exact && v >= plan.min && v <= plan.max
+	}
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	other := p.planFor(sel, ctx)
+	return other != nil && other.kind == "enum" && types.Identical(other.field.Type(), plan.field.Type())
+}
+
+// freshPointer reports whether expr is a pointer nobody else holds: nil,
+// new(T), &T{...} or a read of another packed tri-state field. The getter
+// returns a new pointer on every call, so a pointer to a variable (&b) would
+// lose later writes to b and its identity.
+func (p *BitfieldPackPass) freshPointer(plan *bitfieldPlan, expr ast.Expr, ctx *astutil.TranspileContext) bool {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		return isNilIdent(e)
+	case *ast.CallExpr:
+		return isBuiltinCall(e, "new", ctx)
+	case *ast.UnaryExpr:
+		_, lit := ast.Unparen(e.X).(*ast.CompositeLit)
+		return e.Op == token.AND && lit
+	case *ast.SelectorExpr:
+		other := p.planFor(e, ctx)
+		return other != nil && other.kind == "tristate" && types.Identical(other.field.Type(), plan.field.Type())
+	}
+	return false
+}
+
+// planFor returns the plan of the field selected by sel, if any
+func (p *BitfieldPackPass) planFor(sel *ast.SelectorExpr, ctx *astutil.TranspileContext) *bitfieldPlan {
+	s := ctx.GetSelections()[sel]
+	if s == nil || s.Kind() != types.FieldVal {
+		return nil
+	}
+	v, ok := s.Obj().(*types.Var)
+	if !ok {
+		return nil
+	}
+	return p.plans[v]
+}
+
+func (p *BitfieldPackPass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	if len(p.plans) == 0 {
+		return nil
+	}
+	transformations := 0
+
+	// === 1️⃣ Reescreve as declarações de struct deste arquivo ===
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			if err := p.packStruct(file, ts, st, fset, ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	// === 2️⃣ Leituras → getter, escritas → setter ===
+	stdastutil.Apply(file, nil, func(c *stdastutil.Cursor) bool {
+		switch node := c.Node().(type) {
+		case *ast.SelectorExpr:
+			plan := p.planFor(node, ctx)
+			if plan == nil || isWriteTarget(c) {
+				return true
+			}
+			c.Replace(&ast.CallExpr{Fun: &ast.SelectorExpr{X: node.X, Sel: ast.NewIdent(plan.getter)}})
+			transformations++
+		case *ast.AssignStmt:
+			if len(node.Lhs) != 1 || len(node.Rhs) != 1 {
+				return true
+			}
+			sel, ok := node.Lhs[0].(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			plan := p.planFor(sel, ctx)
+			if plan == nil {
+				return true
+			}
+			value := node.Rhs[0]
+			if node.Tok != token.ASSIGN {
+				value = &ast.BinaryExpr{
+					X:  &ast.CallExpr{Fun: &ast.SelectorExpr{X: sel.X, Sel: ast.NewIdent(plan.getter)}},
+					Op: compoundOp(node.Tok),
+					Y:  &ast.ParenExpr{X: value},
+				}
+			}
+			c.Replace(setterCall(sel.X, plan, value))
+			transformations++
+		case *ast.IncDecStmt:
+			sel, ok := node.X.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			plan := p.planFor(sel, ctx)
+			if plan == nil {
+				return true
+			}
+			op := token.ADD
+			if node.Tok == token.DEC {
+				op = token.SUB
+			}
+			c.Replace(setterCall(sel.X, plan, &ast.BinaryExpr{
+				X:  &ast.CallExpr{Fun: &ast.SelectorExpr{X: sel.X, Sel: ast.NewIdent(plan.getter)}},
+				Op: op,
+				Y:  &ast.BasicLit{Kind: token.INT, Value: "1"},
+			}))
+			transformations++
+		case *ast.CompositeLit:
+			if p.packCompositeLit(node, ctx) {
+				transformations++
+			}
+		}
+		return true
+	})
+
+	if transformations > 0 {
+		ctx.LogVerbose(fset, "🧩 BitfieldPackPass: %d transformations applied", transformations)
+	}
+	return nil
+}
+
+// packStruct removes the packed fields, sizes the flags word and emits the helpers
+func (p *BitfieldPackPass) packStruct(file *ast.File, ts *ast.TypeSpec, st *ast.StructType, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	var packed []*bitfieldPlan
+	var kept []*ast.Field
+	for _, field := range st.Fields.List {
+		var names []*ast.Ident
+		for _, name := range field.Names {
+			if v, ok := ctx.GetDefs()[name].(*types.Var); ok && p.plans[v] != nil && p.plans[v].structName == ts.Name.Name {
+				packed = append(packed, p.plans[v])
+				continue
+			}
+			names = append(names, name)
+		}
+		if len(field.Names) > 0 && len(names) == 0 {
+			dropComments(file, field.Doc, field.Comment)
+			continue
+		}
+		field.Names = names
+		kept = append(kept, field)
+	}
+	if len(packed) == 0 {
+		return nil
+	}
+
+	info := ctx.GetStructInfo(ts.Name.Name)
+	flagsType := astutil.MenorTipoParaFlags(info.UsedBits)
+	if info.FlagsType != "" && info.FlagsType != flagsType {
+		// BoolToFlags já criou o flags word com um tipo menor: alarga campo e constantes
+		widenFlagConstants(file, info, flagsType)
+	}
+
+	hasFlags := false
+	for _, field := range kept {
+		if len(field.Names) == 1 && field.Names[0].Name == "flags" {
+			field.Type = ast.NewIdent(flagsType)
+			hasFlags = true
+		}
+	}
+	if !hasFlags {
+		kept = append([]*ast.Field{{Names: []*ast.Ident{ast.NewIdent("flags")}, Type: ast.NewIdent(flagsType)}}, kept...)
+	}
+	st.Fields.List = kept
+	info.FlagsType = flagsType
+
+	var src strings.Builder
+	for _, plan := range packed {
+		src.WriteString(bitfieldHelpers(plan, flagsType))
+		if info.BitFields == nil {
+			info.BitFields = make(map[string]*astutil.BitField)
+		}
+		info.BitFields[plan.field.Name()] = &astutil.BitField{
+			Field:  plan.field.Name(),
+			Kind:   plan.kind,
+			Offset: plan.offset,
+			Width:  plan.width,
+			Min:    plan.min,
+			Max:    plan.max,
+			Getter: plan.getter,
+			Setter: plan.setter,
+		}
+		gl.Log("info", fmt.Sprintf("Packed field: %s.%s → bits %d..%d (%s)", plan.structName, plan.field.Name(), plan.offset, plan.offset+plan.width-1, flagsType))
+	}
+
+	decls, err := astutil.ParseDecls(fset, src.String())
+	if err != nil {
+		return fmt.Errorf("bitfield helpers for %s: %w", ts.Name.Name, err)
+	}
+	file.Decls = append(file.Decls, decls...)
+	return nil
+}
+
+// packCompositeLit moves keyed values of packed fields into the flags element
+func (p *BitfieldPackPass) packCompositeLit(lit *ast.CompositeLit, ctx *astutil.TranspileContext) bool {
+	tv, ok := ctx.GetTypes()[lit]
+	if !ok {
+		return false
+	}
+	st := structOf(tv.Type)
+	if st == nil {
+		return false
+	}
+
+	var packedValue ast.Expr
+	var flagsKV *ast.KeyValueExpr
+	var elts []ast.Expr
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return false
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			elts = append(elts, elt)
+			continue
+		}
+		if key.Name == "flags" {
+			flagsKV = kv
+			elts = append(elts, elt)
+			continue
+		}
+		var plan *bitfieldPlan
+		for _, candidate := range p.plans {
+			if candidate.field.Name() == key.Name && structHasField(st, candidate.field) {
+				plan = candidate
+			}
+		}
+		if plan == nil {
+			elts = append(elts, elt)
+			continue
+		}
+		encoded := &ast.CallExpr{Fun: ast.NewIdent(plan.encoder), Args: []ast.Expr{kv.Value}}
+		if packedValue == nil {
+			packedValue = encoded
+		} else {
+			packedValue = &ast.BinaryExpr{X: packedValue, Op: token.OR, Y: encoded}
+		}
+	}
+	if packedValue == nil {
+		return false
+	}
+	if flagsKV != nil {
+		flagsKV.Value = &ast.BinaryExpr{X: flagsKV.Value, Op: token.OR, Y: packedValue}
+	} else {
+		elts = append(elts, &ast.KeyValueExpr{Key: ast.NewIdent("flags"), Value: packedValue})
+	}
+	lit.Elts = elts
+	return true
+}
+
+// bitfieldHelpers renders constants, encoder, getter and setter for one field
+func bitfieldHelpers(plan *bitfieldPlan, flagsType string) string {
+	var b strings.Builder
+	s, t := plan.structName, plan.typeExpr
+	fmt.Fprintf(&b, "const (\n\t%s = %d\n\t%s %s = %#x << %s\n)\n\n",
+		plan.shiftName, plan.offset, plan.maskName, flagsType, uint64(1)<<plan.width-1, plan.shiftName)
+
+	if plan.kind == "tristate" {
+		fmt.Fprintf(&b, "func %s(v %s) %s {\n\tif v == nil {\n\t\treturn 0\n\t}\n\tif *v {\n\t\treturn 3 << %s\n\t}\n\treturn 1 << %s\n}\n\n",
+			plan.encoder, t, flagsType, plan.shiftName, plan.shiftName)
+		fmt.Fprintf(&b, "func (s %s) %s() %s {\n\tbits := s.flags & %s >> %s\n\tif bits == 0 {\n\t\treturn nil\n\t}\n\tv := bits == 3\n\treturn &v\n}\n\n",
+			s, plan.getter, t, plan.maskName, plan.shiftName)
+	} else {
+		stored, loaded := "v", fmt.Sprintf("%s(s.flags & %s >> %s)", t, plan.maskName, plan.shiftName)
+		if plan.min != 0 {
+			stored = fmt.Sprintf("(v - (%d))", plan.min)
+			loaded = fmt.Sprintf("%s + (%d)", loaded, plan.min)
+		}
+		fmt.Fprintf(&b, "func %s(v %s) %s {\n\treturn %s(%s) << %s & %s\n}\n\n",
+			plan.encoder, t, flagsType, flagsType, stored, plan.shiftName, plan.maskName)
+		fmt.Fprintf(&b, "func (s %s) %s() %s {\n\treturn %s\n}\n\n", s, plan.getter, t, loaded)
+	}
+	fmt.Fprintf(&b, "func (s *%s) %s(v %s) {\n\ts.flags = s.flags&^%s | %s(v)\n}\n\n",
+		s, plan.setter, t, plan.maskName, plan.encoder)
+	return b.String()
+}
+
+// widenFlagConstants retypes the flags constants created by BoolToFlagsPass
+func widenFlagConstants(file *ast.File, info *astutil.StructInfo, flagsType string) {
+	names := make(map[string]bool)
+	for _, flagName := range info.FlagMapping {
+		names[flagName] = true
+	}
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if len(vs.Names) == 1 && names[vs.Names[0].Name] {
+				vs.Type = ast.NewIdent(flagsType)
+			}
+		}
+	}
+}
+
+func setterCall(recv ast.Expr, plan *bitfieldPlan, value ast.Expr) ast.Stmt {
+	return &ast.ExprStmt{X: &ast.CallExpr{
+		Fun:  &ast.SelectorExpr{X: recv, Sel: ast.NewIdent(plan.setter)},
+		Args: []ast.Expr{value},
+	}}
+}
+
+// compoundOp maps `+=` to `+`, `<<=` to `<<` and so on
+func compoundOp(tok token.Token) token.Token {
+	switch tok {
+	case token.ADD_ASSIGN:
+		return token.ADD
+	case token.SUB_ASSIGN:
+		return token.SUB
+	case token.MUL_ASSIGN:
+		return token.MUL
+	case token.QUO_ASSIGN:
+		return token.QUO
+	case token.REM_ASSIGN:
+		return token.REM
+	case token.AND_ASSIGN:
+		return token.AND
+	case token.OR_ASSIGN:
+		return token.OR
+	case token.XOR_ASSIGN:
+		return token.XOR
+	case token.SHL_ASSIGN:
+		return token.SHL
+	case token.SHR_ASSIGN:
+		return token.SHR
+	case token.AND_NOT_ASSIGN:
+		return token.AND_NOT
+	}
+	return tok
+}
+
+// isWriteTarget reports whether the cursor sits on the left side of an assignment or ++/--
+func isWriteTarget(c *stdastutil.Cursor) bool {
+	switch c.Parent().(type) {
+	case *ast.AssignStmt:
+		return c.Name() == "Lhs"
+	case *ast.IncDecStmt:
+		return true
+	}
+	return false
+}
+
+// assignedStars collects the *x expressions used as assignment or ++/-- targets
+func assignedStars(file *ast.File) map[*ast.StarExpr]bool {
+	writes := make(map[*ast.StarExpr]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.AssignStmt:
+			for _, l := range s.Lhs {
+				if star, ok := l.(*ast.StarExpr); ok {
+					writes[star] = true
+				}
+			}
+		case *ast.IncDecStmt:
+			if star, ok := s.X.(*ast.StarExpr); ok {
+				writes[star] = true
+			}
+		}
+		return true
+	})
+	return writes
+}
+
+// dropComments removes comment groups of deleted nodes so the printer does not orphan them
+func dropComments(file *ast.File, groups ...*ast.CommentGroup) {
+	drop := make(map[*ast.CommentGroup]bool)
+	for _, g := range groups {
+		if g != nil {
+			drop[g] = true
+		}
+	}
+	if len(drop) == 0 {
+		return
+	}
+	kept := file.Comments[:0]
+	for _, g := range file.Comments {
+		if !drop[g] {
+			kept = append(kept, g)
+		}
+	}
+	file.Comments = kept
+}
+
+func fieldDirectives(field *ast.Field) string {
+	var text string
+	if field.Doc != nil {
+		for _, c := range field.Doc.List {
+			text += c.Text + "\n"
+		}
+	}
+	if field.Comment != nil {
+		for _, c := range field.Comment.List {
+			text += c.Text + "\n"
+		}
+	}
+	return text
+}
+
+// constRange returns min, max and count of the package constants of type named
+func constRange(named *types.Named, pkg *types.Package) (int64, int64, int) {
+	var lo, hi int64
+	count := 0
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		c, ok := scope.Lookup(name).(*types.Const)
+		if !ok || !types.Identical(c.Type(), named) {
+			continue
+		}
+		v, exact := constant.Int64Val(constant.ToInt(c.Val()))
+		if !exact {
+			return 0, 0, 0
+		}
+		if count == 0 || v < lo {
+			lo = v
+		}
+		if count == 0 || v > hi {
+			hi = v
+		}
+		count++
+	}
+	return lo, hi, count
+}
+
+func isIntegerType(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsInteger != 0
+}
+
+// basicBits returns the storage size in bits of an integer type
+func basicBits(t types.Type) int {
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return 64
+	}
+	switch b.Kind() {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int32, types.Uint32:
+		return 32
+	}
+	return 64
+}
+
+func structOf(t types.Type) *types.Struct {
+	if t == nil {
+		return nil
+	}
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	st, _ := t.Underlying().(*types.Struct)
+	return st
+}
+
+func structHasField(st *types.Struct, v *types.Var) bool {
+	for i := 0; i < st.NumFields(); i++ {
+		if st.Field(i) == v {
+			return true
+		}
+	}
+	return false
+}
+
+func isNilIdent(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "nil"
+}
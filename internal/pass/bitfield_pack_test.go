@@ -0,0 +1,82 @@
+package pass
+
+import (
+	"strings"
+	"testing"
+)
+
+const bitfieldPackProbe = `package main
+
+import "fmt"
+
+type Level int
+
+const (
+	Debug Level = iota
+	Info
+	Warn
+	Error
+)
+
+// Empacotável: só constantes e nil/new(bool)
+type Packed struct {
+	Lvl   Level
+	Cache *bool
+	Name  string
+}
+
+// Valor calculado do enum pode sair do conjunto de constantes
+type Computed struct {
+	Lvl Level
+}
+
+// Ponteiro vindo de uma variável: escritas posteriores em b precisam aparecer
+type Aliased struct {
+	Cache *bool
+}
+
+// Impresso com %+v: os nomes dos campos aparecem na saída
+type Printed struct {
+	Lvl  Level
+	Name string
+}
+
+func bump(l Level) Level { return l + 1 }
+
+func main() {
+	p := Packed{Lvl: Warn, Name: "p"}
+	fmt.Println(p.Lvl, p.Cache == nil, p.Name)
+	p.Cache = new(bool)
+	p.Lvl = Error
+	fmt.Println(p.Lvl, *p.Cache)
+
+	c := Computed{Lvl: Error}
+	c.Lvl = bump(c.Lvl)
+	fmt.Println(c.Lvl)
+
+	b := true
+	a := Aliased{}
+	a.Cache = &b
+	b = false
+	fmt.Println(*a.Cache)
+
+	fmt.Printf("%+v\n", Printed{Lvl: Info, Name: "x"})
+}
+`
+
+func TestBitfieldPackPreservesBehavior(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, bitfieldPackProbe)
+	out, _ := transpileSource(t, bitfieldPackProbe, false, NewBitfieldPackPass())
+	if !strings.Contains(out, "func (s Packed) GetLvl()") || !strings.Contains(out, "func (s Packed) GetCache()") {
+		t.Fatalf("Packed fields not packed\n%s", out)
+	}
+	for _, name := range []string{"Computed", "Aliased", "Printed"} {
+		if strings.Contains(out, "func (s "+name+")") {
+			t.Errorf("%s packed\n%s", name, out)
+		}
+	}
+	if got := runSource(t, out); got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+}
@@ -0,0 +1,961 @@
+package pass
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	stdastutil "golang.org/x/tools/go/ast/astutil"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// BoolBitsetPass empacota []bool e [N]bool locais e campos de struct num
+// bitset gerado, no espírito do BoolToFlagsPass: um bit por elemento em vez
+// de um byte.
+//
+//	seen := make([]bool, n)    →  seen := makeBoolSlice(n)
+//	seen[i] = true             →  seen.Set(i, true)
+//	if seen[j] {               →  if seen.Get(j) {
+//	seen = append(seen, ok)    →  seen = seen.Append(ok)
+//	for i, v := range seen {   →  for bi, bs := 0, seen; bi < bs.Len(); bi++ { i, v := bi, bs.Get(bi)
+//	var mask [128]bool         →  var mask boolArray128   // [2]uint64: 128 → 16 bytes
+//
+// Slices viram boolSlice ({words []uint64; n int}, que compartilha as words
+// como um slice compartilha o array); arrays viram [W]uint64 e continuam
+// valores copiáveis e comparáveis. Get e Set conferem o índice e entram em
+// pânico fora do tamanho, como o acesso original. Só entram valores que nunca
+// saem como []bool de verdade: passados, retornados, fatiados, com endereço
+// tomado ou copiados para outra variável ficam como estão, assim como campos
+// de structs observáveis (interfaces, tags, conversões). Arrays pequenos
+// demais para economizar memória também ficam, assim como campos []bool que
+// recebem append num struct copiado por valor: as cópias dividiriam a última
+// word que o Append faz crescer. Cada conversão vai para a seção bitsets do
+// --map.
+type BoolBitsetPass struct {
+	plans  map[*types.Var]*bitsetPlan
+	order  []*bitsetPlan
+	slice  *bitsetType           // boolSlice do pacote
+	arrays map[int64]*bitsetType // boolArrayN do pacote, por N
+}
+
+// bitsetPlan describes one []bool/[N]bool variable or field and its bitset
+type bitsetPlan struct {
+	obj      *types.Var // var ou campo original
+	target   string     // var local ou Struct.Field
+	pos      token.Position
+	array    bool
+	length   int64 // N, para arrays
+	bits     *bitsetType
+	field    *ast.Field      // declaração do campo (structs)
+	file     *ast.File       // arquivo da declaração do campo
+	owner    *types.TypeName // struct do campo
+	appended bool            // recebe s = append(s, ...)
+	reason   string
+}
+
+// bitsetType is a generated bitset type and its constructors
+type bitsetType struct {
+	name    string
+	make    string // makeBoolSlice(n)
+	of      string // newBoolSlice(vs...) / boolArrayNOf(vs...)
+	length  int64
+	named   *types.Named
+	emitted bool
+}
+
+func NewBoolBitsetPass() *BoolBitsetPass { return &BoolBitsetPass{} }
+func (p *BoolBitsetPass) Name() string   { return "BoolBitset" }
+
+func (plan *bitsetPlan) reject(reason string) {
+	if plan.reason == "" {
+		plan.reason = reason
+	}
+}
+
+// boolContainer returns whether t is []bool or [N]bool, and N for arrays
+func boolContainer(t types.Type) (ok, array bool, n int64) {
+	switch tt := types.Unalias(t).(type) {
+	case *types.Slice:
+		return types.Identical(tt.Elem(), types.Typ[types.Bool]), false, 0
+	case *types.Array:
+		return types.Identical(tt.Elem(), types.Typ[types.Bool]), true, tt.Len()
+	}
+	return false, false, 0
+}
+
+// Prepare collects the candidates of the package, checks every use and
+// allocates the generated types of the accepted ones
+func (p *BoolBitsetPass) Prepare(files []*ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	p.plans = make(map[*types.Var]*bitsetPlan)
+	p.order = nil
+	p.slice = nil
+	p.arrays = make(map[int64]*bitsetType)
+	if ctx.Package == nil {
+		return nil
+	}
+	a := &bitsetAnalysis{
+		fset: fset, ctx: ctx,
+		plans:     make(map[*types.Var]*bitsetPlan),
+		accounted: make(map[ast.Expr]bool),
+		special:   make(map[*ast.IndexExpr]bool),
+	}
+
+	// === 1️⃣ Coleta locais e campos candidatos ===
+	observed := observedStructs(files, fset, ctx)
+	for _, file := range files {
+		a.collect(file, observed)
+	}
+	if len(a.order) == 0 {
+		return nil
+	}
+
+	// === 2️⃣ Rejeita os que escapam como []bool ===
+	for _, file := range files {
+		a.scan(file)
+	}
+	// Cópias da struct dividem words: Append na cópia escreveria nos bits
+	// livres da última word, onde o append do Go realocaria o array
+	var copies map[*types.TypeName]string
+	for _, plan := range a.order {
+		if plan.field == nil || plan.array || !plan.appended || plan.reason != "" {
+			continue
+		}
+		if copies == nil {
+			copies = structCopies(files, ctx)
+		}
+		if how := copies[plan.owner]; how != "" {
+			plan.reject("appended while " + plan.owner.Name() + " is copied by value (" + how + ")")
+		}
+	}
+	for _, plan := range a.order {
+		if plan.array && plan.length-8*((plan.length+63)/64) < 8 {
+			plan.reject(fmt.Sprintf("[%d]bool too small to save memory", plan.length))
+		}
+	}
+	// Campos declarados juntos (a, b []bool) dividem a expressão de tipo
+	for _, plan := range a.order {
+		if plan.field == nil || plan.reason != "" {
+			continue
+		}
+		for _, name := range plan.field.Names {
+			v, _ := ctx.GetDefs()[name].(*types.Var)
+			if other := a.plans[v]; other == nil || other.reason != "" {
+				plan.reject("declared together with " + name.Name)
+			}
+		}
+	}
+
+	// === 3️⃣ Aloca os tipos gerados e registra a economia ===
+	used := make(map[string]bool)
+	for _, file := range files {
+		collectIdentNames(file, used)
+	}
+	sizes := ctx.Sizes()
+	rebound := make(map[types.Object]*types.Var)
+	for _, plan := range a.order {
+		if plan.reason != "" {
+			gl.Log("info", fmt.Sprintf("BoolBitset: skipping %s (%s)", plan.target, plan.reason))
+			ctx.RecordLedger(p.Name(), plan.pos, plan.target, "rejected", plan.reason)
+			continue
+		}
+		plan.bits = p.bitsetType(plan, used, ctx)
+		p.plans[plan.obj] = plan
+		p.order = append(p.order, plan)
+		if !plan.obj.IsField() {
+			// Passes seguintes (flatten, opaque) leem o tipo da var local
+			v := types.NewVar(plan.obj.Pos(), ctx.Package, plan.obj.Name(), plan.bits.named)
+			rebound[plan.obj] = v
+			p.plans[v] = plan
+		}
+
+		report := astutil.BoolBitset{
+			Target: plan.target, File: plan.pos.Filename, Line: plan.pos.Line,
+			Original: plan.obj.Type().String(), Packed: plan.bits.name,
+			OriginalBytes: 64, PackedBytes: 8,
+		}
+		detail := plan.obj.Type().String() + " → " + plan.bits.name + " (1 bit per element)"
+		if plan.array {
+			report.OriginalBytes = sizes.Sizeof(plan.obj.Type())
+			report.PackedBytes = 8 * ((plan.length + 63) / 64)
+			detail = fmt.Sprintf("%s → %s (%d → %d bytes)", plan.obj.Type(), plan.bits.name, report.OriginalBytes, report.PackedBytes)
+		}
+		ctx.RegisterBitset(report)
+		ctx.RecordLedger(p.Name(), plan.pos, plan.target, "rewritten", detail)
+	}
+	for id, obj := range ctx.GetDefs() {
+		if v, ok := rebound[obj]; ok {
+			ctx.GetDefs()[id] = v
+		}
+	}
+	for id, obj := range ctx.GetUses() {
+		if v, ok := rebound[obj]; ok {
+			ctx.GetUses()[id] = v
+		}
+	}
+	return nil
+}
+
+// bitsetType returns the generated type of the plan, allocating it on first use
+func (p *BoolBitsetPass) bitsetType(plan *bitsetPlan, used map[string]bool, ctx *astutil.TranspileContext) *bitsetType {
+	if !plan.array && p.slice != nil {
+		return p.slice
+	}
+	if bt := p.arrays[plan.length]; plan.array && bt != nil {
+		return bt
+	}
+
+	base, suffix := "boolSlice", ""
+	if plan.array {
+		base = "boolArray" + strconv.FormatInt(plan.length, 10)
+	}
+	var bt *bitsetType
+	for i := 1; ; i++ {
+		if i > 1 {
+			suffix = "_" + strconv.Itoa(i)
+		}
+		title := strings.ToUpper(base[:1]) + base[1:] + suffix
+		bt = &bitsetType{name: base + suffix, make: "make" + title, of: "new" + title, length: plan.length}
+		if plan.array {
+			bt.of = bt.name + "Of"
+		}
+		free := true
+		for _, name := range []string{bt.name, bt.make, bt.of} {
+			if used[name] || freshPackageName(ctx.Package, name) != name {
+				free = false
+			}
+		}
+		if free {
+			break
+		}
+	}
+	used[bt.name], used[bt.make], used[bt.of] = true, true, true
+
+	words := types.NewSlice(types.Typ[types.Uint64])
+	var underlying types.Type = types.NewStruct([]*types.Var{
+		types.NewField(token.NoPos, ctx.Package, "words", words, false),
+		types.NewField(token.NoPos, ctx.Package, "n", types.Typ[types.Int], false),
+	}, nil)
+	if plan.array {
+		underlying = types.NewArray(types.Typ[types.Uint64], (plan.length+63)/64)
+	}
+	tn := types.NewTypeName(token.NoPos, ctx.Package, bt.name, nil)
+	bt.named = types.NewNamed(tn, underlying, nil)
+	ctx.Package.Scope().Insert(tn)
+
+	if plan.array {
+		p.arrays[plan.length] = bt
+	} else {
+		p.slice = bt
+	}
+	return bt
+}
+
+// bitsetAnalysis collects the candidates of a package and classifies their uses
+type bitsetAnalysis struct {
+	fset      *token.FileSet
+	ctx       *astutil.TranspileContext
+	plans     map[*types.Var]*bitsetPlan
+	order     []*bitsetPlan
+	accounted map[ast.Expr]bool       // usos já classificados pelo nó pai
+	special   map[*ast.IndexExpr]bool // s[i] que são alvo de escrita
+}
+
+// collect registers the []bool/[N]bool locals and struct fields of file
+func (a *bitsetAnalysis) collect(file *ast.File, observed map[*types.TypeName]string) {
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				tn, _ := a.ctx.GetDefs()[ts.Name].(*types.TypeName)
+				if !ok || ts.TypeParams != nil || tn == nil {
+					continue
+				}
+				for _, field := range st.Fields.List {
+					for _, name := range field.Names {
+						plan := a.add(name, ts.Name.Name+"."+name.Name)
+						if plan == nil {
+							continue
+						}
+						plan.field, plan.file, plan.owner = field, file, tn
+						switch {
+						case name.IsExported() && a.ctx.Package.Name() != "main":
+							plan.reject("exported outside package main")
+						case observed[tn] != "":
+							plan.reject(observed[tn])
+						}
+					}
+				}
+			}
+		case *ast.FuncDecl:
+			if decl.Body == nil {
+				continue
+			}
+			ast.Inspect(decl.Body, func(n ast.Node) bool {
+				switch node := n.(type) {
+				case *ast.AssignStmt:
+					if node.Tok != token.DEFINE {
+						return true
+					}
+					for i, lhs := range node.Lhs {
+						id, ok := lhs.(*ast.Ident)
+						if !ok {
+							continue
+						}
+						plan := a.add(id, id.Name)
+						if plan == nil {
+							continue
+						}
+						if len(node.Lhs) != len(node.Rhs) {
+							plan.reject("initialized from a multi-value expression")
+							continue
+						}
+						a.init(plan, node.Rhs[i])
+					}
+				case *ast.ValueSpec:
+					for i, name := range node.Names {
+						plan := a.add(name, name.Name)
+						switch {
+						case plan == nil:
+						case len(node.Names) > 1:
+							plan.reject("declared together with other variables")
+						case len(node.Values) > 0:
+							a.init(plan, node.Values[i])
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+}
+
+// add creates the plan of the []bool/[N]bool defined by id, if it is one
+func (a *bitsetAnalysis) add(id *ast.Ident, target string) *bitsetPlan {
+	v, ok := a.ctx.GetDefs()[id].(*types.Var)
+	if !ok || id.Name == "_" {
+		return nil
+	}
+	isBools, array, n := boolContainer(v.Type())
+	if !isBools {
+		return nil
+	}
+	plan := &bitsetPlan{obj: v, target: target, pos: a.fset.Position(id.Pos()), array: array, length: n}
+	a.plans[v] = plan
+	a.order = append(a.order, plan)
+	return plan
+}
+
+// init checks a value given to a candidate: make, an unkeyed literal or nil
+func (a *bitsetAnalysis) init(plan *bitsetPlan, e ast.Expr) {
+	switch v := ast.Unparen(e).(type) {
+	case *ast.CompositeLit:
+		for _, elt := range v.Elts {
+			if _, keyed := elt.(*ast.KeyValueExpr); keyed {
+				plan.reject("keyed literal")
+			}
+		}
+		return
+	case *ast.CallExpr:
+		if !plan.array && isBuiltinCall(v, "make", a.ctx) && (len(v.Args) < 3 || astutil.IsSideEffectFree(v.Args[2], a.ctx.Info)) {
+			return
+		}
+	case *ast.Ident:
+		if _, ok := a.ctx.GetUses()[v].(*types.Nil); ok {
+			return
+		}
+	}
+	plan.reject("assigned from " + types.ExprString(e))
+}
+
+// planOf returns the plan of a local (identifier) or field (selector) candidate
+func (a *bitsetAnalysis) planOf(e ast.Expr) *bitsetPlan {
+	switch e := e.(type) {
+	case *ast.Ident:
+		if v, ok := a.ctx.GetUses()[e].(*types.Var); ok && !v.IsField() {
+			return a.plans[v]
+		}
+	case *ast.SelectorExpr:
+		if s := a.ctx.GetSelections()[e]; s != nil && s.Kind() == types.FieldVal {
+			return a.plans[s.Obj().(*types.Var)]
+		}
+	case *ast.ParenExpr:
+		return a.planOf(e.X)
+	}
+	return nil
+}
+
+// scan classifies every use of the candidates in file
+func (a *bitsetAnalysis) scan(file *ast.File) {
+	stdastutil.Apply(file, func(c *stdastutil.Cursor) bool {
+		switch node := c.Node().(type) {
+		case *ast.AssignStmt:
+			a.assign(node)
+		case *ast.CallExpr:
+			if isBuiltinCall(node, "len", a.ctx) && len(node.Args) == 1 && a.planOf(node.Args[0]) != nil {
+				a.accounted[node.Args[0]] = true
+			}
+		case *ast.RangeStmt:
+			if a.planOf(node.X) != nil {
+				a.accounted[node.X] = true
+			}
+		case *ast.IndexExpr:
+			plan := a.planOf(node.X)
+			if plan == nil || a.special[node] {
+				return true
+			}
+			a.accounted[node.X] = true
+			switch parent := c.Parent().(type) {
+			case *ast.UnaryExpr:
+				if parent.Op == token.AND {
+					plan.reject("element address taken")
+				}
+			case *ast.RangeStmt:
+				if c.Name() != "X" {
+					plan.reject("element assigned by range")
+				}
+			}
+		case *ast.CompositeLit:
+			st := structOf(a.ctx.GetTypes()[node].Type)
+			if st == nil {
+				return true
+			}
+			for i, elt := range node.Elts {
+				kv, keyed := elt.(*ast.KeyValueExpr)
+				if !keyed {
+					if i < st.NumFields() && a.plans[st.Field(i)] != nil {
+						a.plans[st.Field(i)].reject("unkeyed composite literal")
+					}
+					continue
+				}
+				if key, ok := kv.Key.(*ast.Ident); ok {
+					if v, ok := a.ctx.GetUses()[key].(*types.Var); ok && a.plans[v] != nil {
+						a.init(a.plans[v], kv.Value)
+					}
+				}
+			}
+		case *ast.Ident, *ast.SelectorExpr:
+			expr := node.(ast.Expr)
+			if plan := a.planOf(expr); plan != nil && !a.accounted[expr] {
+				plan.reject(bitsetEscape(c))
+			}
+		}
+		return true
+	}, nil)
+}
+
+// assign classifies element writes (s[i] = v) and values given to a candidate
+func (a *bitsetAnalysis) assign(as *ast.AssignStmt) {
+	for _, lhs := range as.Lhs {
+		ix, ok := lhs.(*ast.IndexExpr)
+		if !ok {
+			continue
+		}
+		plan := a.planOf(ix.X)
+		if plan == nil {
+			continue
+		}
+		a.special[ix], a.accounted[ix.X] = true, true
+		if len(as.Lhs) != 1 || len(as.Rhs) != 1 || as.Tok != token.ASSIGN {
+			plan.reject("multi-value assignment")
+		}
+	}
+
+	for i, lhs := range as.Lhs {
+		plan := a.planOf(lhs)
+		if plan == nil {
+			continue
+		}
+		a.accounted[lhs] = true
+		if len(as.Lhs) != len(as.Rhs) || as.Tok != token.ASSIGN && as.Tok != token.DEFINE {
+			plan.reject("assigned from a multi-value expression")
+			continue
+		}
+		// s = append(s, v...) com o mesmo s
+		if call, ok := ast.Unparen(as.Rhs[i]).(*ast.CallExpr); ok && isBuiltinCall(call, "append", a.ctx) {
+			if call.Ellipsis.IsValid() || !sameExpr(lhs, call.Args[0], a.ctx) {
+				plan.reject("append from another slice")
+				continue
+			}
+			a.accounted[call.Args[0]] = true
+			plan.appended = true
+			continue
+		}
+		a.init(plan, as.Rhs[i])
+	}
+}
+
+// structCopies returns, for each struct of the package, the first place where
+// a value of it is copied: assigned, passed, returned, stored, ranged over or
+// used as a value receiver. Arrays and structs holding it by value count too.
+func structCopies(files []*ast.File, ctx *astutil.TranspileContext) map[*types.TypeName]string {
+	copies := make(map[*types.TypeName]string)
+	record := func(t types.Type, how string) {
+		for _, tn := range valueStructs(t, ctx.Package, nil) {
+			if copies[tn] == "" {
+				copies[tn] = how
+			}
+		}
+	}
+	for _, file := range files {
+		stdastutil.Apply(file, func(c *stdastutil.Cursor) bool {
+			if rs, ok := c.Node().(*ast.RangeStmt); ok && rs.Value != nil {
+				if tv, ok := ctx.GetTypes()[rs.Value]; ok && tv.Type != nil {
+					record(tv.Type, "ranged over")
+				} else if id, ok := rs.Value.(*ast.Ident); ok && ctx.GetDefs()[id] != nil {
+					record(ctx.GetDefs()[id].Type(), "ranged over")
+				}
+			}
+			switch c.Node().(type) {
+			case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.StarExpr, *ast.ParenExpr:
+			default:
+				return true
+			}
+			expr := c.Node().(ast.Expr)
+			tv, ok := ctx.GetTypes()[expr]
+			if !ok || !tv.IsValue() || tv.Type == nil {
+				return true
+			}
+			how := "copied"
+			switch parent := c.Parent().(type) {
+			case *ast.SelectorExpr:
+				s := ctx.GetSelections()[parent]
+				if s == nil || s.Kind() != types.MethodVal {
+					return true
+				}
+				if sig, ok := s.Obj().Type().(*types.Signature); ok && sig.Recv() != nil {
+					if _, ptr := sig.Recv().Type().(*types.Pointer); ptr {
+						return true
+					}
+				}
+				how = "value receiver of " + parent.Sel.Name
+			case *ast.UnaryExpr:
+				if parent.Op == token.AND {
+					return true
+				}
+			case *ast.AssignStmt:
+				if c.Name() == "Lhs" {
+					return true
+				}
+				how = "assigned"
+			case *ast.ValueSpec:
+				how = "assigned"
+			case *ast.IndexExpr, *ast.SliceExpr, *ast.RangeStmt, *ast.ParenExpr, *ast.BinaryExpr, *ast.IncDecStmt, *ast.ExprStmt:
+				return true
+			case *ast.CallExpr:
+				how = "passed to " + types.ExprString(parent.Fun)
+			case *ast.ReturnStmt:
+				how = "returned"
+			case *ast.CompositeLit, *ast.KeyValueExpr:
+				how = "stored in a composite literal"
+			}
+			record(tv.Type, how)
+			return true
+		}, nil)
+	}
+	return copies
+}
+
+// valueStructs lists the named structs of pkg held by value in t: t itself,
+// its array elements and its fields, recursively
+func valueStructs(t types.Type, pkg *types.Package, seen map[types.Type]bool) []*types.TypeName {
+	if seen == nil {
+		seen = make(map[types.Type]bool)
+	}
+	if seen[t] {
+		return nil
+	}
+	seen[t] = true
+	var out []*types.TypeName
+	if named, ok := types.Unalias(t).(*types.Named); ok && named.Obj().Pkg() == pkg {
+		if _, ok := named.Underlying().(*types.Struct); ok {
+			out = append(out, named.Obj())
+		}
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Array:
+		out = append(out, valueStructs(u.Elem(), pkg, seen)...)
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			out = append(out, valueStructs(u.Field(i).Type(), pkg, seen)...)
+		}
+	}
+	return out
+}
+
+// bitsetEscape describes a use that needs a real []bool
+func bitsetEscape(c *stdastutil.Cursor) string {
+	switch parent := c.Parent().(type) {
+	case *ast.CallExpr:
+		return "passed to " + types.ExprString(parent.Fun)
+	case *ast.ReturnStmt:
+		return "returned"
+	case *ast.SliceExpr:
+		return "sliced"
+	case *ast.UnaryExpr:
+		if parent.Op == token.AND {
+			return "address taken"
+		}
+	case *ast.BinaryExpr:
+		return "compared"
+	case *ast.AssignStmt, *ast.ValueSpec:
+		return "copied to another variable"
+	case *ast.CompositeLit, *ast.KeyValueExpr:
+		return "stored in a composite literal"
+	case *ast.SendStmt:
+		return "sent on a channel"
+	}
+	return "used as a value"
+}
+
+func (p *BoolBitsetPass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	if len(p.order) == 0 {
+		return nil
+	}
+	r := &bitsetRewriter{pass: p, file: file, ctx: ctx, used: make(map[*bitsetType]bool)}
+
+	// === 1️⃣ Tipos dos campos empacotados ===
+	for _, plan := range p.order {
+		if plan.field != nil && plan.file == file {
+			plan.field.Type = ast.NewIdent(plan.bits.name)
+			r.used[plan.bits] = true
+			r.count++
+		}
+	}
+
+	// === 2️⃣ Usos: índices → Get/Set, len → Len, append → Append, range → for ===
+	stdastutil.Apply(file, nil, r.rewrite)
+
+	// === 3️⃣ Tipos gerados, uma vez por pacote ===
+	for _, bt := range append([]*bitsetType{p.slice}, p.sortedArrays()...) {
+		if bt == nil || bt.emitted || !r.used[bt] {
+			continue
+		}
+		decls, err := astutil.ParseDecls(fset, bitsetHelpers(bt))
+		if err != nil {
+			return fmt.Errorf("BoolBitset: %w", err)
+		}
+		file.Decls = append(file.Decls, decls...)
+		bt.emitted = true
+	}
+
+	if r.count > 0 {
+		gl.Log("info", fmt.Sprintf("🧮 BoolBitsetPass: %d transformations applied", r.count))
+	}
+	return nil
+}
+
+// sortedArrays returns the generated array types in allocation order
+func (p *BoolBitsetPass) sortedArrays() []*bitsetType {
+	var out []*bitsetType
+	seen := make(map[*bitsetType]bool)
+	for _, plan := range p.order {
+		if plan.array && !seen[plan.bits] {
+			seen[plan.bits] = true
+			out = append(out, plan.bits)
+		}
+	}
+	return out
+}
+
+// bitsetHelpers renders a generated bitset type and its methods
+func bitsetHelpers(bt *bitsetType) string {
+	if bt.length == 0 {
+		return fmt.Sprintf(`type %[1]s struct {
+	words []uint64
+	n     int
+}
+
+func %[2]s(n int) %[1]s {
+	if n < 0 {
+		panic("makeslice: len out of range")
+	}
+	return %[1]s{words: make([]uint64, (n+63)>>6), n: n}
+}
+
+func %[3]s(vs ...bool) %[1]s {
+	s := %[2]s(len(vs))
+	for i, v := range vs {
+		s.Set(i, v)
+	}
+	return s
+}
+
+func (s %[1]s) Len() int { return s.n }
+
+func (s %[1]s) Get(i int) bool {
+	if uint(i) >= uint(s.n) {
+		panic("index out of range")
+	}
+	return s.words[i>>6]&(1<<(uint(i)&63)) != 0
+}
+
+func (s %[1]s) Set(i int, v bool) {
+	if uint(i) >= uint(s.n) {
+		panic("index out of range")
+	}
+	if v {
+		s.words[i>>6] |= 1 << (uint(i) & 63)
+	} else {
+		s.words[i>>6] &^= 1 << (uint(i) & 63)
+	}
+}
+
+func (s %[1]s) Append(vs ...bool) %[1]s {
+	for _, v := range vs {
+		if s.n>>6 == len(s.words) {
+			s.words = append(s.words, 0)
+		}
+		s.n++
+		s.Set(s.n-1, v)
+	}
+	return s
+}
+`, bt.name, bt.make, bt.of)
+	}
+	return fmt.Sprintf(`type %[1]s [%[3]d]uint64
+
+func %[2]s(vs ...bool) (a %[1]s) {
+	for i, v := range vs {
+		a.Set(i, v)
+	}
+	return a
+}
+
+func (a %[1]s) Len() int { return %[4]d }
+
+func (a %[1]s) Get(i int) bool {
+	if uint(i) >= %[4]d {
+		panic("index out of range")
+	}
+	return a[i>>6]&(1<<(uint(i)&63)) != 0
+}
+
+func (a *%[1]s) Set(i int, v bool) {
+	if uint(i) >= %[4]d {
+		panic("index out of range")
+	}
+	if v {
+		a[i>>6] |= 1 << (uint(i) & 63)
+	} else {
+		a[i>>6] &^= 1 << (uint(i) & 63)
+	}
+}
+`, bt.name, bt.of, (bt.length+63)/64, bt.length)
+}
+
+// bitsetRewriter rewrites the uses of the accepted candidates in one file
+type bitsetRewriter struct {
+	pass  *BoolBitsetPass
+	file  *ast.File
+	ctx   *astutil.TranspileContext
+	used  map[*bitsetType]bool
+	count int
+}
+
+// planOf returns the plan of a local or field candidate, declared or used
+func (r *bitsetRewriter) planOf(e ast.Expr) *bitsetPlan {
+	switch e := e.(type) {
+	case *ast.Ident:
+		obj := r.ctx.GetUses()[e]
+		if obj == nil {
+			obj = r.ctx.GetDefs()[e]
+		}
+		if v, ok := obj.(*types.Var); ok && !v.IsField() {
+			return r.pass.plans[v]
+		}
+	case *ast.SelectorExpr:
+		if s := r.ctx.GetSelections()[e]; s != nil && s.Kind() == types.FieldVal {
+			return r.pass.plans[s.Obj().(*types.Var)]
+		}
+	case *ast.ParenExpr:
+		return r.planOf(e.X)
+	}
+	return nil
+}
+
+// index converts an index to int when needed: Get and Set take an int
+func (r *bitsetRewriter) index(e ast.Expr) ast.Expr {
+	if tv, ok := r.ctx.GetTypes()[e]; ok && tv.Type != nil {
+		if basic, ok := tv.Type.(*types.Basic); ok && (basic.Kind() == types.Int || basic.Info()&types.IsUntyped != 0) {
+			return e
+		}
+	}
+	return &ast.CallExpr{Fun: ast.NewIdent("int"), Args: []ast.Expr{e}}
+}
+
+// value renders the bitset for a make, literal or nil given to plan
+func (r *bitsetRewriter) value(plan *bitsetPlan, e ast.Expr) ast.Expr {
+	bt := plan.bits
+	r.used[bt] = true
+	switch v := ast.Unparen(e).(type) {
+	case *ast.CallExpr:
+		if isBuiltinCall(v, "make", r.ctx) {
+			return &ast.CallExpr{Fun: ast.NewIdent(bt.make), Args: []ast.Expr{r.index(v.Args[1])}}
+		}
+	case *ast.CompositeLit:
+		if len(v.Elts) > 0 {
+			return &ast.CallExpr{Fun: ast.NewIdent(bt.of), Args: v.Elts}
+		}
+	}
+	return &ast.CompositeLit{Type: ast.NewIdent(bt.name)}
+}
+
+func method(x ast.Expr, name string, args ...ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{Fun: &ast.SelectorExpr{X: x, Sel: ast.NewIdent(name)}, Args: args}
+}
+
+// rewrite runs after the children of each node were rewritten
+func (r *bitsetRewriter) rewrite(c *stdastutil.Cursor) bool {
+	switch node := c.Node().(type) {
+	case *ast.IndexExpr:
+		if r.planOf(node.X) == nil {
+			return true
+		}
+		if as, ok := c.Parent().(*ast.AssignStmt); ok && c.Name() == "Lhs" && len(as.Lhs) == 1 {
+			return true
+		}
+		c.Replace(method(node.X, "Get", r.index(node.Index)))
+		r.count++
+
+	case *ast.AssignStmt:
+		if len(node.Lhs) == 1 && len(node.Rhs) == 1 {
+			if ix, ok := node.Lhs[0].(*ast.IndexExpr); ok && r.planOf(ix.X) != nil {
+				c.Replace(&ast.ExprStmt{X: method(ix.X, "Set", r.index(ix.Index), node.Rhs[0])})
+				r.count++
+				return true
+			}
+		}
+		for i, lhs := range node.Lhs {
+			plan := r.planOf(lhs)
+			if plan == nil || i >= len(node.Rhs) {
+				continue
+			}
+			if call, ok := ast.Unparen(node.Rhs[i]).(*ast.CallExpr); ok && isBuiltinCall(call, "append", r.ctx) {
+				node.Rhs[i] = method(call.Args[0], "Append", call.Args[1:]...)
+			} else {
+				node.Rhs[i] = r.value(plan, node.Rhs[i])
+			}
+			r.count++
+		}
+
+	case *ast.ValueSpec:
+		if len(node.Names) != 1 {
+			return true
+		}
+		plan := r.planOf(node.Names[0])
+		if plan == nil {
+			return true
+		}
+		node.Type = ast.NewIdent(plan.bits.name)
+		r.used[plan.bits] = true
+		if len(node.Values) == 1 {
+			if value := r.value(plan, node.Values[0]); isEmptyLit(value) {
+				node.Values = nil
+			} else {
+				node.Values[0] = value
+			}
+		}
+		r.count++
+
+	case *ast.KeyValueExpr:
+		key, ok := node.Key.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if v, ok := r.ctx.GetUses()[key].(*types.Var); ok && v.IsField() && r.pass.plans[v] != nil {
+			node.Value = r.value(r.pass.plans[v], node.Value)
+			r.count++
+		}
+
+	case *ast.CallExpr:
+		if !isBuiltinCall(node, "len", r.ctx) || len(node.Args) != 1 {
+			return true
+		}
+		plan := r.planOf(node.Args[0])
+		if plan == nil {
+			return true
+		}
+		if tv, ok := r.ctx.GetTypes()[node]; ok && tv.Value != nil {
+			c.Replace(&ast.BasicLit{Kind: token.INT, Value: strconv.FormatInt(plan.length, 10)})
+		} else {
+			c.Replace(method(node.Args[0], "Len"))
+		}
+		r.count++
+
+	case *ast.RangeStmt:
+		if r.planOf(node.X) == nil {
+			return true
+		}
+		c.Replace(r.rangeLoop(node))
+		r.count++
+	}
+	return true
+}
+
+func isEmptyLit(e ast.Expr) bool {
+	lit, ok := e.(*ast.CompositeLit)
+	return ok && len(lit.Elts) == 0
+}
+
+// rangeLoop walks a copy of the bitset: range evaluates its operand once,
+// and a copied array keeps the values the range would have seen
+//
+//	for k, v := range s {  →  for bi, bs := 0, s; bi < bs.Len(); bi++ {
+//	                              k, v := bi, bs.Get(bi)
+func (r *bitsetRewriter) rangeLoop(rs *ast.RangeStmt) ast.Stmt {
+	names := freshLocalNames(r.file, "b", 2)
+	// Objetos para as vars do loop: flatten e opaque só mexem no que tem tipo
+	idxVar := types.NewVar(token.NoPos, r.ctx.Package, names[0], types.Typ[types.Int])
+	tmpVar := types.NewVar(token.NoPos, r.ctx.Package, names[1], r.planOf(rs.X).bits.named)
+	idx := func() *ast.Ident { return r.bind(names[0], idxVar, r.ctx.GetUses()) }
+	tmp := func() *ast.Ident { return r.bind(names[1], tmpVar, r.ctx.GetUses()) }
+	var body []ast.Stmt
+	var lhs, rhs []ast.Expr
+	if rs.Key != nil && !isBlank(rs.Key) {
+		lhs, rhs = append(lhs, rs.Key), append(rhs, idx())
+	}
+	if rs.Value != nil && !isBlank(rs.Value) {
+		if len(lhs) == 0 && rs.Tok == token.DEFINE {
+			lhs, rhs = append(lhs, ast.NewIdent("_")), append(rhs, idx())
+		}
+		lhs, rhs = append(lhs, rs.Value), append(rhs, method(tmp(), "Get", idx()))
+	}
+	if len(lhs) > 0 {
+		body = append(body, &ast.AssignStmt{Lhs: lhs, Tok: rs.Tok, Rhs: rhs})
+	}
+	body = append(body, rs.Body.List...)
+	return &ast.ForStmt{
+		For: rs.For,
+		Init: &ast.AssignStmt{
+			Lhs: []ast.Expr{r.bind(names[0], idxVar, r.ctx.GetDefs()), r.bind(names[1], tmpVar, r.ctx.GetDefs())},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{&ast.BasicLit{Kind: token.INT, Value: "0"}, rs.X},
+		},
+		Cond: &ast.BinaryExpr{X: idx(), Op: token.LSS, Y: method(tmp(), "Len")},
+		Post: &ast.IncDecStmt{X: idx(), Tok: token.INC},
+		Body: &ast.BlockStmt{Lbrace: rs.Body.Lbrace, List: body, Rbrace: rs.Body.Rbrace},
+	}
+}
+
+// bind returns a new identifier recorded as a definition or use of obj
+func (r *bitsetRewriter) bind(name string, obj types.Object, into map[*ast.Ident]types.Object) *ast.Ident {
+	id := ast.NewIdent(name)
+	into[id] = obj
+	return id
+}
+
+func isBlank(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "_"
+}
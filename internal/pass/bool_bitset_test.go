@@ -0,0 +1,120 @@
+package pass
+
+import "testing"
+
+const boolBitsetProbe = `package main
+
+import "fmt"
+
+type grid struct {
+	cells [64]bool
+	name  string
+}
+
+func sieve(n int) []int {
+	composite := make([]bool, n)
+	var primes []int
+	for i := 2; i < n; i++ {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, i)
+		for j := i * i; j < n; j += i {
+			composite[j] = true
+		}
+	}
+	return primes
+}
+
+func flags() (int, bool) {
+	var seen []bool
+	for i := 0; i < 70; i++ {
+		seen = append(seen, i%3 == 0)
+	}
+	count := 0
+	for i, v := range seen {
+		if v && i%2 == 0 {
+			count++
+		}
+	}
+	return count, len(seen) == 70
+}
+
+func arrays() (bool, bool, int) {
+	var mask [128]bool
+	mask[5] = true
+	mask[127] = true
+	// Copiado para outra variável: os dois ficam [128]bool
+	var a [128]bool
+	a[5] = true
+	b := a
+	b[5] = false
+	g := grid{name: "g"}
+	g.cells[63] = true
+	h := g
+	h.cells[63] = false
+	n := 0
+	for _, v := range mask {
+		if v {
+			n++
+		}
+	}
+	return a == b, g.cells[63] && !h.cells[63], n
+}
+
+// Fora do tamanho: o pânico continua acontecendo
+func outOfRange(i int) (panicked bool) {
+	defer func() { panicked = recover() != nil }()
+	s := make([]bool, 3)
+	s[0] = true
+	return s[i]
+}
+
+func use(s []bool) int { return len(s) }
+
+func main() {
+	fmt.Println(sieve(50))
+	fmt.Println(flags())
+	fmt.Println(arrays())
+	fmt.Println(outOfRange(2), outOfRange(3), outOfRange(-1))
+
+	// Sai como []bool: fica como está
+	passed := make([]bool, 10)
+	fmt.Println(use(passed))
+
+	// Pequeno demais para economizar
+	var tiny [4]bool
+	tiny[1] = true
+	fmt.Println(tiny[1], tiny[2])
+
+	// Endereço de elemento tomado
+	addr := make([]bool, 2)
+	p := &addr[1]
+	*p = true
+	fmt.Println(addr[1])
+}
+`
+
+func TestBoolBitsetPreservesBehavior(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, boolBitsetProbe)
+	out, ctx := transpileSource(t, boolBitsetProbe, false, NewBoolBitsetPass())
+	rejected := map[string]bool{}
+	for _, e := range ctx.Ledger {
+		if e.Pass == "BoolBitset" && e.Action == "rejected" {
+			rejected[e.Target] = true
+		}
+	}
+	for _, name := range []string{"a", "b", "passed", "tiny", "addr"} {
+		if !rejected[name] {
+			t.Errorf("%s was not rejected\n%+v", name, ctx.Ledger)
+		}
+	}
+	// composite, seen, mask, grid.cells, s
+	if got := countLedger(ctx, "BoolBitset", "rewritten"); got != 5 {
+		t.Errorf("%d values rewritten, want 5\n%+v\n%s", got, ctx.Ledger, out)
+	}
+	if got := runSource(t, out); got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+}
@@ -0,0 +1,609 @@
+package pass
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	stdastutil "golang.org/x/tools/go/ast/astutil"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// BoolParamsPass junta os parâmetros bool de uma função num único parâmetro
+// flag gerado, com uma constante por parâmetro. A chamada deixa de ser uma
+// fila de true/false posicional e passa a nomear o que liga.
+//
+//	func receiveBoolArgs(first, second, third bool)  →  func receiveBoolArgs(flags receiveBoolArgsFlags)
+//	if first {                                        →  if flags&receiveBoolArgsFirst != 0 {
+//	receiveBoolArgs(true, false, true)                →  receiveBoolArgs(receiveBoolArgsFirst | receiveBoolArgsThird)
+//	receiveBoolArgs(a, b, false)                      →  receiveBoolArgs(receiveBoolArgsFirst.when(a) | receiveBoolArgsSecond.when(b))
+//
+// Entram funções e métodos não exportados (ou de package main) com dois ou
+// mais parâmetros do tipo bool. Ficam de fora funções usadas como valor
+// (atribuídas, passadas, method values), métodos cujo nome aparece em alguma
+// interface, genéricos, parâmetros com endereço tomado e chamadas em que
+// juntar os bools mudaria a ordem de avaliação de argumentos com efeito.
+type BoolParamsPass struct {
+	plans  map[*types.Func]*boolParamsPlan
+	params map[*types.Var]boolParamsBit // parâmetro bool original → bit
+	order  []*boolParamsPlan
+}
+
+// boolParamsPlan describes one function and the flag type of its bool parameters
+type boolParamsPlan struct {
+	fn       *types.Func
+	decl     *ast.FuncDecl
+	file     *ast.File
+	name     string // "receiveBoolArgs" ou "server.start"
+	pos      token.Position
+	bools    []int    // índices dos parâmetros bool na assinatura
+	names    []string // nome de cada parâmetro bool ("" se anônimo ou _)
+	consts   []string // constante de cada parâmetro bool
+	typeName string
+	word     string
+	param    *types.Var // parâmetro gerado
+	calls    int
+	when     bool // alguma chamada passa um bool não constante
+	reason   string
+}
+
+// boolParamsBit is the bit of one original bool parameter
+type boolParamsBit struct {
+	plan *boolParamsPlan
+	k    int
+}
+
+func NewBoolParamsPass() *BoolParamsPass { return &BoolParamsPass{} }
+func (p *BoolParamsPass) Name() string   { return "BoolParams" }
+
+func (plan *boolParamsPlan) reject(reason string) {
+	if plan.reason == "" {
+		plan.reason = reason
+	}
+}
+
+// Prepare finds the candidate functions, checks every use of them and of
+// their bool parameters, and declares the generated flag types
+func (p *BoolParamsPass) Prepare(files []*ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	p.plans = make(map[*types.Func]*boolParamsPlan)
+	p.params = make(map[*types.Var]boolParamsBit)
+	p.order = nil
+	if ctx.Package == nil {
+		return nil
+	}
+	a := &boolParamsAnalysis{
+		fset: fset, ctx: ctx,
+		plans:     make(map[*types.Func]*boolParamsPlan),
+		params:    make(map[*types.Var]boolParamsBit),
+		accounted: make(map[*ast.Ident]bool),
+		ifaces:    interfaceMethodNames(ctx),
+	}
+
+	// === 1️⃣ Coleta as funções com dois ou mais parâmetros bool ===
+	for _, file := range files {
+		a.collect(file)
+	}
+	if len(a.order) == 0 {
+		return nil
+	}
+
+	// === 2️⃣ Classifica chamadas e usos dos parâmetros ===
+	for _, file := range files {
+		a.scan(file)
+	}
+
+	// === 3️⃣ Decide, nomeia e declara os tipos gerados ===
+	used := make(map[string]bool)
+	for _, file := range files {
+		collectIdentNames(file, used)
+	}
+	for _, plan := range a.order {
+		if plan.reason != "" {
+			gl.Log("info", fmt.Sprintf("BoolParams: skipping %s (%s)", plan.name, plan.reason))
+			ctx.RecordLedger(p.Name(), plan.pos, plan.name, "rejected", plan.reason)
+			continue
+		}
+		p.allocate(plan, used)
+		named := declareFlagType(ctx, plan.typeName, plan.word, plan.consts)
+		plan.param = types.NewParam(plan.decl.Type.Params.Opening, ctx.Package, boolParamsParamName(plan.decl), named)
+		p.plans[plan.fn] = plan
+		p.order = append(p.order, plan)
+	}
+	for v, bit := range a.params {
+		if p.plans[bit.plan.fn] != nil {
+			p.params[v] = bit
+		}
+	}
+	return nil
+}
+
+// allocate picks the flag type and the constant of each bool parameter
+func (p *BoolParamsPass) allocate(plan *boolParamsPlan, used map[string]bool) {
+	plan.word = astutil.MenorTipoParaFlags(len(plan.bools))
+	base := plan.fn.Name()
+	if recv := plan.fn.Type().(*types.Signature).Recv(); recv != nil {
+		if named := derefNamed(recv.Type()); named != nil {
+			base = named.Obj().Name() + mapSetKeyName(base)
+		}
+	}
+	for i := 1; ; i++ {
+		prefix := base
+		if i > 1 {
+			prefix += strconv.Itoa(i)
+		}
+		typeName := prefix + "Flags"
+		consts := make([]string, len(plan.bools))
+		names := []string{typeName}
+		seen := make(map[string]bool)
+		for k, name := range plan.names {
+			suffix := mapSetKeyName(name)
+			if suffix == "" || suffix == "Flags" || seen[suffix] {
+				suffix = "Arg" + strconv.Itoa(plan.bools[k])
+			}
+			seen[suffix] = true
+			consts[k] = prefix + suffix
+			names = append(names, consts[k])
+		}
+		free := true
+		for _, name := range names {
+			if used[name] || types.Universe.Lookup(name) != nil {
+				free = false
+				break
+			}
+		}
+		if !free {
+			continue
+		}
+		for _, name := range names {
+			used[name] = true
+		}
+		plan.typeName, plan.consts = typeName, consts
+		return
+	}
+}
+
+// declareFlagType adds a generated flag type and its constants (1, 2, 4...)
+// to the package scope
+func declareFlagType(ctx *astutil.TranspileContext, typeName, word string, consts []string) *types.Named {
+	words := map[string]types.Type{
+		"uint8": types.Typ[types.Uint8], "uint16": types.Typ[types.Uint16],
+		"uint32": types.Typ[types.Uint32], "uint64": types.Typ[types.Uint64],
+	}
+	tn := types.NewTypeName(token.NoPos, ctx.Package, typeName, nil)
+	named := types.NewNamed(tn, words[word], nil)
+	ctx.Package.Scope().Insert(tn)
+	for k, name := range consts {
+		ctx.Package.Scope().Insert(types.NewConst(token.NoPos, ctx.Package, name, named, constant.MakeUint64(1<<k)))
+	}
+	return named
+}
+
+// boolParamsParamName returns "flags", or "flags" with a numeric suffix, so
+// that it appears nowhere in decl
+func boolParamsParamName(decl *ast.FuncDecl) string {
+	used := make(map[string]bool)
+	ast.Inspect(decl, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			used[id.Name] = true
+		}
+		return true
+	})
+	name := "flags"
+	for i := 2; used[name]; i++ {
+		name = "flags" + strconv.Itoa(i)
+	}
+	return name
+}
+
+// boolParamsAnalysis collects the candidate functions of a package and classifies their uses
+type boolParamsAnalysis struct {
+	fset      *token.FileSet
+	ctx       *astutil.TranspileContext
+	plans     map[*types.Func]*boolParamsPlan
+	params    map[*types.Var]boolParamsBit
+	order     []*boolParamsPlan
+	accounted map[*ast.Ident]bool // usos já classificados pelo nó pai
+	ifaces    map[string]bool
+}
+
+// collect registers the functions of file with two or more bool parameters
+func (a *boolParamsAnalysis) collect(file *ast.File) {
+	for _, decl := range file.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Body == nil {
+			continue
+		}
+		fn, ok := a.ctx.GetDefs()[fd.Name].(*types.Func)
+		if !ok {
+			continue
+		}
+		sig := fn.Type().(*types.Signature)
+		var bools []int
+		for i := 0; i < sig.Params().Len(); i++ {
+			if types.Identical(sig.Params().At(i).Type(), types.Typ[types.Bool]) {
+				bools = append(bools, i)
+			}
+		}
+		if len(bools) < 2 {
+			continue
+		}
+
+		plan := &boolParamsPlan{fn: fn, decl: fd, file: file, name: fn.Name(), pos: a.fset.Position(fd.Pos()), bools: bools}
+		if sig.Recv() != nil {
+			if named := derefNamed(sig.Recv().Type()); named != nil {
+				plan.name = named.Obj().Name() + "." + fn.Name()
+			}
+		}
+		switch {
+		case fn.Exported() && a.ctx.Package.Name() != "main":
+			plan.reject("exported: callers outside the package")
+		case sig.TypeParams().Len() > 0 || sig.RecvTypeParams().Len() > 0:
+			plan.reject("generic function")
+		case sig.Recv() != nil && a.ifaces[fn.Name()]:
+			plan.reject("method name appears in an interface")
+		case len(bools) > 64:
+			plan.reject(fmt.Sprintf("%d bool parameters do not fit in 64 bits", len(bools)))
+		}
+
+		// Liga cada parâmetro bool nomeado ao seu bit
+		i := 0
+		for _, field := range fd.Type.Params.List {
+			n := len(field.Names)
+			if n == 0 {
+				n = 1
+			}
+			for j := 0; j < n; j++ {
+				k := indexOf(bools, i)
+				if k >= 0 {
+					name := ""
+					if j < len(field.Names) && field.Names[j].Name != "_" {
+						name = field.Names[j].Name
+						if v, ok := a.ctx.GetDefs()[field.Names[j]].(*types.Var); ok {
+							a.params[v] = boolParamsBit{plan: plan, k: k}
+						}
+					}
+					plan.names = append(plan.names, name)
+				}
+				i++
+			}
+		}
+		a.plans[fn] = plan
+		a.order = append(a.order, plan)
+	}
+}
+
+func indexOf(xs []int, x int) int {
+	for i, v := range xs {
+		if v == x {
+			return i
+		}
+	}
+	return -1
+}
+
+// calledFunc returns the package function or method called by call and the
+// identifier that names it; calls through function values return nil
+func calledFunc(call *ast.CallExpr, ctx *astutil.TranspileContext) (*types.Func, *ast.Ident) {
+	var id *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		id = fun
+	case *ast.SelectorExpr:
+		if s := ctx.GetSelections()[fun]; s == nil || s.Kind() != types.MethodVal {
+			return nil, nil
+		}
+		id = fun.Sel
+	default:
+		return nil, nil
+	}
+	fn, ok := ctx.GetUses()[id].(*types.Func)
+	if !ok {
+		return nil, nil
+	}
+	return fn, id
+}
+
+// paramOf returns the bit of the bool parameter named by e
+func (a *boolParamsAnalysis) paramOf(e ast.Expr) (boolParamsBit, bool) {
+	id, ok := ast.Unparen(e).(*ast.Ident)
+	if !ok {
+		return boolParamsBit{}, false
+	}
+	v, ok := a.ctx.GetUses()[id].(*types.Var)
+	if !ok {
+		return boolParamsBit{}, false
+	}
+	bit, ok := a.params[v]
+	return bit, ok
+}
+
+// scan classifies the calls of the candidates and the uses of their bool parameters
+func (a *boolParamsAnalysis) scan(file *ast.File) {
+	stdastutil.Apply(file, func(c *stdastutil.Cursor) bool {
+		switch node := c.Node().(type) {
+		case *ast.CallExpr:
+			if fn, id := calledFunc(node, a.ctx); a.plans[fn] != nil {
+				a.accounted[id] = true
+				a.call(a.plans[fn], node)
+			}
+		case *ast.AssignStmt:
+			for _, lhs := range node.Lhs {
+				bit, ok := a.paramOf(lhs)
+				if !ok {
+					continue
+				}
+				if len(node.Lhs) != 1 || len(node.Rhs) != 1 || node.Tok != token.ASSIGN {
+					bit.plan.reject(fmt.Sprintf("parameter %s assigned in a multi-value assignment", bit.plan.names[bit.k]))
+				}
+			}
+		case *ast.RangeStmt:
+			for _, e := range []ast.Expr{node.Key, node.Value} {
+				if bit, ok := a.paramOf(e); ok && node.Tok == token.ASSIGN {
+					bit.plan.reject(fmt.Sprintf("parameter %s assigned by range", bit.plan.names[bit.k]))
+				}
+			}
+		case *ast.UnaryExpr:
+			if bit, ok := a.paramOf(node.X); ok && node.Op == token.AND {
+				bit.plan.reject(fmt.Sprintf("address of parameter %s taken", bit.plan.names[bit.k]))
+			}
+		case *ast.Ident:
+			if a.accounted[node] {
+				return true
+			}
+			if fn, ok := a.ctx.GetUses()[node].(*types.Func); ok && a.plans[fn] != nil {
+				a.plans[fn].reject(fmt.Sprintf("used as a value at %s", a.fset.Position(node.Pos())))
+			}
+		}
+		return true
+	}, nil)
+}
+
+// call checks one call of plan: the bool arguments are merged at the position
+// of the first one, so no argument with side effects may be crossed by another
+func (a *boolParamsAnalysis) call(plan *boolParamsPlan, call *ast.CallExpr) {
+	sig := plan.fn.Type().(*types.Signature)
+	if len(call.Args) < sig.Params().Len() {
+		plan.reject(fmt.Sprintf("called with a multi-value argument at %s", a.fset.Position(call.Pos())))
+		return
+	}
+	plan.calls++
+	info := a.ctx.Info
+	first := plan.bools[0]
+	for _, j := range plan.bools {
+		if _, ok := boolConst(call.Args[j], a.ctx); !ok {
+			plan.when = true
+		}
+		if astutil.IsSideEffectFree(call.Args[j], info) {
+			continue
+		}
+		for k := first + 1; k < j; k++ {
+			if indexOf(plan.bools, k) < 0 && !astutil.IsSideEffectFree(call.Args[k], info) {
+				plan.reject(fmt.Sprintf("merging arguments would reorder side effects at %s", a.fset.Position(call.Pos())))
+				return
+			}
+		}
+	}
+}
+
+func (p *BoolParamsPass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	if len(p.order) == 0 {
+		return nil
+	}
+	r := &boolParamsRewriter{pass: p, ctx: ctx}
+	stdastutil.Apply(file, nil, r.rewrite)
+
+	for _, plan := range p.order {
+		if plan.file != file {
+			continue
+		}
+		decls, err := astutil.ParseDecls(fset, flagTypeDecls(plan.typeName, plan.word, plan.consts, plan.when))
+		if err != nil {
+			return fmt.Errorf("BoolParams: %w", err)
+		}
+		file.Decls = append(file.Decls, decls...)
+		ctx.RecordLedger(p.Name(), plan.pos, plan.name, "rewritten",
+			fmt.Sprintf("%d bool params → %s %s (%d call sites)", len(plan.bools), plan.word, plan.typeName, plan.calls))
+	}
+
+	if r.count > 0 {
+		ctx.LogVerbose(fset, "🚩 BoolParamsPass: %d transformations applied", r.count)
+	}
+	return nil
+}
+
+// flagTypeDecls renders a flag type, one constant per bit and, when some
+// bit is set from a non-constant bool, the when helper
+func flagTypeDecls(typeName, word string, consts []string, when bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "type %s %s\n\nconst (\n", typeName, word)
+	for k, name := range consts {
+		if k == 0 {
+			fmt.Fprintf(&b, "\t%s %s = 1 << iota\n", name, typeName)
+		} else {
+			fmt.Fprintf(&b, "\t%s\n", name)
+		}
+	}
+	b.WriteString(")\n")
+	if when {
+		fmt.Fprintf(&b, `
+// when returns f if b is true and 0 otherwise
+func (f %[1]s) when(b bool) %[1]s {
+	if b {
+		return f
+	}
+	return 0
+}
+`, typeName)
+	}
+	return b.String()
+}
+
+// flagMask renders the OR of consts[k] for each true values[k]: constants
+// are folded, other values go through when
+func flagMask(consts []string, values []ast.Expr, ctx *astutil.TranspileContext) ast.Expr {
+	var mask ast.Expr
+	for k, v := range values {
+		var term ast.Expr
+		if value, ok := boolConst(v, ctx); ok {
+			if !value {
+				continue
+			}
+			term = ast.NewIdent(consts[k])
+		} else {
+			term = method(ast.NewIdent(consts[k]), "when", v)
+		}
+		if mask == nil {
+			mask = term
+		} else {
+			mask = &ast.BinaryExpr{X: mask, Op: token.OR, Y: term}
+		}
+	}
+	if mask == nil {
+		mask = &ast.BasicLit{Kind: token.INT, Value: "0"}
+	}
+	return mask
+}
+
+// flagTest renders x&bit != 0
+func flagTest(x ast.Expr, bit string) ast.Expr {
+	return &ast.BinaryExpr{
+		X:  &ast.BinaryExpr{X: x, Op: token.AND, Y: ast.NewIdent(bit)},
+		Op: token.NEQ,
+		Y:  &ast.BasicLit{Kind: token.INT, Value: "0"},
+	}
+}
+
+// boolParamsRewriter rewrites the declarations, bodies and calls of the accepted functions in one file
+type boolParamsRewriter struct {
+	pass  *BoolParamsPass
+	ctx   *astutil.TranspileContext
+	count int
+}
+
+// paramOf returns the bit of the bool parameter named by e
+func (r *boolParamsRewriter) paramOf(e ast.Expr) (boolParamsBit, bool) {
+	id, ok := ast.Unparen(e).(*ast.Ident)
+	if !ok {
+		return boolParamsBit{}, false
+	}
+	v, ok := r.ctx.GetUses()[id].(*types.Var)
+	if !ok {
+		return boolParamsBit{}, false
+	}
+	bit, ok := r.pass.params[v]
+	return bit, ok
+}
+
+// flags returns a new identifier for the generated parameter of plan
+func (r *boolParamsRewriter) flags(plan *boolParamsPlan) *ast.Ident {
+	id := ast.NewIdent(plan.param.Name())
+	r.ctx.GetUses()[id] = plan.param
+	return id
+}
+
+// rewrite runs after the children of each node were rewritten
+func (r *boolParamsRewriter) rewrite(c *stdastutil.Cursor) bool {
+	switch node := c.Node().(type) {
+	case *ast.Ident:
+		bit, ok := r.paramOf(node)
+		if !ok || c.Name() == "Lhs" {
+			return true
+		}
+		test := flagTest(r.flags(bit.plan), bit.plan.consts[bit.k])
+		switch c.Parent().(type) {
+		case *ast.UnaryExpr, *ast.BinaryExpr:
+			test = &ast.ParenExpr{X: test}
+		}
+		c.Replace(test)
+		r.count++
+
+	case *ast.AssignStmt:
+		if len(node.Lhs) != 1 {
+			return true
+		}
+		bit, ok := r.paramOf(node.Lhs[0])
+		if !ok {
+			return true
+		}
+		// first = v  →  flags |= C, flags &^= C ou flags = flags&^C | C.when(v)
+		plan, bitName := bit.plan, ast.NewIdent(bit.plan.consts[bit.k])
+		if value, ok := boolConst(node.Rhs[0], r.ctx); ok {
+			tok := token.OR_ASSIGN
+			if !value {
+				tok = token.AND_NOT_ASSIGN
+			}
+			c.Replace(&ast.AssignStmt{Lhs: []ast.Expr{r.flags(plan)}, TokPos: node.TokPos, Tok: tok, Rhs: []ast.Expr{bitName}})
+		} else {
+			plan.when = true
+			c.Replace(&ast.AssignStmt{Lhs: []ast.Expr{r.flags(plan)}, TokPos: node.TokPos, Tok: token.ASSIGN, Rhs: []ast.Expr{
+				&ast.BinaryExpr{
+					X:  &ast.BinaryExpr{X: r.flags(plan), Op: token.AND_NOT, Y: ast.NewIdent(plan.consts[bit.k])},
+					Op: token.OR,
+					Y:  method(bitName, "when", node.Rhs[0]),
+				},
+			}})
+		}
+		r.count++
+
+	case *ast.CallExpr:
+		fn, _ := calledFunc(node, r.ctx)
+		plan := r.pass.plans[fn]
+		if plan == nil {
+			return true
+		}
+		values := make([]ast.Expr, len(plan.bools))
+		for k, i := range plan.bools {
+			values[k] = node.Args[i]
+		}
+		mask := flagMask(plan.consts, values, r.ctx)
+		args := make([]ast.Expr, 0, len(node.Args)-len(plan.bools)+1)
+		for i, arg := range node.Args {
+			switch {
+			case i == plan.bools[0]:
+				args = append(args, mask)
+			case indexOf(plan.bools, i) < 0:
+				args = append(args, arg)
+			}
+		}
+		node.Args = args
+		r.count++
+
+	case *ast.FuncDecl:
+		fn, ok := r.ctx.GetDefs()[node.Name].(*types.Func)
+		if !ok || r.pass.plans[fn] == nil {
+			return true
+		}
+		plan := r.pass.plans[fn]
+		// Os parâmetros bool saem; o parâmetro flag entra no lugar do primeiro
+		param := ast.NewIdent(plan.param.Name())
+		r.ctx.GetDefs()[param] = plan.param
+		var list []*ast.Field
+		i, inserted := 0, false
+		for _, field := range node.Type.Params.List {
+			n := len(field.Names)
+			if n == 0 {
+				n = 1
+			}
+			if indexOf(plan.bools, i) < 0 {
+				list = append(list, field)
+			} else if !inserted {
+				param.NamePos = field.Pos()
+				typ := ast.NewIdent(plan.typeName)
+				typ.NamePos = field.Type.Pos()
+				list = append(list, &ast.Field{Names: []*ast.Ident{param}, Type: typ})
+				inserted = true
+			}
+			i += n
+		}
+		node.Type.Params.List = list
+		r.count++
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package pass
+
+import "testing"
+
+const boolParamsProbe = `package main
+
+import "fmt"
+
+var trace []string
+
+func step(name string, v bool) bool {
+	trace = append(trace, name)
+	return v
+}
+
+func count(name string, n int) int {
+	trace = append(trace, name)
+	return n
+}
+
+func configure(verbose, dry, force bool) string {
+	return fmt.Sprint(verbose, dry, force)
+}
+
+func mixed(a bool, n int, b bool) string {
+	if a {
+		n++
+	}
+	return fmt.Sprint(a, n, b)
+}
+
+// Entre os dois bools há um argumento com efeito: juntar os bools mudaria a ordem
+func crossed(a bool, n int, b bool) string {
+	return fmt.Sprint(a, n, b)
+}
+
+// Usada como valor
+func callback(a, b bool) bool { return a && b }
+
+// Endereço de parâmetro tomado
+func pointer(a, b bool) bool {
+	p := &a
+	*p = !*p
+	return a || b
+}
+
+type toggler interface{ toggle(a, b bool) bool }
+
+type light struct{}
+
+// O nome aparece numa interface
+func (light) toggle(a, b bool) bool { return a != b }
+
+func main() {
+	fmt.Println(configure(true, false, true))
+	fmt.Println(configure(step("v", true), step("d", false), step("f", true)))
+	fmt.Println(mixed(step("a", true), 4, step("b", false)))
+	fmt.Println(crossed(step("a", true), count("n", 7), step("b", true)))
+	fmt.Println(trace)
+
+	f := callback
+	fmt.Println(f(true, true), pointer(true, false))
+	var t toggler = light{}
+	fmt.Println(t.toggle(true, false))
+}
+`
+
+func TestBoolParamsPreservesBehavior(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, boolParamsProbe)
+	out, ctx := transpileSource(t, boolParamsProbe, false, NewBoolParamsPass())
+	rejected := map[string]bool{}
+	for _, e := range ctx.Ledger {
+		if e.Pass == "BoolParams" && e.Action == "rejected" {
+			rejected[e.Target] = true
+		}
+	}
+	for _, name := range []string{"crossed", "callback", "pointer", "light.toggle"} {
+		if !rejected[name] {
+			t.Errorf("%s was not rejected\n%+v", name, ctx.Ledger)
+		}
+	}
+	if got := countLedger(ctx, "BoolParams", "rewritten"); got != 2 {
+		t.Errorf("%d functions rewritten, want 2 (configure and mixed)\n%+v\n%s", got, ctx.Ledger, out)
+	}
+	if got := runSource(t, out); got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+}
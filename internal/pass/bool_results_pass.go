@@ -0,0 +1,486 @@
+package pass
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	stdastutil "golang.org/x/tools/go/ast/astutil"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// BoolResultsPass troca os resultados de funções que devolvem só bools por
+// um único resultado flag gerado. O return monta a máscara e quem chama
+// desmonta com testes de bit:
+//
+//	func returnConvertedBools(cfg Config) (bool, bool, bool)  →  func returnConvertedBools(cfg Config) returnConvertedBoolsResults
+//	return cfg.A, cfg.B, false                                 →  return returnConvertedBoolsR0.when(cfg.A) | returnConvertedBoolsR1.when(cfg.B)
+//	a, b, c := returnConvertedBools(cfg)                       →  res := returnConvertedBools(cfg)
+//	                                                               a, b, c := res&returnConvertedBoolsR0 != 0, res&returnConvertedBoolsR1 != 0, ...
+//
+// Entram funções e métodos não exportados (ou de package main) com dois ou
+// mais resultados, todos do tipo bool. A assinatura não pode escapar: funções
+// usadas como valor, métodos cujo nome aparece em alguma interface e
+// genéricos ficam de fora, assim como chamadas cujo resultado vai direto
+// para outra chamada ou return (f(g()), return g()) e desmontagens fora de
+// uma lista de statements (if a, b := g(); a {).
+type BoolResultsPass struct {
+	plans map[*types.Func]*boolResultsPlan
+	order []*boolResultsPlan
+}
+
+// boolResultsPlan describes one function and the flag type of its results
+type boolResultsPlan struct {
+	fn       *types.Func
+	decl     *ast.FuncDecl
+	file     *ast.File
+	name     string
+	pos      token.Position
+	results  []*types.Var // resultados nomeados (nil se anônimos)
+	consts   []string
+	typeName string
+	word     string
+	typ      *types.Named
+	calls    int
+	when     bool // algum return devolve um bool não constante
+	reason   string
+}
+
+func NewBoolResultsPass() *BoolResultsPass { return &BoolResultsPass{} }
+func (p *BoolResultsPass) Name() string    { return "BoolResults" }
+
+func (plan *boolResultsPlan) reject(reason string) {
+	if plan.reason == "" {
+		plan.reason = reason
+	}
+}
+
+// Prepare finds the candidate functions, checks every call and declares the
+// generated flag types
+func (p *BoolResultsPass) Prepare(files []*ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	p.plans = make(map[*types.Func]*boolResultsPlan)
+	p.order = nil
+	if ctx.Package == nil {
+		return nil
+	}
+	a := &boolResultsAnalysis{
+		fset: fset, ctx: ctx,
+		plans:     make(map[*types.Func]*boolResultsPlan),
+		accounted: make(map[ast.Node]bool),
+		ifaces:    interfaceMethodNames(ctx),
+	}
+
+	// === 1️⃣ Coleta as funções que só devolvem bools ===
+	for _, file := range files {
+		a.collect(file)
+	}
+	if len(a.order) == 0 {
+		return nil
+	}
+
+	// === 2️⃣ Classifica as chamadas ===
+	for _, file := range files {
+		a.scan(file)
+	}
+
+	// === 3️⃣ Decide, nomeia e declara os tipos gerados ===
+	used := make(map[string]bool)
+	for _, file := range files {
+		collectIdentNames(file, used)
+	}
+	for _, plan := range a.order {
+		if plan.reason != "" {
+			gl.Log("info", fmt.Sprintf("BoolResults: skipping %s (%s)", plan.name, plan.reason))
+			ctx.RecordLedger(p.Name(), plan.pos, plan.name, "rejected", plan.reason)
+			continue
+		}
+		p.allocate(plan, used)
+		plan.typ = declareFlagType(ctx, plan.typeName, plan.word, plan.consts)
+		p.plans[plan.fn] = plan
+		p.order = append(p.order, plan)
+	}
+	return nil
+}
+
+// allocate picks the flag type and the constant of each result
+func (p *BoolResultsPass) allocate(plan *boolResultsPlan, used map[string]bool) {
+	n := plan.fn.Type().(*types.Signature).Results().Len()
+	plan.word = astutil.MenorTipoParaFlags(n)
+	base := plan.fn.Name()
+	if recv := plan.fn.Type().(*types.Signature).Recv(); recv != nil {
+		if named := derefNamed(recv.Type()); named != nil {
+			base = named.Obj().Name() + mapSetKeyName(base)
+		}
+	}
+	for i := 1; ; i++ {
+		prefix := base
+		if i > 1 {
+			prefix += strconv.Itoa(i)
+		}
+		typeName := prefix + "Results"
+		consts := make([]string, n)
+		names := []string{typeName}
+		seen := make(map[string]bool)
+		for k := range consts {
+			suffix := ""
+			if plan.results != nil {
+				suffix = mapSetKeyName(plan.results[k].Name())
+			}
+			if suffix == "" || suffix == "Results" || seen[suffix] {
+				suffix = "R" + strconv.Itoa(k)
+			}
+			seen[suffix] = true
+			consts[k] = prefix + suffix
+			names = append(names, consts[k])
+		}
+		free := true
+		for _, name := range names {
+			if used[name] || types.Universe.Lookup(name) != nil {
+				free = false
+				break
+			}
+		}
+		if !free {
+			continue
+		}
+		for _, name := range names {
+			used[name] = true
+		}
+		plan.typeName, plan.consts = typeName, consts
+		return
+	}
+}
+
+// boolResultsAnalysis collects the candidate functions of a package and classifies their calls
+type boolResultsAnalysis struct {
+	fset      *token.FileSet
+	ctx       *astutil.TranspileContext
+	plans     map[*types.Func]*boolResultsPlan
+	order     []*boolResultsPlan
+	accounted map[ast.Node]bool // chamadas e identificadores já classificados
+	ifaces    map[string]bool
+}
+
+// collect registers the functions of file whose results are two or more bools
+func (a *boolResultsAnalysis) collect(file *ast.File) {
+	for _, decl := range file.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Body == nil {
+			continue
+		}
+		fn, ok := a.ctx.GetDefs()[fd.Name].(*types.Func)
+		if !ok {
+			continue
+		}
+		sig := fn.Type().(*types.Signature)
+		n, bools := sig.Results().Len(), 0
+		for i := 0; i < n; i++ {
+			if types.Identical(sig.Results().At(i).Type(), types.Typ[types.Bool]) {
+				bools++
+			}
+		}
+		if bools < 2 {
+			continue
+		}
+
+		plan := &boolResultsPlan{fn: fn, decl: fd, file: file, name: fn.Name(), pos: a.fset.Position(fd.Pos())}
+		if sig.Recv() != nil {
+			if named := derefNamed(sig.Recv().Type()); named != nil {
+				plan.name = named.Obj().Name() + "." + fn.Name()
+			}
+		}
+		if sig.Results().At(0).Name() != "" {
+			for i := 0; i < n; i++ {
+				plan.results = append(plan.results, sig.Results().At(i))
+			}
+		}
+		switch {
+		case bools < n:
+			plan.reject("results mix bool and other types")
+		case fn.Exported() && a.ctx.Package.Name() != "main":
+			plan.reject("exported: callers outside the package")
+		case sig.TypeParams().Len() > 0 || sig.RecvTypeParams().Len() > 0:
+			plan.reject("generic function")
+		case sig.Recv() != nil && a.ifaces[fn.Name()]:
+			plan.reject("method name appears in an interface")
+		case n > 64:
+			plan.reject(fmt.Sprintf("%d bool results do not fit in 64 bits", n))
+		}
+
+		// Os returns do corpo (não os de closures) viram máscaras
+		ast.Inspect(fd.Body, func(node ast.Node) bool {
+			switch s := node.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.DeferStmt:
+				if plan.results != nil {
+					plan.reject("named results with defer")
+				}
+			case *ast.ReturnStmt:
+				switch len(s.Results) {
+				case 0:
+					plan.when = true
+				case n:
+					for _, r := range s.Results {
+						if _, ok := boolConst(r, a.ctx); !ok {
+							plan.when = true
+						}
+					}
+				default:
+					plan.reject(fmt.Sprintf("returns the results of a call at %s", a.fset.Position(s.Pos())))
+				}
+			}
+			return true
+		})
+		a.plans[fn] = plan
+		a.order = append(a.order, plan)
+	}
+}
+
+// callee returns the candidate called by e
+func (a *boolResultsAnalysis) callee(e ast.Expr) (*boolResultsPlan, *ast.CallExpr) {
+	call, ok := ast.Unparen(e).(*ast.CallExpr)
+	if !ok {
+		return nil, nil
+	}
+	fn, _ := calledFunc(call, a.ctx)
+	return a.plans[fn], call
+}
+
+// scan classifies the calls of the candidates: only destructuring into as
+// many operands, in a statement list, or discarding the results is accepted
+func (a *boolResultsAnalysis) scan(file *ast.File) {
+	stdastutil.Apply(file, func(c *stdastutil.Cursor) bool {
+		switch node := c.Node().(type) {
+		case *ast.AssignStmt:
+			if len(node.Rhs) != 1 || len(node.Lhs) < 2 {
+				return true
+			}
+			plan, call := a.callee(node.Rhs[0])
+			if plan == nil {
+				return true
+			}
+			a.accounted[call] = true
+			if c.Index() < 0 {
+				plan.reject(fmt.Sprintf("destructured outside a statement list at %s", a.fset.Position(node.Pos())))
+			}
+			for _, lhs := range node.Lhs {
+				if !astutil.IsSideEffectFree(lhs, a.ctx.Info) {
+					plan.reject(fmt.Sprintf("assigned to operands with side effects at %s", a.fset.Position(node.Pos())))
+				}
+			}
+		case *ast.DeclStmt:
+			gd, ok := node.Decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.VAR {
+				return true
+			}
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				if len(vs.Values) != 1 {
+					continue
+				}
+				if plan, call := a.callee(vs.Values[0]); plan != nil {
+					a.accounted[call] = true
+					if len(gd.Specs) != 1 {
+						plan.reject(fmt.Sprintf("declared in a var group at %s", a.fset.Position(node.Pos())))
+					}
+				}
+			}
+		case *ast.CallExpr:
+			fn, id := calledFunc(node, a.ctx)
+			plan := a.plans[fn]
+			if plan == nil {
+				return true
+			}
+			a.accounted[id] = true
+			plan.calls++
+			if a.accounted[node] {
+				return true
+			}
+			switch c.Parent().(type) {
+			case *ast.ExprStmt, *ast.GoStmt, *ast.DeferStmt:
+			default:
+				plan.reject(fmt.Sprintf("results used as a tuple at %s", a.fset.Position(node.Pos())))
+			}
+		case *ast.Ident:
+			if fn, ok := a.ctx.GetUses()[node].(*types.Func); ok && a.plans[fn] != nil && !a.accounted[node] {
+				a.plans[fn].reject(fmt.Sprintf("used as a value at %s", a.fset.Position(node.Pos())))
+			}
+		}
+		return true
+	}, nil)
+}
+
+func (p *BoolResultsPass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	if len(p.order) == 0 {
+		return nil
+	}
+	r := &boolResultsRewriter{pass: p, file: file, ctx: ctx}
+	stdastutil.Apply(file, r.enter, r.rewrite)
+
+	for _, plan := range p.order {
+		if plan.file != file {
+			continue
+		}
+		decls, err := astutil.ParseDecls(fset, flagTypeDecls(plan.typeName, plan.word, plan.consts, plan.when))
+		if err != nil {
+			return fmt.Errorf("BoolResults: %w", err)
+		}
+		file.Decls = append(file.Decls, decls...)
+		ctx.RecordLedger(p.Name(), plan.pos, plan.name, "rewritten",
+			fmt.Sprintf("%d bool results → %s %s (%d call sites)", len(plan.consts), plan.word, plan.typeName, plan.calls))
+	}
+
+	if r.count > 0 {
+		ctx.LogVerbose(fset, "🚩 BoolResultsPass: %d transformations applied", r.count)
+	}
+	return nil
+}
+
+// boolResultsRewriter rewrites the declarations, returns and calls of the accepted functions in one file
+type boolResultsRewriter struct {
+	pass  *BoolResultsPass
+	file  *ast.File
+	ctx   *astutil.TranspileContext
+	funcs []ast.Node // FuncDecl/FuncLit em volta do nó atual
+	count int
+}
+
+// planOf returns the plan of the function called by e
+func (r *boolResultsRewriter) planOf(e ast.Expr) *boolResultsPlan {
+	call, ok := ast.Unparen(e).(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	fn, _ := calledFunc(call, r.ctx)
+	return r.pass.plans[fn]
+}
+
+func (r *boolResultsRewriter) enter(c *stdastutil.Cursor) bool {
+	switch c.Node().(type) {
+	case *ast.FuncDecl, *ast.FuncLit:
+		r.funcs = append(r.funcs, c.Node())
+	}
+	return true
+}
+
+// unpack stores the result of call in a new variable declared before the
+// current statement and returns one bit test per result
+func (r *boolResultsRewriter) unpack(plan *boolResultsPlan, call ast.Expr, c *stdastutil.Cursor) []ast.Expr {
+	name := freshLocalName(r.file, "res")
+	v := types.NewVar(call.Pos(), r.ctx.Package, name, plan.typ)
+	def := ast.NewIdent(name)
+	r.ctx.GetDefs()[def] = v
+	c.InsertBefore(&ast.AssignStmt{Lhs: []ast.Expr{def}, Tok: token.DEFINE, Rhs: []ast.Expr{call}})
+	tests := make([]ast.Expr, len(plan.consts))
+	for k, bit := range plan.consts {
+		use := ast.NewIdent(name)
+		r.ctx.GetUses()[use] = v
+		tests[k] = flagTest(use, bit)
+	}
+	return tests
+}
+
+// rewrite runs after the children of each node were rewritten
+func (r *boolResultsRewriter) rewrite(c *stdastutil.Cursor) bool {
+	switch node := c.Node().(type) {
+	case *ast.ReturnStmt:
+		fd, ok := r.funcs[len(r.funcs)-1].(*ast.FuncDecl)
+		if !ok {
+			return true
+		}
+		fn, _ := r.ctx.GetDefs()[fd.Name].(*types.Func)
+		plan := r.pass.plans[fn]
+		if plan == nil {
+			return true
+		}
+		values := node.Results
+		if len(values) == 0 {
+			// return nu: os resultados nomeados viraram vars locais
+			for _, v := range plan.results {
+				if v.Name() == "_" {
+					values = append(values, ast.NewIdent("false"))
+					continue
+				}
+				id := ast.NewIdent(v.Name())
+				r.ctx.GetUses()[id] = v
+				values = append(values, id)
+			}
+		}
+		node.Results = []ast.Expr{flagMask(plan.consts, values, r.ctx)}
+		r.count++
+
+	case *ast.AssignStmt:
+		if len(node.Rhs) != 1 || len(node.Lhs) < 2 {
+			return true
+		}
+		if plan := r.planOf(node.Rhs[0]); plan != nil {
+			node.Rhs = r.unpack(plan, node.Rhs[0], c)
+			r.count++
+		}
+
+	case *ast.DeclStmt:
+		gd, ok := node.Decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.VAR || len(gd.Specs) != 1 {
+			return true
+		}
+		vs := gd.Specs[0].(*ast.ValueSpec)
+		if len(vs.Values) != 1 || len(vs.Names) < 2 {
+			return true
+		}
+		if plan := r.planOf(vs.Values[0]); plan != nil {
+			vs.Values = r.unpack(plan, vs.Values[0], c)
+			r.count++
+		}
+
+	case *ast.FuncLit:
+		r.funcs = r.funcs[:len(r.funcs)-1]
+
+	case *ast.FuncDecl:
+		r.funcs = r.funcs[:len(r.funcs)-1]
+		fn, ok := r.ctx.GetDefs()[node.Name].(*types.Func)
+		if !ok || r.pass.plans[fn] == nil {
+			return true
+		}
+		plan := r.pass.plans[fn]
+		// Resultados nomeados continuam existindo como vars locais; os que o
+		// corpo nunca lê viram _ (var local sem uso não compila)
+		if plan.results != nil {
+			read := make(map[types.Object]bool)
+			ast.Inspect(node.Body, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok {
+					read[r.ctx.GetUses()[id]] = true
+				}
+				return true
+			})
+			var names []*ast.Ident
+			kept := false
+			for _, field := range node.Type.Results.List {
+				for _, id := range field.Names {
+					if !read[r.ctx.GetDefs()[id]] {
+						id = ast.NewIdent("_")
+					} else {
+						kept = true
+					}
+					names = append(names, id)
+				}
+			}
+			if kept {
+				decl := &ast.DeclStmt{Decl: &ast.GenDecl{Tok: token.VAR, Specs: []ast.Spec{
+					&ast.ValueSpec{Names: names, Type: ast.NewIdent("bool")},
+				}}}
+				node.Body.List = append([]ast.Stmt{decl}, node.Body.List...)
+			}
+		}
+		typ := ast.NewIdent(plan.typeName)
+		typ.NamePos = node.Type.Results.Pos()
+		node.Type.Results = &ast.FieldList{List: []*ast.Field{{Type: typ}}}
+		r.count++
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package pass
+
+import "testing"
+
+const boolResultsProbe = `package main
+
+import "fmt"
+
+type config struct{ a, b bool }
+
+func split(c config) (bool, bool, bool) {
+	return c.a, c.b, false
+}
+
+func named(n int) (even, small bool) {
+	even = n%2 == 0
+	small = n < 10
+	return
+}
+
+func pair() (bool, bool) { return true, false }
+
+// O resultado vai direto para outra chamada
+func forwarded() (bool, bool) { return false, true }
+
+// Desmontado no init de um if
+func guarded(n int) (bool, bool) { return n > 0, n > 100 }
+
+// Usada como valor
+func valued() (bool, bool) { return true, true }
+
+func either(a, b bool) bool { return a || b }
+
+func main() {
+	a, b, c := split(config{a: true})
+	fmt.Println(a, b, c)
+	var x, y bool
+	x, y = named(4)
+	fmt.Println(x, y)
+	_, small := named(42)
+	fmt.Println(small)
+	p, q := pair()
+	fmt.Println(p, q)
+
+	fmt.Println(either(forwarded()))
+	if pos, big := guarded(5); pos {
+		fmt.Println("positive", big)
+	}
+	f := valued
+	fmt.Println(f())
+}
+`
+
+func TestBoolResultsPreservesBehavior(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, boolResultsProbe)
+	out, ctx := transpileSource(t, boolResultsProbe, false, NewBoolResultsPass())
+	rejected := map[string]bool{}
+	for _, e := range ctx.Ledger {
+		if e.Pass == "BoolResults" && e.Action == "rejected" {
+			rejected[e.Target] = true
+		}
+	}
+	for _, name := range []string{"forwarded", "guarded", "valued"} {
+		if !rejected[name] {
+			t.Errorf("%s was not rejected\n%+v", name, ctx.Ledger)
+		}
+	}
+	if got := countLedger(ctx, "BoolResults", "rewritten"); got != 3 {
+		t.Errorf("%d functions rewritten, want 3 (split, named and pair)\n%+v\n%s", got, ctx.Ledger, out)
+	}
+	if got := runSource(t, out); got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+}
@@ -3,7 +3,9 @@ package pass
 import (
 	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/token"
+	"go/types"
 	"strings"
 
 	"github.com/kubex-ecosystem/gastype/internal/astutil"
@@ -13,7 +15,9 @@ import (
 )
 
 // BoolToFlagsPass converte campos bool em flags bitwise
-type BoolToFlagsPass struct{}
+type BoolToFlagsPass struct {
+	rejected map[*types.TypeName]string // struct → motivo para ficar como está
+}
 
 func NewBoolToFlagsPass() *BoolToFlagsPass {
 	return &BoolToFlagsPass{}
@@ -23,6 +27,102 @@ func (p *BoolToFlagsPass) Name() string {
 	return "BoolToFlags"
 }
 
+// Prepare rejects the structs whose bool fields cannot all become flags:
+// unkeyed literals, bool fields set to non-constant values in literals, and
+// fields whose address is taken or that a range loop assigns to
+func (p *BoolToFlagsPass) Prepare(files []*ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	p.rejected = make(map[*types.TypeName]string)
+	reject := func(obj *types.TypeName, reason string) {
+		if obj != nil && p.rejected[obj] == "" {
+			p.rejected[obj] = reason
+		}
+	}
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			// Um bit não tem endereço nem pode ser variável de range
+			switch n := n.(type) {
+			case *ast.UnaryExpr:
+				if sel, ok := ast.Unparen(n.X).(*ast.SelectorExpr); ok && n.Op == token.AND {
+					reject(boolFieldOwner(sel, ctx), fmt.Sprintf("address of %s taken at %s", types.ExprString(sel), fset.Position(n.Pos())))
+				}
+			case *ast.RangeStmt:
+				for _, e := range []ast.Expr{n.Key, n.Value} {
+					if sel, ok := e.(*ast.SelectorExpr); ok {
+						reject(boolFieldOwner(sel, ctx), fmt.Sprintf("range assigns %s at %s", types.ExprString(sel), fset.Position(sel.Pos())))
+					}
+				}
+			}
+			lit, ok := n.(*ast.CompositeLit)
+			if !ok || len(lit.Elts) == 0 {
+				return true
+			}
+			obj, fields := boolFlagsStruct(lit, ctx)
+			if obj == nil || p.rejected[obj] != "" {
+				return true
+			}
+			for _, elt := range lit.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					p.rejected[obj] = fmt.Sprintf("unkeyed literal at %s", fset.Position(lit.Pos()))
+					break
+				}
+				key, ok := kv.Key.(*ast.Ident)
+				if ok && contains(fields, key.Name) && ctx.GetTypes()[kv.Value].Value == nil {
+					p.rejected[obj] = fmt.Sprintf("non-constant %s in literal at %s", key.Name, fset.Position(kv.Pos()))
+					break
+				}
+			}
+			return true
+		})
+	}
+	return nil
+}
+
+// boolFieldOwner returns the struct declaring the bool field selected by sel,
+// following embedded fields, or nil when sel is not a bool field
+func boolFieldOwner(sel *ast.SelectorExpr, ctx *astutil.TranspileContext) *types.TypeName {
+	selection := ctx.GetSelections()[sel]
+	if selection == nil || selection.Kind() != types.FieldVal || selection.Obj().Type().String() != "bool" {
+		return nil
+	}
+	t := selection.Recv()
+	index := selection.Index()
+	for _, i := range index[:len(index)-1] {
+		st := structOf(t)
+		if st == nil {
+			return nil
+		}
+		t = st.Field(i).Type()
+	}
+	if named := derefNamed(t); named != nil {
+		return named.Obj()
+	}
+	return nil
+}
+
+// boolFlagsStruct returns the struct type built by lit and its bool fields
+func boolFlagsStruct(lit *ast.CompositeLit, ctx *astutil.TranspileContext) (*types.TypeName, []string) {
+	tv, ok := ctx.GetTypes()[lit]
+	if !ok {
+		return nil, nil
+	}
+	named, ok := types.Unalias(tv.Type).(*types.Named)
+	if !ok {
+		return nil, nil
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return nil, nil
+	}
+	var fields []string
+	for i := 0; i < st.NumFields(); i++ {
+		if f := st.Field(i); !f.Embedded() && f.Type().String() == "bool" {
+			fields = append(fields, f.Name())
+		}
+	}
+	return named.Obj(), fields
+}
+
 func (p *BoolToFlagsPass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
 	// Guarda mapeamento struct → campos booleanos convertidos
 	convertedStructs := make(map[string][]string)
@@ -57,6 +157,12 @@ func (p *BoolToFlagsPass) Apply(file *ast.File, fset *token.FileSet, ctx *astuti
 		if !astutil.DeveConverterBools(len(boolFields)) {
 			return true
 		}
+		obj, _ := ctx.GetDefs()[typeDecl.Name].(*types.TypeName)
+		if reason := p.rejected[obj]; reason != "" {
+			gl.Log("info", fmt.Sprintf("BoolToFlags: skipping %s (%s)", structName, reason))
+			ctx.RecordLedger(p.Name(), fset.Position(typeDecl.Pos()), structName, "rejected", reason)
+			return true
+		}
 
 		convertedStructs[structName] = boolFields
 
@@ -86,9 +192,31 @@ func (p *BoolToFlagsPass) Apply(file *ast.File, fset *token.FileSet, ctx *astuti
 			})
 
 			ctx.AddDef(ast.NewIdent(constName), nil)
+			ctx.AddFlagMapping(structName, fieldName, constName, i)
 			gl.Log("info", fmt.Sprintf("Added constant: %s (%s)", constName, flagType))
 		}
-		file.Decls = append(constDecls, file.Decls...) // insere no topo
+
+		// Registra a ocupação do flags word para os passes seguintes
+		info := ctx.GetStructInfo(structName)
+		if info == nil {
+			info = &astutil.StructInfo{OriginalName: structName, FlagMapping: map[string]string{}}
+			ctx.Structs[structName] = info
+		}
+		info.BoolFields = boolFields
+		info.FlagsType = flagType
+		info.UsedBits = len(boolFields)
+		// insere no topo, logo após os imports
+		insertAt := 0
+		for insertAt < len(file.Decls) {
+			if gd, ok := file.Decls[insertAt].(*ast.GenDecl); !ok || gd.Tok != token.IMPORT {
+				break
+			}
+			insertAt++
+		}
+		decls := make([]ast.Decl, 0, len(file.Decls)+len(constDecls))
+		decls = append(decls, file.Decls[:insertAt]...)
+		decls = append(decls, constDecls...)
+		file.Decls = append(decls, file.Decls[insertAt:]...)
 
 		// === 3️⃣ Substitui campo bool por "flags" ===
 		newFields := []*ast.Field{
@@ -110,11 +238,20 @@ func (p *BoolToFlagsPass) Apply(file *ast.File, fset *token.FileSet, ctx *astuti
 
 	// === 4️⃣ Substitui acessos cfg.Debug → cfg.flags & FlagStruct_Debug != 0 ===
 	stdastutil.Apply(file, func(cr *stdastutil.Cursor) bool {
+		if lit, ok := cr.Node().(*ast.CompositeLit); ok {
+			p.packLiteral(lit, ctx)
+			return true
+		}
 		sel, ok := cr.Node().(*ast.SelectorExpr)
 		if !ok {
 			return true
 		}
 
+		// Escritas (cfg.Debug = v) ficam para o AssignToBitwise
+		if isWriteTarget(cr) {
+			return true
+		}
+
 		selInfo := ctx.GetSelections()[sel]
 		if selInfo == nil || selInfo.Obj() == nil {
 			return true
@@ -150,6 +287,45 @@ func (p *BoolToFlagsPass) Apply(file *ast.File, fset *token.FileSet, ctx *astuti
 	return nil
 }
 
+// packLiteral moves the keyed bool values of a converted struct into the
+// flags element: Config{Debug: true, Name: n} → Config{Name: n, flags: FlagConfig_Debug}
+func (p *BoolToFlagsPass) packLiteral(lit *ast.CompositeLit, ctx *astutil.TranspileContext) {
+	obj, fields := boolFlagsStruct(lit, ctx)
+	if obj == nil || obj.Pkg() != ctx.Package || p.rejected[obj] != "" || !astutil.DeveConverterBools(len(fields)) {
+		return
+	}
+	var flags ast.Expr
+	var elts []ast.Expr
+	for _, elt := range lit.Elts {
+		// Sem Prepare, literais sem chave ou com valor dinâmico ficam como estão
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok || !contains(fields, key.Name) {
+			elts = append(elts, elt)
+			continue
+		}
+		value := ctx.GetTypes()[kv.Value].Value
+		if value == nil || value.Kind() != constant.Bool {
+			return
+		}
+		if constant.BoolVal(value) {
+			flag := ast.NewIdent(fmt.Sprintf("Flag%s_%s", obj.Name(), key.Name))
+			if flags == nil {
+				flags = flag
+			} else {
+				flags = &ast.BinaryExpr{X: flags, Op: token.OR, Y: flag}
+			}
+		}
+	}
+	if flags != nil {
+		elts = append(elts, &ast.KeyValueExpr{Key: ast.NewIdent("flags"), Value: flags})
+	}
+	lit.Elts = elts
+}
+
 // contains verifica se s está em slice arr
 func contains(arr []string, s string) bool {
 	for _, v := range arr {
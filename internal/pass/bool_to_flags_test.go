@@ -0,0 +1,85 @@
+package pass
+
+import (
+	"strings"
+	"testing"
+)
+
+const boolToFlagsProbe = `package main
+
+import "fmt"
+
+type Addr struct {
+	Debug bool
+	Trace bool
+}
+
+type Ranged struct {
+	Verbose bool
+	Quiet   bool
+}
+
+type Plain struct {
+	On  bool
+	Off bool
+}
+
+func set(b *bool) { *b = true }
+
+func main() {
+	a := Addr{Trace: true}
+	set(&a.Debug)
+	fmt.Println(a.Debug, a.Trace)
+
+	var r Ranged
+	for _, r.Verbose = range []bool{false, true} {
+		fmt.Println(r.Verbose, r.Quiet)
+	}
+
+	p := Plain{On: true}
+	p.Off = !p.On
+	fmt.Println(p.On, p.Off)
+}
+`
+
+// TestBoolToFlagsRejectsAddressAndRange checks that a struct whose bool field
+// is address-taken or assigned by range keeps its fields and still compiles
+func TestBoolToFlagsRejectsAddressAndRange(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, boolToFlagsProbe)
+	out, ctx := transpileSource(t, boolToFlagsProbe, false,
+		NewBoolToFlagsPass(), NewAssignToBitwisePass(), NewFieldAccessToBitwisePass())
+	if got := countLedger(ctx, "BoolToFlags", "rejected"); got != 2 {
+		t.Fatalf("%d structs rejected, want 2 (Addr, Ranged)\n%s", got, out)
+	}
+	if !strings.Contains(out, "FlagPlain_On") || strings.Contains(out, "FlagAddr_") || strings.Contains(out, "FlagRanged_") {
+		t.Fatalf("only Plain should be converted\n%s", out)
+	}
+	if got := runSource(t, out); got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+}
+
+// TestBoolToFlagsWithoutPrepare runs Apply alone: literals it cannot rewrite
+// are left untouched instead of panicking
+func TestBoolToFlagsWithoutPrepare(t *testing.T) {
+	src := `package main
+
+type S struct {
+	A bool
+	B bool
+}
+
+var on = true
+
+var x = S{true, false}
+var y = S{A: on}
+`
+	out, _ := transpileSource(t, src, false, applyOnly{NewBoolToFlagsPass()})
+	if !strings.Contains(out, "S{true, false}") || !strings.Contains(out, "S{A: on}") {
+		t.Errorf("literals rewritten without Prepare\n%s", out)
+	}
+}
+
+// applyOnly hides the Prepare method of a pass
+type applyOnly struct{ testPass }
@@ -5,12 +5,19 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
-	"strings"
+	"go/types"
 
 	"github.com/kubex-ecosystem/gastype/internal/astutil"
+	stdastutil "golang.org/x/tools/go/ast/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
 )
 
-// FieldAccessToBitwisePass troca usos “cfg.Debug” em args/returns/exprs por bitwise check
+// FieldAccessToBitwisePass troca toda leitura “cfg.Debug” de um campo convertido
+// pelo teste “cfg.flags&FlagConfig_Debug != 0”, em qualquer contexto de expressão
+// (args, literais compostos, índices, sends, closures, defer/go, binárias...).
+// Escritas ficam para o AssignToBitwise. &cfg.Debug e range sobre o campo não têm
+// equivalente: o BoolToFlags já não converte essas structs, o rejeito aqui só avisa.
 type FieldAccessToBitwisePass struct{}
 
 func NewFieldAccessToBitwisePass() *FieldAccessToBitwisePass { return &FieldAccessToBitwisePass{} }
@@ -19,87 +26,48 @@ func (p *FieldAccessToBitwisePass) Name() string             { return "FieldAcce
 func (p *FieldAccessToBitwisePass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
 	transformations := 0
 
-	// helper interno para transformar um expr se for selector de campo bool convertido
-	transform := func(expr ast.Expr) ast.Expr {
-		sel, ok := expr.(*ast.SelectorExpr)
+	stdastutil.Apply(file, nil, func(c *stdastutil.Cursor) bool {
+		sel, ok := c.Node().(*ast.SelectorExpr)
 		if !ok {
-			return nil
+			return true
 		}
-
-		selInfo := ctx.GetSelections()[sel]
-		if selInfo == nil || selInfo.Obj() == nil || selInfo.Recv() == nil {
-			return nil
+		ref := resolveFlagRef(sel, ctx)
+		if ref == nil {
+			return true
 		}
+		pos := fset.Position(sel.Pos())
+		target := types.ExprString(sel)
 
-		fieldName := selInfo.Obj().Name()
-		typName := selInfo.Recv().String()
-
-		// remover ponteiro se houver
-		typName = strings.TrimPrefix(typName, `*`)
-
-		if info, exists := ctx.Structs[typName]; exists {
-			if flagName, ok := info.FlagMapping[fieldName]; ok {
-				// construir expressão de bitwise
-				flagObj := &ast.BinaryExpr{
-					X: &ast.ParenExpr{
-						X: &ast.BinaryExpr{
-							X:  &ast.SelectorExpr{X: sel.X, Sel: ast.NewIdent("flags")},
-							Op: token.AND,
-							Y:  ast.NewIdent(flagName),
-						},
-					},
-					Op: token.NEQ,
-					Y:  &ast.BasicLit{Kind: token.INT, Value: "0"},
-				}
-
-				// Evitar transformar dentro de atribuições (ex: cfg.Debug = true)
-				// Note que isso não cobre todos os casos, mas cobre os mais comuns
-				// Para casos mais complexos, o usuário deve ajustar manualmente
-				// Ex: dentro de chamadas de função, retornos, etc, é seguro transformar
-				// Mas dentro de AssignStmt não é seguro
-				// Então subimos a árvore AST para ver se o pai é um AssignStmt
-				if parent := astutil.GetParentNode(file, expr); parent != nil {
-					if _, isAssign := parent.(*ast.AssignStmt); isAssign {
-						// Não transformar em atribuição direta (ex: cfg.Debug = true)
-						return nil
-					}
-				}
-
-				// Registrar transformação para log/relatório
-				ctx.RegisterAssignToBitwise(fset.Position(file.Pos()).Filename, fieldName, flagName, fmt.Sprint(flagObj))
-
-				transformations++
-				return flagObj
-			}
-		}
-		return nil
-	}
-
-	ast.Inspect(file, func(n ast.Node) bool {
-		switch node := n.(type) {
-		case *ast.CallExpr:
-			for i, a := range node.Args {
-				if r := transform(a); r != nil {
-					node.Args[i] = r
-				}
-			}
-		case *ast.ReturnStmt:
-			for i, r := range node.Results {
-				if rr := transform(r); rr != nil {
-					node.Results[i] = rr
-				}
-			}
+		switch parent := c.Parent().(type) {
 		case *ast.AssignStmt:
-			for i, r := range node.Rhs {
-				if rr := transform(r); rr != nil {
-					node.Rhs[i] = rr
-				}
+			if c.Name() == "Lhs" {
+				ctx.RecordLedger(p.Name(), pos, target, "delegated", "write left to AssignToBitwise")
+				return true
 			}
-		case *ast.ExprStmt:
-			if rr := transform(node.X); rr != nil {
-				node.X = rr
+		case *ast.RangeStmt:
+			if c.Name() == "Key" || c.Name() == "Value" {
+				p.reject(ctx, pos, target, "range assignment to a flag field")
+				return true
+			}
+		case *ast.UnaryExpr:
+			if parent.Op == token.AND {
+				p.reject(ctx, pos, target, "address of a flag field")
+				return true
 			}
 		}
+
+		var read ast.Expr = &ast.BinaryExpr{
+			X:  &ast.BinaryExpr{X: ref.Flags, Op: token.AND, Y: ast.NewIdent(ref.Flag)},
+			Op: token.NEQ,
+			Y:  &ast.BasicLit{Kind: token.INT, Value: "0"},
+		}
+		// `x == cfg.Debug` precisa de parênteses; && e || não
+		if bin, ok := c.Parent().(*ast.BinaryExpr); ok && bin.Op != token.LAND && bin.Op != token.LOR {
+			read = &ast.ParenExpr{X: read}
+		}
+		c.Replace(read)
+		ctx.RecordLedger(p.Name(), pos, target, "rewritten", types.ExprString(read))
+		transformations++
 		return true
 	})
 
@@ -108,3 +76,9 @@ func (p *FieldAccessToBitwisePass) Apply(file *ast.File, fset *token.FileSet, ct
 	}
 	return nil
 }
+
+// reject records a use of a converted field that has no bitwise equivalent
+func (p *FieldAccessToBitwisePass) reject(ctx *astutil.TranspileContext, pos token.Position, target, reason string) {
+	gl.Log("warn", fmt.Sprintf("FieldAccessToBitwise: cannot convert %s at %s (%s)", target, pos, reason))
+	ctx.RecordLedger(p.Name(), pos, target, "rejected", reason)
+}
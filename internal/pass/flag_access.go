@@ -0,0 +1,201 @@
+package pass
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	stdastutil "golang.org/x/tools/go/ast/astutil"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+)
+
+// flagRef describes a bool field that BoolToFlags moved into a flags word
+type flagRef struct {
+	Struct    string   // struct that declares the field
+	Field     string   // original field name
+	Flag      string   // flag constant (FlagStruct_Field)
+	FlagsType string   // type of the flags word (uint8..uint64)
+	Flags     ast.Expr // expression selecting the flags word, e.g. s.cfg.flags
+}
+
+// resolveFlagRef resolves x.F to the flags word that replaced F. It relies on
+// the type-checked selection, so only the struct that really declares F
+// matches, and promoted fields are reached through their embedding path.
+func resolveFlagRef(sel *ast.SelectorExpr, ctx *astutil.TranspileContext) *flagRef {
+	selection := ctx.GetSelections()[sel]
+	if selection == nil || selection.Kind() != types.FieldVal {
+		return nil
+	}
+
+	recv := sel.X
+	t := selection.Recv()
+	index := selection.Index()
+	for _, i := range index[:len(index)-1] {
+		st := structOf(t)
+		if st == nil {
+			return nil
+		}
+		embedded := st.Field(i)
+		recv = &ast.SelectorExpr{X: recv, Sel: ast.NewIdent(embedded.Name())}
+		t = embedded.Type()
+	}
+
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() != ctx.Package {
+		return nil
+	}
+	info := ctx.GetStructInfo(named.Obj().Name())
+	if info == nil {
+		return nil
+	}
+	flag, ok := info.FlagMapping[sel.Sel.Name]
+	if !ok || !contains(info.BoolFields, sel.Sel.Name) {
+		return nil
+	}
+	flagsType := info.FlagsType
+	if flagsType == "" {
+		flagsType = astutil.MenorTipoParaFlags(len(info.BoolFields))
+	}
+
+	return &flagRef{
+		Struct:    named.Obj().Name(),
+		Field:     sel.Sel.Name,
+		Flag:      flag,
+		FlagsType: flagsType,
+		Flags:     &ast.SelectorExpr{X: recv, Sel: ast.NewIdent("flags")},
+	}
+}
+
+// flagTestOf recognizes a read of a flag, either the original x.F selector or
+// the `x.flags&FlagS_F != 0` test emitted by BoolToFlags. It returns the flags
+// word expression and the flag constant.
+func flagTestOf(expr ast.Expr, ctx *astutil.TranspileContext) (ast.Expr, string, bool) {
+	expr = ast.Unparen(expr)
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if ref := resolveFlagRef(sel, ctx); ref != nil {
+			return ref.Flags, ref.Flag, true
+		}
+		return nil, "", false
+	}
+
+	test, ok := expr.(*ast.BinaryExpr)
+	if !ok || test.Op != token.NEQ || !isZeroLit(test.Y) {
+		return nil, "", false
+	}
+	and, ok := ast.Unparen(test.X).(*ast.BinaryExpr)
+	if !ok || and.Op != token.AND {
+		return nil, "", false
+	}
+	flag, ok := and.Y.(*ast.Ident)
+	if !ok {
+		return nil, "", false
+	}
+	flags, ok := and.X.(*ast.SelectorExpr)
+	if !ok || flags.Sel.Name != "flags" {
+		return nil, "", false
+	}
+	return flags, flag.Name, true
+}
+
+// sameExpr reports whether a and b denote the same side-effect-free expression
+func sameExpr(a, b ast.Expr, ctx *astutil.TranspileContext) bool {
+	return types.ExprString(a) == types.ExprString(b) &&
+		astutil.IsSideEffectFree(a, ctx.Info) && astutil.IsSideEffectFree(b, ctx.Info)
+}
+
+// boolConst returns the constant value of a bool expression, if it has one
+func boolConst(expr ast.Expr, ctx *astutil.TranspileContext) (bool, bool) {
+	if tv, ok := ctx.GetTypes()[expr]; ok && tv.Value != nil && tv.Value.Kind() == constant.Bool {
+		return constant.BoolVal(tv.Value), true
+	}
+	// Nós sintetizados por passes anteriores não têm informação de tipo
+	if id, ok := expr.(*ast.Ident); ok && (id.Name == "true" || id.Name == "false") {
+		if obj := ctx.GetUses()[id]; obj == nil || obj.Parent() == types.Universe {
+			return id.Name == "true", true
+		}
+	}
+	return false, false
+}
+
+func isZeroLit(e ast.Expr) bool {
+	lit, ok := e.(*ast.BasicLit)
+	return ok && lit.Kind == token.INT && lit.Value == "0"
+}
+
+// freshPackageName returns base, or base with a numeric suffix, so that it
+// does not collide with any package-level or universe identifier
+func freshPackageName(pkg *types.Package, base string) string {
+	name := base
+	for i := 2; ; i++ {
+		if types.Universe.Lookup(name) == nil && (pkg == nil || pkg.Scope().Lookup(name) == nil) {
+			return name
+		}
+		name = base + strconv.Itoa(i)
+	}
+}
+
+// freshLocalName returns base, or base with a numeric suffix, so that it appears nowhere in file
+func freshLocalName(file *ast.File, base string) string {
+	used := false
+	ast.Inspect(file, func(node ast.Node) bool {
+		if id, ok := node.(*ast.Ident); ok && id.Name == base {
+			used = true
+		}
+		return !used
+	})
+	if !used {
+		return base
+	}
+	return freshLocalNames(file, base, 1)[0]
+}
+
+// freshLocalNames returns n identifiers prefixed with base that appear nowhere in file
+func freshLocalNames(file *ast.File, base string, n int) []string {
+	used := make(map[string]bool)
+	ast.Inspect(file, func(node ast.Node) bool {
+		if id, ok := node.(*ast.Ident); ok {
+			used[id.Name] = true
+		}
+		return true
+	})
+	names := make([]string, 0, n)
+	for i := 0; len(names) < n; i++ {
+		name := base + strconv.Itoa(i)
+		if !used[name] {
+			names = append(names, name)
+			used[name] = true
+		}
+	}
+	return names
+}
+
+// importName returns the name under which file imports path, adding the
+// import (aliased if the package name is taken) when it is missing
+func importName(fset *token.FileSet, file *ast.File, path string) string {
+	base := path[strings.LastIndex(path, "/")+1:]
+	for _, imp := range file.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == path {
+			name := base
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			if name != "_" && name != "." {
+				return name
+			}
+		}
+	}
+	name := freshLocalName(file, base)
+	if name == base {
+		stdastutil.AddImport(fset, file, path)
+	} else {
+		stdastutil.AddNamedImport(fset, file, name, path)
+	}
+	return name
+}
@@ -0,0 +1,920 @@
+package pass
+
+import (
+	"encoding/binary"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+
+	stdastutil "golang.org/x/tools/go/ast/astutil"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+const flattenDirective = "//gastype:flatten"
+
+// FlattenPass achata o fluxo de controle das funções marcadas com
+// //gastype:flatten: cada bloco básico vira um case de um switch sobre uma
+// variável de estado opaca, dentro de um laço despachante.
+//
+//	//gastype:flatten                 func check(key string) bool {
+//	func check(key string) bool {         var n int
+//		n := len(key)                     flatState := uint32(0x5f1c93a2)
+//		if n != 16 {          →           for {
+//			return false                      switch flatState {
+//		}                                     case 0x5f1c93a2:
+//		return valid(key)                         n = len(key)
+//	}                                             if n != 16 { flatState ^= 0x2e07b4c1 } else { flatState ^= 0x91d3a6f0 }
+//	                                          case 0x711b2763:
+//	                                              return false
+//	                                          ...
+//
+// As locais sobem para o topo da função; os estados e a ordem dos cases vêm
+// da seed do build, e cada transição é um XOR com a diferença entre os
+// estados. if, for e switch viram blocos; range, select e type switch ficam
+// inteiros dentro do seu bloco, com os desvios para fora reescritos em
+// transições. defer, resultados nomeados, panic/recover, goto e loops com
+// label mantêm o significado. Funções em que subir uma variável mudaria o
+// programa (variável declarada num loop capturada por closure ou com endereço
+// tomado) ficam como estão, com o motivo no ledger.
+type FlattenPass struct{}
+
+func NewFlattenPass() *FlattenPass  { return &FlattenPass{} }
+func (p *FlattenPass) Name() string { return "Flatten" }
+
+func (p *FlattenPass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	// Etapa 1 (--no-obfuscate) gera código legível
+	if !ctx.Ofuscate {
+		return nil
+	}
+	flattened := 0
+	for _, decl := range file.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Body == nil || !hasDirective(fd.Doc, flattenDirective) {
+			continue
+		}
+		pos := fset.Position(fd.Pos())
+		f := newFlattener(fd, file, ctx)
+		if reason := f.lower(); reason != "" {
+			ctx.RecordLedger(p.Name(), pos, fd.Name.Name, "rejected", reason)
+			gl.Log("warn", fmt.Sprintf("⚠️ Flatten: %s not flattened: %s", fd.Name.Name, reason))
+			continue
+		}
+		blocks := f.commit()
+		ctx.RecordLedger(p.Name(), pos, fd.Name.Name, "rewritten", fmt.Sprintf("%d blocks", blocks))
+		flattened++
+	}
+
+	if flattened > 0 {
+		gl.Log("info", fmt.Sprintf("🔄 FlattenPass: %d functions flattened", flattened))
+	}
+	return nil
+}
+
+// flatBlock é um bloco básico: um case do despachante
+type flatBlock struct {
+	id    uint32
+	stmts []ast.Stmt
+	refs  int
+}
+
+// flatLabel guarda os destinos de um label achatado
+type flatLabel struct {
+	start, brk, cont *flatBlock
+}
+
+// flatScope é um alvo de break/continue sem label
+type flatScope struct {
+	brk, cont *flatBlock
+}
+
+type flattener struct {
+	ctx  *astutil.TranspileContext
+	file *ast.File
+	fn   *ast.FuncDecl
+	rng  uint64
+
+	state, loop string // variável de estado e label do laço despachante
+	loopUsed    bool
+	used        map[string]bool // nomes do arquivo e nomes já gerados
+
+	blocks []*flatBlock
+	ids    map[uint32]bool
+	labels map[string]*flatLabel
+	scopes []flatScope
+	depth  int // loops achatados em volta do statement atual
+
+	vars     []*ast.ValueSpec // locais que sobem para o topo
+	decls    []ast.Stmt       // const e type locais, no topo
+	hoisted  map[types.Object]bool
+	inLoop   map[types.Object]bool
+	backGoto bool
+	escapes  []flatEscape
+	types    map[string]*types.TypeName // tipos locais e parâmetros de tipo da função
+	renames  map[types.Object]string
+	reason   string
+}
+
+// flatEscape é um desvio de dentro de um range/select/type switch para um bloco achatado
+type flatEscape struct {
+	stmt     ast.Stmt
+	branches map[*ast.BranchStmt][]ast.Stmt
+}
+
+func newFlattener(fd *ast.FuncDecl, file *ast.File, ctx *astutil.TranspileContext) *flattener {
+	scope := fd.Name.Name
+	if ctx.Package != nil {
+		scope = ctx.Package.Name() + "." + scope
+	}
+	if fd.Recv != nil && len(fd.Recv.List) > 0 {
+		scope += "/" + types.ExprString(fd.Recv.List[0].Type)
+	}
+	key := ctx.DeriveKey("flatten/" + scope)
+	f := &flattener{
+		ctx: ctx, file: file, fn: fd,
+		rng:     binary.LittleEndian.Uint64(key[:8]),
+		ids:     make(map[uint32]bool),
+		labels:  make(map[string]*flatLabel),
+		hoisted: make(map[types.Object]bool),
+		inLoop:  make(map[types.Object]bool),
+		types:   make(map[string]*types.TypeName),
+		renames: make(map[types.Object]string),
+	}
+	f.state, f.loop = f.fresh("flatState"), f.fresh("flatLoop")
+	return f
+}
+
+// lower splits the body into blocks without touching the AST; it returns
+// why the function cannot be flattened, or ""
+func (f *flattener) lower() string {
+	if f.ctx.GetDefs() == nil {
+		return "no type information"
+	}
+	labelPos := make(map[string]token.Pos)
+	ast.Inspect(f.fn.Body, func(n ast.Node) bool {
+		if ls, ok := n.(*ast.LabeledStmt); ok {
+			labelPos[ls.Label.Name] = ls.Pos()
+		}
+		_, isLit := n.(*ast.FuncLit)
+		return !isLit
+	})
+	ast.Inspect(f.fn, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			if tn, ok := f.ctx.GetDefs()[id].(*types.TypeName); ok {
+				f.types[tn.Name()] = tn
+			}
+		}
+		return true
+	})
+	ast.Inspect(f.fn.Body, func(n ast.Node) bool {
+		if bs, ok := n.(*ast.BranchStmt); ok && bs.Tok == token.GOTO && labelPos[bs.Label.Name] < bs.Pos() {
+			f.backGoto = true // goto para trás: qualquer bloco pode rodar de novo
+		}
+		_, isLit := n.(*ast.FuncLit)
+		return !isLit
+	})
+
+	entry := f.newBlock()
+	entry.refs++
+	if end := f.stmts(f.fn.Body.List, entry); end != nil {
+		// Só alcançável em funções sem resultados (senão o original não compilaria)
+		var last ast.Stmt = &ast.ReturnStmt{}
+		if f.fn.Type.Results != nil && len(f.fn.Type.Results.List) > 0 {
+			last = &ast.ExprStmt{X: &ast.CallExpr{Fun: ast.NewIdent("panic"), Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: `"unreachable"`}}}}
+		}
+		end.stmts = append(end.stmts, last)
+	}
+	f.planRenames()
+	if f.reason != "" {
+		return f.reason
+	}
+	return f.checkEscapes()
+}
+
+// planRenames gives a new name to every hoisted declaration that would clash
+// with another object of the same name in the function scope
+func (f *flattener) planRenames() {
+	objects := make(map[string]map[types.Object]bool)
+	ast.Inspect(f.fn, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		obj := f.ctx.GetDefs()[id]
+		if obj == nil {
+			obj = f.ctx.GetUses()[id]
+		}
+		switch o := obj.(type) {
+		case nil, *types.Label:
+			return true
+		case *types.Var:
+			if o.IsField() {
+				return true
+			}
+		case *types.Func:
+			if o.Type().(*types.Signature).Recv() != nil {
+				return true
+			}
+		}
+		if objects[id.Name] == nil {
+			objects[id.Name] = make(map[types.Object]bool)
+		}
+		objects[id.Name][obj] = true
+		return true
+	})
+
+	var clashes []types.Object
+	for obj := range f.hoisted {
+		if len(objects[obj.Name()]) > 1 {
+			clashes = append(clashes, obj)
+		}
+	}
+	sort.Slice(clashes, func(i, j int) bool { return clashes[i].Pos() < clashes[j].Pos() })
+	for _, obj := range clashes {
+		if _, isType := obj.(*types.TypeName); isType {
+			// Os tipos das variáveis hoisted são escritos pelo nome
+			f.fail("local type %s shadows another declaration", obj.Name())
+			return
+		}
+		f.renames[obj] = f.fresh(obj.Name())
+	}
+}
+
+func (f *flattener) fail(format string, args ...any) {
+	if f.reason == "" {
+		f.reason = fmt.Sprintf(format, args...)
+	}
+}
+
+func (f *flattener) stmts(list []ast.Stmt, cur *flatBlock) *flatBlock {
+	for _, s := range list {
+		cur = f.stmt(s, "", cur)
+	}
+	return cur
+}
+
+// stmt lowers s into cur and returns the block where execution continues,
+// or nil when s never completes normally
+func (f *flattener) stmt(s ast.Stmt, label string, cur *flatBlock) *flatBlock {
+	if ls, ok := s.(*ast.LabeledStmt); ok {
+		target := f.label(ls.Label.Name)
+		if cur != nil {
+			f.goTo(cur, target.start)
+		}
+		return f.stmt(ls.Stmt, ls.Label.Name, target.start)
+	}
+	if cur == nil {
+		cur = f.newBlock() // código inalcançável
+	}
+	switch s := s.(type) {
+	case *ast.BlockStmt:
+		return f.stmts(s.List, cur)
+
+	case *ast.IfStmt:
+		if s.Init != nil {
+			cur = f.stmt(s.Init, "", cur)
+		}
+		then, join := f.newBlock(), f.newBlock()
+		otherwise := join
+		if s.Else != nil {
+			otherwise = f.newBlock()
+		}
+		cur.stmts = append(cur.stmts, f.branch(s.Cond, cur, then, otherwise))
+		if end := f.stmts(s.Body.List, then); end != nil {
+			f.goTo(end, join)
+		}
+		if s.Else != nil {
+			if end := f.stmt(s.Else, "", otherwise); end != nil {
+				f.goTo(end, join)
+			}
+		}
+		return join
+
+	case *ast.ForStmt:
+		if s.Init != nil {
+			f.depth++ // as variáveis do init são por iteração
+			cur = f.stmt(s.Init, "", cur)
+			f.depth--
+		}
+		head, body, exit := f.newBlock(), f.newBlock(), f.newBlock()
+		cont := head
+		if s.Post != nil {
+			cont = f.newBlock()
+		}
+		f.goTo(cur, head)
+		if s.Cond != nil {
+			head.stmts = append(head.stmts, f.branch(s.Cond, head, body, exit))
+		} else {
+			f.goTo(head, body)
+		}
+		f.push(label, flatScope{brk: exit, cont: cont})
+		f.depth++
+		if end := f.stmts(s.Body.List, body); end != nil {
+			f.goTo(end, cont)
+		}
+		if s.Post != nil {
+			if end := f.stmt(s.Post, "", cont); end != nil {
+				f.goTo(end, head)
+			}
+		}
+		f.depth--
+		f.scopes = f.scopes[:len(f.scopes)-1]
+		return exit
+
+	case *ast.SwitchStmt:
+		return f.switchStmt(s, label, cur)
+
+	case *ast.BranchStmt:
+		if target := f.target(s); target != nil {
+			f.goTo(cur, target)
+		}
+		return nil
+
+	case *ast.ReturnStmt:
+		cur.stmts = append(cur.stmts, s)
+		return nil
+
+	case *ast.DeclStmt:
+		f.declStmt(s, cur)
+		return cur
+
+	case *ast.AssignStmt:
+		if s.Tok == token.DEFINE {
+			s = &ast.AssignStmt{Lhs: f.define(s.Lhs), TokPos: s.TokPos, Tok: token.ASSIGN, Rhs: s.Rhs}
+		}
+		cur.stmts = append(cur.stmts, s)
+		return cur
+
+	case *ast.ExprStmt:
+		cur.stmts = append(cur.stmts, s)
+		if call, ok := s.X.(*ast.CallExpr); ok {
+			if id, ok := ast.Unparen(call.Fun).(*ast.Ident); ok {
+				if _, builtin := f.ctx.GetUses()[id].(*types.Builtin); builtin && id.Name == "panic" {
+					return nil
+				}
+			}
+		}
+		return cur
+
+	case *ast.RangeStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+		f.atomic(s, label, cur)
+		return cur
+
+	default:
+		cur.stmts = append(cur.stmts, s)
+		return cur
+	}
+}
+
+// switchStmt lowers an expression switch into a chain of tests over the tag,
+// evaluated once, with one block per clause
+func (f *flattener) switchStmt(s *ast.SwitchStmt, label string, cur *flatBlock) *flatBlock {
+	if s.Init != nil {
+		cur = f.stmt(s.Init, "", cur)
+	}
+	var tag ast.Expr
+	if s.Tag != nil {
+		tv, ok := f.ctx.GetTypes()[s.Tag]
+		if !ok || tv.Type == nil {
+			f.fail("switch tag without type information")
+			return nil
+		}
+		typ := f.typeExpr(types.Default(tv.Type))
+		if typ == nil {
+			f.fail("switch tag of type %s not expressible here", tv.Type)
+			return nil
+		}
+		name := f.fresh("flatTag")
+		f.vars = append(f.vars, &ast.ValueSpec{Names: []*ast.Ident{ast.NewIdent(name)}, Type: typ})
+		cur.stmts = append(cur.stmts, &ast.AssignStmt{Lhs: []ast.Expr{ast.NewIdent(name)}, Tok: token.ASSIGN, Rhs: []ast.Expr{s.Tag}})
+		tag = ast.NewIdent(name)
+	}
+
+	clauses := s.Body.List
+	exit := f.newBlock()
+	bodies := make([]*flatBlock, len(clauses))
+	fallback := exit
+	for i, c := range clauses {
+		bodies[i] = f.newBlock()
+		if c.(*ast.CaseClause).List == nil {
+			fallback = bodies[i]
+		}
+	}
+
+	// Os casos são testados na ordem do código; default só se nenhum casar
+	var chain ast.Stmt = &ast.BlockStmt{List: []ast.Stmt{f.transfer(cur, fallback)}}
+	for i := len(clauses) - 1; i >= 0; i-- {
+		cc := clauses[i].(*ast.CaseClause)
+		if cc.List == nil {
+			continue
+		}
+		var cond ast.Expr
+		for _, e := range cc.List {
+			test := e
+			if tag != nil {
+				test = &ast.BinaryExpr{X: ast.NewIdent(tag.(*ast.Ident).Name), Op: token.EQL, Y: e}
+			}
+			if cond == nil {
+				cond = test
+			} else {
+				cond = &ast.BinaryExpr{X: cond, Op: token.LOR, Y: test}
+			}
+		}
+		chain = &ast.IfStmt{Cond: cond, Body: &ast.BlockStmt{List: []ast.Stmt{f.transfer(cur, bodies[i])}}, Else: chain}
+	}
+	if ifs, ok := chain.(*ast.IfStmt); ok {
+		cur.stmts = append(cur.stmts, ifs)
+	} else {
+		cur.stmts = append(cur.stmts, chain.(*ast.BlockStmt).List...)
+	}
+
+	f.push(label, flatScope{brk: exit})
+	for i, c := range clauses {
+		body := c.(*ast.CaseClause).Body
+		next := exit
+		if n := len(body); n > 0 {
+			if bs, ok := body[n-1].(*ast.BranchStmt); ok && bs.Tok == token.FALLTHROUGH {
+				body, next = body[:n-1], bodies[i+1]
+			}
+		}
+		if end := f.stmts(body, bodies[i]); end != nil {
+			f.goTo(end, next)
+		}
+	}
+	f.scopes = f.scopes[:len(f.scopes)-1]
+	return exit
+}
+
+// atomic keeps a range, select or type switch whole inside cur, turning the
+// branches that leave it into transitions to the flattened blocks
+func (f *flattener) atomic(s ast.Stmt, label string, cur *flatBlock) {
+	inner := make(map[string]bool)
+	if label != "" {
+		inner[label] = true
+	}
+	ast.Inspect(s, func(n ast.Node) bool {
+		if ls, ok := n.(*ast.LabeledStmt); ok {
+			inner[ls.Label.Name] = true
+		}
+		_, isLit := n.(*ast.FuncLit)
+		return !isLit
+	})
+
+	escape := flatEscape{stmt: s, branches: make(map[*ast.BranchStmt][]ast.Stmt)}
+	labelUsed := false
+	var stack []ast.Node
+	ast.Inspect(s, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		stack = append(stack, n)
+		bs, ok := n.(*ast.BranchStmt)
+		if !ok {
+			return true
+		}
+		if bs.Label != nil && bs.Label.Name == label {
+			labelUsed = true
+		}
+		escapes := bs.Label != nil && !inner[bs.Label.Name]
+		if bs.Label == nil && bs.Tok == token.CONTINUE {
+			escapes = true
+			for _, outer := range stack {
+				switch outer.(type) {
+				case *ast.ForStmt, *ast.RangeStmt:
+					escapes = false
+				}
+			}
+		}
+		if escapes {
+			if target := f.target(bs); target != nil {
+				f.loopUsed = true
+				escape.branches[bs] = []ast.Stmt{f.transfer(cur, target), &ast.BranchStmt{Tok: token.CONTINUE, Label: ast.NewIdent(f.loop)}}
+			}
+		}
+		return true
+	})
+	if len(escape.branches) > 0 {
+		f.escapes = append(f.escapes, escape)
+	}
+	if labelUsed {
+		s = &ast.LabeledStmt{Label: ast.NewIdent(label), Stmt: s}
+	}
+	cur.stmts = append(cur.stmts, s)
+}
+
+// target resolves the block a break, continue or goto jumps to
+func (f *flattener) target(s *ast.BranchStmt) *flatBlock {
+	scopes := f.scopes
+	var target *flatBlock
+	switch {
+	case s.Tok == token.GOTO:
+		target = f.label(s.Label.Name).start
+	case s.Label != nil && s.Tok == token.BREAK:
+		target = f.label(s.Label.Name).brk
+	case s.Label != nil && s.Tok == token.CONTINUE:
+		target = f.label(s.Label.Name).cont
+	case s.Tok == token.BREAK && len(scopes) > 0:
+		target = scopes[len(scopes)-1].brk
+	case s.Tok == token.CONTINUE:
+		for i := len(scopes) - 1; i >= 0 && target == nil; i-- {
+			target = scopes[i].cont
+		}
+	}
+	if target == nil {
+		f.fail("unsupported %s statement", s.Tok)
+	}
+	return target
+}
+
+// declStmt hoists local vars (their initializers become assignments) and
+// moves local consts and types to the top of the function
+func (f *flattener) declStmt(s *ast.DeclStmt, cur *flatBlock) {
+	gd := s.Decl.(*ast.GenDecl)
+	if gd.Tok != token.VAR {
+		for _, spec := range gd.Specs {
+			var names []*ast.Ident
+			switch spec := spec.(type) {
+			case *ast.ValueSpec:
+				names = spec.Names
+				for _, v := range spec.Values {
+					ast.Inspect(v, func(n ast.Node) bool {
+						if id, ok := n.(*ast.Ident); ok {
+							if v, ok := f.ctx.GetUses()[id].(*types.Var); ok && v.Parent() != nil && v.Parent() != types.Universe && (f.ctx.Package == nil || v.Parent() != f.ctx.Package.Scope()) {
+								f.fail("constant %s depends on local %s", spec.Names[0].Name, id.Name)
+							}
+						}
+						return true
+					})
+				}
+			case *ast.TypeSpec:
+				names = []*ast.Ident{spec.Name}
+			}
+			for _, name := range names {
+				if obj := f.ctx.GetDefs()[name]; obj != nil {
+					f.hoisted[obj] = true
+				}
+			}
+		}
+		f.decls = append(f.decls, s)
+		return
+	}
+
+	for _, spec := range gd.Specs {
+		vs := spec.(*ast.ValueSpec)
+		lhs := f.define(identExprs(vs.Names))
+		if len(vs.Values) > 0 {
+			cur.stmts = append(cur.stmts, &ast.AssignStmt{Lhs: lhs, Tok: token.ASSIGN, Rhs: vs.Values})
+			continue
+		}
+		// Sem valor: zera de novo a cada vez que o bloco roda
+		if f.depth > 0 || f.backGoto {
+			for i, name := range vs.Names {
+				obj := f.ctx.GetDefs()[name]
+				if obj == nil {
+					continue
+				}
+				if typ := f.typeExpr(obj.Type()); typ != nil {
+					zero := &ast.StarExpr{X: &ast.CallExpr{Fun: ast.NewIdent("new"), Args: []ast.Expr{typ}}}
+					cur.stmts = append(cur.stmts, &ast.AssignStmt{Lhs: []ast.Expr{lhs[i]}, Tok: token.ASSIGN, Rhs: []ast.Expr{zero}})
+				}
+			}
+		}
+	}
+}
+
+// define hoists the variables declared by lhs and returns the expressions
+// that assign them in place
+func (f *flattener) define(lhs []ast.Expr) []ast.Expr {
+	out := make([]ast.Expr, len(lhs))
+	for i, e := range lhs {
+		out[i] = e
+		id, ok := e.(*ast.Ident)
+		if !ok || id.Name == "_" {
+			continue
+		}
+		obj := f.ctx.GetDefs()[id]
+		if obj == nil {
+			if f.ctx.GetUses()[id] == nil {
+				f.fail("%s is declared by generated code without type information", id.Name)
+			}
+			continue // redeclaração em :=
+		}
+		typ := f.typeExpr(obj.Type())
+		if typ == nil {
+			f.fail("type of %s (%s) is not expressible here", id.Name, obj.Type())
+			continue
+		}
+		f.hoisted[obj] = true
+		if f.depth > 0 {
+			f.inLoop[obj] = true
+		}
+		f.vars = append(f.vars, &ast.ValueSpec{Names: []*ast.Ident{id}, Type: typ})
+		use := &ast.Ident{NamePos: id.NamePos, Name: id.Name}
+		f.ctx.GetUses()[use] = obj
+		out[i] = use
+	}
+	return out
+}
+
+// checkEscapes rejects functions where hoisting a variable declared inside a
+// loop would share it between iterations: closures and pointers would see
+// one variable instead of one per iteration
+func (f *flattener) checkEscapes() string {
+	perIteration := func(obj types.Object) bool {
+		_, isVar := obj.(*types.Var)
+		return isVar && f.hoisted[obj] && (f.backGoto || f.inLoop[obj])
+	}
+	reason := ""
+	ast.Inspect(f.fn.Body, func(n ast.Node) bool {
+		if reason != "" {
+			return false
+		}
+		var root types.Object
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			ast.Inspect(node.Body, func(m ast.Node) bool {
+				if id, ok := m.(*ast.Ident); ok && reason == "" && perIteration(f.ctx.GetUses()[id]) {
+					reason = fmt.Sprintf("%s is declared inside a loop and captured by a closure", id.Name)
+				}
+				return reason == ""
+			})
+			return false
+		default:
+			root = addressTaken(n, f.ctx)
+		}
+		if perIteration(root) {
+			reason = fmt.Sprintf("%s is declared inside a loop and its address is taken", root.Name())
+		}
+		return true
+	})
+	return reason
+}
+
+// addressTaken returns the variable whose address n takes, if any: &x, an
+// array sliced in place, or a pointer-receiver method called on a value
+func addressTaken(n ast.Node, ctx *astutil.TranspileContext) types.Object {
+	switch node := n.(type) {
+	case *ast.UnaryExpr:
+		if node.Op == token.AND {
+			return addressedVar(node.X, ctx)
+		}
+	case *ast.SliceExpr:
+		if tv, ok := ctx.GetTypes()[node.X]; ok && tv.Type != nil {
+			if _, isArray := tv.Type.Underlying().(*types.Array); isArray {
+				return addressedVar(node.X, ctx)
+			}
+		}
+	case *ast.SelectorExpr:
+		// Método com receptor ponteiro chamado sobre valor: &x implícito
+		if sel := ctx.GetSelections()[node]; sel != nil && sel.Kind() != types.FieldVal {
+			if sig, ok := sel.Obj().Type().(*types.Signature); ok && sig.Recv() != nil {
+				_, ptrRecv := sig.Recv().Type().(*types.Pointer)
+				_, ptrX := sel.Recv().(*types.Pointer)
+				if ptrRecv && !ptrX {
+					return addressedVar(node.X, ctx)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// addressedVar returns the variable whose storage e refers to, if any
+func addressedVar(e ast.Expr, ctx *astutil.TranspileContext) types.Object {
+	switch node := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		return ctx.GetUses()[node]
+	case *ast.SelectorExpr:
+		if sel := ctx.GetSelections()[node]; sel != nil && sel.Kind() == types.FieldVal && !sel.Indirect() {
+			return addressedVar(node.X, ctx)
+		}
+	case *ast.IndexExpr:
+		if tv, ok := ctx.GetTypes()[node.X]; ok && tv.Type != nil {
+			if _, isArray := tv.Type.Underlying().(*types.Array); isArray {
+				return addressedVar(node.X, ctx)
+			}
+		}
+	}
+	return nil
+}
+
+// commit rewrites the function body into the dispatcher and returns the
+// number of blocks
+func (f *flattener) commit() int {
+	for _, escape := range f.escapes {
+		stdastutil.Apply(escape.stmt, func(c *stdastutil.Cursor) bool {
+			if bs, ok := c.Node().(*ast.BranchStmt); ok && escape.branches[bs] != nil {
+				c.Replace(&ast.BlockStmt{List: escape.branches[bs]})
+			}
+			_, isLit := c.Node().(*ast.FuncLit)
+			return !isLit
+		}, nil)
+	}
+
+	// Casos em ordem embaralhada pela seed
+	var live []*flatBlock
+	for _, b := range f.blocks {
+		if b.refs > 0 || len(b.stmts) > 0 {
+			live = append(live, b)
+		}
+	}
+	for i := len(live) - 1; i > 0; i-- {
+		j := int(f.next() % uint64(i+1))
+		live[i], live[j] = live[j], live[i]
+	}
+	clauses := make([]ast.Stmt, len(live))
+	for i, b := range live {
+		clauses[i] = &ast.CaseClause{List: []ast.Expr{stateLit(b.id)}, Body: b.stmts}
+	}
+
+	var body []ast.Stmt
+	body = append(body, f.decls...)
+	if len(f.vars) > 0 {
+		specs := make([]ast.Spec, len(f.vars))
+		for i, vs := range f.vars {
+			specs[i] = vs
+		}
+		body = append(body, &ast.DeclStmt{Decl: &ast.GenDecl{Tok: token.VAR, Lparen: f.fn.Body.Lbrace, Specs: specs, Rparen: f.fn.Body.Lbrace}})
+	}
+	body = append(body, &ast.AssignStmt{
+		Lhs: []ast.Expr{ast.NewIdent(f.state)},
+		Tok: token.DEFINE,
+		Rhs: []ast.Expr{&ast.CallExpr{Fun: ast.NewIdent("uint32"), Args: []ast.Expr{stateLit(f.blocks[0].id)}}},
+	})
+	var dispatcher ast.Stmt = &ast.ForStmt{Body: &ast.BlockStmt{List: []ast.Stmt{
+		&ast.SwitchStmt{Tag: ast.NewIdent(f.state), Body: &ast.BlockStmt{List: clauses}},
+	}}}
+	if f.loopUsed {
+		dispatcher = &ast.LabeledStmt{Label: ast.NewIdent(f.loop), Stmt: dispatcher}
+	}
+	body = append(body, dispatcher)
+
+	newBody := &ast.BlockStmt{List: body}
+	if len(f.renames) > 0 {
+		ast.Inspect(newBody, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok {
+				obj := f.ctx.GetDefs()[id]
+				if obj == nil {
+					obj = f.ctx.GetUses()[id]
+				}
+				if name, ok := f.renames[obj]; ok {
+					id.Name = name
+				}
+			}
+			return true
+		})
+	}
+
+	// Comentários do corpo antigo perderiam a posição; a diretiva não deve sobrar no binário de saída
+	var drop []*ast.CommentGroup
+	for _, cg := range f.file.Comments {
+		if cg.Pos() > f.fn.Body.Lbrace && cg.End() < f.fn.Body.Rbrace {
+			drop = append(drop, cg)
+		}
+	}
+	if f.fn.Doc != nil {
+		kept := f.fn.Doc.List[:0]
+		for _, c := range f.fn.Doc.List {
+			if !hasDirective(&ast.CommentGroup{List: []*ast.Comment{c}}, flattenDirective) {
+				kept = append(kept, c)
+			}
+		}
+		f.fn.Doc.List = kept
+		if len(kept) == 0 {
+			drop = append(drop, f.fn.Doc)
+			f.fn.Doc = nil
+		}
+	}
+	dropComments(f.file, drop...)
+
+	collapsePositions(newBody, f.fn.Body.Lbrace)
+	f.fn.Body = newBody
+	return len(live)
+}
+
+// branch returns `if cond { → then } else { → otherwise }` for the end of from
+func (f *flattener) branch(cond ast.Expr, from, then, otherwise *flatBlock) ast.Stmt {
+	return &ast.IfStmt{
+		Cond: cond,
+		Body: &ast.BlockStmt{List: []ast.Stmt{f.transfer(from, then)}},
+		Else: &ast.BlockStmt{List: []ast.Stmt{f.transfer(from, otherwise)}},
+	}
+}
+
+// transfer returns the statement that moves the dispatcher from one block to another
+func (f *flattener) transfer(from, to *flatBlock) ast.Stmt {
+	to.refs++
+	return &ast.AssignStmt{Lhs: []ast.Expr{ast.NewIdent(f.state)}, Tok: token.XOR_ASSIGN, Rhs: []ast.Expr{stateLit(from.id ^ to.id)}}
+}
+
+func (f *flattener) goTo(from, to *flatBlock) {
+	from.stmts = append(from.stmts, f.transfer(from, to))
+}
+
+func (f *flattener) newBlock() *flatBlock {
+	id := uint32(f.next())
+	for id == 0 || f.ids[id] {
+		id = uint32(f.next())
+	}
+	f.ids[id] = true
+	b := &flatBlock{id: id}
+	f.blocks = append(f.blocks, b)
+	return b
+}
+
+// label returns the targets of a label, creating its start block on first use
+func (f *flattener) label(name string) *flatLabel {
+	if f.labels[name] == nil {
+		f.labels[name] = &flatLabel{start: f.newBlock()}
+	}
+	return f.labels[name]
+}
+
+func (f *flattener) push(label string, scope flatScope) {
+	f.scopes = append(f.scopes, scope)
+	if label != "" {
+		target := f.label(label)
+		target.brk, target.cont = scope.brk, scope.cont
+	}
+}
+
+func (f *flattener) next() uint64 {
+	f.rng += 0x9e3779b97f4a7c15
+	return splitmix64(f.rng)
+}
+
+// fresh returns a name based on base that appears nowhere in the file
+func (f *flattener) fresh(base string) string {
+	if f.used == nil {
+		f.used = make(map[string]bool)
+		collectIdentNames(f.file, f.used)
+	}
+	name := base
+	for i := 0; f.used[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	f.used[name] = true
+	return name
+}
+
+// typeExpr renders t for a hoisted declaration, binding the names of local
+// types and type parameters to their objects so later passes can follow them
+func (f *flattener) typeExpr(t types.Type) ast.Expr {
+	typ := typeExprFor(t, f.file, f.ctx)
+	if typ != nil {
+		ast.Inspect(typ, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && f.types[id.Name] != nil {
+				f.ctx.GetUses()[id] = f.types[id.Name]
+			}
+			return true
+		})
+	}
+	return typ
+}
+
+// optionalPos are the position fields whose zero value changes what the printer emits
+var optionalPos = map[string]bool{"Ellipsis": true, "Lparen": true, "Rparen": true, "Opening": true, "Closing": true}
+
+// collapsePositions moves every node under root to pos. The printer places
+// comments and blank lines by position, and the dispatcher mixes moved and
+// synthesized nodes with no source order left; zero positions would make it
+// drift past the end of the function and pull in the comments that follow.
+func collapsePositions(root ast.Node, pos token.Pos) {
+	posType := reflect.TypeOf(token.NoPos)
+	ast.Inspect(root, func(n ast.Node) bool {
+		v := reflect.ValueOf(n)
+		if n == nil || v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+			return n != nil
+		}
+		v = v.Elem()
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if field.Type() == posType && (field.Int() != 0 || !optionalPos[v.Type().Field(i).Name]) {
+				field.SetInt(int64(pos))
+			}
+		}
+		return true
+	})
+}
+
+func stateLit(id uint32) ast.Expr {
+	return &ast.BasicLit{Kind: token.INT, Value: fmt.Sprintf("%#08x", id)}
+}
+
+func identExprs(ids []*ast.Ident) []ast.Expr {
+	out := make([]ast.Expr, len(ids))
+	for i, id := range ids {
+		out[i] = id
+	}
+	return out
+}
@@ -0,0 +1,100 @@
+package pass
+
+import "testing"
+
+const flattenProbe = `package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+//gastype:flatten
+func classify(xs []int) (out string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("recovered: %v", r)
+		}
+	}()
+	total := 0
+outer:
+	for i := 0; i < len(xs); i++ {
+		switch {
+		case xs[i] < 0:
+			continue outer
+		case xs[i] == 0:
+			break outer
+		case xs[i] > 100:
+			panic("too big")
+		}
+		for _, d := range []int{1, 2} {
+			if d == 2 {
+				break
+			}
+			total += xs[i] * d
+		}
+	}
+	if total > 10 {
+		goto big
+	}
+	return fmt.Sprint("small ", total), nil
+big:
+	out = fmt.Sprint("big ", total)
+	return
+}
+
+// Cada closure precisa da sua própria v: subir v para o topo mudaria isso
+//
+//gastype:flatten
+func captured() []func() int {
+	var fs []func() int
+	for i := 0; i < 3; i++ {
+		v := i * 10
+		fs = append(fs, func() int { return v })
+	}
+	return fs
+}
+
+// Idem para o endereço de p tomado a cada volta
+//
+//gastype:flatten
+func addressed() []*int {
+	var ps []*int
+	for i := 0; i < 3; i++ {
+		p := i
+		ps = append(ps, &p)
+	}
+	if len(ps) == 0 {
+		return nil
+	}
+	return ps
+}
+
+func main() {
+	for _, xs := range [][]int{{1, 2, 3}, {5, -1, 9}, {4, 0, 50}, {1, 200}} {
+		fmt.Println(classify(xs))
+	}
+	for _, f := range captured() {
+		fmt.Println(f())
+	}
+	for _, p := range addressed() {
+		fmt.Println(*p)
+	}
+	fmt.Println(errors.New("done"))
+}
+`
+
+func TestFlattenPreservesBehavior(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, flattenProbe)
+	out, ctx := transpileSource(t, flattenProbe, true, NewFlattenPass())
+	if got := countLedger(ctx, "Flatten", "rewritten"); got != 1 {
+		t.Errorf("%d functions flattened, want 1\n%s", got, out)
+	}
+	if got := countLedger(ctx, "Flatten", "rejected"); got != 2 {
+		t.Errorf("%d functions rejected, want 2 (captured and addressed)\n%+v", got, ctx.Ledger)
+	}
+	if got := runSource(t, out); got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+}
@@ -0,0 +1,160 @@
+package pass
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	stdastutil "golang.org/x/tools/go/ast/astutil"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// FmtEliminatePass tira o fmt de binários pequenos: as chamadas de verbos
+// simples viram concatenação com strconv, e a saída e os erros passam por um
+// formatador mínimo gerado no próprio pacote:
+//
+//	fmt.Println("n:", n)          →  stdoutWrite("n: " + strconv.Itoa(n) + "\n")
+//	fmt.Printf("%s=%d\n", k, v)   →  stdoutWrite(k + "=" + strconv.Itoa(v) + "\n")
+//	fmt.Errorf("open %s: %v", p, err)  →  errors.New("open " + p + ": " + errorText(err))
+//
+// Cobre Print, Println, Printf, Errorf, Sprint, Sprintln e Sprintf com as
+// mesmas regras do FmtStrconv (formato constante, %s %q %d %t %v %%, tipos
+// sem métodos de formatação), aceitando também argumentos do tipo error.
+// Errorf sem %w já devolve errors.New(s) no fmt, e stdoutWrite devolve o
+// (n, err) de os.Stdout como Print. Quando nenhum uso sobra o import de fmt
+// cai; o que não foi coberto fica no ledger. É opt-in: o ganho só aparece
+// se nenhum pacote do binário importar fmt (veja build --baseline).
+type FmtEliminatePass struct {
+	writeFunc, errorFunc       string // helpers gerados, nomeados por pacote
+	writeEmitted, errorEmitted bool
+	used                       map[string]bool
+}
+
+func NewFmtEliminatePass() *FmtEliminatePass { return &FmtEliminatePass{} }
+func (p *FmtEliminatePass) Name() string     { return "FmtEliminate" }
+
+// Prepare names the helpers shared by the files of a package
+func (p *FmtEliminatePass) Prepare(files []*ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	p.used = make(map[string]bool)
+	for _, file := range files {
+		collectIdentNames(file, p.used)
+	}
+	p.writeFunc = p.fresh("stdoutWrite")
+	p.errorFunc = p.fresh("errorText")
+	p.writeEmitted, p.errorEmitted = false, false
+	return nil
+}
+
+func (p *FmtEliminatePass) fresh(base string) string {
+	name := base
+	for i := 2; p.used[name] || types.Universe.Lookup(name) != nil; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	p.used[name] = true
+	return name
+}
+
+func (p *FmtEliminatePass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	r := &fmtEliminator{
+		pass:        p,
+		fmtRewriter: fmtRewriter{file: file, fset: fset, ctx: ctx, errorFunc: p.errorFunc},
+	}
+	stdastutil.Apply(file, nil, r.rewrite)
+	if r.count == 0 {
+		return nil
+	}
+
+	// === Helpers gerados, uma vez por pacote ===
+	var src []string
+	if r.needWrite && !p.writeEmitted {
+		osName := importName(fset, file, "os")
+		src = append(src, fmt.Sprintf("// %s writes s to os.Stdout like fmt.Print\nfunc %s(s string) (int, error) {\n\treturn %s.Stdout.WriteString(s)\n}\n",
+			p.writeFunc, p.writeFunc, osName))
+		p.writeEmitted = true
+	}
+	if r.needError && !p.errorEmitted {
+		// O fmt imprime "<nil>" quando Error entra em pânico num ponteiro nil
+		src = append(src, fmt.Sprintf("// %s formats err like fmt's %%v\nfunc %s(err error) (s string) {\n\tif err == nil {\n\t\treturn \"<nil>\"\n\t}\n\tdefer func() {\n\t\tif recover() != nil {\n\t\t\ts = \"<nil>\"\n\t\t}\n\t}()\n\treturn err.Error()\n}\n",
+			p.errorFunc, p.errorFunc))
+		p.errorEmitted = true
+	}
+	if len(src) > 0 {
+		decls, err := astutil.ParseDecls(fset, strings.Join(src, "\n"))
+		if err != nil {
+			return fmt.Errorf("FmtEliminate: %w", err)
+		}
+		file.Decls = append(file.Decls, decls...)
+	}
+
+	pos := fset.Position(file.Package)
+	if stdastutil.UsesImport(file, "fmt") {
+		ctx.RecordLedger(p.Name(), pos, `import "fmt"`, "skipped", "fmt still used in this file")
+	} else {
+		stdastutil.DeleteImport(fset, file, "fmt")
+		ctx.RecordLedger(p.Name(), pos, `import "fmt"`, "rewritten", "import dropped")
+	}
+	ctx.LogVerbose(fset, "✂️ FmtEliminatePass: %d fmt calls replaced", r.count)
+	return nil
+}
+
+// fmtEliminator replaces the covered fmt calls of one file
+type fmtEliminator struct {
+	fmtRewriter
+	pass                 *FmtEliminatePass
+	errorsName           string
+	needWrite, needError bool
+}
+
+// rewrite runs after the children of each node were rewritten
+func (r *fmtEliminator) rewrite(c *stdastutil.Cursor) bool {
+	call, ok := c.Node().(*ast.CallExpr)
+	if !ok {
+		return true
+	}
+	name, parts, args, reason := planFmtCall(call, r.ctx, true)
+	if name == "" {
+		return true
+	}
+	pos := r.fset.Position(call.Pos())
+	if reason != "" {
+		gl.Log("info", fmt.Sprintf("FmtEliminate: skipping %s at %s (%s)", name, pos, reason))
+		r.ctx.RecordLedger(r.pass.Name(), pos, name, "skipped", reason)
+		return true
+	}
+	for _, part := range parts {
+		if part.kind == "error" {
+			r.needError = true
+		}
+	}
+
+	var repl ast.Expr
+	var how string
+	switch name {
+	case "fmt.Print", "fmt.Println", "fmt.Printf":
+		repl = &ast.CallExpr{Fun: ast.NewIdent(r.pass.writeFunc), Args: []ast.Expr{r.concat(parts, args, call)}}
+		how = r.pass.writeFunc
+		r.needWrite = true
+	case "fmt.Errorf":
+		if r.errorsName == "" {
+			r.errorsName = importName(r.fset, r.file, "errors")
+		}
+		repl = &ast.CallExpr{
+			Fun:  &ast.SelectorExpr{X: ast.NewIdent(r.errorsName), Sel: ast.NewIdent("New")},
+			Args: []ast.Expr{r.concat(parts, args, call)},
+		}
+		how = "errors.New"
+	default:
+		repl = r.concat(parts, args, c.Parent())
+		how = "concatenation"
+	}
+	r.replace(c, call, repl)
+	r.count++
+	r.ctx.RecordLedger(r.pass.Name(), pos, name, "rewritten", how)
+	return true
+}
@@ -0,0 +1,63 @@
+package pass
+
+import (
+	"strings"
+	"testing"
+)
+
+const fmtEliminateProbe = `package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Level int
+
+func (l Level) String() string { return "level" }
+
+type nilErr struct{}
+
+func (*nilErr) Error() string { return "boom" }
+
+func open(p string) error {
+	if p == "" {
+		return errors.New("empty")
+	}
+	return nil
+}
+
+func main() {
+	n, name := 3, "gas"
+	fmt.Println("n:", n, name)
+	fmt.Printf("%s=%d %t\n", name, n, n > 2)
+	fmt.Print(n, name, "\n")
+	err := fmt.Errorf("open %s: %v", name, open(""))
+	fmt.Println(err)
+	fmt.Println(fmt.Errorf("wrap %d", n), open("x"))
+	fmt.Println(fmt.Sprintf("[%s]", fmt.Sprint(n)))
+	var typed *nilErr
+	var e error = typed
+	fmt.Println(e)
+
+	// Ficam: Stringer e %w
+	fmt.Println(Level(1))
+	fmt.Println(fmt.Errorf("w: %w", err))
+}
+`
+
+func TestFmtEliminatePreservesBehavior(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, fmtEliminateProbe)
+	out, ctx := transpileSource(t, fmtEliminateProbe, false, NewFmtEliminatePass())
+	if got := countLedger(ctx, "FmtEliminate", "skipped"); got != 3 {
+		t.Errorf("%d entries skipped, want 3 (Stringer, %%w, the import)\n%+v", got, ctx.Ledger)
+	}
+	// Errorf e Sprint internos já reescritos não podem derrubar a chamada externa
+	if strings.Contains(out, `fmt.Errorf("wrap`) || strings.Contains(out, `fmt.Sprint(n)`) || strings.Contains(out, `fmt.Sprintf("[%s]"`) {
+		t.Errorf("nested calls left in place\n%s", out)
+	}
+	if got := runSource(t, out); got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+}
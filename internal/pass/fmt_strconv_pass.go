@@ -0,0 +1,575 @@
+package pass
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	stdastutil "golang.org/x/tools/go/ast/astutil"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// FmtStrconvPass troca fmt.Sprintf e fmt.Sprint de verbos simples sobre
+// tipos conhecidos por strconv, concatenação ou um helper com strings.Builder:
+//
+//	fmt.Sprintf("%d", n)              →  strconv.Itoa(n)
+//	fmt.Sprint(s)                     →  s
+//	fmt.Sprintf("%s:%s", a, b)        →  a + ":" + b
+//	fmt.Sprintf("%s=%d/%d", k, x, y)  →  sprintf(k, int64(x), int64(y))
+//
+// Entram formatos constantes com %s, %q, %d, %t, %v e %% (sem flags, largura
+// ou precisão) e exatamente um argumento por verbo. Os argumentos precisam
+// ser string, []byte, inteiros, bool ou float, e o tipo não pode ter String,
+// Error, Format ou GoString: o fmt chamaria o método. Com duas ou mais
+// conversões numéricas o resultado vai para um helper gerado que escreve
+// tudo num único strings.Builder, em vez de uma string por conversão. Os
+// imports são ajustados (fmt cai quando nenhum uso sobra) e cada troca vai
+// para a seção fmt_rewrites do --map, com as alocações estimadas antes e depois.
+type FmtStrconvPass struct {
+	helpers map[string]*fmtHelper // formato + tipos → helper gerado
+	order   []*fmtHelper
+	used    map[string]bool
+}
+
+// fmtPart is a literal run or one formatted argument of a format string
+type fmtPart struct {
+	lit  string
+	arg  int  // índice do argumento, -1 para texto literal
+	verb rune // s, q, d, t ou v
+	kind string
+}
+
+// fmtHelper is a generated strings.Builder formatter shared by identical calls
+type fmtHelper struct {
+	name    string
+	parts   []fmtPart
+	emitted bool
+}
+
+func NewFmtStrconvPass() *FmtStrconvPass { return &FmtStrconvPass{} }
+func (p *FmtStrconvPass) Name() string   { return "FmtStrconv" }
+
+// Prepare resets the helpers shared by the files of a package
+func (p *FmtStrconvPass) Prepare(files []*ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	p.helpers = make(map[string]*fmtHelper)
+	p.order = nil
+	p.used = make(map[string]bool)
+	for _, file := range files {
+		collectIdentNames(file, p.used)
+	}
+	return nil
+}
+
+// fmtKind classifies the static type of a formatted argument, or returns ""
+// when fmt would format it with methods or reflection. The error interface
+// is its own kind: fmt calls Error, and "<nil>" for a nil error
+func fmtKind(t types.Type) string {
+	if t == nil {
+		return ""
+	}
+	if types.Identical(t, types.Universe.Lookup("error").Type()) {
+		return "error"
+	}
+	ms := types.NewMethodSet(t)
+	for _, name := range []string{"String", "Error", "Format", "GoString"} {
+		if ms.Lookup(nil, name) != nil {
+			return ""
+		}
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		info := u.Info()
+		switch {
+		case info&types.IsString != 0:
+			return "string"
+		case info&types.IsBoolean != 0:
+			return "bool"
+		case info&types.IsUnsigned != 0:
+			return "uint64"
+		case u.Kind() == types.Int || u.Kind() == types.UntypedInt:
+			return "int"
+		case info&types.IsInteger != 0:
+			return "int64"
+		case u.Kind() == types.Float32:
+			return "float32"
+		case u.Kind() == types.Float64 || u.Kind() == types.UntypedFloat:
+			return "float64"
+		}
+	case *types.Slice:
+		if b, ok := u.Elem().(*types.Basic); ok && b.Kind() == types.Byte {
+			return "bytes"
+		}
+	}
+	return ""
+}
+
+// fmtVerbAccepts reports whether verb formats kind like the strconv rewrite does
+func fmtVerbAccepts(verb rune, kind string) bool {
+	switch verb {
+	case 'v':
+		return kind != "bytes"
+	case 's':
+		return kind == "string" || kind == "bytes" || kind == "error"
+	case 'q':
+		return kind == "string" || kind == "bytes"
+	case 'd':
+		return kind == "int" || kind == "int64" || kind == "uint64"
+	case 't':
+		return kind == "bool"
+	}
+	return false
+}
+
+// parseFormat splits a Sprintf format into literal runs and simple verbs
+func parseFormat(format string) ([]fmtPart, string) {
+	var parts []fmtPart
+	var lit strings.Builder
+	arg := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			lit.WriteByte(format[i])
+			continue
+		}
+		if i+1 == len(format) {
+			return nil, "format ends with %"
+		}
+		i++
+		switch verb := rune(format[i]); verb {
+		case '%':
+			lit.WriteByte('%')
+		case 's', 'q', 'd', 't', 'v':
+			if lit.Len() > 0 {
+				parts = append(parts, fmtPart{lit: lit.String(), arg: -1})
+				lit.Reset()
+			}
+			parts = append(parts, fmtPart{arg: arg, verb: verb})
+			arg++
+		default:
+			return nil, fmt.Sprintf("verb or flag %%%c not supported", verb)
+		}
+	}
+	if lit.Len() > 0 {
+		parts = append(parts, fmtPart{lit: lit.String(), arg: -1})
+	}
+	return parts, ""
+}
+
+// planFmtCall returns the fmt function called by call ("fmt.Sprintf"), the
+// parts of its output and the formatted arguments, or why the call cannot be
+// rewritten. Error arguments are only accepted with withErrors, by passes
+// that generate the helper formatting them. name is "" for calls outside fmt
+func planFmtCall(call *ast.CallExpr, ctx *astutil.TranspileContext, withErrors bool) (name string, parts []fmtPart, args []ast.Expr, reason string) {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return "", nil, nil, ""
+	}
+	fn, ok := ctx.GetUses()[sel.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "fmt" {
+		return "", nil, nil, ""
+	}
+	name = "fmt." + fn.Name()
+	if call.Ellipsis.IsValid() {
+		return name, nil, nil, "spread arguments"
+	}
+
+	args = call.Args
+	kindOf := func(e ast.Expr) string { return fmtKind(ctx.GetTypes()[e].Type) }
+	switch fn.Name() {
+	case "Sprintf", "Printf", "Errorf":
+		if len(args) == 0 {
+			return name, nil, nil, "no format"
+		}
+		tv, ok := ctx.GetTypes()[args[0]]
+		if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+			return name, nil, nil, "non-constant format"
+		}
+		if parts, reason = parseFormat(constant.StringVal(tv.Value)); reason != "" {
+			return name, nil, nil, reason
+		}
+		args = args[1:]
+	case "Sprint", "Print":
+		// Separa com espaço os operandos vizinhos que não são strings; o tipo
+		// dinâmico de um error decidiria, então error fica de fora
+		for i := range args {
+			if kindOf(args[i]) == "error" {
+				return name, nil, nil, "error operand"
+			}
+			if i > 0 && kindOf(args[i-1]) != "string" && kindOf(args[i]) != "string" {
+				parts = append(parts, fmtPart{lit: " ", arg: -1})
+			}
+			parts = append(parts, fmtPart{arg: i, verb: 'v'})
+		}
+	case "Sprintln", "Println":
+		for i := range args {
+			if i > 0 {
+				parts = append(parts, fmtPart{lit: " ", arg: -1})
+			}
+			parts = append(parts, fmtPart{arg: i, verb: 'v'})
+		}
+		parts = append(parts, fmtPart{lit: "\n", arg: -1})
+	default:
+		return name, nil, nil, "function not covered"
+	}
+
+	verbs := 0
+	for i := range parts {
+		if parts[i].arg < 0 {
+			continue
+		}
+		verbs++
+		if parts[i].arg >= len(args) {
+			return name, nil, nil, "missing argument"
+		}
+		t := ctx.GetTypes()[args[parts[i].arg]].Type
+		kind := fmtKind(t)
+		if kind == "" || kind == "error" && !withErrors {
+			return name, nil, nil, fmt.Sprintf("argument of type %s not supported", types.TypeString(t, nil))
+		}
+		if !fmtVerbAccepts(parts[i].verb, kind) {
+			return name, nil, nil, fmt.Sprintf("%%%c of %s not supported", parts[i].verb, types.TypeString(t, nil))
+		}
+		parts[i].kind = kind
+	}
+	if verbs != len(args) {
+		return name, nil, nil, "extra arguments"
+	}
+	// Literais vizinhos (Sprint/Println) viram um só
+	merged := parts[:0]
+	for _, part := range parts {
+		if n := len(merged); n > 0 && part.arg < 0 && merged[n-1].arg < 0 {
+			merged[n-1].lit += part.lit
+			continue
+		}
+		merged = append(merged, part)
+	}
+	if len(merged) == 0 {
+		merged = append(merged, fmtPart{arg: -1}) // Sprint() e Sprintf("")
+	}
+	return name, merged, args, ""
+}
+
+func (p *FmtStrconvPass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	r := &fmtRewriter{pass: p, file: file, fset: fset, ctx: ctx}
+	stdastutil.Apply(file, nil, r.rewrite)
+	if r.count == 0 {
+		return nil
+	}
+
+	// === Helpers gerados, uma vez por pacote ===
+	var src []string
+	for _, h := range p.order {
+		if !h.emitted {
+			src = append(src, r.helperSource(h))
+			h.emitted = true
+		}
+	}
+	if len(src) > 0 {
+		decls, err := astutil.ParseDecls(fset, strings.Join(src, "\n"))
+		if err != nil {
+			return fmt.Errorf("FmtStrconv: %w", err)
+		}
+		file.Decls = append(file.Decls, decls...)
+	}
+
+	if !stdastutil.UsesImport(file, "fmt") {
+		stdastutil.DeleteImport(fset, file, "fmt")
+	}
+	ctx.LogVerbose(fset, "🧵 FmtStrconvPass: %d fmt calls rewritten", r.count)
+	return nil
+}
+
+// fmtRewriter replaces the accepted fmt calls of one file
+type fmtRewriter struct {
+	pass        *FmtStrconvPass
+	file        *ast.File
+	fset        *token.FileSet
+	ctx         *astutil.TranspileContext
+	strconvName string
+	stringsName string
+	errorFunc   string // helper que formata error, só com withErrors
+	count       int
+}
+
+// rewrite runs after the children of each node were rewritten
+func (r *fmtRewriter) rewrite(c *stdastutil.Cursor) bool {
+	call, ok := c.Node().(*ast.CallExpr)
+	if !ok {
+		return true
+	}
+	name, parts, args, reason := planFmtCall(call, r.ctx, false)
+	if name != "fmt.Sprintf" && name != "fmt.Sprint" {
+		return true
+	}
+	pos := r.fset.Position(call.Pos())
+	if reason != "" {
+		gl.Log("info", fmt.Sprintf("FmtStrconv: skipping %s at %s (%s)", name, pos, reason))
+		r.ctx.RecordLedger(r.pass.Name(), pos, name, "skipped", reason)
+		return true
+	}
+
+	// fmt: o resultado, o slice ...any e cada argumento não constante que
+	// não cabe num byte precisa ir para uma interface
+	before := 2
+	for _, arg := range args {
+		tv := r.ctx.GetTypes()[arg]
+		if b, ok := tv.Type.Underlying().(*types.Basic); tv.Value == nil && (!ok || (b.Info()&types.IsBoolean == 0 && astutil.SizesFor(r.ctx.GOARCH).Sizeof(b) > 1)) {
+			before++
+		}
+	}
+
+	// conversions alocam uma string cada; buffered são as que o helper
+	// escreve direto no buffer (números, floats e %q)
+	conversions, buffered := 0, 0
+	for _, part := range parts {
+		switch {
+		case part.arg < 0 || part.kind == "bool" || part.kind == "string" && part.verb != 'q':
+		case part.kind == "bytes" && part.verb != 'q':
+			conversions++
+		default:
+			conversions++
+			buffered++
+		}
+	}
+	var repl ast.Expr
+	var how string
+	var after int
+	if buffered >= 2 {
+		h := r.pass.helper(parts)
+		callArgs := make([]ast.Expr, 0, len(args))
+		for _, part := range parts {
+			if part.arg >= 0 {
+				callArgs = append(callArgs, r.param(part, args[part.arg]))
+			}
+		}
+		repl = &ast.CallExpr{Fun: ast.NewIdent(h.name), Args: callArgs}
+		how, after = h.name+" (strings.Builder)", 1
+	} else {
+		repl = r.concat(parts, args, c.Parent())
+		after = conversions
+		if len(parts) > 1 {
+			after++
+			how = "concatenation"
+		} else {
+			how = "strconv"
+		}
+	}
+	r.replace(c, call, repl)
+	r.count++
+
+	r.ctx.RegisterFmtRewrite(astutil.FmtRewrite{
+		File: pos.Filename, Line: pos.Line, Call: name, Replacement: how,
+		AllocsBefore: before, AllocsAfter: after,
+	})
+	r.ctx.RecordLedger(r.pass.Name(), pos, name, "rewritten",
+		fmt.Sprintf("%s (~%d → %d allocations)", how, before, after))
+	return true
+}
+
+// replace swaps call for repl and gives repl the call's type: the walk is
+// post-order, so an enclosing fmt call is planned after its arguments were
+// rewritten and needs their types, as in fmt.Sprintf("[%s]", fmt.Sprint(n))
+func (r *fmtRewriter) replace(c *stdastutil.Cursor, call *ast.CallExpr, repl ast.Expr) {
+	if tv, ok := r.ctx.GetTypes()[call]; ok {
+		if _, typed := r.ctx.GetTypes()[repl]; !typed {
+			r.ctx.GetTypes()[repl] = tv
+		}
+	}
+	c.Replace(repl)
+}
+
+// concat joins the formatted parts with +, parenthesized unless parent
+// already delimits the expression
+func (r *fmtRewriter) concat(parts []fmtPart, args []ast.Expr, parent ast.Node) ast.Expr {
+	var operands []ast.Expr
+	for _, part := range parts {
+		if part.arg < 0 {
+			operands = append(operands, &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(part.lit)})
+			continue
+		}
+		operands = append(operands, r.format(part, args[part.arg]))
+	}
+	e := operands[0]
+	for _, op := range operands[1:] {
+		e = &ast.BinaryExpr{X: e, Op: token.ADD, Y: op}
+	}
+	if len(operands) > 1 {
+		switch parent.(type) {
+		case *ast.AssignStmt, *ast.ReturnStmt, *ast.ValueSpec, *ast.CallExpr, *ast.CompositeLit,
+			*ast.KeyValueExpr, *ast.SendStmt, *ast.ExprStmt, *ast.CaseClause, *ast.BinaryExpr:
+		default:
+			e = &ast.ParenExpr{X: e}
+		}
+	}
+	return e
+}
+
+// convert returns T(e), or e when its type already is T (or is untyped)
+func (r *fmtRewriter) convert(e ast.Expr, to types.Type) ast.Expr {
+	t := r.ctx.GetTypes()[e].Type
+	if b, ok := t.(*types.Basic); types.Identical(t, to) || ok && b.Info()&types.IsUntyped != 0 {
+		return e
+	}
+	return &ast.CallExpr{Fun: ast.NewIdent(types.TypeString(to, nil)), Args: []ast.Expr{e}}
+}
+
+func (r *fmtRewriter) strconvCall(name string, args ...ast.Expr) ast.Expr {
+	if r.strconvName == "" {
+		r.strconvName = importName(r.fset, r.file, "strconv")
+	}
+	return &ast.CallExpr{Fun: &ast.SelectorExpr{X: ast.NewIdent(r.strconvName), Sel: ast.NewIdent(name)}, Args: args}
+}
+
+// format renders one argument as a string expression
+func (r *fmtRewriter) format(part fmtPart, e ast.Expr) ast.Expr {
+	ten := &ast.BasicLit{Kind: token.INT, Value: "10"}
+	switch part.kind {
+	case "string", "bytes":
+		s := r.convert(e, types.Typ[types.String])
+		if part.kind == "bytes" {
+			s = &ast.CallExpr{Fun: ast.NewIdent("string"), Args: []ast.Expr{e}}
+		}
+		if part.verb == 'q' {
+			return r.strconvCall("Quote", s)
+		}
+		return s
+	case "int":
+		return r.strconvCall("Itoa", r.convert(e, types.Typ[types.Int]))
+	case "int64":
+		return r.strconvCall("FormatInt", r.convert(e, types.Typ[types.Int64]), ten)
+	case "uint64":
+		return r.strconvCall("FormatUint", r.convert(e, types.Typ[types.Uint64]), ten)
+	case "bool":
+		return r.strconvCall("FormatBool", r.convert(e, types.Typ[types.Bool]))
+	case "error":
+		return &ast.CallExpr{Fun: ast.NewIdent(r.errorFunc), Args: []ast.Expr{e}}
+	}
+	size := "64"
+	if part.kind == "float32" {
+		size = "32"
+	}
+	return r.strconvCall("FormatFloat", r.convert(e, types.Typ[types.Float64]),
+		&ast.BasicLit{Kind: token.CHAR, Value: "'g'"},
+		&ast.UnaryExpr{Op: token.SUB, X: &ast.BasicLit{Kind: token.INT, Value: "1"}},
+		&ast.BasicLit{Kind: token.INT, Value: size})
+}
+
+// param converts an argument to the parameter type of the generated helper
+func (r *fmtRewriter) param(part fmtPart, e ast.Expr) ast.Expr {
+	switch part.kind {
+	case "string":
+		return r.convert(e, types.Typ[types.String])
+	case "int", "int64":
+		return r.convert(e, types.Typ[types.Int64])
+	case "uint64":
+		return r.convert(e, types.Typ[types.Uint64])
+	case "bool":
+		return r.convert(e, types.Typ[types.Bool])
+	case "float32":
+		return r.convert(e, types.Typ[types.Float32])
+	case "float64":
+		return r.convert(e, types.Typ[types.Float64])
+	}
+	return e // []byte é atribuível ao parâmetro
+}
+
+// helper returns the generated formatter for parts, allocating it on first use
+func (p *FmtStrconvPass) helper(parts []fmtPart) *fmtHelper {
+	var key strings.Builder
+	for _, part := range parts {
+		if part.arg < 0 {
+			key.WriteString(strconv.Quote(part.lit))
+		} else {
+			fmt.Fprintf(&key, "%%%c:%s", part.verb, part.kind)
+		}
+	}
+	if h, ok := p.helpers[key.String()]; ok {
+		return h
+	}
+	name := "sprintf"
+	for i := 2; p.used[name] || types.Universe.Lookup(name) != nil; i++ {
+		name = "sprintf" + strconv.Itoa(i)
+	}
+	p.used[name] = true
+	h := &fmtHelper{name: name, parts: parts}
+	p.helpers[key.String()] = h
+	p.order = append(p.order, h)
+	return h
+}
+
+// helperSource renders a helper writing every part to one pre-sized builder
+func (r *fmtRewriter) helperSource(h *fmtHelper) string {
+	sc := r.strconvName
+	if sc == "" {
+		sc = importName(r.fset, r.file, "strconv")
+		r.strconvName = sc
+	}
+	if r.stringsName == "" {
+		r.stringsName = importName(r.fset, r.file, "strings")
+	}
+	paramTypes := map[string]string{
+		"string": "string", "bytes": "[]byte", "int": "int64", "int64": "int64",
+		"uint64": "uint64", "bool": "bool", "float32": "float32", "float64": "float64",
+	}
+	var params, body, format []string
+	grow := 0
+	var lens []string
+	for _, part := range h.parts {
+		if part.arg < 0 {
+			format = append(format, strings.ReplaceAll(part.lit, "%", "%%"))
+			grow += len(part.lit)
+			body = append(body, fmt.Sprintf("\tb.WriteString(%s)", strconv.Quote(part.lit)))
+			continue
+		}
+		format = append(format, "%"+string(part.verb))
+		a := "a" + strconv.Itoa(part.arg)
+		params = append(params, a+" "+paramTypes[part.kind])
+		switch part.kind {
+		case "string", "bytes":
+			lens = append(lens, "len("+a+")")
+			write := "WriteString"
+			if part.kind == "bytes" {
+				write = "Write"
+			}
+			if part.verb == 'q' {
+				grow += 2
+				if part.kind == "bytes" {
+					a = "string(" + a + ")"
+				}
+				body = append(body, fmt.Sprintf("\tb.Write(%s.AppendQuote(buf[:0], %s))", sc, a))
+			} else {
+				body = append(body, fmt.Sprintf("\tb.%s(%s)", write, a))
+			}
+		case "int", "int64":
+			grow += 20
+			body = append(body, fmt.Sprintf("\tb.Write(%s.AppendInt(buf[:0], %s, 10))", sc, a))
+		case "uint64":
+			grow += 20
+			body = append(body, fmt.Sprintf("\tb.Write(%s.AppendUint(buf[:0], %s, 10))", sc, a))
+		case "bool":
+			grow += 5
+			body = append(body, fmt.Sprintf("\tb.WriteString(%s.FormatBool(%s))", sc, a))
+		case "float32":
+			grow += 24
+			body = append(body, fmt.Sprintf("\tb.Write(%s.AppendFloat(buf[:0], float64(%s), 'g', -1, 32))", sc, a))
+		case "float64":
+			grow += 24
+			body = append(body, fmt.Sprintf("\tb.Write(%s.AppendFloat(buf[:0], %s, 'g', -1, 64))", sc, a))
+		}
+	}
+	lens = append(lens, strconv.Itoa(grow))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s formats %s like fmt.Sprintf, with a single allocation\n", h.name, strconv.Quote(strings.Join(format, "")))
+	fmt.Fprintf(&b, "func %s(%s) string {\n", h.name, strings.Join(params, ", "))
+	fmt.Fprintf(&b, "\tvar b %s.Builder\n\tvar buf [32]byte\n\tb.Grow(%s)\n", r.stringsName, strings.Join(lens, " + "))
+	b.WriteString(strings.Join(body, "\n"))
+	b.WriteString("\n\treturn b.String()\n}\n")
+	return b.String()
+}
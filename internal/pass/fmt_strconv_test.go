@@ -0,0 +1,46 @@
+package pass
+
+import (
+	"strings"
+	"testing"
+)
+
+const fmtStrconvProbe = `package main
+
+import "fmt"
+
+type Level int
+
+func (l Level) String() string { return "level" }
+
+type ID int64
+
+func main() {
+	n, id, ok, f := 42, ID(-7), true, 1.5
+	name := "gas"
+	fmt.Println(fmt.Sprintf("%d", n), fmt.Sprint(name), fmt.Sprintf("%s:%s", name, name))
+	fmt.Println(fmt.Sprintf("%s=%d/%d %t %v %q", name, n, id, ok, f, name))
+	fmt.Println(fmt.Sprintf("[%s]", fmt.Sprint(n)), fmt.Sprintf("<%s>", fmt.Sprintf("%d-%d", n, id)))
+	fmt.Println(fmt.Sprint(n, id, name, ok), fmt.Sprint())
+
+	// Ficam: Stringer, verbo com flag, formato dinâmico
+	format := "%d"
+	fmt.Println(fmt.Sprintf("%v", Level(1)), fmt.Sprintf("%5d", n), fmt.Sprintf(format, n))
+}
+`
+
+func TestFmtStrconvPreservesBehavior(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, fmtStrconvProbe)
+	out, ctx := transpileSource(t, fmtStrconvProbe, false, NewFmtStrconvPass())
+	if got := countLedger(ctx, "FmtStrconv", "skipped"); got != 3 {
+		t.Errorf("%d calls skipped, want 3 (Stringer, flag, dynamic format)\n%+v", got, ctx.Ledger)
+	}
+	// As chamadas internas já reescritas não podem derrubar as externas
+	if strings.Contains(out, `fmt.Sprint(n)`) || strings.Contains(out, `fmt.Sprintf("<%s>"`) {
+		t.Errorf("nested calls left in place\n%s", out)
+	}
+	if got := runSource(t, out); got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+}
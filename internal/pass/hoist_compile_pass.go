@@ -0,0 +1,621 @@
+package pass
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	htmltemplate "html/template"
+	"regexp"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	stdastutil "golang.org/x/tools/go/ast/astutil"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// HoistCompilePass tira de dentro das funções os construtores caros com
+// argumentos constantes, que hoje recompilam a cada chamada:
+//
+//	func validEmail(s string) bool {              func validEmail(s string) bool {
+//		re := regexp.MustCompile(`^[^@]+@[^@]+$`)  →     re := validEmailRegexp
+//		return re.MatchString(s)                      return re.MatchString(s)
+//	}                                             }
+//
+//	                                              var validEmailRegexp = regexp.MustCompile(`^[^@]+@[^@]+$`)
+//
+// Entram regexp.MustCompile/MustCompilePOSIX, strings.NewReplacer e
+// template.Must(template.New(c).Parse(c)) de text/template e html/template.
+// Os argumentos são validados aqui (regexp inválida, template que não parseia
+// ou NewReplacer ímpar entrariam em pânico na inicialização em vez de na
+// chamada) e o valor só pode ser usado como receptor de métodos que não o
+// alteram: Longest, Funcs, Parse e afins mudariam o valor compartilhado.
+//
+// A var vai para o arquivo da chamada (build tags continuam valendo) e a
+// análise de dependências do Go a inicializa antes de qualquer var que
+// alcance a função, qualquer que seja a posição da declaração. Quando a
+// inicialização do pacote chama código que essa análise não vê (interfaces,
+// valores func, goroutines, valores do pacote entregues a outros pacotes), o
+// valor vira lazy atrás de um sync.Once. Os nomes vêm da função envolvente e
+// os objetos são registrados no type info: Rename os renomeia e
+// StringObfuscate cifra os literais movidos.
+type HoistCompilePass struct {
+	lazy bool
+	used map[string]bool
+}
+
+// hoistKind is a constructor the pass knows how to hoist
+type hoistKind struct {
+	name    string // sufixo do nome gerado
+	typ     string // tipo do resultado no pacote do construtor
+	allowed func(method string) bool
+}
+
+var (
+	hoistRegexp   = &hoistKind{name: "Regexp", typ: "Regexp", allowed: func(m string) bool { return m != "Longest" }}
+	hoistReplacer = &hoistKind{name: "Replacer", typ: "Replacer", allowed: func(string) bool { return true }}
+	hoistTemplate = &hoistKind{name: "Template", typ: "Template", allowed: func(m string) bool {
+		return m == "Execute" || m == "ExecuteTemplate" || m == "Name" || m == "DefinedTemplates"
+	}}
+)
+
+func NewHoistCompilePass() *HoistCompilePass { return &HoistCompilePass{} }
+func (p *HoistCompilePass) Name() string     { return "HoistCompile" }
+
+// Prepare decides between eager and lazy initialization for the package
+func (p *HoistCompilePass) Prepare(files []*ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	p.used = make(map[string]bool)
+	for _, file := range files {
+		collectIdentNames(file, p.used)
+	}
+	p.lazy = dynamicInit(files, ctx)
+	if p.lazy {
+		ctx.LogVerbose(fset, "🏗️ HoistCompilePass: package initialization calls code dynamically, hoisted values are lazy")
+	}
+	return nil
+}
+
+// dynamicInit reports whether the package-level var initializers can run
+// code of the package that Go's initialization order does not account for:
+// calls through interfaces or func values, goroutines, and package values
+// handed to other packages (which may call their methods)
+func dynamicInit(files []*ast.File, ctx *astutil.TranspileContext) bool {
+	funcs := make(map[*types.Func]*ast.FuncDecl)
+	var queue []ast.Node
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if fn, ok := ctx.GetDefs()[d.Name].(*types.Func); ok {
+					funcs[fn] = d
+				}
+			case *ast.GenDecl:
+				if d.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range d.Specs {
+					for _, v := range spec.(*ast.ValueSpec).Values {
+						queue = append(queue, v)
+					}
+				}
+			}
+		}
+	}
+
+	seen := make(map[*types.Func]bool)
+	dynamic := false
+	for len(queue) > 0 && !dynamic {
+		n := queue[0]
+		queue = queue[1:]
+		ast.Inspect(n, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.GoStmt:
+				dynamic = true
+			case *ast.Ident:
+				// Referências a funções e métodos do pacote, chamadas ou não
+				if fn, ok := ctx.GetUses()[n].(*types.Func); ok && fn.Pkg() == ctx.Package && !seen[fn] {
+					seen[fn] = true
+					if d := funcs[fn]; d != nil && d.Body != nil {
+						queue = append(queue, d.Body)
+					}
+				}
+			case *ast.CallExpr:
+				dynamic = !staticCall(n, ctx)
+			}
+			return !dynamic
+		})
+	}
+	return dynamic
+}
+
+// staticCall reports whether call runs only code that is either outside the
+// package or visible to the initialization order
+func staticCall(call *ast.CallExpr, ctx *astutil.TranspileContext) bool {
+	if tv, ok := ctx.GetTypes()[call.Fun]; ok && (tv.IsType() || tv.IsBuiltin()) {
+		return true
+	}
+	var fn *types.Func
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.FuncLit:
+		return true // o corpo é visitado junto com a expressão
+	case *ast.Ident:
+		fn, _ = ctx.GetUses()[fun].(*types.Func)
+	case *ast.SelectorExpr:
+		if sel := ctx.GetSelections()[fun]; sel != nil && types.IsInterface(sel.Recv()) {
+			return false
+		}
+		fn, _ = ctx.GetUses()[fun.Sel].(*types.Func)
+	}
+	if fn == nil {
+		return false // valor func
+	}
+	if fn.Pkg() == ctx.Package {
+		return true
+	}
+	for _, arg := range call.Args {
+		if carriesPackageCode(ctx.GetTypes()[arg].Type, ctx.Package) {
+			return false
+		}
+	}
+	return true
+}
+
+// carriesPackageCode reports whether a value of type t handed to another
+// package may let it call code of pkg
+func carriesPackageCode(t types.Type, pkg *types.Package) bool {
+	if t == nil {
+		return false
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Interface, *types.Signature:
+		return true
+	case *types.Pointer:
+		t = u.Elem()
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() != pkg {
+		return false
+	}
+	return types.NewMethodSet(types.NewPointer(named)).Len() > 0
+}
+
+func (p *HoistCompilePass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	h := &hoister{pass: p, file: file, fset: fset, ctx: ctx, shared: make(map[string]*hoisted)}
+	stdastutil.Apply(file, h.pre, h.post)
+	if len(h.order) == 0 {
+		return nil
+	}
+
+	// === Declarações no fim do arquivo da chamada ===
+	for _, hv := range h.order {
+		decls, err := h.declare(hv)
+		if err != nil {
+			return fmt.Errorf("HoistCompile: %w", err)
+		}
+		file.Decls = append(file.Decls, decls...)
+	}
+	ctx.LogVerbose(fset, "🏗️ HoistCompilePass: %d constructors hoisted into %d package vars", h.count, len(h.order))
+	return nil
+}
+
+// hoisted is one package-level value and the objects generated for it
+type hoisted struct {
+	kind   *hoistKind
+	name   string
+	pkg    string // nome do import do construtor neste arquivo
+	value  ast.Expr
+	result types.Type
+	obj    types.Object // a var, ou a função de acesso quando lazy
+	lazy   bool
+}
+
+// hoister rewrites the constructor calls of one file
+type hoister struct {
+	pass   *HoistCompilePass
+	file   *ast.File
+	fset   *token.FileSet
+	ctx    *astutil.TranspileContext
+	stack  []ast.Node
+	funcs  []string // nome das funções envolventes ("" para FuncLit)
+	bodies []*ast.BlockStmt
+	shared map[string]*hoisted // construtor + argumentos → valor já içado
+	order  []*hoisted
+	count  int
+}
+
+func (h *hoister) pre(c *stdastutil.Cursor) bool {
+	h.stack = append(h.stack, c.Node())
+	switch n := c.Node().(type) {
+	case *ast.FuncDecl:
+		name := n.Name.Name
+		if n.Recv != nil && len(n.Recv.List) == 1 {
+			if recv := derefNamed(h.ctx.GetTypes()[n.Recv.List[0].Type].Type); recv != nil {
+				name = recv.Obj().Name() + strings.ToUpper(name[:1]) + name[1:]
+			}
+		}
+		h.funcs = append(h.funcs, name)
+		h.bodies = append(h.bodies, n.Body)
+	case *ast.FuncLit:
+		h.funcs = append(h.funcs, "")
+		h.bodies = append(h.bodies, n.Body)
+	}
+	return true
+}
+
+func (h *hoister) post(c *stdastutil.Cursor) bool {
+	h.stack = h.stack[:len(h.stack)-1]
+	switch n := c.Node().(type) {
+	case *ast.FuncDecl, *ast.FuncLit:
+		h.funcs = h.funcs[:len(h.funcs)-1]
+		h.bodies = h.bodies[:len(h.bodies)-1]
+	case *ast.CallExpr:
+		if len(h.bodies) > 0 && h.bodies[len(h.bodies)-1] != nil {
+			h.rewrite(c, n)
+		}
+	}
+	return true
+}
+
+// rewrite replaces an accepted constructor call by the hoisted value
+func (h *hoister) rewrite(c *stdastutil.Cursor, call *ast.CallExpr) {
+	kind, pkgName, args, reason := h.match(call)
+	if kind == nil {
+		return
+	}
+	pos := h.fset.Position(call.Pos())
+	target := types.ExprString(call.Fun)
+	if reason == "" {
+		reason = h.usage(c, kind)
+	}
+	if reason != "" {
+		gl.Log("info", fmt.Sprintf("HoistCompile: skipping %s at %s (%s)", target, pos, reason))
+		h.ctx.RecordLedger(h.pass.Name(), pos, target, "skipped", reason)
+		return
+	}
+
+	key := kind.name
+	for _, a := range args {
+		key += "\x00" + constant.StringVal(h.ctx.GetTypes()[a].Value)
+	}
+	hv := h.shared[key]
+	detail := ""
+	if hv == nil {
+		hv = h.hoist(kind, pkgName, call)
+		h.shared[key] = hv
+		h.order = append(h.order, hv)
+		detail = "hoisted into " + hv.name
+	} else {
+		detail = "shares " + hv.name
+	}
+	if hv.lazy {
+		detail += " (lazy)"
+	}
+
+	// A chamada sai da árvore: seus usos não contam mais (StringObfuscate
+	// transformaria uma const local agora sem uso em var)
+	ast.Inspect(call, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			delete(h.ctx.GetUses(), id)
+		}
+		return true
+	})
+	id := ast.NewIdent(hv.name)
+	h.ctx.GetUses()[id] = hv.obj
+	if hv.lazy {
+		c.Replace(&ast.CallExpr{Fun: id})
+	} else {
+		c.Replace(id)
+	}
+	h.count++
+	h.ctx.RecordLedger(h.pass.Name(), pos, target, "rewritten", detail)
+}
+
+// match recognizes the hoistable constructors and returns their constant
+// arguments; reason is set when a recognized call has to stay
+func (h *hoister) match(call *ast.CallExpr) (kind *hoistKind, pkgName string, args []ast.Expr, reason string) {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return nil, "", nil, ""
+	}
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return nil, "", nil, ""
+	}
+	pkgName = x.Name
+	constStrings := func(es []ast.Expr) bool {
+		for _, e := range es {
+			tv := h.ctx.GetTypes()[e]
+			if tv.Value == nil || tv.Value.Kind() != constant.String {
+				return false
+			}
+		}
+		return true
+	}
+	str := func(e ast.Expr) string { return constant.StringVal(h.ctx.GetTypes()[e].Value) }
+
+	switch {
+	case isPackageFunc(call, "regexp", "MustCompile", h.ctx), isPackageFunc(call, "regexp", "MustCompilePOSIX", h.ctx):
+		args = call.Args
+		if len(args) != 1 || !constStrings(args) {
+			return nil, "", nil, ""
+		}
+		compile := regexp.Compile
+		if sel.Sel.Name == "MustCompilePOSIX" {
+			compile = regexp.CompilePOSIX
+		}
+		if _, err := compile(str(args[0])); err != nil {
+			reason = "pattern does not compile: " + err.Error()
+		}
+		return hoistRegexp, pkgName, args, reason
+
+	case isPackageFunc(call, "strings", "NewReplacer", h.ctx):
+		args = call.Args
+		if call.Ellipsis.IsValid() || !constStrings(args) {
+			return nil, "", nil, ""
+		}
+		if len(args)%2 == 1 {
+			reason = "odd argument count panics"
+		}
+		return hoistReplacer, pkgName, args, reason
+
+	case isPackageFunc(call, "text/template", "Must", h.ctx), isPackageFunc(call, "html/template", "Must", h.ctx):
+		if len(call.Args) != 1 {
+			return nil, "", nil, ""
+		}
+		parse, ok := ast.Unparen(call.Args[0]).(*ast.CallExpr)
+		if !ok || len(parse.Args) != 1 {
+			return nil, "", nil, ""
+		}
+		psel, ok := ast.Unparen(parse.Fun).(*ast.SelectorExpr)
+		if !ok || psel.Sel.Name != "Parse" {
+			return nil, "", nil, ""
+		}
+		newCall, ok := ast.Unparen(psel.X).(*ast.CallExpr)
+		path := h.ctx.GetUses()[sel.Sel].Pkg().Path()
+		if !ok || !isPackageFunc(newCall, path, "New", h.ctx) || len(newCall.Args) != 1 {
+			return nil, "", nil, ""
+		}
+		args = []ast.Expr{newCall.Args[0], parse.Args[0]}
+		if !constStrings(args) {
+			return nil, "", nil, ""
+		}
+		var err error
+		if path == "html/template" {
+			_, err = htmltemplate.New(str(args[0])).Parse(str(args[1]))
+		} else {
+			_, err = texttemplate.New(str(args[0])).Parse(str(args[1]))
+		}
+		if err != nil {
+			reason = "template does not parse: " + err.Error()
+		}
+		return hoistTemplate, pkgName, args, reason
+	}
+	return nil, "", nil, ""
+}
+
+// usage checks that the value is only used as the receiver of methods that
+// leave it unchanged, directly or through a local variable
+func (h *hoister) usage(c *stdastutil.Cursor, kind *hoistKind) string {
+	// Em post a pilha já não tem a chamada: o topo é o pai, antes dele o avô
+	switch p := c.Parent().(type) {
+	case *ast.SelectorExpr:
+		if len(h.stack) >= 2 {
+			if call, ok := h.stack[len(h.stack)-2].(*ast.CallExpr); ok && call.Fun == p {
+				if !kind.allowed(p.Sel.Name) {
+					return p.Sel.Name + " changes the shared value"
+				}
+				return ""
+			}
+		}
+		return "method value escapes"
+	case *ast.AssignStmt:
+		if p.Tok == token.DEFINE && len(p.Lhs) == 1 && len(p.Rhs) == 1 {
+			if v, ok := h.ctx.GetDefs()[p.Lhs[0].(*ast.Ident)].(*types.Var); ok {
+				return h.localUses(v, kind)
+			}
+		}
+	case *ast.ValueSpec:
+		if len(p.Names) == 1 && len(p.Values) == 1 {
+			if v, ok := h.ctx.GetDefs()[p.Names[0]].(*types.Var); ok {
+				return h.localUses(v, kind)
+			}
+		}
+	}
+	return "value escapes the function"
+}
+
+// localUses checks every use of a local variable holding the value
+func (h *hoister) localUses(v *types.Var, kind *hoistKind) string {
+	reason := ""
+	var stack []ast.Node
+	ast.Inspect(h.bodies[len(h.bodies)-1], func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if id, ok := n.(*ast.Ident); ok && h.ctx.GetUses()[id] == v && reason == "" {
+			reason = "value escapes through " + v.Name()
+			if len(stack) >= 2 {
+				sel, ok := stack[len(stack)-1].(*ast.SelectorExpr)
+				call, isCall := stack[len(stack)-2].(*ast.CallExpr)
+				if ok && isCall && sel.X == id && call.Fun == sel {
+					reason = ""
+					if !kind.allowed(sel.Sel.Name) {
+						reason = sel.Sel.Name + " changes the shared value"
+					}
+				}
+			}
+		}
+		stack = append(stack, n)
+		return true
+	})
+	return reason
+}
+
+// hoist allocates the names and objects of a new package-level value
+func (h *hoister) hoist(kind *hoistKind, pkgName string, call *ast.CallExpr) *hoisted {
+	base := "hoisted"
+	for i := len(h.funcs) - 1; i >= 0; i-- {
+		if h.funcs[i] != "" {
+			base = strings.ToLower(h.funcs[i][:1]) + h.funcs[i][1:]
+			break
+		}
+	}
+	base += kind.name
+	lazy := h.pass.lazy
+	free := func(name string) bool {
+		return !h.pass.used[name] && types.Universe.Lookup(name) == nil && h.ctx.Package.Scope().Lookup(name) == nil
+	}
+	name := base
+	for i := 2; !free(name) || lazy && (!free(name+"Once") || !free(name+"Value")); i++ {
+		name = base + strconv.Itoa(i)
+	}
+	h.pass.used[name] = true
+	if lazy {
+		h.pass.used[name+"Once"], h.pass.used[name+"Value"] = true, true
+	}
+
+	hv := &hoisted{kind: kind, name: name, pkg: pkgName, value: h.detach(call), result: h.ctx.GetTypes()[call].Type, lazy: lazy}
+	if lazy {
+		res := types.NewTuple(types.NewVar(token.NoPos, h.ctx.Package, "", hv.result))
+		hv.obj = types.NewFunc(token.NoPos, h.ctx.Package, name, types.NewSignatureType(nil, nil, nil, nil, res, false))
+	} else {
+		hv.obj = types.NewVar(token.NoPos, h.ctx.Package, name, hv.result)
+	}
+	h.ctx.Package.Scope().Insert(hv.obj)
+	return hv
+}
+
+// detach copies the constructor call without positions, so the printer does
+// not pull the comments around its old place into the new declaration.
+// Arguments that name local constants become literals
+func (h *hoister) detach(e ast.Expr) ast.Expr {
+	var out ast.Expr
+	switch n := e.(type) {
+	case *ast.ParenExpr:
+		return h.detach(n.X)
+	case *ast.CallExpr:
+		call := &ast.CallExpr{Fun: h.detach(n.Fun)}
+		for _, a := range n.Args {
+			call.Args = append(call.Args, h.detach(a))
+		}
+		out = call
+	case *ast.SelectorExpr:
+		if tv, ok := h.ctx.GetTypes()[n]; ok && tv.Value != nil {
+			return h.literal(n)
+		}
+		sel := &ast.SelectorExpr{X: h.detach(n.X), Sel: h.detach(n.Sel).(*ast.Ident)}
+		if s := h.ctx.GetSelections()[n]; s != nil {
+			h.ctx.GetSelections()[sel] = s
+		}
+		out = sel
+	case *ast.Ident:
+		obj := h.ctx.GetUses()[n]
+		if c, ok := obj.(*types.Const); ok && c.Parent() != h.ctx.Package.Scope() {
+			return h.literal(n)
+		}
+		id := ast.NewIdent(n.Name)
+		if obj != nil {
+			h.ctx.GetUses()[id] = obj
+		}
+		out = id
+	case *ast.BasicLit:
+		out = &ast.BasicLit{Kind: n.Kind, Value: n.Value}
+	default:
+		return h.literal(e) // expressão constante
+	}
+	if tv, ok := h.ctx.GetTypes()[e]; ok {
+		h.ctx.GetTypes()[out] = tv
+	}
+	return out
+}
+
+// literal renders a constant string expression, raw when it reads better
+func (h *hoister) literal(e ast.Expr) ast.Expr {
+	tv := h.ctx.GetTypes()[e]
+	s := constant.StringVal(tv.Value)
+	value := strconv.Quote(s)
+	if strings.Contains(s, `\`) && strconv.CanBackquote(s) {
+		value = "`" + s + "`"
+	}
+	lit := &ast.BasicLit{Kind: token.STRING, Value: value}
+	h.ctx.GetTypes()[lit] = tv
+	return lit
+}
+
+// declare renders the package-level declarations of a hoisted value
+func (h *hoister) declare(hv *hoisted) ([]ast.Decl, error) {
+	if !hv.lazy {
+		id := ast.NewIdent(hv.name)
+		h.ctx.GetDefs()[id] = hv.obj
+		return []ast.Decl{&ast.GenDecl{Tok: token.VAR, Specs: []ast.Spec{&ast.ValueSpec{
+			Names: []*ast.Ident{id}, Values: []ast.Expr{hv.value},
+		}}}}, nil
+	}
+
+	syncName := importName(h.fset, h.file, "sync")
+	typ := "*" + hv.pkg + "." + hv.kind.typ
+	src := fmt.Sprintf(`var (
+	%[1]sOnce  %[2]s.Once
+	%[1]sValue %[3]s
+)
+
+func %[1]s() %[3]s {
+	%[1]sOnce.Do(func() { %[1]sValue = nil })
+	return %[1]sValue
+}
+`, hv.name, syncName, typ)
+	decls, err := astutil.ParseDecls(h.fset, src)
+	if err != nil {
+		return nil, err
+	}
+
+	once := types.NewVar(token.NoPos, h.ctx.Package, hv.name+"Once", syncOnceType(h.ctx))
+	value := types.NewVar(token.NoPos, h.ctx.Package, hv.name+"Value", hv.result)
+	h.ctx.Package.Scope().Insert(once)
+	h.ctx.Package.Scope().Insert(value)
+	objs := map[string]types.Object{hv.name: hv.obj, once.Name(): once, value.Name(): value}
+	for _, d := range decls {
+		defining := make(map[*ast.Ident]bool)
+		switch d := d.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				for _, id := range spec.(*ast.ValueSpec).Names {
+					defining[id] = true
+				}
+			}
+		case *ast.FuncDecl:
+			defining[d.Name] = true
+		}
+		stdastutil.Apply(d, func(c *stdastutil.Cursor) bool {
+			if id, ok := c.Node().(*ast.Ident); ok && id.Name == "nil" {
+				c.Replace(hv.value) // o placeholder da atribuição
+			} else if ok && objs[id.Name] != nil {
+				if defining[id] {
+					h.ctx.GetDefs()[id] = objs[id.Name]
+				} else {
+					h.ctx.GetUses()[id] = objs[id.Name]
+				}
+			}
+			return true
+		}, nil)
+	}
+	return decls, nil
+}
+
+// syncOnceType returns sync.Once when the package imports sync
+func syncOnceType(ctx *astutil.TranspileContext) types.Type {
+	for _, imp := range ctx.Package.Imports() {
+		if imp.Path() == "sync" {
+			if obj := imp.Scope().Lookup("Once"); obj != nil {
+				return obj.Type()
+			}
+		}
+	}
+	return types.Typ[types.Invalid]
+}
@@ -0,0 +1,81 @@
+package pass
+
+import (
+	"strings"
+	"testing"
+)
+
+const hoistCompileProbe = `package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+func validEmail(s string) bool {
+	re := regexp.MustCompile(` + "`^[^@]+@[^@]+$`" + `)
+	return re.MatchString(s)
+}
+
+func slug(s string) string {
+	return strings.NewReplacer(" ", "-", "_", "-").Replace(strings.ToLower(s))
+}
+
+func greet(name string) {
+	t := template.Must(template.New("greet").Parse("hi {{.}}\n"))
+	t.Execute(os.Stdout, name)
+}
+
+// Longest muda a regexp: içada, a chamada com longest=true contaminaria as outras
+func match(s string, longest bool) string {
+	re := regexp.MustCompile("a|ab")
+	if longest {
+		re.Longest()
+	}
+	return re.FindString(s)
+}
+
+// Inválida: içada, entraria em pânico na inicialização
+func broken() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("recovered")
+		}
+	}()
+	regexp.MustCompile("(")
+	return nil
+}
+
+// Argumento que não é constante: nem é candidato
+func dynamic(pattern string) bool {
+	return regexp.MustCompile(pattern).MatchString("abc")
+}
+
+func main() {
+	fmt.Println(validEmail("a@b"), validEmail("nope"), slug("Hello World_x"))
+	greet("gopher")
+	fmt.Println(match("ab", false), match("ab", true), match("ab", false))
+	fmt.Println(broken(), dynamic("b+"))
+}
+`
+
+func TestHoistCompilePreservesBehavior(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, hoistCompileProbe)
+	out, ctx := transpileSource(t, hoistCompileProbe, false, NewHoistCompilePass())
+	if got := countLedger(ctx, "HoistCompile", "rewritten"); got != 3 {
+		t.Errorf("%d constructors hoisted, want 3 (validEmail, slug and greet)\n%+v\n%s", got, ctx.Ledger, out)
+	}
+	if got := countLedger(ctx, "HoistCompile", "skipped"); got != 2 {
+		t.Errorf("%d constructors kept, want 2 (match and broken)\n%+v", got, ctx.Ledger)
+	}
+	if !strings.Contains(out, `re := regexp.MustCompile("a|ab")`) {
+		t.Errorf("regexp used with Longest was hoisted\n%s", out)
+	}
+	if got := runSource(t, out); got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+}
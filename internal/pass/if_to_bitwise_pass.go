@@ -4,66 +4,281 @@ package pass
 import (
 	"go/ast"
 	"go/token"
-	"strings"
+	"go/types"
 
 	"github.com/kubex-ecosystem/gastype/internal/astutil"
+	stdastutil "golang.org/x/tools/go/ast/astutil"
 )
 
-// IfToBitwisePass cfg.Debug  ->  (cfg.flags & FlagConfig_Debug) != 0
+// IfToBitwisePass fuses boolean expressions over flags of the same receiver
+// into single mask comparisons, in if/for conditions, `switch {}` and
+// `switch true {}` cases and return values.
+//
+//	cfg.Debug                      →  cfg.flags&FlagConfig_Debug != 0
+//	cfg.A && !cfg.B                →  cfg.flags&(FlagConfig_A|FlagConfig_B) == FlagConfig_A
+//	cfg.A || cfg.B                 →  cfg.flags&(FlagConfig_A|FlagConfig_B) != 0
+//	cfg.A && (cfg.B || !cfg.C)     →  cfg.flags&FlagConfig_A != 0 && cfg.flags&(FlagConfig_B|FlagConfig_C) != FlagConfig_C
+//
+// A conjunction of flag literals is a cube (`flags&M == V`) and a disjunction
+// is a clause (`flags&M != V`); negation turns one into the other. Operands
+// are only merged when none of them has side effects, so short-circuit
+// evaluation stays unobservable.
 type IfToBitwisePass struct{}
 
 func NewIfToBitwisePass() *IfToBitwisePass { return &IfToBitwisePass{} }
 func (p *IfToBitwisePass) Name() string    { return "IfToBitwise" }
 
-func (p *IfToBitwisePass) Apply(file *ast.File, _ *token.FileSet, ctx *astutil.TranspileContext) error {
-	transformations := 0
+// maskTest is `flags&mask == value` (cube) or `flags&mask != value` (clause)
+type maskTest struct {
+	flags ast.Expr
+	mask  []string
+	value map[string]bool
+	cube  bool
+}
 
-	ast.Inspect(file, func(n ast.Node) bool {
-		ifStmt, ok := n.(*ast.IfStmt)
-		if !ok {
-			return true
+func (t *maskTest) negate() *maskTest {
+	return &maskTest{flags: t.flags, mask: t.mask, value: t.value, cube: !t.cube}
+}
+
+// literal reports whether t tests a single flag, so it is both a cube and a clause
+func (t *maskTest) literal() bool {
+	return len(t.mask) == 1
+}
+
+// merge joins u into t (AND of cubes or OR of clauses). It fails when the
+// same flag appears with opposite polarities.
+func (t *maskTest) merge(u *maskTest, cube bool) (*maskTest, bool) {
+	a, b := t.as(cube), u.as(cube)
+	out := &maskTest{flags: t.flags, mask: append([]string{}, a.mask...), value: map[string]bool{}, cube: cube}
+	for f := range a.value {
+		out.value[f] = true
+	}
+	for _, f := range b.mask {
+		if contains(a.mask, f) {
+			if a.value[f] != b.value[f] {
+				return nil, false
+			}
+			continue
 		}
-		sel, ok := ifStmt.Cond.(*ast.SelectorExpr)
-		if !ok {
-			return true
+		out.mask = append(out.mask, f)
+		if b.value[f] {
+			out.value[f] = true
 		}
+	}
+	return out, true
+}
+
+// as rewrites a single-flag test in cube or clause form
+func (t *maskTest) as(cube bool) *maskTest {
+	if t.cube == cube || !t.literal() {
+		return t
+	}
+	// x&F != 0 (clause, V=∅) ≡ x&F == F (cube, V={F}) e vice-versa
+	f := t.mask[0]
+	return &maskTest{flags: t.flags, mask: t.mask, value: map[string]bool{f: !t.value[f]}, cube: cube}
+}
 
-		// Usa types.Selections: pegamos o campo e o tipo receptor reais
-		selInfo := ctx.GetSelections()[sel]
-		if selInfo == nil || selInfo.Obj() == nil {
-			return true
+// expr renders the test, preferring `x&F != 0` for single positive flags
+func (t *maskTest) expr() ast.Expr {
+	if t.literal() {
+		if t.cube {
+			t = t.as(false)
 		}
+		op := token.NEQ
+		if t.value[t.mask[0]] {
+			op = token.EQL // x&F != F ≡ x&F == 0
+		}
+		return &ast.BinaryExpr{
+			X:  &ast.BinaryExpr{X: t.flags, Op: token.AND, Y: ast.NewIdent(t.mask[0])},
+			Op: op,
+			Y:  &ast.BasicLit{Kind: token.INT, Value: "0"},
+		}
+	}
 
-		fieldName := selInfo.Obj().Name()
-		recv := selInfo.Recv()
-		if recv == nil {
-			return true
+	var value []string
+	for _, f := range t.mask {
+		if t.value[f] {
+			value = append(value, f)
 		}
+	}
+	op := token.NEQ
+	if t.cube {
+		op = token.EQL
+	}
+	mask := orFlags(t.mask)
+	if len(t.mask) > 1 {
+		mask = &ast.ParenExpr{X: mask}
+	}
+	return &ast.BinaryExpr{
+		X:  &ast.BinaryExpr{X: t.flags, Op: token.AND, Y: mask},
+		Op: op,
+		Y:  orFlags(value),
+	}
+}
 
-		typName := strings.TrimPrefix(recv.String(), "*")
+// orFlags builds A | B | C, or 0 for an empty set
+func orFlags(flags []string) ast.Expr {
+	if len(flags) == 0 {
+		return &ast.BasicLit{Kind: token.INT, Value: "0"}
+	}
+	var expr ast.Expr = ast.NewIdent(flags[0])
+	for _, f := range flags[1:] {
+		expr = &ast.BinaryExpr{X: expr, Op: token.OR, Y: ast.NewIdent(f)}
+	}
+	return expr
+}
+
+func (p *IfToBitwisePass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	transformations := 0
 
-		// Agora lookup por TIPO (não por nome de variável)
-		if info, ok := ctx.Structs[typName]; ok {
-			if flagName, ok := info.FlagMapping[fieldName]; ok {
-				ifStmt.Cond = &ast.BinaryExpr{
-					X: &ast.ParenExpr{
-						X: &ast.BinaryExpr{
-							X:  &ast.SelectorExpr{X: sel.X, Sel: ast.NewIdent("flags")},
-							Op: token.AND,
-							Y:  ast.NewIdent(flagName),
-						},
-					},
-					Op: token.NEQ,
-					Y:  &ast.BasicLit{Kind: token.INT, Value: "0"},
+	rewrite := func(e *ast.Expr) {
+		if *e == nil {
+			return
+		}
+		before := types.ExprString(*e)
+		out := p.render(p.normalize(*e, ctx))
+		if types.ExprString(out) != before {
+			*e = out
+			transformations++
+		}
+	}
+
+	stdastutil.Apply(file, func(c *stdastutil.Cursor) bool {
+		switch node := c.Node().(type) {
+		case *ast.IfStmt:
+			rewrite(&node.Cond)
+		case *ast.ForStmt:
+			rewrite(&node.Cond)
+		case *ast.SwitchStmt:
+			if node.Tag != nil {
+				if v, ok := boolConst(node.Tag, ctx); !ok || !v {
+					return true
 				}
-				transformations++
+			}
+			for _, stmt := range node.Body.List {
+				clause := stmt.(*ast.CaseClause)
+				for i := range clause.List {
+					rewrite(&clause.List[i])
+				}
+			}
+		case *ast.ReturnStmt:
+			for i := range node.Results {
+				rewrite(&node.Results[i])
 			}
 		}
 		return true
-	})
+	}, nil)
 
 	if transformations > 0 {
-		ctx.LogVerbose(nil, "⚡ IfToBitwisePass: %d transformations applied", transformations)
+		ctx.LogVerbose(fset, "⚡ IfToBitwisePass: %d transformations applied", transformations)
 	}
 	return nil
 }
+
+// normalized is either a mask test or an expression that could not be reduced
+type normalized struct {
+	test *maskTest
+	expr ast.Expr
+}
+
+func (p *IfToBitwisePass) render(n normalized) ast.Expr {
+	if n.test != nil {
+		return n.test.expr()
+	}
+	return n.expr
+}
+
+func (p *IfToBitwisePass) normalize(e ast.Expr, ctx *astutil.TranspileContext) normalized {
+	switch node := e.(type) {
+	case *ast.ParenExpr:
+		inner := p.normalize(node.X, ctx)
+		if inner.test != nil {
+			return inner
+		}
+		node.X = inner.expr
+		return normalized{expr: node}
+	case *ast.UnaryExpr:
+		if node.Op != token.NOT {
+			return normalized{expr: node}
+		}
+		inner := p.normalize(node.X, ctx)
+		if inner.test != nil {
+			return normalized{test: inner.test.negate()}
+		}
+		node.X = inner.expr
+		return normalized{expr: node}
+	case *ast.BinaryExpr:
+		if node.Op == token.LAND || node.Op == token.LOR {
+			return p.normalizeChain(node, ctx)
+		}
+	}
+
+	if flags, flag, ok := flagTestOf(e, ctx); ok {
+		return normalized{test: &maskTest{flags: flags, mask: []string{flag}, value: map[string]bool{}, cube: false}}
+	}
+	return normalized{expr: e}
+}
+
+// normalizeChain flattens a && b && c (or ||) and merges the tests on the same flags word
+func (p *IfToBitwisePass) normalizeChain(node *ast.BinaryExpr, ctx *astutil.TranspileContext) normalized {
+	op := node.Op
+	cube := op == token.LAND
+	operands := flattenChain(node, op)
+
+	pure := true
+	for _, operand := range operands {
+		if !astutil.IsSideEffectFree(operand, ctx.Info) {
+			pure = false
+		}
+	}
+
+	var parts []normalized
+	groups := make(map[string]int) // flags → índice em parts
+	for _, operand := range operands {
+		n := p.normalize(operand, ctx)
+		if pure && n.test != nil && (n.test.cube == cube || n.test.literal()) {
+			key := types.ExprString(n.test.flags)
+			if i, ok := groups[key]; ok {
+				if merged, ok := parts[i].test.merge(n.test, cube); ok {
+					parts[i].test = merged
+					continue
+				}
+			} else {
+				groups[key] = len(parts)
+			}
+		}
+		parts = append(parts, n)
+	}
+
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	var expr ast.Expr
+	for _, part := range parts {
+		operand := p.render(part)
+		if inner, ok := operand.(*ast.BinaryExpr); ok && inner.Op == token.LOR && op == token.LAND {
+			operand = &ast.ParenExpr{X: operand}
+		}
+		if expr == nil {
+			expr = operand
+			continue
+		}
+		expr = &ast.BinaryExpr{X: expr, Op: op, Y: operand}
+	}
+	return normalized{expr: expr}
+}
+
+// flattenChain returns the operands of a left- or right-nested chain of op
+func flattenChain(e ast.Expr, op token.Token) []ast.Expr {
+	switch node := e.(type) {
+	case *ast.BinaryExpr:
+		if node.Op == op {
+			return append(flattenChain(node.X, op), flattenChain(node.Y, op)...)
+		}
+	case *ast.ParenExpr:
+		if inner, ok := node.X.(*ast.BinaryExpr); ok && inner.Op == op {
+			return flattenChain(inner, op)
+		}
+	}
+	return []ast.Expr{e}
+}
@@ -0,0 +1,1693 @@
+// Code generated by TestBenchFixtures; DO NOT EDIT.
+
+package pass
+
+import "testing"
+
+// Benchmarks behind the jumpTableMinDenseKeys and jumpTableMinMapKeys
+// thresholds: each form is the code JumpTablePass starts from (if-chain,
+// switch) or emits (array or map index feeding a switch over branch numbers).
+//
+//	go test -run '^$' -bench JumpTable ./internal/pass
+
+func jtIfInt4(k int) int {
+	if k == 1 {
+		return 4
+	} else if k == 2 {
+		return 7
+	} else if k == 3 {
+		return 10
+	} else if k == 4 {
+		return 13
+	}
+	return 0
+}
+
+func jtSwitchInt4(k int) int {
+	switch k {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	}
+	return 0
+}
+
+var jtMapInt4 = map[int]int{1: 1, 2: 2, 3: 3, 4: 4}
+
+func jtMapIndexInt4(k int) int {
+	switch jtMapInt4[k] {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	}
+	return 0
+}
+
+var jtArrayInt4 = [4]uint8{1, 2, 3, 4}
+
+func jtArrayIndexInt4(k int) int {
+	var idx int
+	if i := uint64(k - 1); i < uint64(len(jtArrayInt4)) {
+		idx = int(jtArrayInt4[i])
+	}
+	switch idx {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	}
+	return 0
+}
+
+func jtIfInt8(k int) int {
+	if k == 1 {
+		return 4
+	} else if k == 2 {
+		return 7
+	} else if k == 3 {
+		return 10
+	} else if k == 4 {
+		return 13
+	} else if k == 5 {
+		return 16
+	} else if k == 6 {
+		return 19
+	} else if k == 7 {
+		return 22
+	} else if k == 8 {
+		return 25
+	}
+	return 0
+}
+
+func jtSwitchInt8(k int) int {
+	switch k {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	case 5:
+		return 16
+	case 6:
+		return 19
+	case 7:
+		return 22
+	case 8:
+		return 25
+	}
+	return 0
+}
+
+var jtMapInt8 = map[int]int{1: 1, 2: 2, 3: 3, 4: 4, 5: 5, 6: 6, 7: 7, 8: 8}
+
+func jtMapIndexInt8(k int) int {
+	switch jtMapInt8[k] {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	case 5:
+		return 16
+	case 6:
+		return 19
+	case 7:
+		return 22
+	case 8:
+		return 25
+	}
+	return 0
+}
+
+var jtArrayInt8 = [8]uint8{1, 2, 3, 4, 5, 6, 7, 8}
+
+func jtArrayIndexInt8(k int) int {
+	var idx int
+	if i := uint64(k - 1); i < uint64(len(jtArrayInt8)) {
+		idx = int(jtArrayInt8[i])
+	}
+	switch idx {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	case 5:
+		return 16
+	case 6:
+		return 19
+	case 7:
+		return 22
+	case 8:
+		return 25
+	}
+	return 0
+}
+
+func jtIfInt16(k int) int {
+	if k == 1 {
+		return 4
+	} else if k == 2 {
+		return 7
+	} else if k == 3 {
+		return 10
+	} else if k == 4 {
+		return 13
+	} else if k == 5 {
+		return 16
+	} else if k == 6 {
+		return 19
+	} else if k == 7 {
+		return 22
+	} else if k == 8 {
+		return 25
+	} else if k == 9 {
+		return 28
+	} else if k == 10 {
+		return 31
+	} else if k == 11 {
+		return 34
+	} else if k == 12 {
+		return 37
+	} else if k == 13 {
+		return 40
+	} else if k == 14 {
+		return 43
+	} else if k == 15 {
+		return 46
+	} else if k == 16 {
+		return 49
+	}
+	return 0
+}
+
+func jtSwitchInt16(k int) int {
+	switch k {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	case 5:
+		return 16
+	case 6:
+		return 19
+	case 7:
+		return 22
+	case 8:
+		return 25
+	case 9:
+		return 28
+	case 10:
+		return 31
+	case 11:
+		return 34
+	case 12:
+		return 37
+	case 13:
+		return 40
+	case 14:
+		return 43
+	case 15:
+		return 46
+	case 16:
+		return 49
+	}
+	return 0
+}
+
+var jtMapInt16 = map[int]int{1: 1, 2: 2, 3: 3, 4: 4, 5: 5, 6: 6, 7: 7, 8: 8, 9: 9, 10: 10, 11: 11, 12: 12, 13: 13, 14: 14, 15: 15, 16: 16}
+
+func jtMapIndexInt16(k int) int {
+	switch jtMapInt16[k] {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	case 5:
+		return 16
+	case 6:
+		return 19
+	case 7:
+		return 22
+	case 8:
+		return 25
+	case 9:
+		return 28
+	case 10:
+		return 31
+	case 11:
+		return 34
+	case 12:
+		return 37
+	case 13:
+		return 40
+	case 14:
+		return 43
+	case 15:
+		return 46
+	case 16:
+		return 49
+	}
+	return 0
+}
+
+var jtArrayInt16 = [16]uint8{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+
+func jtArrayIndexInt16(k int) int {
+	var idx int
+	if i := uint64(k - 1); i < uint64(len(jtArrayInt16)) {
+		idx = int(jtArrayInt16[i])
+	}
+	switch idx {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	case 5:
+		return 16
+	case 6:
+		return 19
+	case 7:
+		return 22
+	case 8:
+		return 25
+	case 9:
+		return 28
+	case 10:
+		return 31
+	case 11:
+		return 34
+	case 12:
+		return 37
+	case 13:
+		return 40
+	case 14:
+		return 43
+	case 15:
+		return 46
+	case 16:
+		return 49
+	}
+	return 0
+}
+
+func jtIfInt32(k int) int {
+	if k == 1 {
+		return 4
+	} else if k == 2 {
+		return 7
+	} else if k == 3 {
+		return 10
+	} else if k == 4 {
+		return 13
+	} else if k == 5 {
+		return 16
+	} else if k == 6 {
+		return 19
+	} else if k == 7 {
+		return 22
+	} else if k == 8 {
+		return 25
+	} else if k == 9 {
+		return 28
+	} else if k == 10 {
+		return 31
+	} else if k == 11 {
+		return 34
+	} else if k == 12 {
+		return 37
+	} else if k == 13 {
+		return 40
+	} else if k == 14 {
+		return 43
+	} else if k == 15 {
+		return 46
+	} else if k == 16 {
+		return 49
+	} else if k == 17 {
+		return 52
+	} else if k == 18 {
+		return 55
+	} else if k == 19 {
+		return 58
+	} else if k == 20 {
+		return 61
+	} else if k == 21 {
+		return 64
+	} else if k == 22 {
+		return 67
+	} else if k == 23 {
+		return 70
+	} else if k == 24 {
+		return 73
+	} else if k == 25 {
+		return 76
+	} else if k == 26 {
+		return 79
+	} else if k == 27 {
+		return 82
+	} else if k == 28 {
+		return 85
+	} else if k == 29 {
+		return 88
+	} else if k == 30 {
+		return 91
+	} else if k == 31 {
+		return 94
+	} else if k == 32 {
+		return 97
+	}
+	return 0
+}
+
+func jtSwitchInt32(k int) int {
+	switch k {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	case 5:
+		return 16
+	case 6:
+		return 19
+	case 7:
+		return 22
+	case 8:
+		return 25
+	case 9:
+		return 28
+	case 10:
+		return 31
+	case 11:
+		return 34
+	case 12:
+		return 37
+	case 13:
+		return 40
+	case 14:
+		return 43
+	case 15:
+		return 46
+	case 16:
+		return 49
+	case 17:
+		return 52
+	case 18:
+		return 55
+	case 19:
+		return 58
+	case 20:
+		return 61
+	case 21:
+		return 64
+	case 22:
+		return 67
+	case 23:
+		return 70
+	case 24:
+		return 73
+	case 25:
+		return 76
+	case 26:
+		return 79
+	case 27:
+		return 82
+	case 28:
+		return 85
+	case 29:
+		return 88
+	case 30:
+		return 91
+	case 31:
+		return 94
+	case 32:
+		return 97
+	}
+	return 0
+}
+
+var jtMapInt32 = map[int]int{1: 1, 2: 2, 3: 3, 4: 4, 5: 5, 6: 6, 7: 7, 8: 8, 9: 9, 10: 10, 11: 11, 12: 12, 13: 13, 14: 14, 15: 15, 16: 16, 17: 17, 18: 18, 19: 19, 20: 20, 21: 21, 22: 22, 23: 23, 24: 24, 25: 25, 26: 26, 27: 27, 28: 28, 29: 29, 30: 30, 31: 31, 32: 32}
+
+func jtMapIndexInt32(k int) int {
+	switch jtMapInt32[k] {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	case 5:
+		return 16
+	case 6:
+		return 19
+	case 7:
+		return 22
+	case 8:
+		return 25
+	case 9:
+		return 28
+	case 10:
+		return 31
+	case 11:
+		return 34
+	case 12:
+		return 37
+	case 13:
+		return 40
+	case 14:
+		return 43
+	case 15:
+		return 46
+	case 16:
+		return 49
+	case 17:
+		return 52
+	case 18:
+		return 55
+	case 19:
+		return 58
+	case 20:
+		return 61
+	case 21:
+		return 64
+	case 22:
+		return 67
+	case 23:
+		return 70
+	case 24:
+		return 73
+	case 25:
+		return 76
+	case 26:
+		return 79
+	case 27:
+		return 82
+	case 28:
+		return 85
+	case 29:
+		return 88
+	case 30:
+		return 91
+	case 31:
+		return 94
+	case 32:
+		return 97
+	}
+	return 0
+}
+
+var jtArrayInt32 = [32]uint8{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32}
+
+func jtArrayIndexInt32(k int) int {
+	var idx int
+	if i := uint64(k - 1); i < uint64(len(jtArrayInt32)) {
+		idx = int(jtArrayInt32[i])
+	}
+	switch idx {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	case 5:
+		return 16
+	case 6:
+		return 19
+	case 7:
+		return 22
+	case 8:
+		return 25
+	case 9:
+		return 28
+	case 10:
+		return 31
+	case 11:
+		return 34
+	case 12:
+		return 37
+	case 13:
+		return 40
+	case 14:
+		return 43
+	case 15:
+		return 46
+	case 16:
+		return 49
+	case 17:
+		return 52
+	case 18:
+		return 55
+	case 19:
+		return 58
+	case 20:
+		return 61
+	case 21:
+		return 64
+	case 22:
+		return 67
+	case 23:
+		return 70
+	case 24:
+		return 73
+	case 25:
+		return 76
+	case 26:
+		return 79
+	case 27:
+		return 82
+	case 28:
+		return 85
+	case 29:
+		return 88
+	case 30:
+		return 91
+	case 31:
+		return 94
+	case 32:
+		return 97
+	}
+	return 0
+}
+
+var jtStringKeys16 = []string{"ovhggyywy", "bisrwic", "qsujvzrf", "ftyz", "bdv", "lwieurj", "ytjbfkee", "jgwxg", "zwbxdcubmm", "alrrxr", "vipstun", "eqojelhx", "zrmobx", "lobcorcfc", "ollai", "tfgozvos"}
+
+func jtIfString16(k string) int {
+	if k == "ovhggyywy" {
+		return 4
+	} else if k == "bisrwic" {
+		return 7
+	} else if k == "qsujvzrf" {
+		return 10
+	} else if k == "ftyz" {
+		return 13
+	} else if k == "bdv" {
+		return 16
+	} else if k == "lwieurj" {
+		return 19
+	} else if k == "ytjbfkee" {
+		return 22
+	} else if k == "jgwxg" {
+		return 25
+	} else if k == "zwbxdcubmm" {
+		return 28
+	} else if k == "alrrxr" {
+		return 31
+	} else if k == "vipstun" {
+		return 34
+	} else if k == "eqojelhx" {
+		return 37
+	} else if k == "zrmobx" {
+		return 40
+	} else if k == "lobcorcfc" {
+		return 43
+	} else if k == "ollai" {
+		return 46
+	} else if k == "tfgozvos" {
+		return 49
+	}
+	return 0
+}
+
+func jtSwitchString16(k string) int {
+	switch k {
+	case "ovhggyywy":
+		return 4
+	case "bisrwic":
+		return 7
+	case "qsujvzrf":
+		return 10
+	case "ftyz":
+		return 13
+	case "bdv":
+		return 16
+	case "lwieurj":
+		return 19
+	case "ytjbfkee":
+		return 22
+	case "jgwxg":
+		return 25
+	case "zwbxdcubmm":
+		return 28
+	case "alrrxr":
+		return 31
+	case "vipstun":
+		return 34
+	case "eqojelhx":
+		return 37
+	case "zrmobx":
+		return 40
+	case "lobcorcfc":
+		return 43
+	case "ollai":
+		return 46
+	case "tfgozvos":
+		return 49
+	}
+	return 0
+}
+
+var jtMapString16 = map[string]int{"ovhggyywy": 1, "bisrwic": 2, "qsujvzrf": 3, "ftyz": 4, "bdv": 5, "lwieurj": 6, "ytjbfkee": 7, "jgwxg": 8, "zwbxdcubmm": 9, "alrrxr": 10, "vipstun": 11, "eqojelhx": 12, "zrmobx": 13, "lobcorcfc": 14, "ollai": 15, "tfgozvos": 16}
+
+func jtMapIndexString16(k string) int {
+	switch jtMapString16[k] {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	case 5:
+		return 16
+	case 6:
+		return 19
+	case 7:
+		return 22
+	case 8:
+		return 25
+	case 9:
+		return 28
+	case 10:
+		return 31
+	case 11:
+		return 34
+	case 12:
+		return 37
+	case 13:
+		return 40
+	case 14:
+		return 43
+	case 15:
+		return 46
+	case 16:
+		return 49
+	}
+	return 0
+}
+
+var jtStringKeys32 = []string{"rmj", "jljxikpt", "zjrtgmgrii", "kbpere", "jblqlgiz", "faahibfbp", "qjg", "ffdis", "ptfgytbgv", "qqvdgx", "smfn", "uddrvehq", "gspj", "ufsrrwlgvz", "kluteuhg", "zgnsgyalrk", "hfpeatm", "xpbcg", "vdlqxff", "xzch", "ylz", "cttd", "bphtrclhst", "svd", "ylet", "cwwcyutvya", "uvgoyjz", "pxrm", "opv", "bejxg", "cndtp", "frlszye"}
+
+func jtIfString32(k string) int {
+	if k == "rmj" {
+		return 4
+	} else if k == "jljxikpt" {
+		return 7
+	} else if k == "zjrtgmgrii" {
+		return 10
+	} else if k == "kbpere" {
+		return 13
+	} else if k == "jblqlgiz" {
+		return 16
+	} else if k == "faahibfbp" {
+		return 19
+	} else if k == "qjg" {
+		return 22
+	} else if k == "ffdis" {
+		return 25
+	} else if k == "ptfgytbgv" {
+		return 28
+	} else if k == "qqvdgx" {
+		return 31
+	} else if k == "smfn" {
+		return 34
+	} else if k == "uddrvehq" {
+		return 37
+	} else if k == "gspj" {
+		return 40
+	} else if k == "ufsrrwlgvz" {
+		return 43
+	} else if k == "kluteuhg" {
+		return 46
+	} else if k == "zgnsgyalrk" {
+		return 49
+	} else if k == "hfpeatm" {
+		return 52
+	} else if k == "xpbcg" {
+		return 55
+	} else if k == "vdlqxff" {
+		return 58
+	} else if k == "xzch" {
+		return 61
+	} else if k == "ylz" {
+		return 64
+	} else if k == "cttd" {
+		return 67
+	} else if k == "bphtrclhst" {
+		return 70
+	} else if k == "svd" {
+		return 73
+	} else if k == "ylet" {
+		return 76
+	} else if k == "cwwcyutvya" {
+		return 79
+	} else if k == "uvgoyjz" {
+		return 82
+	} else if k == "pxrm" {
+		return 85
+	} else if k == "opv" {
+		return 88
+	} else if k == "bejxg" {
+		return 91
+	} else if k == "cndtp" {
+		return 94
+	} else if k == "frlszye" {
+		return 97
+	}
+	return 0
+}
+
+func jtSwitchString32(k string) int {
+	switch k {
+	case "rmj":
+		return 4
+	case "jljxikpt":
+		return 7
+	case "zjrtgmgrii":
+		return 10
+	case "kbpere":
+		return 13
+	case "jblqlgiz":
+		return 16
+	case "faahibfbp":
+		return 19
+	case "qjg":
+		return 22
+	case "ffdis":
+		return 25
+	case "ptfgytbgv":
+		return 28
+	case "qqvdgx":
+		return 31
+	case "smfn":
+		return 34
+	case "uddrvehq":
+		return 37
+	case "gspj":
+		return 40
+	case "ufsrrwlgvz":
+		return 43
+	case "kluteuhg":
+		return 46
+	case "zgnsgyalrk":
+		return 49
+	case "hfpeatm":
+		return 52
+	case "xpbcg":
+		return 55
+	case "vdlqxff":
+		return 58
+	case "xzch":
+		return 61
+	case "ylz":
+		return 64
+	case "cttd":
+		return 67
+	case "bphtrclhst":
+		return 70
+	case "svd":
+		return 73
+	case "ylet":
+		return 76
+	case "cwwcyutvya":
+		return 79
+	case "uvgoyjz":
+		return 82
+	case "pxrm":
+		return 85
+	case "opv":
+		return 88
+	case "bejxg":
+		return 91
+	case "cndtp":
+		return 94
+	case "frlszye":
+		return 97
+	}
+	return 0
+}
+
+var jtMapString32 = map[string]int{"rmj": 1, "jljxikpt": 2, "zjrtgmgrii": 3, "kbpere": 4, "jblqlgiz": 5, "faahibfbp": 6, "qjg": 7, "ffdis": 8, "ptfgytbgv": 9, "qqvdgx": 10, "smfn": 11, "uddrvehq": 12, "gspj": 13, "ufsrrwlgvz": 14, "kluteuhg": 15, "zgnsgyalrk": 16, "hfpeatm": 17, "xpbcg": 18, "vdlqxff": 19, "xzch": 20, "ylz": 21, "cttd": 22, "bphtrclhst": 23, "svd": 24, "ylet": 25, "cwwcyutvya": 26, "uvgoyjz": 27, "pxrm": 28, "opv": 29, "bejxg": 30, "cndtp": 31, "frlszye": 32}
+
+func jtMapIndexString32(k string) int {
+	switch jtMapString32[k] {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	case 5:
+		return 16
+	case 6:
+		return 19
+	case 7:
+		return 22
+	case 8:
+		return 25
+	case 9:
+		return 28
+	case 10:
+		return 31
+	case 11:
+		return 34
+	case 12:
+		return 37
+	case 13:
+		return 40
+	case 14:
+		return 43
+	case 15:
+		return 46
+	case 16:
+		return 49
+	case 17:
+		return 52
+	case 18:
+		return 55
+	case 19:
+		return 58
+	case 20:
+		return 61
+	case 21:
+		return 64
+	case 22:
+		return 67
+	case 23:
+		return 70
+	case 24:
+		return 73
+	case 25:
+		return 76
+	case 26:
+		return 79
+	case 27:
+		return 82
+	case 28:
+		return 85
+	case 29:
+		return 88
+	case 30:
+		return 91
+	case 31:
+		return 94
+	case 32:
+		return 97
+	}
+	return 0
+}
+
+var jtStringKeys48 = []string{"buorkxazjo", "xvtrbhgyuw", "rhxlypyosh", "ujg", "gra", "vpooenxrzd", "cdchyr", "ycxd", "adnlmn", "rndvcyp", "uhytt", "jjt", "ygskogceop", "jbtgr", "bkiwaqtxxe", "mfv", "iznjetj", "gnh", "xcatsg", "mpv", "gjnrs", "ecml", "ivvkuw", "rkiknz", "vueogtfes", "ggqr", "rsqk", "vzngyqvgjz", "uajerrbsz", "umexgjywf", "lxsfstefe", "hjxsohr", "sydhelzv", "lqwdqbjkh", "dwqpmohmp", "rituphal", "xrrb", "npau", "gnjndsf", "wmta", "lucmcqctsh", "csyw", "lqcqoydque", "jzpqjsurj", "nrotzu", "nthpldy", "ckldjl", "pladj"}
+
+func jtIfString48(k string) int {
+	if k == "buorkxazjo" {
+		return 4
+	} else if k == "xvtrbhgyuw" {
+		return 7
+	} else if k == "rhxlypyosh" {
+		return 10
+	} else if k == "ujg" {
+		return 13
+	} else if k == "gra" {
+		return 16
+	} else if k == "vpooenxrzd" {
+		return 19
+	} else if k == "cdchyr" {
+		return 22
+	} else if k == "ycxd" {
+		return 25
+	} else if k == "adnlmn" {
+		return 28
+	} else if k == "rndvcyp" {
+		return 31
+	} else if k == "uhytt" {
+		return 34
+	} else if k == "jjt" {
+		return 37
+	} else if k == "ygskogceop" {
+		return 40
+	} else if k == "jbtgr" {
+		return 43
+	} else if k == "bkiwaqtxxe" {
+		return 46
+	} else if k == "mfv" {
+		return 49
+	} else if k == "iznjetj" {
+		return 52
+	} else if k == "gnh" {
+		return 55
+	} else if k == "xcatsg" {
+		return 58
+	} else if k == "mpv" {
+		return 61
+	} else if k == "gjnrs" {
+		return 64
+	} else if k == "ecml" {
+		return 67
+	} else if k == "ivvkuw" {
+		return 70
+	} else if k == "rkiknz" {
+		return 73
+	} else if k == "vueogtfes" {
+		return 76
+	} else if k == "ggqr" {
+		return 79
+	} else if k == "rsqk" {
+		return 82
+	} else if k == "vzngyqvgjz" {
+		return 85
+	} else if k == "uajerrbsz" {
+		return 88
+	} else if k == "umexgjywf" {
+		return 91
+	} else if k == "lxsfstefe" {
+		return 94
+	} else if k == "hjxsohr" {
+		return 97
+	} else if k == "sydhelzv" {
+		return 100
+	} else if k == "lqwdqbjkh" {
+		return 103
+	} else if k == "dwqpmohmp" {
+		return 106
+	} else if k == "rituphal" {
+		return 109
+	} else if k == "xrrb" {
+		return 112
+	} else if k == "npau" {
+		return 115
+	} else if k == "gnjndsf" {
+		return 118
+	} else if k == "wmta" {
+		return 121
+	} else if k == "lucmcqctsh" {
+		return 124
+	} else if k == "csyw" {
+		return 127
+	} else if k == "lqcqoydque" {
+		return 130
+	} else if k == "jzpqjsurj" {
+		return 133
+	} else if k == "nrotzu" {
+		return 136
+	} else if k == "nthpldy" {
+		return 139
+	} else if k == "ckldjl" {
+		return 142
+	} else if k == "pladj" {
+		return 145
+	}
+	return 0
+}
+
+func jtSwitchString48(k string) int {
+	switch k {
+	case "buorkxazjo":
+		return 4
+	case "xvtrbhgyuw":
+		return 7
+	case "rhxlypyosh":
+		return 10
+	case "ujg":
+		return 13
+	case "gra":
+		return 16
+	case "vpooenxrzd":
+		return 19
+	case "cdchyr":
+		return 22
+	case "ycxd":
+		return 25
+	case "adnlmn":
+		return 28
+	case "rndvcyp":
+		return 31
+	case "uhytt":
+		return 34
+	case "jjt":
+		return 37
+	case "ygskogceop":
+		return 40
+	case "jbtgr":
+		return 43
+	case "bkiwaqtxxe":
+		return 46
+	case "mfv":
+		return 49
+	case "iznjetj":
+		return 52
+	case "gnh":
+		return 55
+	case "xcatsg":
+		return 58
+	case "mpv":
+		return 61
+	case "gjnrs":
+		return 64
+	case "ecml":
+		return 67
+	case "ivvkuw":
+		return 70
+	case "rkiknz":
+		return 73
+	case "vueogtfes":
+		return 76
+	case "ggqr":
+		return 79
+	case "rsqk":
+		return 82
+	case "vzngyqvgjz":
+		return 85
+	case "uajerrbsz":
+		return 88
+	case "umexgjywf":
+		return 91
+	case "lxsfstefe":
+		return 94
+	case "hjxsohr":
+		return 97
+	case "sydhelzv":
+		return 100
+	case "lqwdqbjkh":
+		return 103
+	case "dwqpmohmp":
+		return 106
+	case "rituphal":
+		return 109
+	case "xrrb":
+		return 112
+	case "npau":
+		return 115
+	case "gnjndsf":
+		return 118
+	case "wmta":
+		return 121
+	case "lucmcqctsh":
+		return 124
+	case "csyw":
+		return 127
+	case "lqcqoydque":
+		return 130
+	case "jzpqjsurj":
+		return 133
+	case "nrotzu":
+		return 136
+	case "nthpldy":
+		return 139
+	case "ckldjl":
+		return 142
+	case "pladj":
+		return 145
+	}
+	return 0
+}
+
+var jtMapString48 = map[string]int{"buorkxazjo": 1, "xvtrbhgyuw": 2, "rhxlypyosh": 3, "ujg": 4, "gra": 5, "vpooenxrzd": 6, "cdchyr": 7, "ycxd": 8, "adnlmn": 9, "rndvcyp": 10, "uhytt": 11, "jjt": 12, "ygskogceop": 13, "jbtgr": 14, "bkiwaqtxxe": 15, "mfv": 16, "iznjetj": 17, "gnh": 18, "xcatsg": 19, "mpv": 20, "gjnrs": 21, "ecml": 22, "ivvkuw": 23, "rkiknz": 24, "vueogtfes": 25, "ggqr": 26, "rsqk": 27, "vzngyqvgjz": 28, "uajerrbsz": 29, "umexgjywf": 30, "lxsfstefe": 31, "hjxsohr": 32, "sydhelzv": 33, "lqwdqbjkh": 34, "dwqpmohmp": 35, "rituphal": 36, "xrrb": 37, "npau": 38, "gnjndsf": 39, "wmta": 40, "lucmcqctsh": 41, "csyw": 42, "lqcqoydque": 43, "jzpqjsurj": 44, "nrotzu": 45, "nthpldy": 46, "ckldjl": 47, "pladj": 48}
+
+func jtMapIndexString48(k string) int {
+	switch jtMapString48[k] {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	case 5:
+		return 16
+	case 6:
+		return 19
+	case 7:
+		return 22
+	case 8:
+		return 25
+	case 9:
+		return 28
+	case 10:
+		return 31
+	case 11:
+		return 34
+	case 12:
+		return 37
+	case 13:
+		return 40
+	case 14:
+		return 43
+	case 15:
+		return 46
+	case 16:
+		return 49
+	case 17:
+		return 52
+	case 18:
+		return 55
+	case 19:
+		return 58
+	case 20:
+		return 61
+	case 21:
+		return 64
+	case 22:
+		return 67
+	case 23:
+		return 70
+	case 24:
+		return 73
+	case 25:
+		return 76
+	case 26:
+		return 79
+	case 27:
+		return 82
+	case 28:
+		return 85
+	case 29:
+		return 88
+	case 30:
+		return 91
+	case 31:
+		return 94
+	case 32:
+		return 97
+	case 33:
+		return 100
+	case 34:
+		return 103
+	case 35:
+		return 106
+	case 36:
+		return 109
+	case 37:
+		return 112
+	case 38:
+		return 115
+	case 39:
+		return 118
+	case 40:
+		return 121
+	case 41:
+		return 124
+	case 42:
+		return 127
+	case 43:
+		return 130
+	case 44:
+		return 133
+	case 45:
+		return 136
+	case 46:
+		return 139
+	case 47:
+		return 142
+	case 48:
+		return 145
+	}
+	return 0
+}
+
+var jtStringKeys64 = []string{"utizkn", "qvyunag", "luthyb", "oqziwxre", "hgtn", "ugysyfb", "cejuqx", "kjhlxctl", "sgciqddqy", "nicdrvar", "caaylb", "zqdaf", "hnnuooq", "vwnxovsec", "ucyqchpaye", "ytkei", "ufumlmsw", "zqqqppw", "dfpspgmkv", "ykbo", "lpbjnqqbi", "kqias", "xjqnm", "ujfotmpfq", "dhoujjh", "bmy", "xmbv", "rid", "dour", "vqt", "vrapccxlcr", "oum", "rdilsycke", "kbqa", "ycoglevzy", "kfzh", "aoxztkf", "cqwwtywpbo", "pxrrjtyzf", "rudp", "qqcb", "krqrjrki", "zrrz", "hlhrblfa", "glpchbapzx", "hepgxkx", "lnceh", "csrly", "hdjwdhg", "ybjyvnkgp", "qym", "ljh", "tulsfsq", "eafwd", "asqpjgffg", "mqnmlwmvwl", "lgp", "dnoqfsac", "pqpnimi", "rtkilwk", "kkoom", "pdvdnlmxcc", "woconw", "viopdiobkj"}
+
+func jtIfString64(k string) int {
+	if k == "utizkn" {
+		return 4
+	} else if k == "qvyunag" {
+		return 7
+	} else if k == "luthyb" {
+		return 10
+	} else if k == "oqziwxre" {
+		return 13
+	} else if k == "hgtn" {
+		return 16
+	} else if k == "ugysyfb" {
+		return 19
+	} else if k == "cejuqx" {
+		return 22
+	} else if k == "kjhlxctl" {
+		return 25
+	} else if k == "sgciqddqy" {
+		return 28
+	} else if k == "nicdrvar" {
+		return 31
+	} else if k == "caaylb" {
+		return 34
+	} else if k == "zqdaf" {
+		return 37
+	} else if k == "hnnuooq" {
+		return 40
+	} else if k == "vwnxovsec" {
+		return 43
+	} else if k == "ucyqchpaye" {
+		return 46
+	} else if k == "ytkei" {
+		return 49
+	} else if k == "ufumlmsw" {
+		return 52
+	} else if k == "zqqqppw" {
+		return 55
+	} else if k == "dfpspgmkv" {
+		return 58
+	} else if k == "ykbo" {
+		return 61
+	} else if k == "lpbjnqqbi" {
+		return 64
+	} else if k == "kqias" {
+		return 67
+	} else if k == "xjqnm" {
+		return 70
+	} else if k == "ujfotmpfq" {
+		return 73
+	} else if k == "dhoujjh" {
+		return 76
+	} else if k == "bmy" {
+		return 79
+	} else if k == "xmbv" {
+		return 82
+	} else if k == "rid" {
+		return 85
+	} else if k == "dour" {
+		return 88
+	} else if k == "vqt" {
+		return 91
+	} else if k == "vrapccxlcr" {
+		return 94
+	} else if k == "oum" {
+		return 97
+	} else if k == "rdilsycke" {
+		return 100
+	} else if k == "kbqa" {
+		return 103
+	} else if k == "ycoglevzy" {
+		return 106
+	} else if k == "kfzh" {
+		return 109
+	} else if k == "aoxztkf" {
+		return 112
+	} else if k == "cqwwtywpbo" {
+		return 115
+	} else if k == "pxrrjtyzf" {
+		return 118
+	} else if k == "rudp" {
+		return 121
+	} else if k == "qqcb" {
+		return 124
+	} else if k == "krqrjrki" {
+		return 127
+	} else if k == "zrrz" {
+		return 130
+	} else if k == "hlhrblfa" {
+		return 133
+	} else if k == "glpchbapzx" {
+		return 136
+	} else if k == "hepgxkx" {
+		return 139
+	} else if k == "lnceh" {
+		return 142
+	} else if k == "csrly" {
+		return 145
+	} else if k == "hdjwdhg" {
+		return 148
+	} else if k == "ybjyvnkgp" {
+		return 151
+	} else if k == "qym" {
+		return 154
+	} else if k == "ljh" {
+		return 157
+	} else if k == "tulsfsq" {
+		return 160
+	} else if k == "eafwd" {
+		return 163
+	} else if k == "asqpjgffg" {
+		return 166
+	} else if k == "mqnmlwmvwl" {
+		return 169
+	} else if k == "lgp" {
+		return 172
+	} else if k == "dnoqfsac" {
+		return 175
+	} else if k == "pqpnimi" {
+		return 178
+	} else if k == "rtkilwk" {
+		return 181
+	} else if k == "kkoom" {
+		return 184
+	} else if k == "pdvdnlmxcc" {
+		return 187
+	} else if k == "woconw" {
+		return 190
+	} else if k == "viopdiobkj" {
+		return 193
+	}
+	return 0
+}
+
+func jtSwitchString64(k string) int {
+	switch k {
+	case "utizkn":
+		return 4
+	case "qvyunag":
+		return 7
+	case "luthyb":
+		return 10
+	case "oqziwxre":
+		return 13
+	case "hgtn":
+		return 16
+	case "ugysyfb":
+		return 19
+	case "cejuqx":
+		return 22
+	case "kjhlxctl":
+		return 25
+	case "sgciqddqy":
+		return 28
+	case "nicdrvar":
+		return 31
+	case "caaylb":
+		return 34
+	case "zqdaf":
+		return 37
+	case "hnnuooq":
+		return 40
+	case "vwnxovsec":
+		return 43
+	case "ucyqchpaye":
+		return 46
+	case "ytkei":
+		return 49
+	case "ufumlmsw":
+		return 52
+	case "zqqqppw":
+		return 55
+	case "dfpspgmkv":
+		return 58
+	case "ykbo":
+		return 61
+	case "lpbjnqqbi":
+		return 64
+	case "kqias":
+		return 67
+	case "xjqnm":
+		return 70
+	case "ujfotmpfq":
+		return 73
+	case "dhoujjh":
+		return 76
+	case "bmy":
+		return 79
+	case "xmbv":
+		return 82
+	case "rid":
+		return 85
+	case "dour":
+		return 88
+	case "vqt":
+		return 91
+	case "vrapccxlcr":
+		return 94
+	case "oum":
+		return 97
+	case "rdilsycke":
+		return 100
+	case "kbqa":
+		return 103
+	case "ycoglevzy":
+		return 106
+	case "kfzh":
+		return 109
+	case "aoxztkf":
+		return 112
+	case "cqwwtywpbo":
+		return 115
+	case "pxrrjtyzf":
+		return 118
+	case "rudp":
+		return 121
+	case "qqcb":
+		return 124
+	case "krqrjrki":
+		return 127
+	case "zrrz":
+		return 130
+	case "hlhrblfa":
+		return 133
+	case "glpchbapzx":
+		return 136
+	case "hepgxkx":
+		return 139
+	case "lnceh":
+		return 142
+	case "csrly":
+		return 145
+	case "hdjwdhg":
+		return 148
+	case "ybjyvnkgp":
+		return 151
+	case "qym":
+		return 154
+	case "ljh":
+		return 157
+	case "tulsfsq":
+		return 160
+	case "eafwd":
+		return 163
+	case "asqpjgffg":
+		return 166
+	case "mqnmlwmvwl":
+		return 169
+	case "lgp":
+		return 172
+	case "dnoqfsac":
+		return 175
+	case "pqpnimi":
+		return 178
+	case "rtkilwk":
+		return 181
+	case "kkoom":
+		return 184
+	case "pdvdnlmxcc":
+		return 187
+	case "woconw":
+		return 190
+	case "viopdiobkj":
+		return 193
+	}
+	return 0
+}
+
+var jtMapString64 = map[string]int{"utizkn": 1, "qvyunag": 2, "luthyb": 3, "oqziwxre": 4, "hgtn": 5, "ugysyfb": 6, "cejuqx": 7, "kjhlxctl": 8, "sgciqddqy": 9, "nicdrvar": 10, "caaylb": 11, "zqdaf": 12, "hnnuooq": 13, "vwnxovsec": 14, "ucyqchpaye": 15, "ytkei": 16, "ufumlmsw": 17, "zqqqppw": 18, "dfpspgmkv": 19, "ykbo": 20, "lpbjnqqbi": 21, "kqias": 22, "xjqnm": 23, "ujfotmpfq": 24, "dhoujjh": 25, "bmy": 26, "xmbv": 27, "rid": 28, "dour": 29, "vqt": 30, "vrapccxlcr": 31, "oum": 32, "rdilsycke": 33, "kbqa": 34, "ycoglevzy": 35, "kfzh": 36, "aoxztkf": 37, "cqwwtywpbo": 38, "pxrrjtyzf": 39, "rudp": 40, "qqcb": 41, "krqrjrki": 42, "zrrz": 43, "hlhrblfa": 44, "glpchbapzx": 45, "hepgxkx": 46, "lnceh": 47, "csrly": 48, "hdjwdhg": 49, "ybjyvnkgp": 50, "qym": 51, "ljh": 52, "tulsfsq": 53, "eafwd": 54, "asqpjgffg": 55, "mqnmlwmvwl": 56, "lgp": 57, "dnoqfsac": 58, "pqpnimi": 59, "rtkilwk": 60, "kkoom": 61, "pdvdnlmxcc": 62, "woconw": 63, "viopdiobkj": 64}
+
+func jtMapIndexString64(k string) int {
+	switch jtMapString64[k] {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	case 5:
+		return 16
+	case 6:
+		return 19
+	case 7:
+		return 22
+	case 8:
+		return 25
+	case 9:
+		return 28
+	case 10:
+		return 31
+	case 11:
+		return 34
+	case 12:
+		return 37
+	case 13:
+		return 40
+	case 14:
+		return 43
+	case 15:
+		return 46
+	case 16:
+		return 49
+	case 17:
+		return 52
+	case 18:
+		return 55
+	case 19:
+		return 58
+	case 20:
+		return 61
+	case 21:
+		return 64
+	case 22:
+		return 67
+	case 23:
+		return 70
+	case 24:
+		return 73
+	case 25:
+		return 76
+	case 26:
+		return 79
+	case 27:
+		return 82
+	case 28:
+		return 85
+	case 29:
+		return 88
+	case 30:
+		return 91
+	case 31:
+		return 94
+	case 32:
+		return 97
+	case 33:
+		return 100
+	case 34:
+		return 103
+	case 35:
+		return 106
+	case 36:
+		return 109
+	case 37:
+		return 112
+	case 38:
+		return 115
+	case 39:
+		return 118
+	case 40:
+		return 121
+	case 41:
+		return 124
+	case 42:
+		return 127
+	case 43:
+		return 130
+	case 44:
+		return 133
+	case 45:
+		return 136
+	case 46:
+		return 139
+	case 47:
+		return 142
+	case 48:
+		return 145
+	case 49:
+		return 148
+	case 50:
+		return 151
+	case 51:
+		return 154
+	case 52:
+		return 157
+	case 53:
+		return 160
+	case 54:
+		return 163
+	case 55:
+		return 166
+	case 56:
+		return 169
+	case 57:
+		return 172
+	case 58:
+		return 175
+	case 59:
+		return 178
+	case 60:
+		return 181
+	case 61:
+		return 184
+	case 62:
+		return 187
+	case 63:
+		return 190
+	case 64:
+		return 193
+	}
+	return 0
+}
+
+func BenchmarkJumpTableInt(b *testing.B) {
+	b.Run("dense4/if", func(b *testing.B) { benchJumpInt(b, 4, jtIfInt4) })
+	b.Run("dense4/switch", func(b *testing.B) { benchJumpInt(b, 4, jtSwitchInt4) })
+	b.Run("dense4/map", func(b *testing.B) { benchJumpInt(b, 4, jtMapIndexInt4) })
+	b.Run("dense4/array", func(b *testing.B) { benchJumpInt(b, 4, jtArrayIndexInt4) })
+	b.Run("dense8/if", func(b *testing.B) { benchJumpInt(b, 8, jtIfInt8) })
+	b.Run("dense8/switch", func(b *testing.B) { benchJumpInt(b, 8, jtSwitchInt8) })
+	b.Run("dense8/map", func(b *testing.B) { benchJumpInt(b, 8, jtMapIndexInt8) })
+	b.Run("dense8/array", func(b *testing.B) { benchJumpInt(b, 8, jtArrayIndexInt8) })
+	b.Run("dense16/if", func(b *testing.B) { benchJumpInt(b, 16, jtIfInt16) })
+	b.Run("dense16/switch", func(b *testing.B) { benchJumpInt(b, 16, jtSwitchInt16) })
+	b.Run("dense16/map", func(b *testing.B) { benchJumpInt(b, 16, jtMapIndexInt16) })
+	b.Run("dense16/array", func(b *testing.B) { benchJumpInt(b, 16, jtArrayIndexInt16) })
+	b.Run("dense32/if", func(b *testing.B) { benchJumpInt(b, 32, jtIfInt32) })
+	b.Run("dense32/switch", func(b *testing.B) { benchJumpInt(b, 32, jtSwitchInt32) })
+	b.Run("dense32/map", func(b *testing.B) { benchJumpInt(b, 32, jtMapIndexInt32) })
+	b.Run("dense32/array", func(b *testing.B) { benchJumpInt(b, 32, jtArrayIndexInt32) })
+}
+
+func BenchmarkJumpTableString(b *testing.B) {
+	b.Run("keys16/if", func(b *testing.B) { benchJumpString(b, jtStringKeys16, jtIfString16) })
+	b.Run("keys16/switch", func(b *testing.B) { benchJumpString(b, jtStringKeys16, jtSwitchString16) })
+	b.Run("keys16/map", func(b *testing.B) { benchJumpString(b, jtStringKeys16, jtMapIndexString16) })
+	b.Run("keys32/if", func(b *testing.B) { benchJumpString(b, jtStringKeys32, jtIfString32) })
+	b.Run("keys32/switch", func(b *testing.B) { benchJumpString(b, jtStringKeys32, jtSwitchString32) })
+	b.Run("keys32/map", func(b *testing.B) { benchJumpString(b, jtStringKeys32, jtMapIndexString32) })
+	b.Run("keys48/if", func(b *testing.B) { benchJumpString(b, jtStringKeys48, jtIfString48) })
+	b.Run("keys48/switch", func(b *testing.B) { benchJumpString(b, jtStringKeys48, jtSwitchString48) })
+	b.Run("keys48/map", func(b *testing.B) { benchJumpString(b, jtStringKeys48, jtMapIndexString48) })
+	b.Run("keys64/if", func(b *testing.B) { benchJumpString(b, jtStringKeys64, jtIfString64) })
+	b.Run("keys64/switch", func(b *testing.B) { benchJumpString(b, jtStringKeys64, jtSwitchString64) })
+	b.Run("keys64/map", func(b *testing.B) { benchJumpString(b, jtStringKeys64, jtMapIndexString64) })
+}
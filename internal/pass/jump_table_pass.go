@@ -4,126 +4,697 @@ package pass
 import (
 	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/token"
+	"go/types"
 	"strconv"
 
 	stdastutil "golang.org/x/tools/go/ast/astutil"
 
 	"github.com/kubex-ecosystem/gastype/internal/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
 )
 
-// JumpTablePass: if/else encadeado por igualdade da MESMA variável string → jump table.
-type JumpTablePass struct{}
+// JumpTablePass: if/else encadeado por igualdade da MESMA expressão, ou switch com
+// casos constantes, → tabela chave → índice do ramo + switch de despacho.
+//
+//	if mode == "a" {            var jumpTable_mode = map[string]int{"a": 1, "b": 2, "c": 2}
+//		A()                     ...
+//	} else if mode == "b" ||    switch jumpTable_mode[mode] {
+//		mode == "c" {      →    case 1:
+//		B()                         A()
+//	} else {                    case 2:
+//		C()                         B()
+//	}                           default:
+//	                                C()
+//	                            }
+//
+// As tabelas são vars de pacote inicializadas uma vez. Chaves inteiras densas
+// usam um array indexado por (chave - mínimo) em vez de map. Os corpos continuam
+// inline, então return, continue, goto e break com label mantêm o significado.
+type JumpTablePass struct {
+	used map[string]bool // nomes de tabelas já emitidos no pacote
+}
+
+// Limiares medidos com jump_table_bench_test.go (amd64, go1.27), ns/op por
+// despacho, entradas embaralhadas com chaves ausentes:
+//
+//	chaves             if-chain  switch   map   array
+//	int, 4 densas         4.8      3.5    13.1    4.8
+//	int, 8 densas         5.7      3.8    14.5    4.8
+//	int, 16 densas        7.3      3.8    22.0    4.3
+//	int, 32 densas       10.3      3.6    19.2    4.3
+//	string, 16            8.9      6.3    30.9     -
+//	string, 32           20.3      8.3    25.3     -
+//	string, 48           21.9      6.5    24.8     -
+//	string, 64           33.6      7.6    24.2     -
+//
+// O switch nativo (busca binária / jump table do gc) vence as duas tabelas, então
+// switch só vira tabela com ctx.Ofuscate; cadeias de if só quando a tabela ganha.
+const (
+	jumpTableMinBranches  = 3   // mínimo de ramos com chave para considerar a cadeia
+	jumpTableMinDenseKeys = 8   // array: a partir de 8 chaves inteiras densas
+	jumpTableMaxSpan      = 256 // array: maior intervalo max-min+1 aceito
+	jumpTableMinMapKeys   = 64  // map: a partir de 64 chaves string
+)
 
 func NewJumpTablePass() *JumpTablePass { return &JumpTablePass{} }
 func (p *JumpTablePass) Name() string  { return "JumpTable" }
 
-func (p *JumpTablePass) Apply(file *ast.File, _ *token.FileSet, ctx *astutil.TranspileContext) error {
+// jumpChain is a dispatch on one subject expression with constant keys
+type jumpChain struct {
+	subject    ast.Expr
+	keyType    types.Type
+	init       ast.Stmt
+	cases      []jumpCase
+	name       string // nome base da tabela
+	pos        token.Pos
+	end        token.Pos
+	fromSwitch bool
+	tails      []*ast.IfStmt // `else if` da cadeia além do primeiro if
+}
+
+type jumpCase struct {
+	keys      []constant.Value
+	body      []ast.Stmt
+	isDefault bool
+	pos       token.Pos // posição original do ramo, para o printer manter o layout
+}
+
+// Prepare resets the table names emitted for the package
+func (p *JumpTablePass) Prepare(_ []*ast.File, _ *token.FileSet, _ *astutil.TranspileContext) error {
+	p.used = make(map[string]bool)
+	return nil
+}
+
+func (p *JumpTablePass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	if p.used == nil {
+		p.used = make(map[string]bool)
+	}
 	transformations := 0
+	var tables []ast.Decl
 
-	stdastutil.Apply(file, func(c *stdastutil.Cursor) bool {
-		ifStmt, ok := c.Node().(*ast.IfStmt)
-		if !ok {
+	// Apply continua descendo pelo nó original depois de Replace, o que
+	// recolheria as caudas `else if` da cadeia já convertida. Por isso o
+	// percurso para no nó substituído e continua só nos corpos dos ramos.
+	// Caudas de uma cadeia recusada também ficam de fora, senão cada uma seria
+	// recolhida de novo como cadeia menor.
+	done := make(map[*ast.IfStmt]bool)
+	var visit func(c *stdastutil.Cursor) bool
+	visit = func(c *stdastutil.Cursor) bool {
+		var chain *jumpChain
+		var reason string
+		switch node := c.Node().(type) {
+		case *ast.IfStmt:
+			if done[node] {
+				return true
+			}
+			chain, reason = collectIfChain(node, ctx)
+		case *ast.SwitchStmt:
+			chain, reason = collectSwitch(node, ctx)
+		default:
+			return true
+		}
+		if chain == nil && reason == "" {
 			return true
 		}
+		pos := fset.Position(c.Node().Pos())
 
-		varName, branches := p.collectBranches(ifStmt)
-		if varName == "" || len(branches) < 3 {
+		var keyType ast.Expr
+		strategy := ""
+		if reason == "" {
+			strategy, reason = p.strategy(chain, ctx)
+		}
+		if reason == "" {
+			if keyType = typeExprFor(chain.keyType, file, ctx); keyType == nil || !packageLevelType(chain.keyType, ctx) {
+				reason = "key type not visible at package level"
+			}
+		}
+		if reason != "" {
+			if chain != nil {
+				chain.skipTails(done)
+			}
+			gl.Log("info", fmt.Sprintf("JumpTable: skipping chain at %s (%s)", pos, reason))
+			ctx.RecordLedger(p.Name(), pos, "", "skipped", reason)
 			return true
 		}
+
+		tableName := p.tableName(file, chain, ctx)
+		var tag ast.Expr
+		if strategy == "array" {
+			decls, indexFn := p.arrayTable(chain, tableName, keyType)
+			tables = append(tables, decls...)
+			tag = &ast.CallExpr{Fun: ast.NewIdent(indexFn), Args: []ast.Expr{chain.subject}}
+		} else {
+			tables = append(tables, tableVar(tableName, p.mapLit(chain, keyType)))
+			tag = &ast.IndexExpr{X: ast.NewIdent(tableName), Index: chain.subject}
+		}
+
+		sw := dispatchSwitch(chain, tag)
+		var replacement ast.Stmt = sw
+		if c.Name() == "Else" {
+			replacement = &ast.BlockStmt{Lbrace: chain.pos, Rbrace: chain.end, List: []ast.Stmt{replacement}}
+		}
+		c.Replace(replacement)
+		ctx.RecordLedger(p.Name(), pos, types.ExprString(chain.subject), "rewritten",
+			fmt.Sprintf("%d branches via %s %s", len(chain.cases), strategy, tableName))
 		transformations++
 
-		jumpTableName := fmt.Sprintf("jumpTable_%s", varName)
+		for _, clause := range sw.Body.List {
+			stdastutil.Apply(clause, visit, nil)
+		}
+		return false
+	}
+	stdastutil.Apply(file, visit, nil)
+
+	file.Decls = append(file.Decls, tables...)
+
+	if transformations > 0 {
+		ctx.LogVerbose(fset, "🔀 JumpTablePass: %d transformations applied", transformations)
+	}
+	return nil
+}
+
+// strategy picks "array" or "map" for a chain, or explains why the original
+// code is kept. See the thresholds above.
+func (p *JumpTablePass) strategy(chain *jumpChain, ctx *astutil.TranspileContext) (string, string) {
+	keys := 0
+	var lo, hi constant.Value
+	for _, jc := range chain.cases {
+		for _, k := range jc.keys {
+			keys++
+			if k.Kind() != constant.Int {
+				continue
+			}
+			if lo == nil || constant.Compare(k, token.LSS, lo) {
+				lo = k
+			}
+			if hi == nil || constant.Compare(k, token.GTR, hi) {
+				hi = k
+			}
+		}
+	}
+
+	dense := false
+	if lo != nil {
+		span, exact := constant.Int64Val(constant.BinaryOp(hi, token.SUB, lo))
+		dense = exact && span+1 <= jumpTableMaxSpan && span+1 <= int64(2*keys)
+	}
+
+	if ctx.Ofuscate {
+		if dense {
+			return "array", ""
+		}
+		return "map", ""
+	}
+	if chain.fromSwitch {
+		return "", "native switch is faster than a table"
+	}
+	if dense && keys >= jumpTableMinDenseKeys {
+		return "array", ""
+	}
+	if lo == nil && keys >= jumpTableMinMapKeys {
+		return "map", ""
+	}
+	return "", fmt.Sprintf("%d keys below the table threshold", keys)
+}
+
+// tableName returns a jumpTable_ name whose jumpIndex_ twin is also free in the package and in file
+func (p *JumpTablePass) tableName(file *ast.File, chain *jumpChain, ctx *astutil.TranspileContext) string {
+	free := func(name string) bool {
+		return !p.used[name] && freshLocalName(file, name) == name && freshPackageName(ctx.Package, name) == name
+	}
+	for i := 1; ; i++ {
+		suffix := chain.name
+		if i > 1 {
+			suffix += strconv.Itoa(i)
+		}
+		table, index := "jumpTable_"+suffix, "jumpIndex_"+suffix
+		if free(table) && free(index) {
+			p.used[table], p.used[index] = true, true
+			return table
+		}
+	}
+}
 
-		// var jumpTable_varName = map[string]func(){ "a": func(){...}, ... }
-		mapEntries := []ast.Expr{}
-		for _, b := range branches {
-			mapEntries = append(mapEntries, &ast.KeyValueExpr{
-				Key:   &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(b.matchValue)},
-				Value: &ast.FuncLit{Type: &ast.FuncType{Params: &ast.FieldList{}}, Body: b.body},
+// mapLit builds map[K]int{key: branch index}; index 0 is the default branch
+func (p *JumpTablePass) mapLit(chain *jumpChain, keyType ast.Expr) *ast.CompositeLit {
+	lit := &ast.CompositeLit{Type: &ast.MapType{Key: keyType, Value: ast.NewIdent("int")}}
+	index := 0
+	for _, jc := range chain.cases {
+		if jc.isDefault {
+			continue
+		}
+		index++
+		for _, key := range jc.keys {
+			lit.Elts = append(lit.Elts, &ast.KeyValueExpr{
+				Key:   constantLit(key),
+				Value: &ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(index)},
 			})
 		}
-		mapDecl := &ast.DeclStmt{
-			Decl: &ast.GenDecl{
-				Tok: token.VAR,
-				Specs: []ast.Spec{
-					&ast.ValueSpec{
-						Names: []*ast.Ident{ast.NewIdent(jumpTableName)},
-						Type: &ast.MapType{
-							Key:   ast.NewIdent("string"),
-							Value: &ast.FuncType{Params: &ast.FieldList{}},
-						},
-						Values: []ast.Expr{
-							&ast.CompositeLit{
-								Type: &ast.MapType{
-									Key:   ast.NewIdent("string"),
-									Value: &ast.FuncType{Params: &ast.FieldList{}},
-								},
-								Elts: mapEntries,
-							},
-						},
-					},
-				},
-			},
+	}
+	return lit
+}
+
+// arrayTable builds `var jumpTable_x = [N]uint8{...}` indexed by key-min and
+// the bounds-checked jumpIndex_x(k) helper; index 0 is the default branch
+func (p *JumpTablePass) arrayTable(chain *jumpChain, tableName string, keyType ast.Expr) ([]ast.Decl, string) {
+	var lo constant.Value
+	for _, jc := range chain.cases {
+		for _, k := range jc.keys {
+			if lo == nil || constant.Compare(k, token.LSS, lo) {
+				lo = k
+			}
+		}
+	}
+
+	slots := map[int64]int{}
+	span := int64(0)
+	index := 0
+	for _, jc := range chain.cases {
+		if jc.isDefault {
+			continue
 		}
+		index++
+		for _, k := range jc.keys {
+			off, _ := constant.Int64Val(constant.BinaryOp(k, token.SUB, lo))
+			slots[off] = index
+			span = max(span, off+1)
+		}
+	}
+	elemType := "uint8"
+	if index > 255 {
+		elemType = "uint16"
+	}
+	lit := &ast.CompositeLit{Type: &ast.ArrayType{
+		Len: &ast.BasicLit{Kind: token.INT, Value: strconv.FormatInt(span, 10)},
+		Elt: ast.NewIdent(elemType),
+	}}
+	for off := int64(0); off < span; off++ {
+		lit.Elts = append(lit.Elts, &ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(slots[off])})
+	}
 
-		// if fn, ok := jumpTable[varName]; ok { fn() }
-		lookup := &ast.IfStmt{
-			Init: &ast.AssignStmt{
-				Lhs: []ast.Expr{ast.NewIdent("fn"), ast.NewIdent("ok")},
-				Tok: token.DEFINE,
-				Rhs: []ast.Expr{
-					&ast.IndexExpr{X: ast.NewIdent(jumpTableName), Index: ast.NewIdent(varName)},
+	indexFn := "jumpIndex" + tableName[len("jumpTable"):]
+	// uint64(k - lo) também rejeita chaves abaixo do mínimo (wrap-around)
+	min := constantLit(lo)
+	if constant.Sign(lo) < 0 {
+		min = &ast.ParenExpr{X: min}
+	}
+	offset := &ast.BinaryExpr{X: ast.NewIdent("k"), Op: token.SUB, Y: min}
+	fn := &ast.FuncDecl{
+		Name: ast.NewIdent(indexFn),
+		Type: &ast.FuncType{
+			Params:  &ast.FieldList{List: []*ast.Field{{Names: []*ast.Ident{ast.NewIdent("k")}, Type: keyType}}},
+			Results: &ast.FieldList{List: []*ast.Field{{Type: ast.NewIdent("int")}}},
+		},
+		Body: &ast.BlockStmt{List: []ast.Stmt{
+			&ast.IfStmt{
+				Init: &ast.AssignStmt{
+					Lhs: []ast.Expr{ast.NewIdent("i")},
+					Tok: token.DEFINE,
+					Rhs: []ast.Expr{&ast.CallExpr{Fun: ast.NewIdent("uint64"), Args: []ast.Expr{offset}}},
 				},
+				Cond: &ast.BinaryExpr{
+					X:  ast.NewIdent("i"),
+					Op: token.LSS,
+					Y:  &ast.CallExpr{Fun: ast.NewIdent("uint64"), Args: []ast.Expr{&ast.CallExpr{Fun: ast.NewIdent("len"), Args: []ast.Expr{ast.NewIdent(tableName)}}}},
+				},
+				Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{
+					&ast.CallExpr{Fun: ast.NewIdent("int"), Args: []ast.Expr{&ast.IndexExpr{X: ast.NewIdent(tableName), Index: ast.NewIdent("i")}}},
+				}}}},
 			},
-			Cond: ast.NewIdent("ok"),
-			Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ExprStmt{X: &ast.CallExpr{Fun: ast.NewIdent("fn")}}}},
-		}
+			&ast.ReturnStmt{Results: []ast.Expr{&ast.BasicLit{Kind: token.INT, Value: "0"}}},
+		}},
+	}
+	return []ast.Decl{tableVar(tableName, lit), fn}, indexFn
+}
 
-		c.Replace(&ast.BlockStmt{List: []ast.Stmt{mapDecl, lookup}})
-		return true
-	}, nil)
+func tableVar(name string, value ast.Expr) ast.Decl {
+	return &ast.GenDecl{
+		Tok: token.VAR,
+		Specs: []ast.Spec{&ast.ValueSpec{
+			Names:  []*ast.Ident{ast.NewIdent(name)},
+			Values: []ast.Expr{value},
+		}},
+	}
+}
 
-	if transformations > 0 {
-		ctx.LogVerbose(nil, "🔀 JumpTablePass: %d transformations applied", transformations)
+// dispatchSwitch builds `switch tag { case 1: ... default: ... }` keeping the original case order
+func dispatchSwitch(chain *jumpChain, tag ast.Expr) *ast.SwitchStmt {
+	sw := &ast.SwitchStmt{Switch: chain.pos, Init: chain.init, Tag: tag, Body: &ast.BlockStmt{Lbrace: chain.pos, Rbrace: chain.end}}
+	index := 0
+	for _, jc := range chain.cases {
+		clause := &ast.CaseClause{Case: jc.pos, Colon: jc.pos, Body: jc.body}
+		if !jc.isDefault {
+			index++
+			clause.List = []ast.Expr{&ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(index)}}
+		}
+		sw.Body.List = append(sw.Body.List, clause)
 	}
-	return nil
+	return sw
 }
 
-type branchInfo struct {
-	matchValue string
-	body       *ast.BlockStmt
+// packageLevelType reports whether t can be named outside the enclosing function
+func packageLevelType(t types.Type, ctx *astutil.TranspileContext) bool {
+	if _, ok := types.Unalias(t).(*types.TypeParam); ok {
+		return false
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return true
+	}
+	obj := named.Obj()
+	return obj.Pkg() != ctx.Package || obj.Parent() == ctx.Package.Scope()
 }
 
-func (p *JumpTablePass) collectBranches(ifStmt *ast.IfStmt) (string, []branchInfo) {
-	branches := []branchInfo{}
-	var varName string
+// collectIfChain collects `if x == k1 || x == k2 {...} else if x == k3 {...} else {...}`.
+// The chain stops at the first condition that is not an equality on x; the
+// rest becomes the default branch. A nil chain with an empty reason means the
+// statement is simply not a candidate; a chain with a reason was collected but
+// cannot be converted.
+func collectIfChain(ifStmt *ast.IfStmt, ctx *astutil.TranspileContext) (*jumpChain, string) {
+	chain := &jumpChain{init: ifStmt.Init, pos: ifStmt.Pos(), end: ifStmt.End() - 1}
+	seen := make(map[string]bool)
 
 	cur := ifStmt
 	for cur != nil {
-		bin, ok := cur.Cond.(*ast.BinaryExpr)
-		if !ok || bin.Op != token.EQL {
+		if cur != ifStmt && cur.Init != nil {
 			break
 		}
-		left, ok := bin.X.(*ast.Ident)
-		right, ok2 := bin.Y.(*ast.BasicLit)
-		if !ok || !ok2 || right.Kind != token.STRING {
+		keys := equalityKeys(cur.Cond, chain, ctx)
+		if keys == nil {
 			break
 		}
-		if varName == "" {
-			varName = left.Name
-		} else if left.Name != varName {
+		if cur != ifStmt {
+			chain.tails = append(chain.tails, cur)
+		}
+		jc := jumpCase{body: cur.Body.List, pos: cur.Pos()}
+		for _, k := range keys {
+			// A primeira comparação vence, como no if original
+			if !seen[k.ExactString()] {
+				seen[k.ExactString()] = true
+				jc.keys = append(jc.keys, k)
+			}
+		}
+		if len(jc.keys) > 0 {
+			chain.cases = append(chain.cases, jc)
+		}
+
+		next, ok := cur.Else.(*ast.IfStmt)
+		if !ok {
+			if block, ok := cur.Else.(*ast.BlockStmt); ok {
+				chain.cases = append(chain.cases, jumpCase{body: block.List, isDefault: true, pos: block.Lbrace})
+			}
+			cur = nil
 			break
 		}
-		branches = append(branches, branchInfo{
-			matchValue: right.Value[1 : len(right.Value)-1], // tira aspas
-			body:       cur.Body,
-		})
-		if next, ok := cur.Else.(*ast.IfStmt); ok {
-			cur = next
+		cur = next
+	}
+	if cur != nil {
+		chain.cases = append(chain.cases, jumpCase{body: []ast.Stmt{cur}, isDefault: true, pos: cur.Pos()})
+	}
+
+	if countKeyed(chain.cases) < jumpTableMinBranches {
+		return nil, ""
+	}
+	if !astutil.IsSideEffectFree(chain.subject, ctx.Info) {
+		return chain, "compared expression has side effects"
+	}
+	for _, jc := range chain.cases {
+		if hasUnlabeledBreak(jc.body) {
+			return chain, "unlabeled break inside a branch"
+		}
+	}
+	return chain, ""
+}
+
+// skipTails marks the `else if` tails of a chain that was left as is, so the
+// walk does not collect each of them again as a shorter chain
+func (chain *jumpChain) skipTails(done map[*ast.IfStmt]bool) {
+	for _, tail := range chain.tails {
+		done[tail] = true
+	}
+}
+
+// equalityKeys returns the constants of `x == k` or `x == k1 || x == k2 ...`,
+// setting the chain subject on first use
+func equalityKeys(cond ast.Expr, chain *jumpChain, ctx *astutil.TranspileContext) []constant.Value {
+	var keys []constant.Value
+	for _, term := range flattenChain(ast.Unparen(cond), token.LOR) {
+		bin, ok := ast.Unparen(term).(*ast.BinaryExpr)
+		if !ok || bin.Op != token.EQL {
+			return nil
+		}
+		subject, key := bin.X, bin.Y
+		if constValue(subject, ctx) != nil {
+			subject, key = key, subject
+		}
+		value := constValue(key, ctx)
+		if value == nil {
+			return nil
+		}
+		if chain.subject == nil {
+			t := jumpKeyType(subject, ctx)
+			if t == nil {
+				return nil
+			}
+			chain.subject, chain.keyType, chain.name = subject, t, subjectName(subject)
+		} else if types.ExprString(subject) != types.ExprString(chain.subject) {
+			return nil
+		}
+		keys = append(keys, value)
+	}
+	return keys
+}
+
+// collectSwitch collects an expression switch whose cases are all constants
+func collectSwitch(sw *ast.SwitchStmt, ctx *astutil.TranspileContext) (*jumpChain, string) {
+	if sw.Tag == nil || len(sw.Body.List) < jumpTableMinBranches {
+		return nil, ""
+	}
+	keyType := jumpKeyType(sw.Tag, ctx)
+	if keyType == nil {
+		return nil, ""
+	}
+	chain := &jumpChain{subject: sw.Tag, keyType: keyType, init: sw.Init, name: subjectName(sw.Tag), pos: sw.Pos(), end: sw.Body.Rbrace, fromSwitch: true}
+	for _, stmt := range sw.Body.List {
+		clause := stmt.(*ast.CaseClause)
+		jc := jumpCase{body: clause.Body, isDefault: clause.List == nil, pos: clause.Case}
+		for _, e := range clause.List {
+			value := constValue(e, ctx)
+			if value == nil {
+				return nil, "non-constant case"
+			}
+			jc.keys = append(jc.keys, value)
+		}
+		chain.cases = append(chain.cases, jc)
+	}
+	if countKeyed(chain.cases) < jumpTableMinBranches {
+		return nil, ""
+	}
+	return chain, ""
+}
+
+func countKeyed(cases []jumpCase) int {
+	n := 0
+	for _, jc := range cases {
+		if !jc.isDefault {
+			n++
+		}
+	}
+	return n
+}
+
+// jumpKeyType returns the type of a dispatch subject when it can key a map of
+// constants: strings and integers, named or not
+func jumpKeyType(e ast.Expr, ctx *astutil.TranspileContext) types.Type {
+	tv, ok := ctx.GetTypes()[e]
+	if !ok || tv.Type == nil || tv.Value != nil {
+		return nil
+	}
+	basic, ok := tv.Type.Underlying().(*types.Basic)
+	if !ok || basic.Info()&types.IsUntyped != 0 || basic.Info()&(types.IsString|types.IsInteger) == 0 {
+		return nil
+	}
+	return tv.Type
+}
+
+func constValue(e ast.Expr, ctx *astutil.TranspileContext) constant.Value {
+	if tv, ok := ctx.GetTypes()[e]; ok && tv.Value != nil {
+		if tv.Value.Kind() == constant.String || tv.Value.Kind() == constant.Int {
+			return tv.Value
+		}
+	}
+	return nil
+}
+
+// constantLit renders a string or integer constant as a literal
+func constantLit(v constant.Value) ast.Expr {
+	if v.Kind() == constant.String {
+		return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(constant.StringVal(v))}
+	}
+	if constant.Sign(v) < 0 {
+		return &ast.UnaryExpr{Op: token.SUB, X: &ast.BasicLit{Kind: token.INT, Value: constant.UnaryOp(token.SUB, v, 0).ExactString()}}
+	}
+	return &ast.BasicLit{Kind: token.INT, Value: v.ExactString()}
+}
+
+// typeExprFor renders t as it must be written inside file, or nil if the
+// file has no name for it
+func typeExprFor(t types.Type, file *ast.File, ctx *astutil.TranspileContext) ast.Expr {
+	switch tt := types.Unalias(t).(type) {
+	case *types.Basic:
+		if tt.Info()&types.IsUntyped != 0 || tt.Kind() == types.UnsafePointer {
+			return nil
+		}
+		return ast.NewIdent(tt.Name())
+	case *types.Named:
+		obj := tt.Obj()
+		var name ast.Expr
+		if obj.Pkg() == nil || obj.Pkg() == ctx.Package {
+			name = ast.NewIdent(obj.Name())
+		} else if obj.Exported() {
+			for _, imp := range file.Imports {
+				if path, _ := strconv.Unquote(imp.Path.Value); path != obj.Pkg().Path() {
+					continue
+				}
+				pkgName := obj.Pkg().Name()
+				if imp.Name != nil {
+					pkgName = imp.Name.Name
+				}
+				if pkgName != "_" && pkgName != "." {
+					name = &ast.SelectorExpr{X: ast.NewIdent(pkgName), Sel: ast.NewIdent(obj.Name())}
+				}
+				break
+			}
+		}
+		if name == nil || tt.TypeArgs() == nil {
+			return name
+		}
+		var args []ast.Expr
+		for i := 0; i < tt.TypeArgs().Len(); i++ {
+			arg := typeExprFor(tt.TypeArgs().At(i), file, ctx)
+			if arg == nil {
+				return nil
+			}
+			args = append(args, arg)
+		}
+		if len(args) == 1 {
+			return &ast.IndexExpr{X: name, Index: args[0]}
+		}
+		return &ast.IndexListExpr{X: name, Indices: args}
+	case *types.TypeParam:
+		return ast.NewIdent(tt.Obj().Name())
+	case *types.Pointer:
+		if elem := typeExprFor(tt.Elem(), file, ctx); elem != nil {
+			return &ast.StarExpr{X: elem}
+		}
+	case *types.Slice:
+		if elem := typeExprFor(tt.Elem(), file, ctx); elem != nil {
+			return &ast.ArrayType{Elt: elem}
+		}
+	case *types.Array:
+		if elem := typeExprFor(tt.Elem(), file, ctx); elem != nil {
+			return &ast.ArrayType{Len: &ast.BasicLit{Kind: token.INT, Value: strconv.FormatInt(tt.Len(), 10)}, Elt: elem}
+		}
+	case *types.Map:
+		key, elem := typeExprFor(tt.Key(), file, ctx), typeExprFor(tt.Elem(), file, ctx)
+		if key != nil && elem != nil {
+			return &ast.MapType{Key: key, Value: elem}
+		}
+	case *types.Chan:
+		dir := map[types.ChanDir]ast.ChanDir{types.SendRecv: ast.SEND | ast.RECV, types.SendOnly: ast.SEND, types.RecvOnly: ast.RECV}[tt.Dir()]
+		if elem := typeExprFor(tt.Elem(), file, ctx); elem != nil {
+			return &ast.ChanType{Dir: dir, Value: elem}
+		}
+	case *types.Signature:
+		params := tupleFields(tt.Params(), tt.Variadic(), file, ctx)
+		results := tupleFields(tt.Results(), false, file, ctx)
+		if params != nil && results != nil {
+			return &ast.FuncType{Params: params, Results: results}
+		}
+	case *types.Struct:
+		fields := &ast.FieldList{}
+		for i := 0; i < tt.NumFields(); i++ {
+			v := tt.Field(i)
+			typ := typeExprFor(v.Type(), file, ctx)
+			if typ == nil {
+				return nil
+			}
+			field := &ast.Field{Type: typ}
+			if !v.Embedded() {
+				field.Names = []*ast.Ident{ast.NewIdent(v.Name())}
+			}
+			if tag := tt.Tag(i); tag != "" {
+				field.Tag = &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(tag)}
+			}
+			fields.List = append(fields.List, field)
+		}
+		return &ast.StructType{Fields: fields}
+	case *types.Interface:
+		if tt.Empty() {
+			return &ast.InterfaceType{Methods: &ast.FieldList{}}
+		}
+	}
+	return nil
+}
+
+// tupleFields renders the parameters or results of a signature, or nil if
+// some type has no name in file
+func tupleFields(tuple *types.Tuple, variadic bool, file *ast.File, ctx *astutil.TranspileContext) *ast.FieldList {
+	list := &ast.FieldList{}
+	for i := 0; i < tuple.Len(); i++ {
+		t := tuple.At(i).Type()
+		var typ ast.Expr
+		if variadic && i == tuple.Len()-1 {
+			if elem := typeExprFor(t.(*types.Slice).Elem(), file, ctx); elem != nil {
+				typ = &ast.Ellipsis{Elt: elem}
+			}
 		} else {
-			break
+			typ = typeExprFor(t, file, ctx)
+		}
+		if typ == nil {
+			return nil
 		}
+		list.List = append(list.List, &ast.Field{Type: typ})
+	}
+	return list
+}
+
+// subjectName returns a readable base name for the table of a subject expression
+func subjectName(e ast.Expr) string {
+	switch node := e.(type) {
+	case *ast.Ident:
+		return node.Name
+	case *ast.SelectorExpr:
+		return node.Sel.Name
+	case *ast.CallExpr:
+		return subjectName(node.Fun)
+	case *ast.IndexExpr:
+		return subjectName(node.X)
+	case *ast.ParenExpr:
+		return subjectName(node.X)
+	}
+	return "key"
+}
+
+// hasUnlabeledBreak reports whether stmts contain a `break` that would bind
+// to a switch wrapped around them
+func hasUnlabeledBreak(stmts []ast.Stmt) bool {
+	found := false
+	for _, stmt := range stmts {
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt, *ast.FuncLit:
+				return false
+			case *ast.BranchStmt:
+				if node.Tok == token.BREAK && node.Label == nil {
+					found = true
+				}
+			}
+			return !found
+		})
 	}
-	return varName, branches
+	return found
 }
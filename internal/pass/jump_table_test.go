@@ -0,0 +1,221 @@
+package pass
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+)
+
+// testPass is the subset of the engine's pass interface the tests drive
+type testPass interface {
+	Name() string
+	Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error
+}
+
+// transpileSource type-checks a single-file main package and runs passes over
+// it the way the engine does, returning the printed result and the context.
+func transpileSource(t *testing.T, src string, ofuscate bool, passes ...testPass) (string, *astutil.TranspileContext) {
+	t.Helper()
+	ctx := astutil.NewContext("main.go", t.TempDir(), ofuscate, "")
+	ctx.Info = astutil.NewInfo()
+	file, err := parser.ParseFile(ctx.Fset, "main.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	info := &types.Info{
+		Types:      ctx.GetTypes(),
+		Defs:       ctx.GetDefs(),
+		Uses:       ctx.GetUses(),
+		Implicits:  ctx.GetImplicits(),
+		Selections: ctx.GetSelections(),
+		Scopes:     ctx.GetScopes(),
+	}
+	if ctx.Package, err = conf.Check("main", ctx.Fset, []*ast.File{file}, info); err != nil {
+		t.Fatalf("type-check: %v", err)
+	}
+
+	for _, pass := range passes {
+		if pp, ok := pass.(interface {
+			Prepare([]*ast.File, *token.FileSet, *astutil.TranspileContext) error
+		}); ok {
+			if err := pp.Prepare([]*ast.File{file}, ctx.Fset, ctx); err != nil {
+				t.Fatalf("%s prepare: %v", pass.Name(), err)
+			}
+		}
+		if err := pass.Apply(file, ctx.Fset, ctx); err != nil {
+			t.Fatalf("%s apply: %v", pass.Name(), err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, ctx.Fset, file); err != nil {
+		t.Fatalf("print: %v", err)
+	}
+	return buf.String(), ctx
+}
+
+// runSource builds and runs a single-file main package, returning its stdout
+func runSource(t *testing.T, src string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module probe\n\ngo 1.22\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("go run: %v\n%s", err, src)
+	}
+	return string(out)
+}
+
+// requireGo skips tests that compile their output when no toolchain is around
+func requireGo(t *testing.T) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("compiles generated code")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not found")
+	}
+}
+
+func countLedger(ctx *astutil.TranspileContext, pass, action string) int {
+	n := 0
+	for _, e := range ctx.Ledger {
+		if e.Pass == pass && e.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+const jumpTableProbe = `package main
+
+import "fmt"
+
+type Op int
+
+const (
+	OpNop Op = iota
+	OpLoad
+	OpStore
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+	OpJmp
+	OpRet
+)
+
+func cost(op Op) int {
+	n := 0
+	if op == OpLoad {
+		n = 3
+	} else if op == OpStore {
+		n = 4
+	} else if op == OpAdd {
+		return 1
+	} else if op == OpSub {
+		n = 1
+	} else if op == OpMul {
+		n = 5
+	} else if op == OpDiv {
+		n = 20
+	} else if op == OpJmp {
+		n = 2
+	} else if op == OpRet {
+		n = 2
+	} else {
+		n = -1
+	}
+	return n * 10
+}
+
+func kind(s string) string {
+	out := ""
+	for i := 0; i < 2; i++ {
+		switch s {
+		case "get", "head":
+			out += "read"
+		case "put":
+			out += "write"
+			fallthrough
+		case "post":
+			out += "+post"
+		case "delete":
+			continue
+		default:
+			out += "?"
+		}
+		out += ";"
+	}
+	return out
+}
+
+func main() {
+	for op := Op(-1); op <= OpRet+1; op++ {
+		fmt.Println(op, cost(op))
+	}
+	for _, s := range []string{"get", "head", "put", "post", "delete", "patch", ""} {
+		fmt.Println(s, kind(s))
+	}
+}
+`
+
+func TestJumpTablePreservesBehavior(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, jumpTableProbe)
+
+	// Sem ofuscação só a cadeia de if densa vira tabela; com ela, o switch também
+	for _, ofuscate := range []bool{false, true} {
+		out, ctx := transpileSource(t, jumpTableProbe, ofuscate, NewJumpTablePass())
+		wantRewrites := 1
+		if ofuscate {
+			wantRewrites = 2
+		}
+		if got := countLedger(ctx, "JumpTable", "rewritten"); got != wantRewrites {
+			t.Fatalf("ofuscate=%v: %d chains rewritten, want %d\n%s", ofuscate, got, wantRewrites, out)
+		}
+		if got := runSource(t, out); got != want {
+			t.Errorf("ofuscate=%v: output differs\n got: %q\nwant: %q\n%s", ofuscate, got, want, out)
+		}
+	}
+}
+
+// sideEffectChain builds an if-chain over a call with n string keys, which
+// every table pass has to leave as is
+func sideEffectChain(n int) string {
+	var src strings.Builder
+	src.WriteString("package main\n\nfunc next() string { return \"\" }\n\nfunc route() int {\n\t")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&src, "if next() == %q {\n\t\treturn %d\n\t} else ", fmt.Sprintf("key%02d", i), i)
+	}
+	src.WriteString("{\n\t\treturn -1\n\t}\n}\n\nfunc main() { route() }\n")
+	return src.String()
+}
+
+func TestJumpTableSkipsChainOnce(t *testing.T) {
+	// Uma cadeia recusada não pode ser recolhida de novo a partir de cada else if
+	_, ctx := transpileSource(t, sideEffectChain(20), true, NewJumpTablePass())
+	if got := countLedger(ctx, "JumpTable", "skipped"); got != 1 {
+		t.Errorf("%d skipped entries for one chain, want 1", got)
+	}
+}
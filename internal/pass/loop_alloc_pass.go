@@ -0,0 +1,701 @@
+package pass
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	stdastutil "golang.org/x/tools/go/ast/astutil"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// LoopAllocPass remove realocações repetidas dentro de loops:
+//
+//	s := ""                      s := ""
+//	for _, w := range ws {   →   var sBuilder strings.Builder
+//		s += w                   for _, w := range ws {
+//	}                                sBuilder.WriteString(w)
+//	                             }
+//	                             s = sBuilder.String()
+//
+//	out := []int{}                      out := make([]int, 0, len(xs))
+//	for _, x := range xs {          →   for _, x := range xs {
+//		out = append(out, x*2)              out = append(out, x*2)
+//	}                                   }
+//
+// A concatenação só vira Builder quando o loop toca a string apenas com
+// s += x, a variável é local, declarada antes do loop, não é capturada por
+// closures nem tem o endereço tomado, e nenhum return, goto ou break/continue
+// para fora deixa o loop antes da atribuição final. O make só entra quando a
+// declaração vem logo antes de um range sobre slice, array ou map sem efeitos
+// colaterais, com um único append por iteração; um slice nil só ganha
+// capacidade se o append é incondicional, para continuar nil quando o range
+// é vazio.
+type LoopAllocPass struct{}
+
+func NewLoopAllocPass() *LoopAllocPass { return &LoopAllocPass{} }
+func (p *LoopAllocPass) Name() string  { return "LoopAlloc" }
+
+func (p *LoopAllocPass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	r := &loopAllocRewriter{pass: p, file: file, fset: fset, ctx: ctx}
+	stdastutil.Apply(file, r.pre, r.post)
+	if r.builders+r.prealloc > 0 {
+		ctx.LogVerbose(fset, "🔁 LoopAllocPass: %d string builders, %d preallocated slices", r.builders, r.prealloc)
+	}
+	return nil
+}
+
+// loopAllocRewriter rewrites the loops of one file, outermost first
+type loopAllocRewriter struct {
+	pass        *LoopAllocPass
+	file        *ast.File
+	fset        *token.FileSet
+	ctx         *astutil.TranspileContext
+	bodies      []*ast.BlockStmt // corpo das funções envolventes
+	stringsName string
+	builders    int
+	prealloc    int
+}
+
+func (r *loopAllocRewriter) post(c *stdastutil.Cursor) bool {
+	switch c.Node().(type) {
+	case *ast.FuncDecl, *ast.FuncLit:
+		r.bodies = r.bodies[:len(r.bodies)-1]
+	}
+	return true
+}
+
+func (r *loopAllocRewriter) pre(c *stdastutil.Cursor) bool {
+	switch node := c.Node().(type) {
+	case *ast.FuncDecl:
+		r.bodies = append(r.bodies, node.Body)
+		return true
+	case *ast.FuncLit:
+		r.bodies = append(r.bodies, node.Body)
+		return true
+	}
+	stmt, ok := c.Node().(ast.Stmt)
+	if !ok || c.Index() < 0 || len(r.bodies) == 0 || r.bodies[len(r.bodies)-1] == nil {
+		return true
+	}
+	loop, label := stmt, ""
+	if l, ok := stmt.(*ast.LabeledStmt); ok {
+		loop, label = l.Stmt, l.Label.Name
+	}
+	switch loop.(type) {
+	case *ast.ForStmt, *ast.RangeStmt:
+	default:
+		return true
+	}
+	var prev ast.Stmt
+	if list := stmtList(c.Parent()); c.Index() > 0 && c.Index() <= len(list) {
+		prev = list[c.Index()-1]
+	}
+
+	// As duas análises olham o statement anterior, então vêm antes de inserir
+	var pre *loopPrealloc
+	if rs, ok := loop.(*ast.RangeStmt); ok {
+		pre = r.planPrealloc(rs, prev)
+	}
+	concats := r.planConcats(loop, label)
+
+	if pre != nil {
+		r.applyPrealloc(c, pre)
+	}
+	for _, cc := range concats {
+		r.applyConcat(c, loop, prev, cc)
+	}
+	return true
+}
+
+// stmtList returns the statement list holding the children of parent
+func stmtList(parent ast.Node) []ast.Stmt {
+	switch p := parent.(type) {
+	case *ast.BlockStmt:
+		return p.List
+	case *ast.CaseClause:
+		return p.Body
+	case *ast.CommClause:
+		return p.Body
+	}
+	return nil
+}
+
+// skip records a recognized pattern that was left alone
+func (r *loopAllocRewriter) skip(pos token.Pos, target, reason string) {
+	p := r.fset.Position(pos)
+	gl.Log("info", fmt.Sprintf("LoopAlloc: skipping %s at %s (%s)", target, p, reason))
+	r.ctx.RecordLedger(r.pass.Name(), p, target, "skipped", reason)
+}
+
+// === 1️⃣ s += x → strings.Builder ===
+
+// loopConcat is a string variable only appended to inside a loop
+type loopConcat struct {
+	v     *types.Var
+	stmts []*ast.AssignStmt
+}
+
+// planConcats returns the string variables of loop that can use a builder
+func (r *loopAllocRewriter) planConcats(loop ast.Stmt, label string) []*loopConcat {
+	var found []*loopConcat
+	byVar := make(map[*types.Var]*loopConcat)
+	ast.Inspect(loop, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		as, ok := n.(*ast.AssignStmt)
+		if !ok || as.Tok != token.ADD_ASSIGN || len(as.Lhs) != 1 {
+			return true
+		}
+		id, ok := as.Lhs[0].(*ast.Ident)
+		if !ok {
+			return true
+		}
+		v, ok := r.ctx.GetUses()[id].(*types.Var)
+		if !ok || !types.Identical(v.Type(), types.Typ[types.String]) {
+			return true
+		}
+		if byVar[v] == nil {
+			byVar[v] = &loopConcat{v: v}
+			found = append(found, byVar[v])
+		}
+		byVar[v].stmts = append(byVar[v].stmts, as)
+		return true
+	})
+	if len(found) == 0 {
+		return nil
+	}
+
+	exit := loopExit(loop, label)
+	var ok []*loopConcat
+	for _, cc := range found {
+		reason := exit
+		if reason == "" {
+			reason = r.concatReason(loop, cc)
+		}
+		if reason != "" {
+			r.skip(cc.stmts[0].Pos(), cc.v.Name()+" +=", reason)
+			continue
+		}
+		ok = append(ok, cc)
+	}
+	return ok
+}
+
+// loopExit explains how the body of loop can leave it without reaching the
+// statement after it, or returns ""
+func loopExit(loop ast.Stmt, label string) string {
+	inner := map[string]bool{}
+	if label != "" {
+		inner[label] = true
+	}
+	ast.Inspect(loop, func(n ast.Node) bool {
+		if l, ok := n.(*ast.LabeledStmt); ok {
+			inner[l.Label.Name] = true
+		}
+		return true
+	})
+	reason := ""
+	ast.Inspect(loop, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			reason = "return inside the loop"
+		case *ast.BranchStmt:
+			if n.Tok == token.GOTO {
+				reason = "goto inside the loop"
+			} else if n.Label != nil && !inner[n.Label.Name] {
+				reason = n.Tok.String() + " " + n.Label.Name + " leaves the loop"
+			}
+		}
+		return reason == ""
+	})
+	return reason
+}
+
+// concatReason checks every use of the variable in the enclosing function
+func (r *loopAllocRewriter) concatReason(loop ast.Stmt, cc *loopConcat) string {
+	body := r.bodies[len(r.bodies)-1]
+	if cc.v.Pos() <= body.Lbrace || cc.v.Pos() >= body.Rbrace {
+		return "not a local variable"
+	}
+	if cc.v.Pos() >= loop.Pos() {
+		return "declared inside the loop"
+	}
+	lhs := make(map[*ast.Ident]bool)
+	for _, as := range cc.stmts {
+		lhs[as.Lhs[0].(*ast.Ident)] = true
+	}
+	reason, reads := "", 0
+	var walk func(n ast.Node, inClosure bool)
+	walk = func(n ast.Node, inClosure bool) {
+		ast.Inspect(n, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				if !inClosure {
+					walk(n.Body, true)
+					return false
+				}
+			case *ast.UnaryExpr:
+				if id, ok := ast.Unparen(n.X).(*ast.Ident); ok && n.Op == token.AND && r.ctx.GetUses()[id] == cc.v {
+					reason = "address taken"
+				}
+			case *ast.AssignStmt:
+				if n.Tok == token.ASSIGN || n.Tok == token.DEFINE {
+					for _, e := range n.Lhs {
+						if id, ok := e.(*ast.Ident); ok && r.ctx.GetUses()[id] == cc.v && (n.Pos() < loop.Pos() || n.Pos() >= loop.End()) {
+							reads-- // escrita fora do loop, não é leitura
+						}
+					}
+				}
+			case *ast.Ident:
+				if r.ctx.GetUses()[n] != cc.v {
+					return true
+				}
+				switch {
+				case inClosure:
+					reason = "captured by a closure"
+				case n.Pos() >= loop.Pos() && n.Pos() < loop.End():
+					if !lhs[n] {
+						reason = "used in the loop other than +="
+					}
+				default:
+					reads++
+				}
+			}
+			return reason == ""
+		})
+	}
+	walk(body, false)
+	if reason == "" && reads <= 0 {
+		reason = "value never read after the loop"
+	}
+	return reason
+}
+
+// applyConcat routes the += statements of the loop to a builder
+func (r *loopAllocRewriter) applyConcat(c *stdastutil.Cursor, loop ast.Stmt, prev ast.Stmt, cc *loopConcat) {
+	if r.stringsName == "" {
+		r.stringsName = importName(r.fset, r.file, "strings")
+	}
+	name := freshLocalName(r.file, cc.v.Name()+"Builder")
+	bv := types.NewVar(loop.Pos(), r.ctx.Package, name, builderType(r.ctx))
+	ident := func() *ast.Ident {
+		id := ast.NewIdent(name)
+		r.ctx.GetUses()[id] = bv
+		return id
+	}
+	varIdent := func() *ast.Ident {
+		id := ast.NewIdent(cc.v.Name())
+		r.ctx.GetUses()[id] = cc.v
+		return id
+	}
+
+	def := ast.NewIdent(name)
+	r.ctx.GetDefs()[def] = bv
+	c.InsertBefore(&ast.DeclStmt{Decl: &ast.GenDecl{Tok: token.VAR, Specs: []ast.Spec{&ast.ValueSpec{
+		Names: []*ast.Ident{def},
+		Type:  &ast.SelectorExpr{X: ast.NewIdent(r.stringsName), Sel: ast.NewIdent("Builder")},
+	}}}})
+	if !r.zeroString(prev, cc.v) {
+		c.InsertBefore(&ast.ExprStmt{X: method(ident(), "WriteString", varIdent())})
+	}
+
+	rewrite := make(map[*ast.AssignStmt]bool)
+	for _, as := range cc.stmts {
+		rewrite[as] = true
+	}
+	stdastutil.Apply(loop, func(lc *stdastutil.Cursor) bool {
+		if as, ok := lc.Node().(*ast.AssignStmt); ok && rewrite[as] {
+			lc.Replace(&ast.ExprStmt{X: method(ident(), "WriteString", as.Rhs[0])})
+		}
+		return true
+	}, nil)
+	c.InsertAfter(&ast.AssignStmt{Lhs: []ast.Expr{varIdent()}, Tok: token.ASSIGN, Rhs: []ast.Expr{method(ident(), "String")}})
+
+	r.builders++
+	r.ctx.RecordLedger(r.pass.Name(), r.fset.Position(cc.stmts[0].Pos()), cc.v.Name()+" +=", "rewritten",
+		fmt.Sprintf("strings.Builder %s (%d += in the loop)", name, len(cc.stmts)))
+}
+
+// zeroString reports whether stmt sets v to "" (s := "", var s string)
+func (r *loopAllocRewriter) zeroString(stmt ast.Stmt, v *types.Var) bool {
+	isEmpty := func(e ast.Expr) bool {
+		tv, ok := r.ctx.GetTypes()[e]
+		return ok && tv.Value != nil && tv.Value.Kind() == constant.String && constant.StringVal(tv.Value) == ""
+	}
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		if len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+			return false
+		}
+		id, ok := s.Lhs[0].(*ast.Ident)
+		return ok && (r.ctx.GetDefs()[id] == v || r.ctx.GetUses()[id] == v) && isEmpty(s.Rhs[0])
+	case *ast.DeclStmt:
+		gd, ok := s.Decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.VAR || len(gd.Specs) != 1 {
+			return false
+		}
+		vs := gd.Specs[0].(*ast.ValueSpec)
+		if len(vs.Names) != 1 || r.ctx.GetDefs()[vs.Names[0]] != v {
+			return false
+		}
+		return len(vs.Values) == 0 || isEmpty(vs.Values[0])
+	}
+	return false
+}
+
+// builderType returns strings.Builder when the package imports strings
+func builderType(ctx *astutil.TranspileContext) types.Type {
+	for _, imp := range ctx.Package.Imports() {
+		if imp.Path() == "strings" {
+			if obj := imp.Scope().Lookup("Builder"); obj != nil {
+				return obj.Type()
+			}
+		}
+	}
+	return types.Typ[types.Invalid]
+}
+
+// === 2️⃣ append em range → make(T, 0, len(src)) ===
+
+// loopPrealloc is a slice declared right before a range loop appending to it
+type loopPrealloc struct {
+	v      *types.Var
+	loop   *ast.RangeStmt
+	typ    ast.Expr // tipo do make
+	value  *ast.Expr
+	isNil  bool
+	per    int // elementos por append
+	target string
+}
+
+// planPrealloc matches prev against `var out T`, `out := T{}` or
+// `out := make(T, 0)` followed by a single `out = append(out, ...)` in loop
+func (r *loopAllocRewriter) planPrealloc(loop *ast.RangeStmt, prev ast.Stmt) *loopPrealloc {
+	pl := r.matchSliceDecl(prev)
+	if pl == nil {
+		return nil
+	}
+	pl.loop = loop
+
+	var appends []*ast.AssignStmt
+	var nested []bool
+	depth := 0
+	var stack []ast.Node
+	reason := ""
+	ast.Inspect(loop.Body, func(n ast.Node) bool {
+		if n == nil {
+			switch stack[len(stack)-1].(type) {
+			case *ast.ForStmt, *ast.RangeStmt, *ast.FuncLit:
+				depth--
+			}
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+		switch n := n.(type) {
+		case *ast.ForStmt, *ast.RangeStmt, *ast.FuncLit:
+			depth++
+		case *ast.UnaryExpr:
+			if id, ok := ast.Unparen(n.X).(*ast.Ident); ok && n.Op == token.AND && r.ctx.GetUses()[id] == pl.v {
+				reason = "address taken in the loop"
+			}
+		case *ast.AssignStmt:
+			for _, e := range n.Lhs {
+				id, ok := e.(*ast.Ident)
+				if !ok || r.ctx.GetUses()[id] != pl.v {
+					continue
+				}
+				if r.isSelfAppend(n, pl.v) {
+					appends = append(appends, n)
+					nested = append(nested, depth > 0)
+				} else {
+					reason = "assigned other than by append in the loop"
+				}
+			}
+		}
+		return true
+	})
+	if len(appends) == 0 {
+		return nil
+	}
+
+	t := r.ctx.GetTypes()[loop.X].Type
+	switch {
+	case reason != "":
+	case len(appends) > 1:
+		reason = "several appends in the loop"
+	case nested[0]:
+		reason = "append inside a nested loop or closure"
+	case t == nil || !hasKnownLen(t):
+		reason = "range is not over a slice, array or map"
+	case !isPathExpr(loop.X, r.ctx) || mentions(loop.X, pl.v, r.ctx):
+		reason = "range expression is not a plain variable or field"
+	case pl.isNil && !r.unconditional(loop.Body, appends[0]):
+		reason = "conditional append into a nil slice (would no longer be nil)"
+	}
+	if reason != "" {
+		r.skip(appends[0].Pos(), pl.target, reason)
+		return nil
+	}
+	pl.per = len(appends[0].Rhs[0].(*ast.CallExpr).Args) - 1
+	return pl
+}
+
+// matchSliceDecl recognizes the declaration of an empty or nil slice
+func (r *loopAllocRewriter) matchSliceDecl(stmt ast.Stmt) *loopPrealloc {
+	var id *ast.Ident
+	var value *ast.Expr
+	var typ ast.Expr
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		if s.Tok != token.DEFINE || len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+			return nil
+		}
+		id, _ = s.Lhs[0].(*ast.Ident)
+		value = &s.Rhs[0]
+	case *ast.DeclStmt:
+		gd, ok := s.Decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.VAR || len(gd.Specs) != 1 {
+			return nil
+		}
+		vs := gd.Specs[0].(*ast.ValueSpec)
+		if len(vs.Names) != 1 || len(vs.Values) > 1 {
+			return nil
+		}
+		id, typ = vs.Names[0], vs.Type
+		if len(vs.Values) == 1 {
+			value = &vs.Values[0]
+		}
+	}
+	if id == nil {
+		return nil
+	}
+	v, ok := r.ctx.GetDefs()[id].(*types.Var)
+	if !ok {
+		return nil
+	}
+	if _, ok := v.Type().Underlying().(*types.Slice); !ok {
+		return nil
+	}
+	pl := &loopPrealloc{v: v, target: id.Name}
+	if value == nil {
+		pl.isNil, pl.typ = true, typ
+		return pl
+	}
+	switch e := ast.Unparen(*value).(type) {
+	case *ast.CompositeLit:
+		if len(e.Elts) != 0 || e.Type == nil {
+			return nil
+		}
+		pl.typ = e.Type
+	case *ast.CallExpr:
+		zero := func(e ast.Expr) bool {
+			tv := r.ctx.GetTypes()[e]
+			return tv.Value != nil && tv.Value.Kind() == constant.Int && constant.Sign(tv.Value) == 0
+		}
+		if !isBuiltinCall(e, "make", r.ctx) || len(e.Args) < 2 || !zero(e.Args[1]) || len(e.Args) == 3 && !zero(e.Args[2]) {
+			return nil
+		}
+		pl.typ = e.Args[0]
+	default:
+		return nil
+	}
+	pl.value = value
+	return pl
+}
+
+// isSelfAppend matches v = append(v, x, ...) without a spread
+func (r *loopAllocRewriter) isSelfAppend(as *ast.AssignStmt, v *types.Var) bool {
+	if as.Tok != token.ASSIGN || len(as.Lhs) != 1 || len(as.Rhs) != 1 {
+		return false
+	}
+	call, ok := ast.Unparen(as.Rhs[0]).(*ast.CallExpr)
+	if !ok || !isBuiltinCall(call, "append", r.ctx) || call.Ellipsis.IsValid() || len(call.Args) < 2 {
+		return false
+	}
+	first, ok := ast.Unparen(call.Args[0]).(*ast.Ident)
+	return ok && r.ctx.GetUses()[first] == v
+}
+
+// unconditional reports whether every iteration runs stmt: it is a
+// top-level statement of body and nothing before it can skip it
+func (r *loopAllocRewriter) unconditional(body *ast.BlockStmt, stmt ast.Stmt) bool {
+	for _, s := range body.List {
+		if s == stmt {
+			return true
+		}
+		jumps := false
+		ast.Inspect(s, func(n ast.Node) bool {
+			switch n.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.ReturnStmt, *ast.BranchStmt:
+				jumps = true
+			}
+			return !jumps
+		})
+		if jumps {
+			return false
+		}
+	}
+	return false
+}
+
+// hasKnownLen reports whether ranging over t runs len(x) iterations
+func hasKnownLen(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Slice, *types.Array, *types.Map:
+		return true
+	case *types.Pointer:
+		_, ok := u.Elem().Underlying().(*types.Array)
+		return ok
+	}
+	return false
+}
+
+// isPathExpr accepts variables, fields and dereferences, which can be
+// evaluated again without side effects or extra allocations
+func isPathExpr(e ast.Expr, ctx *astutil.TranspileContext) bool {
+	switch e := e.(type) {
+	case *ast.Ident:
+		_, ok := ctx.GetUses()[e].(*types.Var)
+		return ok
+	case *ast.ParenExpr:
+		return isPathExpr(e.X, ctx)
+	case *ast.StarExpr:
+		return isPathExpr(e.X, ctx)
+	case *ast.SelectorExpr:
+		if sel := ctx.GetSelections()[e]; sel != nil {
+			return sel.Kind() == types.FieldVal && isPathExpr(e.X, ctx)
+		}
+		_, ok := ctx.GetUses()[e.Sel].(*types.Var) // variável de outro pacote
+		return ok
+	}
+	return false
+}
+
+// mentions reports whether e refers to v
+func mentions(e ast.Expr, v *types.Var, ctx *astutil.TranspileContext) bool {
+	found := false
+	ast.Inspect(e, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && ctx.GetUses()[id] == v {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// applyPrealloc sizes the slice declaration from the range expression
+func (r *loopAllocRewriter) applyPrealloc(c *stdastutil.Cursor, pl *loopPrealloc) {
+	size := r.call("len", r.clone(pl.loop.X))
+	var capExpr ast.Expr = size
+	if pl.per > 1 {
+		capExpr = &ast.BinaryExpr{X: &ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(pl.per)}, Op: token.MUL, Y: size}
+	}
+
+	if !pl.isNil {
+		mk := r.call("make", pl.typ, &ast.BasicLit{Kind: token.INT, Value: "0"}, capExpr)
+		*pl.value = mk
+		r.record(pl, mk)
+		return
+	}
+	typ := r.clone(pl.typ)
+	if typ == nil {
+		r.skip(pl.loop.Pos(), pl.target, "slice type too complex to repeat")
+		return
+	}
+	mk := r.call("make", typ, &ast.BasicLit{Kind: token.INT, Value: "0"}, capExpr)
+	id := ast.NewIdent(pl.v.Name())
+	r.ctx.GetUses()[id] = pl.v
+	c.InsertBefore(&ast.IfStmt{
+		Cond: &ast.BinaryExpr{X: r.call("len", r.clone(pl.loop.X)), Op: token.GTR, Y: &ast.BasicLit{Kind: token.INT, Value: "0"}},
+		Body: &ast.BlockStmt{List: []ast.Stmt{&ast.AssignStmt{Lhs: []ast.Expr{id}, Tok: token.ASSIGN, Rhs: []ast.Expr{mk}}}},
+	})
+	r.record(pl, mk)
+}
+
+func (r *loopAllocRewriter) record(pl *loopPrealloc, mk ast.Expr) {
+	r.prealloc++
+	detail := types.ExprString(mk)
+	if pl.isNil {
+		detail += " when the range is not empty"
+	}
+	r.ctx.RecordLedger(r.pass.Name(), r.fset.Position(pl.loop.Pos()), pl.target, "rewritten", detail)
+}
+
+// call builds a call to a builtin, bound to its universe object
+func (r *loopAllocRewriter) call(builtin string, args ...ast.Expr) *ast.CallExpr {
+	fun := ast.NewIdent(builtin)
+	r.ctx.GetUses()[fun] = types.Universe.Lookup(builtin)
+	return &ast.CallExpr{Fun: fun, Args: args}
+}
+
+// clone copies a path or type expression, carrying over its type
+// information so later passes (Rename) still follow it; nil when e has
+// another shape
+func (r *loopAllocRewriter) clone(e ast.Expr) ast.Expr {
+	var out ast.Expr
+	switch node := e.(type) {
+	case nil:
+		return nil
+	case *ast.Ident:
+		id := &ast.Ident{Name: node.Name}
+		if obj := r.ctx.GetUses()[node]; obj != nil {
+			r.ctx.GetUses()[id] = obj
+		}
+		out = id
+	case *ast.BasicLit:
+		out = &ast.BasicLit{Kind: node.Kind, Value: node.Value}
+	case *ast.ParenExpr:
+		x := r.clone(node.X)
+		if x == nil {
+			return nil
+		}
+		out = &ast.ParenExpr{X: x}
+	case *ast.StarExpr:
+		x := r.clone(node.X)
+		if x == nil {
+			return nil
+		}
+		out = &ast.StarExpr{X: x}
+	case *ast.SelectorExpr:
+		x := r.clone(node.X)
+		if x == nil {
+			return nil
+		}
+		sel := &ast.SelectorExpr{X: x, Sel: r.clone(node.Sel).(*ast.Ident)}
+		if s := r.ctx.GetSelections()[node]; s != nil {
+			r.ctx.GetSelections()[sel] = s
+		}
+		out = sel
+	case *ast.ArrayType:
+		elt := r.clone(node.Elt)
+		if elt == nil || node.Len != nil {
+			return nil
+		}
+		out = &ast.ArrayType{Elt: elt}
+	case *ast.MapType:
+		k, v := r.clone(node.Key), r.clone(node.Value)
+		if k == nil || v == nil {
+			return nil
+		}
+		out = &ast.MapType{Key: k, Value: v}
+	default:
+		return nil
+	}
+	if tv, ok := r.ctx.GetTypes()[e]; ok {
+		r.ctx.GetTypes()[out] = tv
+	}
+	return out
+}
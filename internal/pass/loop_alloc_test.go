@@ -0,0 +1,97 @@
+package pass
+
+import (
+	"strings"
+	"testing"
+)
+
+const loopAllocProbe = `package main
+
+import "fmt"
+
+func join(ws []string) string {
+	s := ""
+	for _, w := range ws {
+		s += w
+		s += ","
+	}
+	return s
+}
+
+func doubled(xs []int) []int {
+	out := []int{}
+	for _, x := range xs {
+		out = append(out, x*2)
+	}
+	return out
+}
+
+// O append é condicional: com range vazio (ou nenhum par) out continua nil
+func evens(xs []int) []int {
+	var out []int
+	for _, x := range xs {
+		if x%2 == 0 {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+// Lida dentro do loop
+func prefixes(ws []string) []string {
+	s := ""
+	var all []string
+	for _, w := range ws {
+		s += w
+		all = append(all, s)
+	}
+	return all
+}
+
+// Sai do loop antes da atribuição final
+func until(ws []string) string {
+	s := ""
+	for _, w := range ws {
+		if w == "stop" {
+			return s
+		}
+		s += w
+	}
+	return s
+}
+
+// Capturada por uma closure
+func captured(ws []string) string {
+	s := ""
+	show := func() string { return "[" + s + "]" }
+	seen := 0
+	for _, w := range ws {
+		s += w
+		seen += len(show())
+	}
+	return fmt.Sprint(show(), seen)
+}
+
+func main() {
+	ws := []string{"a", "b", "stop", "c"}
+	fmt.Println(join(ws), join(nil) == "")
+	fmt.Println(doubled([]int{1, 2, 3}), doubled(nil) == nil)
+	fmt.Println(evens([]int{1, 3}) == nil, evens(nil) == nil, evens([]int{1, 2, 4}))
+	fmt.Println(prefixes(ws), until(ws), captured(ws))
+}
+`
+
+func TestLoopAllocPreservesBehavior(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, loopAllocProbe)
+	out, ctx := transpileSource(t, loopAllocProbe, false, NewLoopAllocPass())
+	if !strings.Contains(out, "strings.Builder") {
+		t.Errorf("join not rewritten\n%s", out)
+	}
+	if got := countLedger(ctx, "LoopAlloc", "skipped"); got != 4 {
+		t.Errorf("%d loops skipped, want 4 (evens, prefixes, until and captured)\n%+v", got, ctx.Ledger)
+	}
+	if got := runSource(t, out); got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+}
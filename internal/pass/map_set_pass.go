@@ -0,0 +1,827 @@
+package pass
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode"
+
+	stdastutil "golang.org/x/tools/go/ast/astutil"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// MapSetPass troca sets map[string]bool de chaves constantes por um bitset,
+// com um tipo flag gerado e uma constante por chave (o que control.FromLegacyMap
+// faz à mão para SecFlag).
+//
+//	perms := map[string]bool{"auth": true}  →  perms := permsSet_Auth
+//	perms["sanitize"] = true                →  perms |= permsSet_Sanitize
+//	if perms["auth"] {                      →  if (perms&permsSet_Auth != 0) {
+//	delete(perms, "auth")                   →  perms &^= permsSet_Auth
+//	n := len(perms)                         →  n := bits.OnesCount8(uint8(perms))
+//
+// Entram vars locais e vars de pacote não exportadas (ou de package main)
+// cujos usos são todos inserção, leitura, comma-ok, delete, clear, len e range
+// com chaves constantes, e que só recebem literais, make ou nil. O map não
+// pode sair da variável: passado, retornado, copiado ou comparado, fica como
+// está. Um map que guarda false tem presença e valor diferentes; ele só vira
+// bitset se ninguém observa a presença (comma-ok, len e range).
+type MapSetPass struct {
+	plans map[*types.Var]*mapSetPlan // var religada ao tipo gerado → plano
+	order []*mapSetPlan
+}
+
+// mapSetPlan describes one map variable and the bitset that replaces it
+type mapSetPlan struct {
+	obj      *types.Var // var original (tipo map)
+	name     string
+	pos      token.Position
+	file     *ast.File // arquivo da declaração: recebe o tipo gerado
+	keyType  types.Type
+	keys     []string          // universo fechado, na ordem em que aparece
+	consts   map[string]string // chave → constante gerada
+	typeName string
+	word     string // uint8, uint16, uint32 ou uint64
+	keysVar  string // tabela de chaves para range
+
+	pure     bool // só guarda true: presença == valor
+	presence bool // comma-ok, len ou range
+	ranged   bool
+	inserts  bool
+	made     bool // recebe literal ou make em algum ponto
+	reason   string
+}
+
+func NewMapSetPass() *MapSetPass   { return &MapSetPass{} }
+func (p *MapSetPass) Name() string { return "MapSet" }
+
+func (plan *mapSetPlan) reject(reason string) {
+	if plan.reason == "" {
+		plan.reason = reason
+	}
+}
+
+func (plan *mapSetPlan) store(key string, value bool) {
+	plan.addKey(key)
+	if !value {
+		plan.pure = false
+	}
+}
+
+func (plan *mapSetPlan) addKey(key string) {
+	for _, k := range plan.keys {
+		if k == key {
+			return
+		}
+	}
+	plan.keys = append(plan.keys, key)
+}
+
+// verdict returns why the plan cannot become a bitset, or ""
+func (plan *mapSetPlan) verdict(ctx *astutil.TranspileContext) string {
+	switch {
+	case plan.reason != "":
+		return plan.reason
+	case len(plan.keys) == 0:
+		return "no constant keys"
+	case len(plan.keys) > 64:
+		return fmt.Sprintf("%d keys do not fit in 64 bits", len(plan.keys))
+	case !plan.pure && plan.presence:
+		return "stores false values and observes presence (comma-ok, len or range)"
+	case plan.inserts && !plan.made:
+		return "nil map written"
+	case plan.ranged && !packageLevelType(plan.keyType, ctx):
+		return "key type " + plan.keyType.String() + " not expressible at package level"
+	}
+	return ""
+}
+
+// Prepare finds the map sets of the package, checks every use and binds the
+// accepted variables to their generated flag types
+func (p *MapSetPass) Prepare(files []*ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	p.plans = make(map[*types.Var]*mapSetPlan)
+	p.order = nil
+	if ctx.Package == nil {
+		return nil
+	}
+	a := &mapSetAnalysis{
+		fset: fset, ctx: ctx,
+		plans:     make(map[*types.Var]*mapSetPlan),
+		accounted: make(map[*ast.Ident]bool),
+		special:   make(map[*ast.IndexExpr]bool),
+	}
+
+	// === 1️⃣ Coleta as declarações candidatas ===
+	for _, file := range files {
+		a.collect(file)
+	}
+	if len(a.order) == 0 {
+		return nil
+	}
+
+	// === 2️⃣ Classifica cada uso ===
+	for _, file := range files {
+		a.scan(file)
+	}
+
+	// === 3️⃣ Decide, nomeia e religa as vars aos tipos gerados ===
+	used := make(map[string]bool)
+	for _, file := range files {
+		collectIdentNames(file, used)
+	}
+	rebound := make(map[types.Object]*types.Var)
+	for _, plan := range a.order {
+		if reason := plan.verdict(ctx); reason != "" {
+			gl.Log("info", fmt.Sprintf("MapSet: skipping %s (%s)", plan.name, reason))
+			ctx.RecordLedger(p.Name(), plan.pos, plan.name, "rejected", reason)
+			continue
+		}
+		p.allocate(plan, used)
+		// Passes seguintes (flatten, opaque) leem o tipo da var: ela passa a ser o bitset
+		named := p.declareTypes(plan, ctx)
+		v := types.NewVar(plan.obj.Pos(), ctx.Package, plan.obj.Name(), named)
+		rebound[plan.obj] = v
+		p.plans[v] = plan
+		p.order = append(p.order, plan)
+	}
+	for id, obj := range ctx.GetDefs() {
+		if v, ok := rebound[obj]; ok {
+			ctx.GetDefs()[id] = v
+		}
+	}
+	for id, obj := range ctx.GetUses() {
+		if v, ok := rebound[obj]; ok {
+			ctx.GetUses()[id] = v
+		}
+	}
+	return nil
+}
+
+// allocate picks the flag type, its constants and the key table names
+func (p *MapSetPass) allocate(plan *mapSetPlan, used map[string]bool) {
+	plan.word = astutil.MenorTipoParaFlags(len(plan.keys))
+	base := plan.name + "Set"
+	for i := 1; ; i++ {
+		typeName := base
+		if i > 1 {
+			typeName += strconv.Itoa(i)
+		}
+		consts := make(map[string]string, len(plan.keys))
+		names := []string{typeName, typeName + "_keys"}
+		seen := make(map[string]bool)
+		for j, key := range plan.keys {
+			suffix := mapSetKeyName(key)
+			if suffix == "" || seen[suffix] {
+				suffix = "Key" + strconv.Itoa(j)
+			}
+			seen[suffix] = true
+			consts[key] = typeName + "_" + suffix
+			names = append(names, consts[key])
+		}
+		free := true
+		for _, name := range names {
+			if used[name] || types.Universe.Lookup(name) != nil {
+				free = false
+				break
+			}
+		}
+		if !free {
+			continue
+		}
+		for _, name := range names {
+			used[name] = true
+		}
+		plan.typeName, plan.keysVar, plan.consts = typeName, typeName+"_keys", consts
+		return
+	}
+}
+
+// declareTypes adds the generated type and constants to the package scope
+func (p *MapSetPass) declareTypes(plan *mapSetPlan, ctx *astutil.TranspileContext) *types.Named {
+	words := map[string]types.Type{
+		"uint8": types.Typ[types.Uint8], "uint16": types.Typ[types.Uint16],
+		"uint32": types.Typ[types.Uint32], "uint64": types.Typ[types.Uint64],
+	}
+	tn := types.NewTypeName(token.NoPos, ctx.Package, plan.typeName, nil)
+	named := types.NewNamed(tn, words[plan.word], nil)
+	ctx.Package.Scope().Insert(tn)
+	for i, key := range plan.keys {
+		ctx.Package.Scope().Insert(types.NewConst(token.NoPos, ctx.Package, plan.consts[key], named, constant.MakeUint64(1<<i)))
+	}
+	return named
+}
+
+// mapSetKeyName turns a key into the suffix of its constant ("sanitize_body" → "SanitizeBody")
+func mapSetKeyName(key string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range key {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// mapSetAnalysis collects the candidate maps of a package and classifies their uses
+type mapSetAnalysis struct {
+	fset      *token.FileSet
+	ctx       *astutil.TranspileContext
+	plans     map[*types.Var]*mapSetPlan
+	order     []*mapSetPlan
+	accounted map[*ast.Ident]bool     // usos já classificados pelo nó pai
+	special   map[*ast.IndexExpr]bool // m[k] que não são leituras (alvo ou comma-ok)
+}
+
+// isMapSet reports whether t is a map with string keys and bool values
+func isMapSet(t types.Type) bool {
+	m, ok := t.Underlying().(*types.Map)
+	if !ok {
+		return false
+	}
+	key, ok := m.Key().Underlying().(*types.Basic)
+	return ok && key.Info()&types.IsString != 0 && types.Identical(m.Elem(), types.Typ[types.Bool])
+}
+
+// collect registers the map sets declared by := and var in file
+func (a *mapSetAnalysis) collect(file *ast.File) {
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			if node.Tok != token.DEFINE {
+				return true
+			}
+			for i, lhs := range node.Lhs {
+				plan := a.add(lhs, file)
+				if plan == nil {
+					continue
+				}
+				if len(node.Lhs) != len(node.Rhs) {
+					plan.reject("initialized from a multi-value expression")
+					continue
+				}
+				a.init(plan, node.Rhs[i])
+			}
+		case *ast.ValueSpec:
+			for i, name := range node.Names {
+				plan := a.add(name, file)
+				switch {
+				case plan == nil:
+				case len(node.Names) > 1:
+					plan.reject("declared together with other variables")
+				case len(node.Values) > 0:
+					a.init(plan, node.Values[i])
+				}
+			}
+		}
+		return true
+	})
+}
+
+// add creates the plan of the map set defined by e, if it is one
+func (a *mapSetAnalysis) add(e ast.Expr, file *ast.File) *mapSetPlan {
+	id, ok := e.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	v, ok := a.ctx.GetDefs()[id].(*types.Var)
+	if !ok || v.IsField() || !isMapSet(v.Type()) {
+		return nil
+	}
+	plan := &mapSetPlan{
+		obj: v, name: id.Name, pos: a.fset.Position(id.Pos()), file: file,
+		keyType: v.Type().Underlying().(*types.Map).Key(),
+		pure:    true,
+	}
+	if v.Parent() == a.ctx.Package.Scope() && v.Exported() && a.ctx.Package.Name() != "main" {
+		plan.reject("exported outside package main")
+	}
+	a.plans[v] = plan
+	a.order = append(a.order, plan)
+	return plan
+}
+
+// init checks a value assigned to a map set: literal, make or nil
+func (a *mapSetAnalysis) init(plan *mapSetPlan, e ast.Expr) {
+	switch v := ast.Unparen(e).(type) {
+	case *ast.CompositeLit:
+		for _, elt := range v.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				plan.reject("malformed map literal")
+				return
+			}
+			key, ok := a.key(plan, kv.Key)
+			if !ok {
+				return
+			}
+			value, ok := boolConst(kv.Value, a.ctx)
+			if !ok {
+				plan.reject("non-constant value in literal")
+				return
+			}
+			plan.store(key, value)
+		}
+		plan.made = true
+		return
+	case *ast.CallExpr:
+		if isBuiltinCall(v, "make", a.ctx) && (len(v.Args) < 2 || astutil.IsSideEffectFree(v.Args[1], a.ctx.Info)) {
+			plan.made = true
+			return
+		}
+	case *ast.Ident:
+		if _, ok := a.ctx.GetUses()[v].(*types.Nil); ok {
+			return
+		}
+	}
+	plan.reject("initialized from " + types.ExprString(e))
+}
+
+// key returns the constant key of an index, rejecting the plan otherwise
+func (a *mapSetAnalysis) key(plan *mapSetPlan, e ast.Expr) (string, bool) {
+	if tv, ok := a.ctx.GetTypes()[e]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		key := constant.StringVal(tv.Value)
+		plan.addKey(key)
+		return key, true
+	}
+	plan.reject("non-constant key " + types.ExprString(e))
+	return "", false
+}
+
+// planOf returns the plan of the map set named by e, and its identifier
+func (a *mapSetAnalysis) planOf(e ast.Expr) (*mapSetPlan, *ast.Ident) {
+	id, ok := ast.Unparen(e).(*ast.Ident)
+	if !ok {
+		return nil, nil
+	}
+	v, ok := a.ctx.GetUses()[id].(*types.Var)
+	if !ok {
+		return nil, nil
+	}
+	return a.plans[v], id
+}
+
+// scan classifies the uses of the map sets in file
+func (a *mapSetAnalysis) scan(file *ast.File) {
+	stdastutil.Apply(file, func(c *stdastutil.Cursor) bool {
+		switch node := c.Node().(type) {
+		case *ast.AssignStmt:
+			a.assign(node, c)
+		case *ast.ExprStmt:
+			call, ok := node.X.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			plan, id := a.planOf(call.Args[0])
+			if plan == nil {
+				return true
+			}
+			switch {
+			case isBuiltinCall(call, "delete", a.ctx) && len(call.Args) == 2:
+				a.accounted[id] = true
+				a.key(plan, call.Args[1])
+			case isBuiltinCall(call, "clear", a.ctx):
+				a.accounted[id] = true
+			}
+		case *ast.CallExpr:
+			if !isBuiltinCall(node, "len", a.ctx) || len(node.Args) != 1 {
+				return true
+			}
+			if plan, id := a.planOf(node.Args[0]); plan != nil {
+				a.accounted[id] = true
+				plan.presence = true
+			}
+		case *ast.RangeStmt:
+			plan, id := a.planOf(node.X)
+			if plan == nil {
+				return true
+			}
+			a.accounted[id] = true
+			plan.presence, plan.ranged = true, true
+			if node.Tok != token.DEFINE && (node.Key != nil || node.Value != nil) {
+				plan.reject("range assigns existing variables")
+			}
+		case *ast.IndexExpr:
+			plan, id := a.planOf(node.X)
+			if plan == nil || a.special[node] {
+				return true
+			}
+			a.accounted[id] = true
+			if vs, ok := c.Parent().(*ast.ValueSpec); ok && len(vs.Names) == 2 {
+				plan.reject("comma-ok in a var declaration")
+			}
+			a.key(plan, node.Index)
+		case *ast.Ident:
+			if v, ok := a.ctx.GetUses()[node].(*types.Var); ok && a.plans[v] != nil && !a.accounted[node] {
+				a.plans[v].reject(mapSetEscape(c))
+			}
+		}
+		return true
+	}, nil)
+}
+
+// assign classifies inserts (m[k] = v), comma-ok lookups and reassignments
+func (a *mapSetAnalysis) assign(as *ast.AssignStmt, c *stdastutil.Cursor) {
+	for _, lhs := range as.Lhs {
+		ix, ok := lhs.(*ast.IndexExpr)
+		if !ok {
+			continue
+		}
+		plan, id := a.planOf(ix.X)
+		if plan == nil {
+			continue
+		}
+		a.special[ix], a.accounted[id] = true, true
+		if len(as.Lhs) != 1 || len(as.Rhs) != 1 || as.Tok != token.ASSIGN {
+			plan.reject("multi-value assignment")
+			continue
+		}
+		key, ok := a.key(plan, ix.Index)
+		if !ok {
+			continue
+		}
+		plan.inserts = true
+		if value, ok := boolConst(as.Rhs[0], a.ctx); ok {
+			plan.store(key, value)
+			continue
+		}
+		plan.pure = false
+		// m[k] = v vira if v { set } else { clear }: precisa de uma lista de statements
+		if c.Index() < 0 {
+			plan.reject("computed insert outside a statement list")
+		}
+	}
+
+	if len(as.Lhs) == 2 && len(as.Rhs) == 1 {
+		if ix, ok := as.Rhs[0].(*ast.IndexExpr); ok {
+			if plan, id := a.planOf(ix.X); plan != nil {
+				a.special[ix], a.accounted[id] = true, true
+				plan.presence = true
+				a.key(plan, ix.Index)
+			}
+		}
+	}
+
+	for i, lhs := range as.Lhs {
+		plan, id := a.planOf(lhs)
+		if plan == nil || id != lhs {
+			continue
+		}
+		a.accounted[id] = true
+		if len(as.Lhs) != len(as.Rhs) || as.Tok != token.ASSIGN && as.Tok != token.DEFINE {
+			plan.reject("assigned from a multi-value expression")
+			continue
+		}
+		a.init(plan, as.Rhs[i])
+	}
+}
+
+// mapSetEscape describes a use that lets the map leave its variable
+func mapSetEscape(c *stdastutil.Cursor) string {
+	switch parent := c.Parent().(type) {
+	case *ast.CallExpr:
+		return "passed to " + types.ExprString(parent.Fun)
+	case *ast.ReturnStmt:
+		return "returned"
+	case *ast.UnaryExpr:
+		if parent.Op == token.AND {
+			return "address taken"
+		}
+	case *ast.BinaryExpr:
+		if isNilIdent(parent.X) || isNilIdent(parent.Y) {
+			return "compared with nil"
+		}
+	case *ast.AssignStmt, *ast.ValueSpec:
+		return "copied to another variable"
+	case *ast.CompositeLit, *ast.KeyValueExpr:
+		return "stored in a composite literal"
+	case *ast.SendStmt:
+		return "sent on a channel"
+	}
+	return "used as a value"
+}
+
+// isBuiltinCall reports whether call calls the builtin name
+func isBuiltinCall(call *ast.CallExpr, name string, ctx *astutil.TranspileContext) bool {
+	id, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok || id.Name != name {
+		return false
+	}
+	_, builtin := ctx.GetUses()[id].(*types.Builtin)
+	return builtin
+}
+
+func (p *MapSetPass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	if len(p.plans) == 0 {
+		return nil
+	}
+	r := &mapSetRewriter{pass: p, file: file, fset: fset, ctx: ctx}
+	stdastutil.Apply(file, nil, r.rewrite)
+
+	for _, plan := range p.order {
+		if plan.file != file {
+			continue
+		}
+		decls, err := astutil.ParseDecls(fset, p.declarations(plan, file, ctx))
+		if err != nil {
+			return fmt.Errorf("MapSet: %w", err)
+		}
+		file.Decls = append(file.Decls, decls...)
+		ctx.RecordLedger(p.Name(), plan.pos, plan.name, "rewritten",
+			fmt.Sprintf("%d keys → %s bitset %s", len(plan.keys), plan.word, plan.typeName))
+	}
+
+	if r.count > 0 {
+		ctx.LogVerbose(fset, "🧮 MapSetPass: %d transformations applied", r.count)
+	}
+	return nil
+}
+
+// declarations renders the flag type, one constant per key and, for range, the key table
+func (p *MapSetPass) declarations(plan *mapSetPlan, file *ast.File, ctx *astutil.TranspileContext) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "type %s %s\n\nconst (\n", plan.typeName, plan.word)
+	for i, key := range plan.keys {
+		if i == 0 {
+			fmt.Fprintf(&b, "\t%s %s = 1 << iota\n", plan.consts[key], plan.typeName)
+		} else {
+			fmt.Fprintf(&b, "\t%s\n", plan.consts[key])
+		}
+	}
+	b.WriteString(")\n")
+	if plan.ranged {
+		keys := make([]string, len(plan.keys))
+		for i, key := range plan.keys {
+			keys[i] = strconv.Quote(key)
+		}
+		fmt.Fprintf(&b, "\nvar %s = [...]%s{%s}\n", plan.keysVar,
+			types.ExprString(typeExprFor(plan.keyType, file, ctx)), strings.Join(keys, ", "))
+	}
+	return b.String()
+}
+
+// mapSetRewriter rewrites the uses of the accepted map sets in one file
+type mapSetRewriter struct {
+	pass     *MapSetPass
+	file     *ast.File
+	fset     *token.FileSet
+	ctx      *astutil.TranspileContext
+	bitsName string // nome do import de math/bits neste arquivo
+	count    int
+}
+
+// planOf returns the plan of the variable named by e
+func (r *mapSetRewriter) planOf(e ast.Expr) *mapSetPlan {
+	id, ok := ast.Unparen(e).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	obj := r.ctx.GetUses()[id]
+	if obj == nil {
+		obj = r.ctx.GetDefs()[id]
+	}
+	v, ok := obj.(*types.Var)
+	if !ok {
+		return nil
+	}
+	return r.pass.plans[v]
+}
+
+// bit returns the constant of the key indexed by e
+func (r *mapSetRewriter) bit(plan *mapSetPlan, e ast.Expr) *ast.Ident {
+	return ast.NewIdent(plan.consts[constant.StringVal(r.ctx.GetTypes()[e].Value)])
+}
+
+// clone returns a new identifier for the map set, bound to the same object
+func (r *mapSetRewriter) clone(e ast.Expr) *ast.Ident {
+	id := ast.Unparen(e).(*ast.Ident)
+	c := ast.NewIdent(id.Name)
+	r.ctx.GetUses()[c] = r.ctx.GetUses()[id]
+	return c
+}
+
+// test renders m&bit != 0
+func (r *mapSetRewriter) test(m ast.Expr, bit ast.Expr) ast.Expr {
+	return &ast.BinaryExpr{
+		X:  &ast.BinaryExpr{X: m, Op: token.AND, Y: bit},
+		Op: token.NEQ,
+		Y:  &ast.BasicLit{Kind: token.INT, Value: "0"},
+	}
+}
+
+// mask renders the value of a literal, make or nil as the OR of its true keys
+func (r *mapSetRewriter) mask(plan *mapSetPlan, e ast.Expr) ast.Expr {
+	var out ast.Expr
+	if lit, ok := ast.Unparen(e).(*ast.CompositeLit); ok {
+		for _, elt := range lit.Elts {
+			kv := elt.(*ast.KeyValueExpr)
+			if value, _ := boolConst(kv.Value, r.ctx); !value {
+				continue
+			}
+			bit := r.bit(plan, kv.Key)
+			if out == nil {
+				out = bit
+			} else {
+				out = &ast.BinaryExpr{X: out, Op: token.OR, Y: bit}
+			}
+		}
+	}
+	if out == nil {
+		out = r.zero(plan)
+	}
+	return out
+}
+
+func isZeroConversion(e ast.Expr) bool {
+	call, ok := e.(*ast.CallExpr)
+	return ok && len(call.Args) == 1 && isZeroLit(call.Args[0])
+}
+
+func (r *mapSetRewriter) zero(plan *mapSetPlan) ast.Expr {
+	return &ast.CallExpr{Fun: ast.NewIdent(plan.typeName), Args: []ast.Expr{&ast.BasicLit{Kind: token.INT, Value: "0"}}}
+}
+
+// rewrite runs after the children of each node were rewritten
+func (r *mapSetRewriter) rewrite(c *stdastutil.Cursor) bool {
+	switch node := c.Node().(type) {
+	case *ast.IndexExpr:
+		plan := r.planOf(node.X)
+		if plan == nil {
+			return true
+		}
+		if as, ok := c.Parent().(*ast.AssignStmt); ok && (c.Name() == "Lhs" || len(as.Lhs) == 2 && len(as.Rhs) == 1) {
+			return true
+		}
+		c.Replace(&ast.ParenExpr{X: r.test(node.X, r.bit(plan, node.Index))})
+		r.count++
+
+	case *ast.AssignStmt:
+		r.assign(node, c)
+
+	case *ast.ValueSpec:
+		if len(node.Names) != 1 {
+			return true
+		}
+		plan := r.planOf(node.Names[0])
+		if plan == nil {
+			return true
+		}
+		node.Type = ast.NewIdent(plan.typeName)
+		if len(node.Values) == 1 {
+			if mask := r.mask(plan, node.Values[0]); isZeroConversion(mask) {
+				node.Values = nil
+			} else {
+				node.Values[0] = mask
+			}
+		}
+		r.count++
+
+	case *ast.ExprStmt:
+		call, ok := node.X.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		plan := r.planOf(call.Args[0])
+		if plan == nil {
+			return true
+		}
+		if isBuiltinCall(call, "delete", r.ctx) {
+			c.Replace(&ast.AssignStmt{Lhs: []ast.Expr{call.Args[0]}, Tok: token.AND_NOT_ASSIGN, Rhs: []ast.Expr{r.bit(plan, call.Args[1])}})
+		} else {
+			c.Replace(&ast.AssignStmt{Lhs: []ast.Expr{call.Args[0]}, Tok: token.ASSIGN, Rhs: []ast.Expr{r.zero(plan)}})
+		}
+		r.count++
+
+	case *ast.CallExpr:
+		if !isBuiltinCall(node, "len", r.ctx) || len(node.Args) != 1 {
+			return true
+		}
+		plan := r.planOf(node.Args[0])
+		if plan == nil {
+			return true
+		}
+		width := strings.TrimPrefix(plan.word, "uint")
+		c.Replace(&ast.CallExpr{
+			Fun:  &ast.SelectorExpr{X: ast.NewIdent(r.bits()), Sel: ast.NewIdent("OnesCount" + width)},
+			Args: []ast.Expr{&ast.CallExpr{Fun: ast.NewIdent(plan.word), Args: node.Args}},
+		})
+		r.count++
+
+	case *ast.RangeStmt:
+		plan := r.planOf(node.X)
+		if plan == nil {
+			return true
+		}
+		c.Replace(r.rangeKeys(plan, node))
+		r.count++
+	}
+	return true
+}
+
+// assign rewrites inserts, comma-ok lookups and the values given to the map
+func (r *mapSetRewriter) assign(as *ast.AssignStmt, c *stdastutil.Cursor) {
+	if len(as.Lhs) == 1 && len(as.Rhs) == 1 {
+		if ix, ok := as.Lhs[0].(*ast.IndexExpr); ok {
+			if plan := r.planOf(ix.X); plan != nil {
+				bit := r.bit(plan, ix.Index)
+				set := &ast.AssignStmt{Lhs: []ast.Expr{ix.X}, Tok: token.OR_ASSIGN, Rhs: []ast.Expr{bit}}
+				unset := &ast.AssignStmt{Lhs: []ast.Expr{ix.X}, Tok: token.AND_NOT_ASSIGN, Rhs: []ast.Expr{bit}}
+				if value, ok := boolConst(as.Rhs[0], r.ctx); ok && value {
+					c.Replace(set)
+				} else if ok {
+					c.Replace(unset)
+				} else {
+					unset.Lhs[0], unset.Rhs[0] = r.clone(ix.X), ast.NewIdent(bit.Name)
+					c.Replace(&ast.IfStmt{
+						Cond: as.Rhs[0],
+						Body: &ast.BlockStmt{List: []ast.Stmt{set}},
+						Else: &ast.BlockStmt{List: []ast.Stmt{unset}},
+					})
+				}
+				r.count++
+				return
+			}
+		}
+	}
+
+	if len(as.Lhs) == 2 && len(as.Rhs) == 1 {
+		if ix, ok := as.Rhs[0].(*ast.IndexExpr); ok {
+			if plan := r.planOf(ix.X); plan != nil {
+				bit := r.bit(plan, ix.Index)
+				as.Rhs = []ast.Expr{r.test(ix.X, bit), r.test(r.clone(ix.X), ast.NewIdent(bit.Name))}
+				r.count++
+				return
+			}
+		}
+	}
+
+	for i, lhs := range as.Lhs {
+		if _, ok := lhs.(*ast.Ident); !ok || i >= len(as.Rhs) {
+			continue
+		}
+		if plan := r.planOf(lhs); plan != nil {
+			as.Rhs[i] = r.mask(plan, as.Rhs[i])
+			r.count++
+		}
+	}
+}
+
+// rangeKeys walks the key table, skipping the keys whose bit is clear
+//
+//	for k, v := range perms {  →  for bit, k := range permsSet_keys {
+//	                                   if perms&(permsSet(1)<<bit) == 0 { continue }
+//	                                   v := true
+func (r *mapSetRewriter) rangeKeys(plan *mapSetPlan, rs *ast.RangeStmt) ast.Stmt {
+	idx := freshLocalName(r.file, "bit")
+	skip := &ast.IfStmt{
+		Cond: &ast.BinaryExpr{
+			X: &ast.BinaryExpr{X: rs.X, Op: token.AND, Y: &ast.ParenExpr{X: &ast.BinaryExpr{
+				X:  &ast.CallExpr{Fun: ast.NewIdent(plan.typeName), Args: []ast.Expr{&ast.BasicLit{Kind: token.INT, Value: "1"}}},
+				Op: token.SHL,
+				Y:  ast.NewIdent(idx),
+			}}},
+			Op: token.EQL,
+			Y:  &ast.BasicLit{Kind: token.INT, Value: "0"},
+		},
+		Body: &ast.BlockStmt{List: []ast.Stmt{&ast.BranchStmt{Tok: token.CONTINUE}}},
+	}
+	body := []ast.Stmt{skip}
+	if v, ok := rs.Value.(*ast.Ident); ok && v.Name != "_" {
+		body = append(body, &ast.AssignStmt{Lhs: []ast.Expr{v}, Tok: token.DEFINE, Rhs: []ast.Expr{ast.NewIdent("true")}})
+	}
+	body = append(body, rs.Body.List...)
+
+	var key ast.Expr
+	if k, ok := rs.Key.(*ast.Ident); ok && k.Name != "_" {
+		key = k
+	}
+	return &ast.RangeStmt{
+		For:   rs.For,
+		Key:   ast.NewIdent(idx),
+		Value: key,
+		Tok:   token.DEFINE,
+		X:     ast.NewIdent(plan.keysVar),
+		Body:  &ast.BlockStmt{Lbrace: rs.Body.Lbrace, List: body, Rbrace: rs.Body.Rbrace},
+	}
+}
+
+// bits returns the name of math/bits in the file, importing it when needed
+func (r *mapSetRewriter) bits() string {
+	if r.bitsName == "" {
+		r.bitsName = importName(r.fset, r.file, "math/bits")
+	}
+	return r.bitsName
+}
@@ -0,0 +1,88 @@
+package pass
+
+import (
+	"strings"
+	"testing"
+)
+
+const mapSetProbe = `package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+var features = map[string]bool{"auth": true, "cache": true}
+
+func enabled(name string) bool { return name != "" }
+
+func main() {
+	features["trace"] = true
+	delete(features, "cache")
+	var names []string
+	for k := range features {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	fmt.Println(names, len(features), features["auth"], features["cache"])
+
+	// Guarda false, mas ninguém observa a presença: valor e bit coincidem
+	flags := map[string]bool{"a": false, "b": true}
+	flags["a"] = flags["b"]
+	flags["b"] = false
+	fmt.Println(flags["a"], flags["b"], flags["c"])
+
+	// Guarda false e pergunta pela presença
+	seen := map[string]bool{"x": false}
+	_, ok := seen["x"]
+	fmt.Println(ok, len(seen))
+
+	// Sai da variável
+	shared := map[string]bool{"y": true}
+	show(shared)
+
+	// Chave que não é constante
+	dynamic := make(map[string]bool)
+	for _, k := range []string{"p", "q"} {
+		if enabled(k) {
+			dynamic[k] = true
+		}
+	}
+	fmt.Println(dynamic["p"], dynamic["z"])
+}
+
+func show(m map[string]bool) { fmt.Println(len(m), m["y"]) }
+`
+
+func TestMapSetPreservesBehavior(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, mapSetProbe)
+	out, ctx := transpileSource(t, mapSetProbe, false, NewMapSetPass())
+	rejected := map[string]bool{}
+	for _, e := range ctx.Ledger {
+		if e.Pass != "MapSet" {
+			continue
+		}
+		switch e.Action {
+		case "rewritten":
+		case "rejected":
+			rejected[e.Target] = true
+		default:
+			t.Errorf("unexpected entry %+v", e)
+		}
+	}
+	for _, name := range []string{"seen", "shared", "dynamic"} {
+		if !rejected[name] {
+			t.Errorf("%s was not rejected\n%+v", name, ctx.Ledger)
+		}
+	}
+	if got := countLedger(ctx, "MapSet", "rewritten"); got != 2 {
+		t.Errorf("%d sets rewritten, want 2 (features and flags)\n%s", got, out)
+	}
+	if strings.Contains(out, `features["auth"]`) {
+		t.Errorf("features still indexed as a map\n%s", out)
+	}
+	if got := runSource(t, out); got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+}
@@ -0,0 +1,627 @@
+package pass
+
+import (
+	"encoding/binary"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"math"
+	"path/filepath"
+
+	stdastutil "golang.org/x/tools/go/ast/astutil"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// MixedBoolArithPass troca constantes inteiras e operações aritméticas simples
+// por expressões mistas booleano-aritméticas (MBA) equivalentes.
+//
+//	timeout := 30            →  timeout := int(((mbaKey>>13 ^ 0x91d3…) + 2*(mbaKey>>13 & 0x91d3…)))
+//	total = a + b            →  total = ((a | b) + (a & b))
+//	var buf [64]byte         →  var buf [((23 ^ 41) + 2*(23 & 41))]byte
+//
+// Fora de contextos constantes, a constante vira uma soma sobre mbaKey, uma
+// var de pacote que o compilador não dobra, e o valor some do binário. A conta
+// é feita em uint64 e convertida no fim: as identidades valem módulo 2^64,
+// então o resultado é o mesmo com overflow, com sinal e em qualquer tamanho de
+// int. Onde a linguagem exige constante (const, tamanho de array, índice de
+// literal de array) ou onde ela importa (labels de case, operandos de shift),
+// a expressão usa só literais e continua constante. Os operandos das operações
+// reescritas são avaliados duas vezes, então só entram variáveis, campos e
+// constantes. A densidade segue --security e loops quentes no --profile ficam
+// de fora. As constantes 1 << i geradas pelo BoolToFlags também entram.
+type MixedBoolArithPass struct {
+	key     uint64 // valor de mbaKey no pacote atual
+	keyVar  string // nome de mbaKey no pacote atual
+	emitted bool   // mbaKey já emitida no pacote
+}
+
+func NewMixedBoolArithPass() *MixedBoolArithPass { return &MixedBoolArithPass{} }
+func (p *MixedBoolArithPass) Name() string       { return "MixedBoolArith" }
+
+// Prepare derives the package key and picks a package-unique name for it
+func (p *MixedBoolArithPass) Prepare(_ []*ast.File, _ *token.FileSet, ctx *astutil.TranspileContext) error {
+	if ctx.Ofuscate {
+		p.reset(ctx)
+	}
+	return nil
+}
+
+func (p *MixedBoolArithPass) reset(ctx *astutil.TranspileContext) {
+	scope := ""
+	if ctx.Package != nil {
+		scope = ctx.Package.Name()
+	}
+	key := ctx.DeriveKey("mba/" + scope)
+	p.key = binary.LittleEndian.Uint64(key[:8])
+	p.keyVar = freshPackageName(ctx.Package, "mbaKey")
+	p.emitted = false
+}
+
+func (p *MixedBoolArithPass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	// Etapa 1 (--no-obfuscate) gera código legível
+	if !ctx.Ofuscate || ctx.SecurityLevel <= 0 {
+		return nil
+	}
+	if p.keyVar == "" {
+		p.reset(ctx)
+	}
+	scope := filepath.Base(fset.Position(file.Pos()).Filename)
+	if ctx.Package != nil {
+		scope = ctx.Package.Name() + "/" + scope
+	}
+	key := ctx.DeriveKey("mba/" + scope)
+	m := &mbaRewriter{
+		pass: p, file: file, fset: fset, ctx: ctx,
+		rng:      binary.LittleEndian.Uint64(key[:8]),
+		required: constantContexts(file, ctx),
+		dropped:  make(map[*types.PkgName]bool),
+	}
+
+	m.generatedConsts()
+	stdastutil.Apply(file, m.visit, nil)
+	m.dropImports()
+
+	if m.runtime > 0 && !p.emitted {
+		decls, err := astutil.ParseDecls(fset, fmt.Sprintf("var %s uint64 = %#x\n", p.keyVar, p.key))
+		if err != nil {
+			return fmt.Errorf("MixedBoolArith: %w", err)
+		}
+		file.Decls = append(file.Decls, decls...)
+		p.emitted = true
+	}
+
+	if m.count > 0 {
+		gl.Log("info", fmt.Sprintf("🔄 MixedBoolArithPass: %d transformations applied", m.count))
+	}
+	return nil
+}
+
+// dropImports removes the imports that only the replaced constants used (pkg.Const)
+func (m *mbaRewriter) dropImports() {
+	for pkg := range m.dropped {
+		used := false
+		ast.Inspect(m.file, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if id, ok := sel.X.(*ast.Ident); ok && id.Name == pkg.Name() {
+					used = true
+				}
+			}
+			return !used
+		})
+		if used {
+			continue
+		}
+		name := ""
+		if pkg.Name() != pkg.Imported().Name() {
+			name = pkg.Name()
+		}
+		stdastutil.DeleteNamedImport(m.fset, m.file, name, pkg.Imported().Path())
+	}
+}
+
+// constantContexts maps the expressions that must stay constant (or whose
+// constness matters) to the reason
+func constantContexts(file *ast.File, ctx *astutil.TranspileContext) map[ast.Expr]string {
+	required := make(map[ast.Expr]string)
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.GenDecl:
+			if node.Tok == token.CONST {
+				for _, spec := range node.Specs {
+					for _, v := range spec.(*ast.ValueSpec).Values {
+						required[v] = "constant declaration"
+					}
+				}
+			}
+		case *ast.ArrayType:
+			if node.Len != nil {
+				required[node.Len] = "array length"
+			}
+		case *ast.SwitchStmt:
+			for _, clause := range node.Body.List {
+				for _, e := range clause.(*ast.CaseClause).List {
+					required[e] = "case label"
+				}
+			}
+		case *ast.BinaryExpr:
+			if node.Op == token.SHL || node.Op == token.SHR {
+				required[node.X], required[node.Y] = "shift operand", "shift operand"
+			}
+		case *ast.CompositeLit:
+			tv, ok := ctx.GetTypes()[node]
+			if !ok || tv.Type == nil {
+				return true
+			}
+			switch tv.Type.Underlying().(type) {
+			case *types.Array, *types.Slice:
+				for _, elt := range node.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						required[kv.Key] = "array literal index"
+					}
+				}
+			}
+		}
+		return true
+	})
+	return required
+}
+
+type mbaRewriter struct {
+	pass     *MixedBoolArithPass
+	file     *ast.File
+	fset     *token.FileSet
+	ctx      *astutil.TranspileContext
+	rng      uint64
+	required map[ast.Expr]string
+
+	runtime int                     // constantes que passaram a depender de mbaKey
+	dropped map[*types.PkgName]bool // pacotes citados pelas constantes substituídas
+	count   int
+}
+
+func (m *mbaRewriter) next() uint64 {
+	m.rng += 0x9e3779b97f4a7c15
+	return splitmix64(m.rng)
+}
+
+// pick decides whether a candidate is rewritten: 1/4 of them at --security 1,
+// 1/2 at 2, all at 3
+func (m *mbaRewriter) pick() bool {
+	switch {
+	case m.ctx.SecurityLevel >= 3:
+		return true
+	case m.ctx.SecurityLevel == 2:
+		return m.next()%2 == 0
+	}
+	return m.next()%4 == 0
+}
+
+// depth is how many times the identities are nested
+func (m *mbaRewriter) depth() int {
+	if m.ctx.SecurityLevel >= 3 {
+		return 2
+	}
+	return 1
+}
+
+func (m *mbaRewriter) visit(c *stdastutil.Cursor) bool {
+	switch node := c.Node().(type) {
+	case *ast.ForStmt, *ast.RangeStmt:
+		if pos, hot := hotLoop(node.(ast.Stmt), m.fset, m.ctx); hot {
+			m.ctx.RecordLedger(m.pass.Name(), pos, "loop", "skipped", fmt.Sprintf("hot loop at line %d (profile)", pos.Line))
+			return false
+		}
+	case *ast.BinaryExpr:
+		tv, ok := m.ctx.GetTypes()[node]
+		if ok && tv.Type != nil && tv.Value == nil && m.arith(c, node, tv.Type) {
+			return false
+		}
+	}
+	e, ok := c.Node().(ast.Expr)
+	if !ok {
+		return true
+	}
+	tv, ok := m.ctx.GetTypes()[e]
+	if !ok || tv.Value == nil {
+		return true
+	}
+	// Raiz de uma expressão constante: as partes não são visitadas
+	m.constant(c, e, tv)
+	return false
+}
+
+// constant rewrites the constant expression e, keeping it constant where required
+func (m *mbaRewriter) constant(c *stdastutil.Cursor, e ast.Expr, tv types.TypeAndValue) {
+	basic, ok := tv.Type.Underlying().(*types.Basic)
+	if !ok || basic.Info()&types.IsInteger == 0 || tv.Value.Kind() != constant.Int {
+		return
+	}
+	untyped := basic.Info()&types.IsUntyped != 0
+	reason, required := m.required[e]
+	if untyped && (!required || basic.Kind() != types.UntypedInt) {
+		return // o tipo final vem do contexto
+	}
+	if small(tv.Value) || !foldable(e, m.ctx) || !m.pick() {
+		return
+	}
+	pos := m.fset.Position(e.Pos())
+	target := types.ExprString(e)
+
+	var expr ast.Expr
+	if required {
+		src, ok := m.constSource(tv.Value)
+		if !ok {
+			m.ctx.RecordLedger(m.pass.Name(), pos, target, "skipped", "constant does not fit in 64 bits")
+			return
+		}
+		if expr, _ = parser.ParseExpr(src); expr == nil {
+			return
+		}
+		if !untyped {
+			typ := m.typeExpr(tv.Type)
+			if typ == nil {
+				m.ctx.RecordLedger(m.pass.Name(), pos, target, "skipped", "type "+tv.Type.String()+" not expressible here")
+				return
+			}
+			expr = &ast.CallExpr{Fun: typ, Args: []ast.Expr{expr}}
+		}
+		m.ctx.RecordLedger(m.pass.Name(), pos, target, "rewritten", "constant MBA ("+reason+")")
+	} else {
+		bits, ok := twosComplement(tv.Value, basic)
+		if !ok {
+			m.ctx.RecordLedger(m.pass.Name(), pos, target, "skipped", "value depends on the size of "+basic.Name())
+			return
+		}
+		shift := m.next() % 57
+		x := fmt.Sprintf("(%s >> %d)", m.pass.keyVar, shift)
+		if expr, _ = parser.ParseExpr(m.sum(x, mbaLit(bits-m.pass.key>>shift), m.depth())); expr == nil {
+			return
+		}
+		if !types.Identical(tv.Type, types.Typ[types.Uint64]) {
+			typ := m.typeExpr(tv.Type)
+			if typ == nil {
+				m.ctx.RecordLedger(m.pass.Name(), pos, target, "skipped", "type "+tv.Type.String()+" not expressible here")
+				return
+			}
+			expr = &ast.CallExpr{Fun: typ, Args: []ast.Expr{expr}}
+		}
+		tv = types.TypeAndValue{Type: tv.Type}
+		m.ctx.RecordLedger(m.pass.Name(), pos, target, "rewritten", "runtime MBA over "+m.pass.keyVar)
+		m.runtime++
+	}
+	ast.Inspect(e, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			if pkg, ok := m.ctx.GetUses()[id].(*types.PkgName); ok {
+				m.dropped[pkg] = true
+			}
+		}
+		return true
+	})
+	// Posições da constante original: sem elas o printer desloca os comentários
+	collapsePositions(expr, e.Pos())
+	m.ctx.GetTypes()[expr] = tv
+	c.Replace(expr)
+	m.count++
+}
+
+// arith rewrites a +, -, ^, | or & of two side-effect-free integer operands
+func (m *mbaRewriter) arith(c *stdastutil.Cursor, bin *ast.BinaryExpr, t types.Type) bool {
+	basic, ok := t.Underlying().(*types.Basic)
+	if !ok || basic.Info()&types.IsInteger == 0 || basic.Info()&types.IsUntyped != 0 {
+		return false
+	}
+	forms := mbaForms[bin.Op]
+	if len(forms) == 0 || !m.simple(bin.X, t) || !m.simple(bin.Y, t) || !m.pick() {
+		return false
+	}
+	var src string
+	if bin.Op == token.ADD {
+		src = m.sum("mbaX", "mbaY", m.depth())
+	} else {
+		src = forms[m.next()%uint64(len(forms))]
+	}
+	expr, err := parser.ParseExpr(src)
+	if err != nil {
+		return false
+	}
+	var failed bool
+	expr = stdastutil.Apply(expr, func(cc *stdastutil.Cursor) bool {
+		id, ok := cc.Node().(*ast.Ident)
+		if !ok || (id.Name != "mbaX" && id.Name != "mbaY") {
+			return true
+		}
+		operand := bin.X
+		if id.Name == "mbaY" {
+			operand = bin.Y
+		}
+		clone := m.operand(operand, t)
+		if clone == nil {
+			failed = true
+			return false
+		}
+		cc.Replace(clone)
+		return false
+	}, nil).(ast.Expr)
+	if failed {
+		return false
+	}
+	collapsePositions(expr, bin.Pos())
+	m.ctx.GetTypes()[expr] = types.TypeAndValue{Type: t}
+	m.ctx.RecordLedger(m.pass.Name(), m.fset.Position(bin.Pos()), types.ExprString(bin), "rewritten", "mixed boolean-arithmetic form")
+	c.Replace(expr)
+	m.count++
+	return true
+}
+
+// Identidades MBA por operador (valem módulo 2^n, com ou sem sinal); a soma
+// é montada por sum
+var mbaForms = map[token.Token][]string{
+	token.ADD: {"mbaX + mbaY"},
+	token.SUB: {"((mbaX ^ mbaY) - 2*(^mbaX & mbaY))", "((mbaX & ^mbaY) - (^mbaX & mbaY))"},
+	token.XOR: {"((mbaX | mbaY) - (mbaX & mbaY))", "((mbaX &^ mbaY) | (mbaY &^ mbaX))"},
+	token.OR:  {"((mbaX ^ mbaY) + (mbaX & mbaY))", "((mbaX &^ mbaY) + mbaY)"},
+	token.AND: {"((mbaX | mbaY) - (mbaX ^ mbaY))", "((mbaX + mbaY) - (mbaX | mbaY))"},
+}
+
+// sum writes a + b with the MBA identities for addition, nested depth times
+func (m *mbaRewriter) sum(a, b string, depth int) string {
+	if depth == 0 {
+		return "(" + a + " + " + b + ")"
+	}
+	switch m.next() % 3 {
+	case 0:
+		return m.sum("("+a+" ^ "+b+")", "(2*("+a+" & "+b+"))", depth-1)
+	case 1:
+		return m.sum("("+a+" | "+b+")", "("+a+" & "+b+")", depth-1)
+	}
+	return fmt.Sprintf("(2*(%[1]s | %[2]s) - (%[1]s ^ %[2]s))", a, b)
+}
+
+// constSource writes v as a sum of two literals in MBA form; untyped constant
+// arithmetic is exact, so no intermediate value can overflow
+func (m *mbaRewriter) constSource(v constant.Value) (string, bool) {
+	neg := constant.Sign(v) < 0
+	if neg {
+		v = constant.UnaryOp(token.SUB, v, 0)
+	}
+	u, exact := constant.Uint64Val(v)
+	if !exact {
+		return "", false
+	}
+	a := 1 + m.next()%(u-1)
+	src := m.sum(mbaLit(a), mbaLit(u-a), m.depth())
+	if neg {
+		src = "-" + src
+	}
+	return src, true
+}
+
+// simple reports whether e can be evaluated twice: a variable, a field of
+// one, or a constant name or literal, of type t
+func (m *mbaRewriter) simple(e ast.Expr, t types.Type) bool {
+	tv, ok := m.ctx.GetTypes()[e]
+	if !ok || tv.Type == nil || !types.Identical(tv.Type, t) {
+		return false
+	}
+	switch node := e.(type) {
+	case *ast.ParenExpr:
+		return m.simple(node.X, t)
+	case *ast.BasicLit:
+		return true
+	case *ast.Ident:
+		switch m.ctx.GetUses()[node].(type) {
+		case *types.Var, *types.Const:
+			return true
+		}
+	case *ast.SelectorExpr:
+		if sel := m.ctx.GetSelections()[node]; sel != nil {
+			return sel.Kind() == types.FieldVal && m.simpleBase(node.X)
+		}
+		switch m.ctx.GetUses()[node.Sel].(type) {
+		case *types.Var, *types.Const:
+			return true // pkg.Name
+		}
+	}
+	return false
+}
+
+// simpleBase reports whether the operand of a field selector is a variable or a field of one
+func (m *mbaRewriter) simpleBase(e ast.Expr) bool {
+	switch node := e.(type) {
+	case *ast.ParenExpr:
+		return m.simpleBase(node.X)
+	case *ast.Ident:
+		_, ok := m.ctx.GetUses()[node].(*types.Var)
+		return ok
+	case *ast.SelectorExpr:
+		sel := m.ctx.GetSelections()[node]
+		return sel != nil && sel.Kind() == types.FieldVal && m.simpleBase(node.X)
+	}
+	return false
+}
+
+// operand copies a simple operand of type t. Constants are converted to t:
+// ^5 is a negative untyped constant, ^uint8(5) is 250.
+func (m *mbaRewriter) operand(e ast.Expr, t types.Type) ast.Expr {
+	clone := m.clone(e)
+	if tv := m.ctx.GetTypes()[e]; tv.Value != nil {
+		typ := m.typeExpr(t)
+		if typ == nil {
+			return nil
+		}
+		return &ast.CallExpr{Fun: typ, Args: []ast.Expr{clone}}
+	}
+	return &ast.ParenExpr{X: clone}
+}
+
+// clone copies a simple expression, carrying over its type information
+func (m *mbaRewriter) clone(e ast.Expr) ast.Expr {
+	var out ast.Expr
+	switch node := e.(type) {
+	case *ast.ParenExpr:
+		out = &ast.ParenExpr{X: m.clone(node.X)}
+	case *ast.BasicLit:
+		out = &ast.BasicLit{Kind: node.Kind, Value: node.Value}
+	case *ast.Ident:
+		id := &ast.Ident{Name: node.Name}
+		if obj := m.ctx.GetUses()[node]; obj != nil {
+			m.ctx.GetUses()[id] = obj
+		}
+		out = id
+	case *ast.SelectorExpr:
+		sel := &ast.SelectorExpr{X: m.clone(node.X), Sel: m.clone(node.Sel).(*ast.Ident)}
+		if s := m.ctx.GetSelections()[node]; s != nil {
+			m.ctx.GetSelections()[sel] = s
+		}
+		out = sel
+	default:
+		return nil
+	}
+	if tv, ok := m.ctx.GetTypes()[e]; ok {
+		m.ctx.GetTypes()[out] = tv
+	}
+	return out
+}
+
+// typeExpr renders t for a conversion, binding a package or local type name
+// to its object so later passes can follow it
+func (m *mbaRewriter) typeExpr(t types.Type) ast.Expr {
+	typ := typeExprFor(t, m.file, m.ctx)
+	if named, ok := types.Unalias(t).(*types.Named); ok && typ != nil {
+		ast.Inspect(typ, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && id.Name == named.Obj().Name() && named.Obj().Pkg() == m.ctx.Package {
+				m.ctx.GetUses()[id] = named.Obj()
+			}
+			return true
+		})
+	}
+	return typ
+}
+
+// generatedConsts rewrites the literal-only values of constants emitted by
+// earlier passes (BoolToFlags' `1 << i`), which have no type information
+func (m *mbaRewriter) generatedConsts() {
+	for _, decl := range m.file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if len(vs.Names) == 0 || m.ctx.GetDefs()[vs.Names[0]] != nil {
+				continue
+			}
+			for i, v := range vs.Values {
+				val, ok := literalConstant(v)
+				if !ok || small(val) || !m.pick() {
+					continue
+				}
+				src, ok := m.constSource(val)
+				if !ok {
+					continue
+				}
+				expr, err := parser.ParseExpr(src)
+				if err != nil {
+					continue
+				}
+				collapsePositions(expr, v.Pos())
+				vs.Values[i] = expr
+				m.ctx.RecordLedger(m.pass.Name(), m.fset.Position(v.Pos()), vs.Names[0].Name, "rewritten", "constant MBA (generated constant)")
+				m.count++
+			}
+		}
+	}
+}
+
+// literalConstant evaluates an integer expression made only of literals
+func literalConstant(e ast.Expr) (constant.Value, bool) {
+	switch node := e.(type) {
+	case *ast.BasicLit:
+		if node.Kind != token.INT {
+			return nil, false
+		}
+		v := constant.MakeFromLiteral(node.Value, node.Kind, 0)
+		return v, v.Kind() == constant.Int
+	case *ast.ParenExpr:
+		return literalConstant(node.X)
+	case *ast.UnaryExpr:
+		x, ok := literalConstant(node.X)
+		if !ok || (node.Op != token.ADD && node.Op != token.SUB && node.Op != token.XOR) {
+			return nil, false
+		}
+		return constant.UnaryOp(node.Op, x, 0), true
+	case *ast.BinaryExpr:
+		x, okX := literalConstant(node.X)
+		y, okY := literalConstant(node.Y)
+		if !okX || !okY {
+			return nil, false
+		}
+		switch node.Op {
+		case token.SHL, token.SHR:
+			s, exact := constant.Uint64Val(y)
+			if !exact || s > 64 {
+				return nil, false
+			}
+			return constant.Shift(x, node.Op, uint(s)), true
+		case token.ADD, token.SUB, token.MUL, token.AND, token.OR, token.XOR, token.AND_NOT:
+			return constant.BinaryOp(x, node.Op, y), true
+		}
+	}
+	return nil, false
+}
+
+// small reports whether |v| < 2: 0, 1 and -1 are not worth hiding
+func small(v constant.Value) bool {
+	abs := v
+	if constant.Sign(v) < 0 {
+		abs = constant.UnaryOp(token.SUB, v, 0)
+	}
+	return constant.Compare(abs, token.LSS, constant.MakeInt64(2))
+}
+
+// foldable reports whether e is a constant the rewrite can reproduce: no
+// iota (the spec may be repeated with another value) and no builtin calls
+// (unsafe.Sizeof, len of an array) whose value depends on the target
+func foldable(e ast.Expr, ctx *astutil.TranspileContext) bool {
+	ok := true
+	ast.Inspect(e, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.Ident:
+			if node.Name == "iota" && ctx.GetUses()[node] == types.Universe.Lookup("iota") {
+				ok = false
+			}
+		case *ast.CallExpr:
+			if tv, found := ctx.GetTypes()[node.Fun]; !found || !tv.IsType() {
+				ok = false
+			}
+		}
+		return ok
+	})
+	return ok
+}
+
+// twosComplement returns the 64-bit pattern of v as a value of basic. Values
+// of int, uint and uintptr outside 32 bits are refused: their meaning depends
+// on the target.
+func twosComplement(v constant.Value, basic *types.Basic) (uint64, bool) {
+	sized := basic.Kind() == types.Int || basic.Kind() == types.Uint || basic.Kind() == types.Uintptr
+	if basic.Info()&types.IsUnsigned != 0 {
+		u, exact := constant.Uint64Val(v)
+		return u, exact && (!sized || u <= math.MaxUint32)
+	}
+	i, exact := constant.Int64Val(v)
+	return uint64(i), exact && (!sized || (i >= math.MinInt32 && i <= math.MaxInt32))
+}
+
+func mbaLit(v uint64) string {
+	if v < 16 {
+		return fmt.Sprint(v)
+	}
+	return fmt.Sprintf("%#x", v)
+}
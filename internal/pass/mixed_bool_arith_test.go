@@ -0,0 +1,74 @@
+package pass
+
+import (
+	"strings"
+	"testing"
+)
+
+const mbaProbe = `package main
+
+import "fmt"
+
+const seed uint32 = 0x5bd1e995
+
+const (
+	red = iota * 7
+	green
+	blue
+)
+
+var calls int
+
+func tick() int {
+	calls++
+	return calls * 3
+}
+
+type point struct{ x, y int16 }
+
+func main() {
+	var table [24]byte
+	lookup := [...]int{5: 50, 17: 170}
+	timeout := 123456789
+	var small int8 = 100
+	small += 100
+	var wide uint = 3
+	wide -= 7
+	p := point{x: 30000, y: 12345}
+	p.x = p.x + p.y
+	h := seed
+	for i := 0; i < 4; i++ {
+		h = h ^ uint32(i)
+		h = h*seed + 977
+	}
+	switch timeout % 1000 {
+	case 789:
+		fmt.Println("case 789")
+	case 123:
+		fmt.Println("case 123")
+	}
+	sum := tick() + tick()
+	fmt.Println(len(table), lookup[17], timeout, small, wide, p, h, 1<<5, red, green, blue)
+	fmt.Println(sum, calls, -timeout&0xffff, timeout|0x1000)
+}
+`
+
+func TestMixedBoolArithPreservesBehavior(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, mbaProbe)
+	out, ctx := transpileSource(t, mbaProbe, true, atSecurity{NewMixedBoolArithPass(), 3})
+	if countLedger(ctx, "MixedBoolArith", "rewritten") == 0 {
+		t.Fatalf("nothing rewritten\n%s", out)
+	}
+	// Fora de contexto constante o valor só existe como soma sobre mbaKey
+	if strings.Contains(out, "123456789") {
+		t.Errorf("runtime constant left in plain text\n%s", out)
+	}
+	// Operandos com efeitos colaterais seriam avaliados duas vezes
+	if got, was := strings.Count(out, "tick()"), strings.Count(mbaProbe, "tick()"); got != was {
+		t.Errorf("tick() appears %d times, want %d\n%s", got, was, out)
+	}
+	if got := runSource(t, out); got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+}
@@ -0,0 +1,527 @@
+package pass
+
+import (
+	"encoding/binary"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+const noOpaqueDirective = "//gastype:noopaque"
+
+// OpaquePredicatePass espalha predicados opacos pelas funções: condições sempre
+// verdadeiras ou sempre falsas, montadas com identidades de teoria dos números
+// sobre variáveis vivas, guardando blocos isca que nunca executam.
+//
+//	total += v            →   if total*(total+1)&1 != 0 {
+//	                              n ^= 0x2d
+//	                          }
+//	                          if (n*n+1)&3 != 0 {
+//	                              total += v
+//	                          } else {
+//	                              total = total*37 + 11
+//	                          }
+//
+// As identidades usam só os bits baixos (x·(x+1) é par, quadrados são 0 ou 1
+// mod 4), então continuam valendo com overflow e sinal. Só entram variáveis
+// locais que nenhuma closure captura e cujo endereço nunca é tomado. A
+// densidade segue --security (1: 1/8 dos statements, 2: 1/4, 3: 1/2); loops
+// quentes no --profile ficam de fora, e //gastype:noopaque desliga a função.
+type OpaquePredicatePass struct{}
+
+func NewOpaquePredicatePass() *OpaquePredicatePass { return &OpaquePredicatePass{} }
+func (p *OpaquePredicatePass) Name() string        { return "OpaquePredicate" }
+
+func (p *OpaquePredicatePass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	// Etapa 1 (--no-obfuscate) gera código legível
+	if !ctx.Ofuscate || ctx.SecurityLevel <= 0 {
+		return nil
+	}
+	inserted := 0
+	for _, decl := range file.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Body == nil || ctx.GetDefs()[fd.Name] == nil {
+			continue // funções geradas por outros passes não têm tipos
+		}
+		name := fd.Name.Name
+		if fd.Recv != nil && len(fd.Recv.List) > 0 {
+			name = types.ExprString(fd.Recv.List[0].Type) + "." + name
+		}
+		if hasDirective(fd.Doc, noOpaqueDirective) {
+			ctx.RecordLedger(p.Name(), fset.Position(fd.Pos()), name, "skipped", "gastype:noopaque")
+			continue
+		}
+		o := newOpaquer(p.Name(), name, file, fset, ctx)
+		o.escaping(fd)
+		// Closures ganham o próprio ambiente: as variáveis de fora são capturadas
+		var lits []*ast.FuncLit
+		ast.Inspect(fd.Body, func(n ast.Node) bool {
+			if lit, ok := n.(*ast.FuncLit); ok {
+				lits = append(lits, lit)
+			}
+			return true
+		})
+		o.function(fd.Recv, fd.Type, fd.Body)
+		for _, lit := range lits {
+			o.function(nil, lit.Type, lit.Body)
+		}
+		inserted += o.count
+	}
+
+	if inserted > 0 {
+		gl.Log("info", fmt.Sprintf("🔄 OpaquePredicatePass: %d opaque predicates inserted", inserted))
+	}
+	return nil
+}
+
+// opaqueScope guarda as variáveis visíveis de um bloco, em ordem de declaração.
+// Um nome sem variável (nil) é declarado por código sem tipos, const ou type e
+// esconde os de fora.
+type opaqueScope struct {
+	names []string
+	vars  map[string]*types.Var
+}
+
+// opaqueOperand é um termo inteiro de um predicado: a variável ou len(variável)
+type opaqueOperand struct {
+	expr string
+	typ  types.Type
+}
+
+type opaquer struct {
+	pass, fn string
+	file     *ast.File
+	fset     *token.FileSet
+	ctx      *astutil.TranspileContext
+	rng      uint64
+	density  uint64 // um ponto a cada density statements, em média
+
+	env    []*opaqueScope
+	unsafe map[types.Object]bool // capturadas por closures ou com endereço tomado
+	count  int
+}
+
+func newOpaquer(pass, fn string, file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) *opaquer {
+	scope := fn
+	if ctx.Package != nil {
+		scope = ctx.Package.Name() + "." + scope
+	}
+	key := ctx.DeriveKey("opaque/" + scope)
+	return &opaquer{
+		pass: pass, fn: fn, file: file, fset: fset, ctx: ctx,
+		rng:     binary.LittleEndian.Uint64(key[:8]),
+		density: 16 >> min(ctx.SecurityLevel, 3),
+		unsafe:  make(map[types.Object]bool),
+	}
+}
+
+// escaping marks the variables of fd that a closure captures or whose address
+// is taken: another goroutine or a pointer could change them under the predicate
+func (o *opaquer) escaping(fd *ast.FuncDecl) {
+	ast.Inspect(fd, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.FuncLit); ok {
+			ast.Inspect(lit.Body, func(m ast.Node) bool {
+				if id, ok := m.(*ast.Ident); ok {
+					if obj := o.ctx.GetUses()[id]; obj != nil && (obj.Pos() < lit.Pos() || obj.Pos() >= lit.End()) {
+						o.unsafe[obj] = true
+					}
+				}
+				return true
+			})
+		}
+		if obj := addressTaken(n, o.ctx); obj != nil {
+			o.unsafe[obj] = true
+		}
+		return true
+	})
+}
+
+// function walks one function body with the parameters in scope
+func (o *opaquer) function(recv *ast.FieldList, ftype *ast.FuncType, body *ast.BlockStmt) {
+	o.env = nil
+	o.push()
+	for _, fields := range []*ast.FieldList{recv, ftype.Params, ftype.Results} {
+		if fields == nil {
+			continue
+		}
+		for _, field := range fields.List {
+			for _, name := range field.Names {
+				o.declare(name)
+			}
+		}
+	}
+	o.stmts(&body.List, false)
+	o.pop()
+}
+
+func (o *opaquer) next() uint64 {
+	o.rng += 0x9e3779b97f4a7c15
+	return splitmix64(o.rng)
+}
+
+func (o *opaquer) push() { o.env = append(o.env, &opaqueScope{vars: make(map[string]*types.Var)}) }
+func (o *opaquer) pop()  { o.env = o.env[:len(o.env)-1] }
+
+// declare adds the object id defines to the innermost scope
+func (o *opaquer) declare(id *ast.Ident) {
+	if id.Name == "_" {
+		return
+	}
+	obj := o.ctx.GetDefs()[id]
+	if obj == nil && o.ctx.GetUses()[id] != nil {
+		return // redeclaração em :=
+	}
+	v, _ := obj.(*types.Var)
+	o.bind(id.Name, v)
+}
+
+func (o *opaquer) bind(name string, v *types.Var) {
+	scope := o.env[len(o.env)-1]
+	if _, ok := scope.vars[name]; !ok {
+		scope.names = append(scope.names, name)
+	}
+	scope.vars[name] = v
+}
+
+// declareStmt adds the names s declares to the innermost scope
+func (o *opaquer) declareStmt(s ast.Stmt) {
+	switch s := s.(type) {
+	case *ast.AssignStmt:
+		if s.Tok == token.DEFINE {
+			for _, lhs := range s.Lhs {
+				if id, ok := lhs.(*ast.Ident); ok {
+					o.declare(id)
+				}
+			}
+		}
+	case *ast.DeclStmt:
+		gd, ok := s.Decl.(*ast.GenDecl)
+		if !ok {
+			return
+		}
+		for _, spec := range gd.Specs {
+			switch spec := spec.(type) {
+			case *ast.ValueSpec:
+				for _, name := range spec.Names {
+					if gd.Tok == token.VAR {
+						o.declare(name)
+					} else if name.Name != "_" {
+						o.bind(name.Name, nil)
+					}
+				}
+			case *ast.TypeSpec:
+				o.bind(spec.Name.Name, nil)
+			}
+		}
+	case *ast.LabeledStmt:
+		o.declareStmt(s.Stmt)
+	}
+}
+
+// lookup returns the variable name refers to here (nil if not a usable local)
+// and whether the name is declared in the function at all
+func (o *opaquer) lookup(name string) (*types.Var, bool) {
+	for i := len(o.env) - 1; i >= 0; i-- {
+		if v, ok := o.env[i].vars[name]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// stmts walks a statement list, inserting predicates between its statements
+func (o *opaquer) stmts(list *[]ast.Stmt, hot bool) {
+	o.push()
+	var out []ast.Stmt
+	for _, s := range *list {
+		if hot || !s.Pos().IsValid() || o.next()%o.density != 0 {
+			out = append(out, s)
+		} else {
+			out = append(out, o.guard(s)...)
+		}
+		o.stmt(s, hot)
+		o.declareStmt(s)
+	}
+	*list = out
+	o.pop()
+}
+
+// stmt walks the blocks nested in s
+func (o *opaquer) stmt(s ast.Stmt, hot bool) {
+	switch s := s.(type) {
+	case *ast.BlockStmt:
+		o.stmts(&s.List, hot)
+	case *ast.LabeledStmt:
+		o.stmt(s.Stmt, hot)
+	case *ast.IfStmt:
+		o.push()
+		if s.Init != nil {
+			o.declareStmt(s.Init)
+		}
+		o.stmts(&s.Body.List, hot)
+		if s.Else != nil {
+			o.stmt(s.Else, hot)
+		}
+		o.pop()
+	case *ast.ForStmt:
+		hot = hot || o.hot(s)
+		o.push()
+		if s.Init != nil {
+			o.declareStmt(s.Init)
+		}
+		o.stmts(&s.Body.List, hot)
+		o.pop()
+	case *ast.RangeStmt:
+		hot = hot || o.hot(s)
+		o.push()
+		if s.Tok == token.DEFINE {
+			for _, e := range []ast.Expr{s.Key, s.Value} {
+				if id, ok := e.(*ast.Ident); ok {
+					o.declare(id)
+				}
+			}
+		}
+		o.stmts(&s.Body.List, hot)
+		o.pop()
+	case *ast.SwitchStmt:
+		o.push()
+		if s.Init != nil {
+			o.declareStmt(s.Init)
+		}
+		for _, clause := range s.Body.List {
+			o.stmts(&clause.(*ast.CaseClause).Body, hot)
+		}
+		o.pop()
+	case *ast.TypeSwitchStmt:
+		o.push()
+		if s.Init != nil {
+			o.declareStmt(s.Init)
+		}
+		for _, clause := range s.Body.List {
+			cc := clause.(*ast.CaseClause)
+			o.push()
+			if assign, ok := s.Assign.(*ast.AssignStmt); ok && len(assign.Lhs) == 1 {
+				if id, ok := assign.Lhs[0].(*ast.Ident); ok {
+					v, _ := o.ctx.GetImplicits()[cc].(*types.Var)
+					o.bind(id.Name, v)
+				}
+			}
+			o.stmts(&cc.Body, hot)
+			o.pop()
+		}
+		o.pop()
+	case *ast.SelectStmt:
+		for _, clause := range s.Body.List {
+			cc := clause.(*ast.CommClause)
+			o.push()
+			if cc.Comm != nil {
+				o.declareStmt(cc.Comm)
+			}
+			o.stmts(&cc.Body, hot)
+			o.pop()
+		}
+	}
+}
+
+// hot reports whether the profile marks loop as hot, recording the skip
+func (o *opaquer) hot(loop ast.Stmt) bool {
+	pos, hot := hotLoop(loop, o.fset, o.ctx)
+	if hot {
+		o.ctx.RecordLedger(o.pass, pos, o.fn, "skipped", fmt.Sprintf("hot loop at line %d (profile)", pos.Line))
+	}
+	return hot
+}
+
+// hotLoop reports whether the --profile marks loop as hot, with its position
+func hotLoop(loop ast.Stmt, fset *token.FileSet, ctx *astutil.TranspileContext) (token.Position, bool) {
+	from, to := fset.Position(loop.Pos()), fset.Position(loop.End())
+	if ctx.Profile == nil || !from.IsValid() {
+		return from, false
+	}
+	return from, ctx.Profile.Hot(from.Filename, from.Line, to.Line)
+}
+
+// guard returns s with an opaque predicate: either a false predicate guarding
+// a decoy before s, or s itself under a true predicate with the decoy in else
+func (o *opaquer) guard(s ast.Stmt) []ast.Stmt {
+	operands, targets := o.live()
+	if len(operands) == 0 || len(targets) == 0 {
+		return []ast.Stmt{s}
+	}
+	wrap := o.wrappable(s) && o.next()%2 == 0
+	cond, src := o.predicate(operands, wrap)
+	decoy := o.decoy(targets)
+	if cond == nil || decoy == nil {
+		return []ast.Stmt{s}
+	}
+	detail := fmt.Sprintf("opaque predicate %s (disable with %s)", src, noOpaqueDirective)
+	o.ctx.RecordLedger(o.pass, o.fset.Position(s.Pos()), o.fn, "rewritten", detail)
+	o.count++
+
+	// Posições do statement original: sem elas o printer desloca os comentários
+	pos := s.Pos()
+	collapsePositions(cond, pos)
+	if !wrap {
+		collapsePositions(decoy, pos)
+		return []ast.Stmt{&ast.IfStmt{If: pos, Cond: cond, Body: decoy}, s}
+	}
+	end := s.End()
+	collapsePositions(decoy, end)
+	return []ast.Stmt{&ast.IfStmt{
+		If:   pos,
+		Cond: cond,
+		Body: &ast.BlockStmt{Lbrace: pos, List: []ast.Stmt{s}, Rbrace: end},
+		Else: decoy,
+	}}
+}
+
+// wrappable reports whether s can move into the body of an if: a simple
+// statement that declares nothing and does not end the function
+func (o *opaquer) wrappable(s ast.Stmt) bool {
+	switch s := s.(type) {
+	case *ast.AssignStmt:
+		return s.Tok != token.DEFINE
+	case *ast.IncDecStmt, *ast.SendStmt:
+		return true
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		id, ok := ast.Unparen(call.Fun).(*ast.Ident)
+		return !ok || id.Name != "panic" || o.ctx.GetUses()[id] != types.Universe.Lookup("panic")
+	}
+	return false
+}
+
+// live returns the integer terms available here and the variables a decoy can assign
+func (o *opaquer) live() (operands []opaqueOperand, targets []*types.Var) {
+	_, lenShadowed := o.lookup("len")
+	if ctx := o.ctx; ctx.Package != nil && ctx.Package.Scope().Lookup("len") != nil {
+		lenShadowed = true
+	}
+	seen := make(map[string]bool)
+	for i := len(o.env) - 1; i >= 0; i-- {
+		for _, name := range o.env[i].names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			v := o.env[i].vars[name]
+			if v == nil || o.unsafe[v] {
+				continue
+			}
+			switch u := v.Type().Underlying().(type) {
+			case *types.Basic:
+				switch {
+				case u.Info()&types.IsInteger != 0 && u.Info()&types.IsUntyped == 0:
+					operands = append(operands, opaqueOperand{name, v.Type()})
+					targets = append(targets, v)
+				case u.Kind() == types.String && !lenShadowed:
+					operands = append(operands, opaqueOperand{"len(" + name + ")", types.Typ[types.Int]})
+					targets = append(targets, v)
+				}
+			case *types.Slice:
+				if !lenShadowed {
+					operands = append(operands, opaqueOperand{"len(" + name + ")", types.Typ[types.Int]})
+					targets = append(targets, v)
+				}
+			case *types.Map, *types.Chan:
+				if !lenShadowed {
+					operands = append(operands, opaqueOperand{"len(" + name + ")", types.Typ[types.Int]})
+				}
+			}
+		}
+	}
+	return operands, targets
+}
+
+// Identidades sobre os bits baixos: valem para qualquer inteiro, com overflow
+var (
+	opaqueUnary = []string{
+		"%[1]s*(%[1]s+1)&1 %[2]s 0", // x·(x+1) é par
+		"%[1]s*%[1]s&3 %[3]s 2",     // x² mod 4 ∈ {0, 1}
+		"(%[1]s*%[1]s+1)&3 %[3]s 0", // x²+1 mod 4 ∈ {1, 2}
+	}
+	opaqueBinary = "(%[1]s*%[1]s+%[4]s*%[4]s)&3 %[3]s 3" // x²+y² mod 4 ∈ {0, 1, 2}
+)
+
+// predicate builds an always-true (or always-false) condition over operands,
+// returning it with its source
+func (o *opaquer) predicate(operands []opaqueOperand, truth bool) (ast.Expr, string) {
+	eq, ne := "==", "!="
+	if !truth {
+		eq, ne = ne, eq
+	}
+	x := operands[o.next()%uint64(len(operands))]
+	var same []opaqueOperand
+	for _, y := range operands {
+		if y.expr != x.expr && types.Identical(x.typ, y.typ) {
+			same = append(same, y)
+		}
+	}
+	var src string
+	if choice := o.next() % uint64(len(opaqueUnary)+1); choice < uint64(len(opaqueUnary)) || len(same) == 0 {
+		src = fmt.Sprintf(opaqueUnary[choice%uint64(len(opaqueUnary))], x.expr, eq, ne)
+	} else {
+		y := same[o.next()%uint64(len(same))]
+		src = fmt.Sprintf(opaqueBinary, x.expr, eq, ne, y.expr)
+	}
+	expr, err := parser.ParseExpr(src)
+	if err != nil {
+		return nil, ""
+	}
+	o.bindUses(expr)
+	return expr, src
+}
+
+// decoy builds the dead block: one or two plausible updates of live variables
+func (o *opaquer) decoy(targets []*types.Var) *ast.BlockStmt {
+	var src string
+	for i := 0; i < 1+int(o.next()%2); i++ {
+		v := targets[o.next()%uint64(len(targets))]
+		if basic, ok := v.Type().Underlying().(*types.Basic); ok && basic.Info()&types.IsInteger != 0 {
+			k, c := 3+o.next()%125, 1+o.next()%127
+			switch o.next() % 3 {
+			case 0:
+				src += fmt.Sprintf("%[1]s = %[1]s*%[2]d + %[3]d\n", v.Name(), k, c)
+			case 1:
+				src += fmt.Sprintf("%s ^= %#x\n", v.Name(), k)
+			default:
+				src += fmt.Sprintf("%[1]s += %[1]s >> 3\n", v.Name())
+			}
+		} else {
+			src += fmt.Sprintf("%[1]s = %[1]s[len(%[1]s)/2:]\n", v.Name())
+		}
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package p\nfunc _() {\n"+src+"}\n", 0)
+	if err != nil {
+		return nil
+	}
+	body := f.Decls[0].(*ast.FuncDecl).Body
+	o.bindUses(body)
+	return body
+}
+
+// bindUses resolves the identifiers of generated code to the live variables
+// (and builtins) they name, so later passes can follow them
+func (o *opaquer) bindUses(root ast.Node) {
+	ast.Inspect(root, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if v, _ := o.lookup(id.Name); v != nil {
+			o.ctx.GetUses()[id] = v
+		} else if obj := types.Universe.Lookup(id.Name); obj != nil {
+			o.ctx.GetUses()[id] = obj
+		}
+		return true
+	})
+}
@@ -0,0 +1,100 @@
+package pass
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+)
+
+// atSecurity runs pass at the density of --security level
+type atSecurity struct {
+	testPass
+	level int
+}
+
+func (a atSecurity) Prepare(files []*ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	ctx.SecurityLevel = a.level
+	if pp, ok := a.testPass.(interface {
+		Prepare([]*ast.File, *token.FileSet, *astutil.TranspileContext) error
+	}); ok {
+		return pp.Prepare(files, fset, ctx)
+	}
+	return nil
+}
+
+const opaqueProbe = `package main
+
+import "fmt"
+
+func mix(xs []int8) (int8, uint) {
+	var acc int8 = 100
+	var wide uint = 1
+	for _, x := range xs {
+		acc += x * 3
+		wide = wide*2654435761 + uint(x)
+		if acc < 0 {
+			acc = -acc
+		}
+		wide ^= wide >> 7
+	}
+	return acc, wide
+}
+
+// capt muda dentro da closure e addr pelo ponteiro: nenhum dos dois pode
+// entrar num predicado
+func escaping(n int) (int, int) {
+	capt, addr := 1, 2
+	bump := func() { capt += n }
+	p := &addr
+	for i := 0; i < n; i++ {
+		bump()
+		*p += i
+		capt++
+		addr++
+	}
+	return capt, addr
+}
+
+//gastype:noopaque
+func untouched(n int) int {
+	total := 0
+	for i := 0; i < n; i++ {
+		total += i
+		total ^= n
+	}
+	return total
+}
+
+func main() {
+	fmt.Println(mix([]int8{1, 100, -128, 127, 55}))
+	fmt.Println(escaping(5))
+	fmt.Println(untouched(9))
+}
+`
+
+func TestOpaquePredicatePreservesBehavior(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, opaqueProbe)
+	out, ctx := transpileSource(t, opaqueProbe, true, atSecurity{NewOpaquePredicatePass(), 3})
+	if countLedger(ctx, "OpaquePredicate", "rewritten") == 0 {
+		t.Fatalf("no predicates inserted\n%s", out)
+	}
+	if got := countLedger(ctx, "OpaquePredicate", "skipped"); got != 1 {
+		t.Errorf("%d functions skipped, want 1 (untouched)\n%+v", got, ctx.Ledger)
+	}
+	for _, name := range []string{"capt", "addr"} {
+		if got, was := strings.Count(out, name), strings.Count(opaqueProbe, name); got != was {
+			t.Errorf("%s appears %d times, want %d: it must not feed a predicate\n%s", name, got, was, out)
+		}
+	}
+	untouched := opaqueProbe[strings.Index(opaqueProbe, "func untouched"):strings.Index(opaqueProbe, "func main")]
+	if !strings.Contains(out, untouched) {
+		t.Errorf("untouched was rewritten\n%s", out)
+	}
+	if got := runSource(t, out); got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+}
@@ -0,0 +1,891 @@
+// Code generated by TestBenchFixtures; DO NOT EDIT.
+
+package pass
+
+import "testing"
+
+// Benchmarks behind perfectHashMinKeys: string keys of 3 to 14 bytes, looked up
+// in shuffled order with 10% absent inputs. The phLookup functions below are
+// perfectHash.source output for the same keys.
+//
+//	go test -run '^$' -bench PerfectHash ./internal/pass
+
+var phBenchKeys8 = []string{"imeimv_", "lcccyimdxjf", "anict", "kwvxjx", "yldb_ivtkidpur", "aqllch", "wum", "gdr"}
+
+func phIf8(k string) int {
+	if k == "imeimv_" {
+		return 4
+	} else if k == "lcccyimdxjf" {
+		return 7
+	} else if k == "anict" {
+		return 10
+	} else if k == "kwvxjx" {
+		return 13
+	} else if k == "yldb_ivtkidpur" {
+		return 16
+	} else if k == "aqllch" {
+		return 19
+	} else if k == "wum" {
+		return 22
+	} else if k == "gdr" {
+		return 25
+	}
+	return 0
+}
+
+func phSwitch8(k string) int {
+	switch k {
+	case "imeimv_":
+		return 4
+	case "lcccyimdxjf":
+		return 7
+	case "anict":
+		return 10
+	case "kwvxjx":
+		return 13
+	case "yldb_ivtkidpur":
+		return 16
+	case "aqllch":
+		return 19
+	case "wum":
+		return 22
+	case "gdr":
+		return 25
+	}
+	return 0
+}
+
+func phHash8(k string) int {
+	switch phLookup_bench8(k) {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	case 5:
+		return 16
+	case 6:
+		return 19
+	case 7:
+		return 22
+	case 8:
+		return 25
+	}
+	return 0
+}
+
+var phBenchKeys16 = []string{"byiqjzvaist", "ynfide", "obdkcd_gg", "dnjoepej", "xpc_guacdtueg", "plqbpqxbcin", "eceyowsvc", "qdfvmovyenly", "otvzrlju", "_r_xrqblrm", "pmllvvnyb", "gwexs", "vsalzbeppaptll", "hxnjogkvu_wp", "w_umru", "rallemtfcdnj"}
+
+func phIf16(k string) int {
+	if k == "byiqjzvaist" {
+		return 4
+	} else if k == "ynfide" {
+		return 7
+	} else if k == "obdkcd_gg" {
+		return 10
+	} else if k == "dnjoepej" {
+		return 13
+	} else if k == "xpc_guacdtueg" {
+		return 16
+	} else if k == "plqbpqxbcin" {
+		return 19
+	} else if k == "eceyowsvc" {
+		return 22
+	} else if k == "qdfvmovyenly" {
+		return 25
+	} else if k == "otvzrlju" {
+		return 28
+	} else if k == "_r_xrqblrm" {
+		return 31
+	} else if k == "pmllvvnyb" {
+		return 34
+	} else if k == "gwexs" {
+		return 37
+	} else if k == "vsalzbeppaptll" {
+		return 40
+	} else if k == "hxnjogkvu_wp" {
+		return 43
+	} else if k == "w_umru" {
+		return 46
+	} else if k == "rallemtfcdnj" {
+		return 49
+	}
+	return 0
+}
+
+func phSwitch16(k string) int {
+	switch k {
+	case "byiqjzvaist":
+		return 4
+	case "ynfide":
+		return 7
+	case "obdkcd_gg":
+		return 10
+	case "dnjoepej":
+		return 13
+	case "xpc_guacdtueg":
+		return 16
+	case "plqbpqxbcin":
+		return 19
+	case "eceyowsvc":
+		return 22
+	case "qdfvmovyenly":
+		return 25
+	case "otvzrlju":
+		return 28
+	case "_r_xrqblrm":
+		return 31
+	case "pmllvvnyb":
+		return 34
+	case "gwexs":
+		return 37
+	case "vsalzbeppaptll":
+		return 40
+	case "hxnjogkvu_wp":
+		return 43
+	case "w_umru":
+		return 46
+	case "rallemtfcdnj":
+		return 49
+	}
+	return 0
+}
+
+func phHash16(k string) int {
+	switch phLookup_bench16(k) {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	case 5:
+		return 16
+	case 6:
+		return 19
+	case 7:
+		return 22
+	case 8:
+		return 25
+	case 9:
+		return 28
+	case 10:
+		return 31
+	case 11:
+		return 34
+	case 12:
+		return 37
+	case 13:
+		return 40
+	case 14:
+		return 43
+	case 15:
+		return 46
+	case 16:
+		return 49
+	}
+	return 0
+}
+
+var phBenchKeys32 = []string{"zqhc", "saxkyjs", "fcd", "gkbnmbpwob", "iuryfqu", "ftqrzt", "mlhpmyglvmfltk", "farxj", "krhc", "xue", "wgvnrnr", "_vk", "flbjxvpguda", "qtmhog_", "pukigpo_", "nlafzm_gpprs", "dbbkcz_qpgmm", "ktatkrr", "zmbfup", "mympc_rh", "jbpcazry", "rlgwzfgsqspng", "sqnvhqqlaim", "ppvz_xjci", "gwrtqawku", "sxtqdspqwxo", "tywzt", "zxrk", "_sgsmv", "ce_nf", "fbdemcoxx", "kodcgpahye"}
+
+func phIf32(k string) int {
+	if k == "zqhc" {
+		return 4
+	} else if k == "saxkyjs" {
+		return 7
+	} else if k == "fcd" {
+		return 10
+	} else if k == "gkbnmbpwob" {
+		return 13
+	} else if k == "iuryfqu" {
+		return 16
+	} else if k == "ftqrzt" {
+		return 19
+	} else if k == "mlhpmyglvmfltk" {
+		return 22
+	} else if k == "farxj" {
+		return 25
+	} else if k == "krhc" {
+		return 28
+	} else if k == "xue" {
+		return 31
+	} else if k == "wgvnrnr" {
+		return 34
+	} else if k == "_vk" {
+		return 37
+	} else if k == "flbjxvpguda" {
+		return 40
+	} else if k == "qtmhog_" {
+		return 43
+	} else if k == "pukigpo_" {
+		return 46
+	} else if k == "nlafzm_gpprs" {
+		return 49
+	} else if k == "dbbkcz_qpgmm" {
+		return 52
+	} else if k == "ktatkrr" {
+		return 55
+	} else if k == "zmbfup" {
+		return 58
+	} else if k == "mympc_rh" {
+		return 61
+	} else if k == "jbpcazry" {
+		return 64
+	} else if k == "rlgwzfgsqspng" {
+		return 67
+	} else if k == "sqnvhqqlaim" {
+		return 70
+	} else if k == "ppvz_xjci" {
+		return 73
+	} else if k == "gwrtqawku" {
+		return 76
+	} else if k == "sxtqdspqwxo" {
+		return 79
+	} else if k == "tywzt" {
+		return 82
+	} else if k == "zxrk" {
+		return 85
+	} else if k == "_sgsmv" {
+		return 88
+	} else if k == "ce_nf" {
+		return 91
+	} else if k == "fbdemcoxx" {
+		return 94
+	} else if k == "kodcgpahye" {
+		return 97
+	}
+	return 0
+}
+
+func phSwitch32(k string) int {
+	switch k {
+	case "zqhc":
+		return 4
+	case "saxkyjs":
+		return 7
+	case "fcd":
+		return 10
+	case "gkbnmbpwob":
+		return 13
+	case "iuryfqu":
+		return 16
+	case "ftqrzt":
+		return 19
+	case "mlhpmyglvmfltk":
+		return 22
+	case "farxj":
+		return 25
+	case "krhc":
+		return 28
+	case "xue":
+		return 31
+	case "wgvnrnr":
+		return 34
+	case "_vk":
+		return 37
+	case "flbjxvpguda":
+		return 40
+	case "qtmhog_":
+		return 43
+	case "pukigpo_":
+		return 46
+	case "nlafzm_gpprs":
+		return 49
+	case "dbbkcz_qpgmm":
+		return 52
+	case "ktatkrr":
+		return 55
+	case "zmbfup":
+		return 58
+	case "mympc_rh":
+		return 61
+	case "jbpcazry":
+		return 64
+	case "rlgwzfgsqspng":
+		return 67
+	case "sqnvhqqlaim":
+		return 70
+	case "ppvz_xjci":
+		return 73
+	case "gwrtqawku":
+		return 76
+	case "sxtqdspqwxo":
+		return 79
+	case "tywzt":
+		return 82
+	case "zxrk":
+		return 85
+	case "_sgsmv":
+		return 88
+	case "ce_nf":
+		return 91
+	case "fbdemcoxx":
+		return 94
+	case "kodcgpahye":
+		return 97
+	}
+	return 0
+}
+
+func phHash32(k string) int {
+	switch phLookup_bench32(k) {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	case 5:
+		return 16
+	case 6:
+		return 19
+	case 7:
+		return 22
+	case 8:
+		return 25
+	case 9:
+		return 28
+	case 10:
+		return 31
+	case 11:
+		return 34
+	case 12:
+		return 37
+	case 13:
+		return 40
+	case 14:
+		return 43
+	case 15:
+		return 46
+	case 16:
+		return 49
+	case 17:
+		return 52
+	case 18:
+		return 55
+	case 19:
+		return 58
+	case 20:
+		return 61
+	case 21:
+		return 64
+	case 22:
+		return 67
+	case 23:
+		return 70
+	case 24:
+		return 73
+	case 25:
+		return 76
+	case 26:
+		return 79
+	case 27:
+		return 82
+	case 28:
+		return 85
+	case 29:
+		return 88
+	case 30:
+		return 91
+	case 31:
+		return 94
+	case 32:
+		return 97
+	}
+	return 0
+}
+
+var phBenchKeys64 = []string{"mdgrnb", "gnnljxs", "sovjs_wchcm_m", "dxluk", "hgll", "izik_", "kawjewzux", "djrasefeeolkj", "sq_jq", "_aa", "onvhkodko_b", "pyxre", "qlxr_imjih", "n_zempto", "inh_do_lzjpbm", "txmkvcdymp_", "iocnflw_ysyzis", "drw_", "lzjhwqksejtdj", "ygqrgow", "iaeu", "jomqtnrrcswf", "jhkok", "xc_", "jhwcqggqc", "a_sshtoi", "pp_lv", "ocuxog_", "ihhtafamum", "oioamokd_umh", "pk_bhrtyqtudg", "nskusjztopdiit", "oxgs", "eucd", "gwpr_z", "atkaxog", "dl_fblfqz", "pxuklisdjlcb", "z_sna", "cqvmhl", "vmxcikbjjwotrd", "pblwogxmdqfzn", "r_jrs", "uwoyfjgumos", "aitb", "wzxciqa_", "_subimpr", "phywfjuzhnkuz", "gkii", "iymvvmgziks", "mbjurxf", "rbwsdeca", "yqbhfsnxh", "kupynebeprigz", "m_mwlnraouui", "kdgjkcdjqfqvv", "ndsm", "ynn", "eaxyrelkabm_ki", "nll", "ipydlxjhxnbez", "gopcky_weh", "iq_mrsxckzn", "zfhxogvsicj"}
+
+func phIf64(k string) int {
+	if k == "mdgrnb" {
+		return 4
+	} else if k == "gnnljxs" {
+		return 7
+	} else if k == "sovjs_wchcm_m" {
+		return 10
+	} else if k == "dxluk" {
+		return 13
+	} else if k == "hgll" {
+		return 16
+	} else if k == "izik_" {
+		return 19
+	} else if k == "kawjewzux" {
+		return 22
+	} else if k == "djrasefeeolkj" {
+		return 25
+	} else if k == "sq_jq" {
+		return 28
+	} else if k == "_aa" {
+		return 31
+	} else if k == "onvhkodko_b" {
+		return 34
+	} else if k == "pyxre" {
+		return 37
+	} else if k == "qlxr_imjih" {
+		return 40
+	} else if k == "n_zempto" {
+		return 43
+	} else if k == "inh_do_lzjpbm" {
+		return 46
+	} else if k == "txmkvcdymp_" {
+		return 49
+	} else if k == "iocnflw_ysyzis" {
+		return 52
+	} else if k == "drw_" {
+		return 55
+	} else if k == "lzjhwqksejtdj" {
+		return 58
+	} else if k == "ygqrgow" {
+		return 61
+	} else if k == "iaeu" {
+		return 64
+	} else if k == "jomqtnrrcswf" {
+		return 67
+	} else if k == "jhkok" {
+		return 70
+	} else if k == "xc_" {
+		return 73
+	} else if k == "jhwcqggqc" {
+		return 76
+	} else if k == "a_sshtoi" {
+		return 79
+	} else if k == "pp_lv" {
+		return 82
+	} else if k == "ocuxog_" {
+		return 85
+	} else if k == "ihhtafamum" {
+		return 88
+	} else if k == "oioamokd_umh" {
+		return 91
+	} else if k == "pk_bhrtyqtudg" {
+		return 94
+	} else if k == "nskusjztopdiit" {
+		return 97
+	} else if k == "oxgs" {
+		return 100
+	} else if k == "eucd" {
+		return 103
+	} else if k == "gwpr_z" {
+		return 106
+	} else if k == "atkaxog" {
+		return 109
+	} else if k == "dl_fblfqz" {
+		return 112
+	} else if k == "pxuklisdjlcb" {
+		return 115
+	} else if k == "z_sna" {
+		return 118
+	} else if k == "cqvmhl" {
+		return 121
+	} else if k == "vmxcikbjjwotrd" {
+		return 124
+	} else if k == "pblwogxmdqfzn" {
+		return 127
+	} else if k == "r_jrs" {
+		return 130
+	} else if k == "uwoyfjgumos" {
+		return 133
+	} else if k == "aitb" {
+		return 136
+	} else if k == "wzxciqa_" {
+		return 139
+	} else if k == "_subimpr" {
+		return 142
+	} else if k == "phywfjuzhnkuz" {
+		return 145
+	} else if k == "gkii" {
+		return 148
+	} else if k == "iymvvmgziks" {
+		return 151
+	} else if k == "mbjurxf" {
+		return 154
+	} else if k == "rbwsdeca" {
+		return 157
+	} else if k == "yqbhfsnxh" {
+		return 160
+	} else if k == "kupynebeprigz" {
+		return 163
+	} else if k == "m_mwlnraouui" {
+		return 166
+	} else if k == "kdgjkcdjqfqvv" {
+		return 169
+	} else if k == "ndsm" {
+		return 172
+	} else if k == "ynn" {
+		return 175
+	} else if k == "eaxyrelkabm_ki" {
+		return 178
+	} else if k == "nll" {
+		return 181
+	} else if k == "ipydlxjhxnbez" {
+		return 184
+	} else if k == "gopcky_weh" {
+		return 187
+	} else if k == "iq_mrsxckzn" {
+		return 190
+	} else if k == "zfhxogvsicj" {
+		return 193
+	}
+	return 0
+}
+
+func phSwitch64(k string) int {
+	switch k {
+	case "mdgrnb":
+		return 4
+	case "gnnljxs":
+		return 7
+	case "sovjs_wchcm_m":
+		return 10
+	case "dxluk":
+		return 13
+	case "hgll":
+		return 16
+	case "izik_":
+		return 19
+	case "kawjewzux":
+		return 22
+	case "djrasefeeolkj":
+		return 25
+	case "sq_jq":
+		return 28
+	case "_aa":
+		return 31
+	case "onvhkodko_b":
+		return 34
+	case "pyxre":
+		return 37
+	case "qlxr_imjih":
+		return 40
+	case "n_zempto":
+		return 43
+	case "inh_do_lzjpbm":
+		return 46
+	case "txmkvcdymp_":
+		return 49
+	case "iocnflw_ysyzis":
+		return 52
+	case "drw_":
+		return 55
+	case "lzjhwqksejtdj":
+		return 58
+	case "ygqrgow":
+		return 61
+	case "iaeu":
+		return 64
+	case "jomqtnrrcswf":
+		return 67
+	case "jhkok":
+		return 70
+	case "xc_":
+		return 73
+	case "jhwcqggqc":
+		return 76
+	case "a_sshtoi":
+		return 79
+	case "pp_lv":
+		return 82
+	case "ocuxog_":
+		return 85
+	case "ihhtafamum":
+		return 88
+	case "oioamokd_umh":
+		return 91
+	case "pk_bhrtyqtudg":
+		return 94
+	case "nskusjztopdiit":
+		return 97
+	case "oxgs":
+		return 100
+	case "eucd":
+		return 103
+	case "gwpr_z":
+		return 106
+	case "atkaxog":
+		return 109
+	case "dl_fblfqz":
+		return 112
+	case "pxuklisdjlcb":
+		return 115
+	case "z_sna":
+		return 118
+	case "cqvmhl":
+		return 121
+	case "vmxcikbjjwotrd":
+		return 124
+	case "pblwogxmdqfzn":
+		return 127
+	case "r_jrs":
+		return 130
+	case "uwoyfjgumos":
+		return 133
+	case "aitb":
+		return 136
+	case "wzxciqa_":
+		return 139
+	case "_subimpr":
+		return 142
+	case "phywfjuzhnkuz":
+		return 145
+	case "gkii":
+		return 148
+	case "iymvvmgziks":
+		return 151
+	case "mbjurxf":
+		return 154
+	case "rbwsdeca":
+		return 157
+	case "yqbhfsnxh":
+		return 160
+	case "kupynebeprigz":
+		return 163
+	case "m_mwlnraouui":
+		return 166
+	case "kdgjkcdjqfqvv":
+		return 169
+	case "ndsm":
+		return 172
+	case "ynn":
+		return 175
+	case "eaxyrelkabm_ki":
+		return 178
+	case "nll":
+		return 181
+	case "ipydlxjhxnbez":
+		return 184
+	case "gopcky_weh":
+		return 187
+	case "iq_mrsxckzn":
+		return 190
+	case "zfhxogvsicj":
+		return 193
+	}
+	return 0
+}
+
+func phHash64(k string) int {
+	switch phLookup_bench64(k) {
+	case 1:
+		return 4
+	case 2:
+		return 7
+	case 3:
+		return 10
+	case 4:
+		return 13
+	case 5:
+		return 16
+	case 6:
+		return 19
+	case 7:
+		return 22
+	case 8:
+		return 25
+	case 9:
+		return 28
+	case 10:
+		return 31
+	case 11:
+		return 34
+	case 12:
+		return 37
+	case 13:
+		return 40
+	case 14:
+		return 43
+	case 15:
+		return 46
+	case 16:
+		return 49
+	case 17:
+		return 52
+	case 18:
+		return 55
+	case 19:
+		return 58
+	case 20:
+		return 61
+	case 21:
+		return 64
+	case 22:
+		return 67
+	case 23:
+		return 70
+	case 24:
+		return 73
+	case 25:
+		return 76
+	case 26:
+		return 79
+	case 27:
+		return 82
+	case 28:
+		return 85
+	case 29:
+		return 88
+	case 30:
+		return 91
+	case 31:
+		return 94
+	case 32:
+		return 97
+	case 33:
+		return 100
+	case 34:
+		return 103
+	case 35:
+		return 106
+	case 36:
+		return 109
+	case 37:
+		return 112
+	case 38:
+		return 115
+	case 39:
+		return 118
+	case 40:
+		return 121
+	case 41:
+		return 124
+	case 42:
+		return 127
+	case 43:
+		return 130
+	case 44:
+		return 133
+	case 45:
+		return 136
+	case 46:
+		return 139
+	case 47:
+		return 142
+	case 48:
+		return 145
+	case 49:
+		return 148
+	case 50:
+		return 151
+	case 51:
+		return 154
+	case 52:
+		return 157
+	case 53:
+		return 160
+	case 54:
+		return 163
+	case 55:
+		return 166
+	case 56:
+		return 169
+	case 57:
+		return 172
+	case 58:
+		return 175
+	case 59:
+		return 178
+	case 60:
+		return 181
+	case 61:
+		return 184
+	case 62:
+		return 187
+	case 63:
+		return 190
+	case 64:
+		return 193
+	}
+	return 0
+}
+
+func BenchmarkPerfectHash(b *testing.B) {
+	b.Run("keys8/if", func(b *testing.B) { benchJumpString(b, phBenchKeys8, phIf8) })
+	b.Run("keys8/switch", func(b *testing.B) { benchJumpString(b, phBenchKeys8, phSwitch8) })
+	b.Run("keys8/hash", func(b *testing.B) { benchJumpString(b, phBenchKeys8, phHash8) })
+	b.Run("keys16/if", func(b *testing.B) { benchJumpString(b, phBenchKeys16, phIf16) })
+	b.Run("keys16/switch", func(b *testing.B) { benchJumpString(b, phBenchKeys16, phSwitch16) })
+	b.Run("keys16/hash", func(b *testing.B) { benchJumpString(b, phBenchKeys16, phHash16) })
+	b.Run("keys32/if", func(b *testing.B) { benchJumpString(b, phBenchKeys32, phIf32) })
+	b.Run("keys32/switch", func(b *testing.B) { benchJumpString(b, phBenchKeys32, phSwitch32) })
+	b.Run("keys32/hash", func(b *testing.B) { benchJumpString(b, phBenchKeys32, phHash32) })
+	b.Run("keys64/if", func(b *testing.B) { benchJumpString(b, phBenchKeys64, phIf64) })
+	b.Run("keys64/switch", func(b *testing.B) { benchJumpString(b, phBenchKeys64, phSwitch64) })
+	b.Run("keys64/hash", func(b *testing.B) { benchJumpString(b, phBenchKeys64, phHash64) })
+}
+
+var phKeys_bench8 = [16]string{0: "aqllch", 2: "imeimv_", 3: "kwvxjx", 4: "anict", 6: "yldb_ivtkidpur", 12: "wum", 13: "lcccyimdxjf", 15: "gdr"}
+
+var phBranch_bench8 = [16]uint8{0: 6, 2: 1, 3: 4, 4: 3, 6: 5, 12: 7, 13: 2, 15: 8}
+
+func phLookup_bench8(s string) int {
+	if len(s) < 3 {
+		return 0
+	}
+	h := uint32(2166136261)
+	h = (h ^ uint32(len(s))) * 16777619
+	h = (h ^ uint32(s[0])) * 16777619
+	if i := (h * 104415703) >> 28; phKeys_bench8[i] == s {
+		return int(phBranch_bench8[i])
+	}
+	return 0
+}
+
+var phKeys_bench16 = [32]string{1: "plqbpqxbcin", 3: "hxnjogkvu_wp", 4: "qdfvmovyenly", 6: "xpc_guacdtueg", 8: "rallemtfcdnj", 9: "ynfide", 11: "byiqjzvaist", 12: "pmllvvnyb", 14: "_r_xrqblrm", 15: "w_umru", 17: "obdkcd_gg", 20: "dnjoepej", 24: "vsalzbeppaptll", 25: "gwexs", 27: "eceyowsvc", 31: "otvzrlju"}
+
+var phBranch_bench16 = [32]uint8{1: 6, 3: 14, 4: 8, 6: 5, 8: 16, 9: 2, 11: 1, 12: 11, 14: 10, 15: 15, 17: 3, 20: 4, 24: 13, 25: 12, 27: 7, 31: 9}
+
+func phLookup_bench16(s string) int {
+	if len(s) < 5 {
+		return 0
+	}
+	h := uint32(2166136261)
+	h = (h ^ uint32(len(s))) * 16777619
+	h = (h ^ uint32(s[0])) * 16777619
+	if i := (h * 2050722675) >> 27; phKeys_bench16[i] == s {
+		return int(phBranch_bench16[i])
+	}
+	return 0
+}
+
+var phKeys_bench32 = [128]string{5: "sqnvhqqlaim", 6: "pukigpo_", 9: "mympc_rh", 13: "gwrtqawku", 15: "wgvnrnr", 16: "fbdemcoxx", 22: "fcd", 28: "_vk", 30: "sxtqdspqwxo", 34: "ktatkrr", 38: "krhc", 39: "_sgsmv", 41: "gkbnmbpwob", 54: "zqhc", 55: "ftqrzt", 56: "nlafzm_gpprs", 59: "saxkyjs", 61: "flbjxvpguda", 63: "ppvz_xjci", 66: "zxrk", 73: "tywzt", 77: "dbbkcz_qpgmm", 79: "rlgwzfgsqspng", 83: "ce_nf", 85: "kodcgpahye", 87: "iuryfqu", 99: "qtmhog_", 100: "jbpcazry", 105: "mlhpmyglvmfltk", 111: "zmbfup", 121: "xue", 123: "farxj"}
+
+var phBranch_bench32 = [128]uint8{5: 23, 6: 15, 9: 20, 13: 25, 15: 11, 16: 31, 22: 3, 28: 12, 30: 26, 34: 18, 38: 9, 39: 29, 41: 4, 54: 1, 55: 6, 56: 16, 59: 2, 61: 13, 63: 24, 66: 28, 73: 27, 77: 17, 79: 22, 83: 30, 85: 32, 87: 5, 99: 14, 100: 21, 105: 7, 111: 19, 121: 10, 123: 8}
+
+func phLookup_bench32(s string) int {
+	if len(s) < 3 {
+		return 0
+	}
+	h := uint32(2166136261)
+	h = (h ^ uint32(len(s))) * 16777619
+	h = (h ^ uint32(s[len(s)-3])) * 16777619
+	if i := (h * 272191893) >> 25; phKeys_bench32[i] == s {
+		return int(phBranch_bench32[i])
+	}
+	return 0
+}
+
+var phKeys_bench64 = [256]string{0: "ndsm", 3: "aitb", 7: "djrasefeeolkj", 8: "iq_mrsxckzn", 9: "drw_", 10: "iaeu", 16: "onvhkodko_b", 17: "izik_", 25: "ocuxog_", 26: "jhwcqggqc", 31: "gopcky_weh", 36: "iocnflw_ysyzis", 42: "n_zempto", 51: "txmkvcdymp_", 63: "jomqtnrrcswf", 68: "pyxre", 71: "r_jrs", 72: "ipydlxjhxnbez", 74: "vmxcikbjjwotrd", 79: "ygqrgow", 81: "xc_", 85: "zfhxogvsicj", 86: "oxgs", 88: "nskusjztopdiit", 90: "mdgrnb", 93: "cqvmhl", 96: "_subimpr", 100: "pxuklisdjlcb", 101: "gkii", 103: "yqbhfsnxh", 108: "_aa", 110: "qlxr_imjih", 111: "eaxyrelkabm_ki", 113: "a_sshtoi", 115: "lzjhwqksejtdj", 121: "nll", 130: "pk_bhrtyqtudg", 131: "dl_fblfqz", 132: "atkaxog", 134: "gnnljxs", 144: "ihhtafamum", 146: "gwpr_z", 147: "dxluk", 149: "sovjs_wchcm_m", 152: "inh_do_lzjpbm", 153: "mbjurxf", 159: "kupynebeprigz", 165: "jhkok", 176: "pp_lv", 178: "eucd", 192: "phywfjuzhnkuz", 195: "m_mwlnraouui", 206: "wzxciqa_", 212: "uwoyfjgumos", 217: "iymvvmgziks", 223: "z_sna", 224: "ynn", 231: "oioamokd_umh", 234: "pblwogxmdqfzn", 236: "rbwsdeca", 245: "kdgjkcdjqfqvv", 250: "hgll", 254: "kawjewzux", 255: "sq_jq"}
+
+var phBranch_bench64 = [256]uint8{0: 57, 3: 45, 7: 8, 8: 63, 9: 18, 10: 21, 16: 11, 17: 6, 25: 28, 26: 25, 31: 62, 36: 17, 42: 14, 51: 16, 63: 22, 68: 12, 71: 43, 72: 61, 74: 41, 79: 20, 81: 24, 85: 64, 86: 33, 88: 32, 90: 1, 93: 40, 96: 47, 100: 38, 101: 49, 103: 53, 108: 10, 110: 13, 111: 59, 113: 26, 115: 19, 121: 60, 130: 31, 131: 37, 132: 36, 134: 2, 144: 29, 146: 35, 147: 4, 149: 3, 152: 15, 153: 51, 159: 54, 165: 23, 176: 27, 178: 34, 192: 48, 195: 55, 206: 46, 212: 44, 217: 50, 223: 39, 224: 58, 231: 30, 234: 42, 236: 52, 245: 56, 250: 5, 254: 7, 255: 9}
+
+func phLookup_bench64(s string) int {
+	if len(s) < 3 {
+		return 0
+	}
+	h := uint32(2166136261)
+	h = (h ^ uint32(len(s))) * 16777619
+	h = (h ^ uint32(s[1])) * 16777619
+	h = (h ^ uint32(s[0])) * 16777619
+	if i := (h * 1547497273) >> 24; phKeys_bench64[i] == s {
+		return int(phBranch_bench64[i])
+	}
+	return 0
+}
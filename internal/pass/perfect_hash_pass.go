@@ -0,0 +1,360 @@
+package pass
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	stdastutil "golang.org/x/tools/go/ast/astutil"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// PerfectHashPass troca switch/if encadeados com muitas chaves string por um
+// hash perfeito calculado em tempo de transpilação: tamanho + alguns bytes
+// escolhidos → slot de um array fixo, seguido de uma única comparação.
+//
+//	switch cmd {                 switch phLookup_cmd(cmd) {
+//	case "GET": ...        →     case 1: ...
+//	... (40+ casos)              ...
+//	default: ...                 default: ...
+//	}                            }
+//
+// O despacho reaproveita o do JumpTable: os ramos ficam inline e na ordem
+// original, então fallthrough, default e o fluxo de controle são preservados.
+type PerfectHashPass struct {
+	used map[string]bool // nomes já emitidos no pacote
+}
+
+// Limiar medido com perfect_hash_bench_test.go (amd64, go1.27), ns/op por
+// despacho com chaves aleatórias de 3 a 14 bytes e entradas embaralhadas (10%
+// ausentes):
+//
+//	chaves   cadeia de if   switch nativo   hash perfeito
+//	   8          7.2            6.1              8.6
+//	  16         11.9            6.0             10.4
+//	  32         21.3            6.6              9.6
+//	  64         33.9            7.3             10.4
+//
+// O mapa do JumpTable fica em 23–31 ns/op na mesma faixa (jump_table_bench_test.go).
+// O hash vence a cadeia de if a partir de 16 chaves e o mapa sempre, mas não o
+// switch nativo: sem ctx.Ofuscate o switch fica como está.
+const perfectHashMinKeys = 16
+
+const (
+	fnvOffset32 = 2166136261
+	fnvPrime32  = 16777619
+)
+
+func NewPerfectHashPass() *PerfectHashPass { return &PerfectHashPass{} }
+func (p *PerfectHashPass) Name() string    { return "PerfectHash" }
+
+// Prepare resets the helper names emitted for the package
+func (p *PerfectHashPass) Prepare(_ []*ast.File, _ *token.FileSet, _ *astutil.TranspileContext) error {
+	p.used = make(map[string]bool)
+	return nil
+}
+
+func (p *PerfectHashPass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	if p.used == nil {
+		p.used = make(map[string]bool)
+	}
+	transformations := 0
+	var helpers []string
+
+	// Mesmo esquema do JumpTable: para no nó substituído, segue pelos ramos e
+	// não volta às caudas `else if` de uma cadeia recusada
+	done := make(map[*ast.IfStmt]bool)
+	var visit func(c *stdastutil.Cursor) bool
+	visit = func(c *stdastutil.Cursor) bool {
+		var chain *jumpChain
+		var reason string
+		switch node := c.Node().(type) {
+		case *ast.IfStmt:
+			if done[node] {
+				return true
+			}
+			chain, reason = collectIfChain(node, ctx)
+		case *ast.SwitchStmt:
+			chain, reason = collectSwitch(node, ctx)
+		default:
+			return true
+		}
+		if chain == nil {
+			return true
+		}
+		if !isStringType(chain.keyType) {
+			chain.skipTails(done)
+			return true
+		}
+		keys, index := chainKeys(chain)
+		if len(keys) < perfectHashMinKeys {
+			chain.skipTails(done)
+			return true
+		}
+		pos := fset.Position(c.Node().Pos())
+
+		var ph *perfectHash
+		if reason == "" && chain.fromSwitch && !ctx.Ofuscate {
+			reason = "native switch is faster than a perfect hash"
+		}
+		if reason == "" {
+			var ok bool
+			if ph, ok = buildPerfectHash(keys); !ok {
+				reason = "no collision-free hash over the selected bytes"
+			}
+		}
+		if reason != "" {
+			chain.skipTails(done)
+			gl.Log("info", fmt.Sprintf("PerfectHash: skipping chain at %s (%s)", pos, reason))
+			ctx.RecordLedger(p.Name(), pos, types.ExprString(chain.subject), "skipped", reason)
+			return true
+		}
+
+		name := p.helperName(file, chain, ctx)
+		helpers = append(helpers, ph.source(name, keys, index))
+
+		var arg ast.Expr = chain.subject
+		if basic, ok := types.Unalias(chain.keyType).(*types.Basic); !ok || basic.Kind() != types.String {
+			arg = &ast.CallExpr{Fun: ast.NewIdent("string"), Args: []ast.Expr{arg}}
+		}
+		sw := dispatchSwitch(chain, &ast.CallExpr{Fun: ast.NewIdent("phLookup_" + name), Args: []ast.Expr{arg}})
+		var replacement ast.Stmt = sw
+		if c.Name() == "Else" {
+			replacement = &ast.BlockStmt{Lbrace: chain.pos, Rbrace: chain.end, List: []ast.Stmt{replacement}}
+		}
+		c.Replace(replacement)
+		ctx.RecordLedger(p.Name(), pos, types.ExprString(chain.subject), "rewritten",
+			fmt.Sprintf("%d keys, %d slots, %d bytes hashed", len(keys), len(ph.slots), len(ph.positions)))
+		transformations++
+
+		for _, clause := range sw.Body.List {
+			stdastutil.Apply(clause, visit, nil)
+		}
+		return false
+	}
+	stdastutil.Apply(file, visit, nil)
+
+	for _, src := range helpers {
+		decls, err := astutil.ParseDecls(fset, src)
+		if err != nil {
+			return fmt.Errorf("PerfectHash: %w", err)
+		}
+		file.Decls = append(file.Decls, decls...)
+	}
+
+	if transformations > 0 {
+		ctx.LogVerbose(fset, "#️⃣ PerfectHashPass: %d transformations applied", transformations)
+	}
+	return nil
+}
+
+// helperName returns a suffix such that phKeys_, phBranch_ and phLookup_ are all free
+func (p *PerfectHashPass) helperName(file *ast.File, chain *jumpChain, ctx *astutil.TranspileContext) string {
+	free := func(name string) bool {
+		return !p.used[name] && freshLocalName(file, name) == name && freshPackageName(ctx.Package, name) == name
+	}
+	for i := 1; ; i++ {
+		suffix := chain.name
+		if i > 1 {
+			suffix += strconv.Itoa(i)
+		}
+		if free("phKeys_"+suffix) && free("phBranch_"+suffix) && free("phLookup_"+suffix) {
+			p.used["phKeys_"+suffix], p.used["phBranch_"+suffix], p.used["phLookup_"+suffix] = true, true, true
+			return suffix
+		}
+	}
+}
+
+// chainKeys returns the string keys of a chain and the branch index of each
+func chainKeys(chain *jumpChain) ([]string, []int) {
+	var keys []string
+	var index []int
+	branch := 0
+	for _, jc := range chain.cases {
+		if jc.isDefault {
+			continue
+		}
+		branch++
+		for _, k := range jc.keys {
+			keys = append(keys, constant.StringVal(k))
+			index = append(index, branch)
+		}
+	}
+	return keys, index
+}
+
+func isStringType(t types.Type) bool {
+	basic, ok := t.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsString != 0
+}
+
+// perfectHash is FNV-1a over len(s) and a few bytes of s, followed by a
+// multiply-shift that keeps the top bits. The empty key, which has no bytes
+// to hash, gets its own slot right after the hashed ones.
+type perfectHash struct {
+	positions []int  // >= 0 conta do início, < 0 do fim (len(s)+p)
+	minLen    int    // menor chave não vazia
+	mult      uint32 // multiplicador ímpar escolhido na busca
+	bits      int
+	slots     []int // slot → índice da chave, -1 vazio
+	empty     bool  // "" é chave e ocupa o slot 1<<bits
+}
+
+func (ph *perfectHash) hash(s string) uint32 {
+	if s == "" && ph.empty {
+		return 1 << ph.bits
+	}
+	h := uint32(fnvOffset32)
+	h = (h ^ uint32(len(s))) * fnvPrime32
+	for _, p := range ph.positions {
+		if p < 0 {
+			p += len(s)
+		}
+		h = (h ^ uint32(s[p])) * fnvPrime32
+	}
+	return (h * ph.mult) >> (32 - ph.bits)
+}
+
+// buildPerfectHash picks the byte positions that tell the keys apart, then
+// searches a seed and table size without collisions
+func buildPerfectHash(keys []string) (*perfectHash, bool) {
+	ph := &perfectHash{}
+	var hashed []string
+	for _, k := range keys {
+		if k == "" {
+			ph.empty = true
+			continue
+		}
+		if len(hashed) == 0 || len(k) < ph.minLen {
+			ph.minLen = len(k)
+		}
+		hashed = append(hashed, k)
+	}
+
+	// Posições seguras para toda chave (e para qualquer s com len >= minLen)
+	var candidates []int
+	for i := 0; i < ph.minLen; i++ {
+		candidates = append(candidates, i, -1-i)
+	}
+	tuple := func(k string, positions []int) string {
+		var b strings.Builder
+		b.WriteString(strconv.Itoa(len(k)))
+		for _, p := range positions {
+			if p < 0 {
+				p += len(k)
+			}
+			b.WriteByte(':')
+			b.WriteByte(k[p])
+		}
+		return b.String()
+	}
+	distinct := func(positions []int) int {
+		seen := make(map[string]bool, len(hashed))
+		for _, k := range hashed {
+			seen[tuple(k, positions)] = true
+		}
+		return len(seen)
+	}
+
+	for distinct(ph.positions) < len(hashed) {
+		if len(ph.positions) == 8 {
+			return nil, false
+		}
+		best, bestCount := 0, -1
+		for _, c := range candidates {
+			if count := distinct(append(ph.positions[:len(ph.positions):len(ph.positions)], c)); count > bestCount {
+				best, bestCount = c, count
+			}
+		}
+		if bestCount <= distinct(ph.positions) {
+			return nil, false
+		}
+		ph.positions = append(ph.positions, best)
+	}
+
+	start := bits.Len(uint(max(len(hashed), 1) - 1))
+	for ph.bits = max(start+1, 1); ph.bits <= start+4; ph.bits++ {
+		for seed := uint32(0); seed < 4096; seed++ {
+			// Só o FNV deixa chaves que diferem num byte final presas aos mesmos bits altos
+			ph.mult = (seed*fnvPrime32+fnvOffset32)*2 + 1
+			if ph.place(keys) {
+				return ph, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// place fills the slots, reporting false on the first collision
+func (ph *perfectHash) place(keys []string) bool {
+	ph.slots = make([]int, 1<<ph.bits)
+	if ph.empty {
+		ph.slots = append(ph.slots, -1)
+	}
+	for i := range ph.slots {
+		ph.slots[i] = -1
+	}
+	for i, k := range keys {
+		h := ph.hash(k)
+		if ph.slots[h] >= 0 {
+			return false
+		}
+		ph.slots[h] = i
+	}
+	return true
+}
+
+// source renders the tables and the lookup function for keys
+func (ph *perfectHash) source(name string, keys []string, index []int) string {
+	branchType := "uint8"
+	if index[len(index)-1] > 255 {
+		branchType = "uint16"
+	}
+	var keyElts, branchElts []string
+	for slot, i := range ph.slots {
+		if i >= 0 {
+			keyElts = append(keyElts, fmt.Sprintf("%d: %s", slot, strconv.Quote(keys[i])))
+			branchElts = append(branchElts, fmt.Sprintf("%d: %d", slot, index[i]))
+		}
+	}
+
+	var mix strings.Builder
+	for _, p := range ph.positions {
+		at := strconv.Itoa(p)
+		if p < 0 {
+			at = fmt.Sprintf("len(s)-%d", -p)
+		}
+		fmt.Fprintf(&mix, "\th = (h ^ uint32(s[%s])) * %d\n", at, fnvPrime32)
+	}
+	guard := ""
+	if ph.empty {
+		guard = fmt.Sprintf("\tif len(s) == 0 {\n\t\treturn int(phBranch_%s[%d])\n\t}\n", name, 1<<ph.bits)
+	}
+	// Com "" já tratado, len(s) >= 1 basta quando a menor chave tem um byte
+	if ph.minLen > 1 || (ph.minLen == 1 && !ph.empty) {
+		guard += fmt.Sprintf("\tif len(s) < %d {\n\t\treturn 0\n\t}\n", ph.minLen)
+	}
+
+	return fmt.Sprintf(`var phKeys_%[1]s = [%[2]d]string{%[3]s}
+
+var phBranch_%[1]s = [%[2]d]%[4]s{%[5]s}
+
+func phLookup_%[1]s(s string) int {
+%[6]s	h := uint32(%[7]d)
+	h = (h ^ uint32(len(s))) * %[8]d
+%[9]s	if i := (h * %[11]d) >> %[10]d; phKeys_%[1]s[i] == s {
+		return int(phBranch_%[1]s[i])
+	}
+	return 0
+}
+`, name, len(ph.slots), strings.Join(keyElts, ", "), branchType, strings.Join(branchElts, ", "),
+		guard, fnvOffset32, fnvPrime32, mix.String(), 32-ph.bits, ph.mult)
+}
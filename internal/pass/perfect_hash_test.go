@@ -0,0 +1,186 @@
+package pass
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// perfectHashKeySets mixes random keys with sets that only differ in one byte,
+// share long prefixes or suffixes, or all have the same length.
+func perfectHashKeySets() map[string][]string {
+	rnd := rand.New(rand.NewSource(33))
+	sets := make(map[string][]string)
+	for _, n := range []int{16, 17, 40, 100, 255, 300} {
+		seen := make(map[string]bool)
+		var keys []string
+		for len(keys) < n {
+			b := make([]byte, 3+rnd.Intn(12))
+			for i := range b {
+				b[i] = "abcdefghijklmnopqrstuvwxyz_-"[rnd.Intn(28)]
+			}
+			if k := string(b); !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+		sets["random"+strconv.Itoa(n)] = keys
+	}
+
+	var lastByte, prefix, suffix, sameLen []string
+	for i := 0; i < 26; i++ {
+		lastByte = append(lastByte, "command_"+string(rune('a'+i)))
+		prefix = append(prefix, "github.com/kubex-ecosystem/"+strconv.Itoa(i*7))
+		suffix = append(suffix, strconv.Itoa(i)+".internal.example.com")
+		sameLen = append(sameLen, fmt.Sprintf("k%03d", i*37))
+	}
+	sets["lastByte"], sets["prefix"], sets["suffix"], sets["sameLen"] = lastByte, prefix, suffix, sameLen
+	// "" fica no próprio slot; "a" força a guarda de tamanho a aceitar 1 byte
+	sets["empty"] = append([]string{"", "a"}, lastByte...)
+	return sets
+}
+
+func TestPerfectHashNoCollisions(t *testing.T) {
+	for name, keys := range perfectHashKeySets() {
+		ph, ok := buildPerfectHash(keys)
+		if !ok {
+			t.Errorf("%s: no perfect hash found for %d keys", name, len(keys))
+			continue
+		}
+		used := make(map[uint32]string)
+		for i, k := range keys {
+			h := ph.hash(k)
+			if other, dup := used[h]; dup {
+				t.Errorf("%s: %q and %q collide in slot %d", name, k, other, h)
+			}
+			used[h] = k
+			if ph.slots[h] != i {
+				t.Errorf("%s: slot %d holds key %d, want %d (%q)", name, h, ph.slots[h], i, k)
+			}
+		}
+		filled := 0
+		for _, i := range ph.slots {
+			if i >= 0 {
+				filled++
+			}
+		}
+		if filled != len(keys) {
+			t.Errorf("%s: %d filled slots for %d keys", name, filled, len(keys))
+		}
+	}
+}
+
+func TestPerfectHashSkipsChainOnce(t *testing.T) {
+	_, ctx := transpileSource(t, sideEffectChain(20), true, NewPerfectHashPass())
+	if got := countLedger(ctx, "PerfectHash", "skipped"); got != 1 {
+		t.Errorf("%d skipped entries for one chain, want 1", got)
+	}
+	// Cadeias de inteiros ficam para o JumpTable
+	if _, ctx := transpileSource(t, jumpTableProbe, true, NewPerfectHashPass()); len(ctx.Ledger) != 0 {
+		t.Errorf("integer chains touched: %+v", ctx.Ledger)
+	}
+}
+
+// TestPerfectHashLookupRejectsNonKeys compiles the generated lookup and checks
+// it returns each key's branch and 0 for strings near the keys.
+func TestPerfectHashLookupRejectsNonKeys(t *testing.T) {
+	requireGo(t)
+	sets := perfectHashKeySets()
+	var names []string
+	var src strings.Builder
+	src.WriteString("package main\n\nimport \"fmt\"\n\n")
+	var checks strings.Builder
+	for name, keys := range sets {
+		ph, ok := buildPerfectHash(keys)
+		if !ok {
+			t.Fatalf("%s: no perfect hash found", name)
+		}
+		index := make([]int, len(keys))
+		isKey := make(map[string]bool)
+		for i, k := range keys {
+			index[i] = i%200 + 1 // ramos repetidos, como cases com várias chaves
+			isKey[k] = true
+		}
+		src.WriteString(ph.source(name, keys, index))
+		names = append(names, name)
+
+		for i, k := range keys {
+			fmt.Fprintf(&checks, "\tcheck(%q, phLookup_%s(%q), %d)\n", name, name, k, index[i])
+			// Vizinhos: byte extra, byte a menos, um byte trocado, caixa diferente
+			near := []string{k + "x", "x" + k, strings.ToUpper(k)}
+			if k != "" {
+				near = append(near, k[1:], k[:len(k)-1], k[:len(k)-1]+string(k[len(k)-1]^1))
+			}
+			for _, n := range near {
+				if !isKey[n] {
+					fmt.Fprintf(&checks, "\tcheck(%q, phLookup_%s(%q), 0)\n", name, name, n)
+				}
+			}
+		}
+	}
+	fmt.Fprintf(&src, `
+var failures int
+
+func check(set string, got, want int) {
+	if got != want {
+		failures++
+		fmt.Println(set, got, want)
+	}
+}
+
+func main() {
+%s	fmt.Println("failures:", failures)
+}
+`, checks.String())
+
+	if got := runSource(t, src.String()); got != "failures: 0\n" {
+		t.Errorf("lookup mismatches over %v:\n%s", names, got)
+	}
+}
+
+const perfectHashProbe = `package main
+
+import "fmt"
+
+func route(cmd string) string {
+	switch cmd {
+	case "GET", "HEAD":
+		return "read"
+	case "PUT", "PATCH":
+		return "write"
+	case "POST":
+		return "create"
+	case "DELETE":
+		return "delete"
+	case "OPTIONS", "TRACE", "CONNECT":
+		return "meta"
+	case "PROPFIND", "PROPPATCH", "MKCOL", "COPY", "MOVE", "LOCK", "UNLOCK":
+		return "dav"
+	case "PURGE", "LINK", "UNLINK":
+		return "cache"
+	}
+	return "unknown"
+}
+
+func main() {
+	for _, cmd := range []string{"GET", "HEAD", "PUT", "PATCH", "POST", "DELETE", "OPTIONS", "TRACE",
+		"CONNECT", "PROPFIND", "PROPPATCH", "MKCOL", "COPY", "MOVE", "LOCK", "UNLOCK", "PURGE", "LINK",
+		"UNLINK", "get", "GETS", "", "G", "DELET", "LOCKS", "UNLINKED"} {
+		fmt.Println(cmd, route(cmd))
+	}
+}
+`
+
+func TestPerfectHashPreservesBehavior(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, perfectHashProbe)
+	out, ctx := transpileSource(t, perfectHashProbe, true, NewPerfectHashPass())
+	if got := countLedger(ctx, "PerfectHash", "rewritten"); got != 1 {
+		t.Fatalf("%d switches rewritten, want 1\n%s", got, out)
+	}
+	if got := runSource(t, out); got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+}
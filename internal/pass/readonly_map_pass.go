@@ -0,0 +1,557 @@
+package pass
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	stdastutil "golang.org/x/tools/go/ast/astutil"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// ReadOnlyMapPass troca tabelas de lookup (maps de pacote inicializados por
+// literal e nunca alterados) por um switch gerado ou, com chaves inteiras
+// contíguas, por um array indexado, sem hashing a cada acesso:
+//
+//	var levels = map[LogType]LogLevel{       func levelsGet(key LogType) (v LogLevel) {
+//		Info:  LevelInfo,                 →      switch key {
+//		Error: LevelError,                       case Info:
+//	}                                                return LevelInfo
+//	                                             ...
+//	lvl := levels[t]                         lvl := levelsGet(t)
+//	lvl, ok := levels[t]                     lvl, ok := levelsLookup(t)
+//	n := len(levels)                         n := 2
+//
+// Chaves e valores precisam ser constantes. A var não pode escapar: todo uso
+// é m[k], v, ok := m[k] ou len(m); escrita, delete, clear, range, passar o
+// map adiante ou exportá-lo de um pacote que não é main mantêm o map. Chave
+// ausente continua devolvendo o zero do tipo (e ok == false).
+type ReadOnlyMapPass struct {
+	plans map[*types.Var]*roMapPlan
+	order []*roMapPlan
+	used  map[string]bool
+}
+
+// roMapPlan describes one read-only map and its generated lookups
+type roMapPlan struct {
+	obj       *types.Var
+	file      *ast.File
+	decl      *ast.GenDecl
+	spec      *ast.ValueSpec
+	lit       *ast.CompositeLit
+	keyType   types.Type
+	valueType types.Type
+	entries   []*ast.KeyValueExpr
+	array     bool // chaves inteiras contíguas
+	min       constant.Value
+	needGet   bool
+	needOk    bool
+	lookups   int
+	get       *types.Func
+	lookup    *types.Func
+	table     *types.Var
+	names     map[string]string // get/lookup/table → nome gerado
+}
+
+// roMapArrayMin is the smallest contiguous integer table turned into an array
+const roMapArrayMin = 4
+
+func NewReadOnlyMapPass() *ReadOnlyMapPass { return &ReadOnlyMapPass{} }
+func (p *ReadOnlyMapPass) Name() string    { return "ReadOnlyMap" }
+
+// Prepare finds the candidate maps and checks every use in the package
+func (p *ReadOnlyMapPass) Prepare(files []*ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	p.plans = make(map[*types.Var]*roMapPlan)
+	p.order = nil
+	p.used = make(map[string]bool)
+	for _, file := range files {
+		collectIdentNames(file, p.used)
+	}
+
+	// === 1️⃣ Candidatos: var de pacote = map literal de constantes ===
+	candidates := make(map[*types.Var]*roMapPlan)
+	var order []*roMapPlan
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				if len(vs.Names) != 1 || len(vs.Values) != 1 {
+					continue
+				}
+				lit, ok := vs.Values[0].(*ast.CompositeLit)
+				if !ok {
+					continue
+				}
+				obj, ok := ctx.GetDefs()[vs.Names[0]].(*types.Var)
+				if !ok {
+					continue
+				}
+				mt, ok := obj.Type().Underlying().(*types.Map)
+				if !ok {
+					continue
+				}
+				plan := &roMapPlan{obj: obj, file: file, decl: gd, spec: vs, lit: lit, keyType: mt.Key(), valueType: mt.Elem()}
+				if reason := plan.literalReason(ctx); reason != "" {
+					p.reject(fset, ctx, plan, reason)
+					continue
+				}
+				if obj.Exported() && ctx.Package.Name() != "main" {
+					p.reject(fset, ctx, plan, "exported outside package main")
+					continue
+				}
+				candidates[obj] = plan
+				order = append(order, plan)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// === 2️⃣ Todo uso precisa ser uma leitura ===
+	rejected := make(map[*types.Var]string)
+	for _, file := range files {
+		var stack []ast.Node
+		ast.Inspect(file, func(n ast.Node) bool {
+			if n == nil {
+				stack = stack[:len(stack)-1]
+				return true
+			}
+			if id, ok := n.(*ast.Ident); ok {
+				if v, ok := ctx.GetUses()[id].(*types.Var); ok && candidates[v] != nil && rejected[v] == "" {
+					if reason := candidates[v].use(id, stack, ctx); reason != "" {
+						rejected[v] = reason
+					}
+				}
+			}
+			stack = append(stack, n)
+			return true
+		})
+	}
+
+	for _, plan := range order {
+		reason := rejected[plan.obj]
+		if reason == "" && plan.lookups == 0 {
+			reason = "never read"
+		}
+		if reason != "" {
+			p.reject(fset, ctx, plan, reason)
+			continue
+		}
+		plan.array = plan.contiguous(ctx)
+		p.allocate(plan, ctx)
+		p.plans[plan.obj] = plan
+		p.order = append(p.order, plan)
+	}
+	return nil
+}
+
+func (p *ReadOnlyMapPass) reject(fset *token.FileSet, ctx *astutil.TranspileContext, plan *roMapPlan, reason string) {
+	gl.Log("info", fmt.Sprintf("ReadOnlyMap: skipping %s (%s)", plan.obj.Name(), reason))
+	ctx.RecordLedger(p.Name(), fset.Position(plan.obj.Pos()), plan.obj.Name(), "rejected", reason)
+}
+
+// literalReason checks that the literal only has constant keys and values
+func (plan *roMapPlan) literalReason(ctx *astutil.TranspileContext) string {
+	if b, ok := plan.keyType.Underlying().(*types.Basic); !ok || b.Info()&types.IsConstType == 0 {
+		return "key type " + plan.keyType.String() + " is not a basic type"
+	}
+	if len(plan.lit.Elts) == 0 {
+		return "empty map"
+	}
+	for _, elt := range plan.lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return "malformed literal"
+		}
+		if ctx.GetTypes()[kv.Key].Value == nil {
+			return "non-constant key " + types.ExprString(kv.Key)
+		}
+		if ctx.GetTypes()[kv.Value].Value == nil {
+			// Literal sem tipo ({...}) sairia como "(ast: <nil>){...}"
+			if lit, ok := kv.Value.(*ast.CompositeLit); ok && lit.Type == nil {
+				return "non-constant value " + plan.valueType.String() + " literal"
+			}
+			return "non-constant value " + types.ExprString(kv.Value)
+		}
+		plan.entries = append(plan.entries, kv)
+	}
+	return ""
+}
+
+// use classifies one use of the map; stack holds the ancestors of id
+func (plan *roMapPlan) use(id *ast.Ident, stack []ast.Node, ctx *astutil.TranspileContext) string {
+	parent := stack[len(stack)-1]
+	var grand ast.Node
+	if len(stack) >= 2 {
+		grand = stack[len(stack)-2]
+	}
+	switch p := parent.(type) {
+	case *ast.IndexExpr:
+		if p.X != id {
+			break
+		}
+		switch g := grand.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range g.Lhs {
+				if lhs == p {
+					return "written"
+				}
+			}
+			if len(g.Lhs) == 2 && len(g.Rhs) == 1 {
+				plan.needOk = true
+				plan.lookups++
+				return ""
+			}
+		case *ast.IncDecStmt:
+			return "written"
+		case *ast.ValueSpec:
+			if len(g.Names) == 2 && len(g.Values) == 1 {
+				plan.needOk = true
+				plan.lookups++
+				return ""
+			}
+		}
+		plan.needGet = true
+		plan.lookups++
+		return ""
+	case *ast.CallExpr:
+		if len(p.Args) == 1 && p.Args[0] == id && isBuiltinCall(p, "len", ctx) {
+			return ""
+		}
+		if isBuiltinCall(p, "delete", ctx) || isBuiltinCall(p, "clear", ctx) {
+			return "written by " + types.ExprString(p.Fun)
+		}
+		return "passed to " + types.ExprString(p.Fun)
+	case *ast.RangeStmt:
+		return "ranged over"
+	case *ast.AssignStmt, *ast.ValueSpec:
+		return "copied to another variable"
+	case *ast.BinaryExpr:
+		return "compared"
+	case *ast.ReturnStmt:
+		return "returned"
+	}
+	return "used as a value"
+}
+
+// contiguous reports whether the integer keys cover min..max, and sets min
+func (plan *roMapPlan) contiguous(ctx *astutil.TranspileContext) bool {
+	b := plan.keyType.Underlying().(*types.Basic)
+	if b.Info()&types.IsInteger == 0 || len(plan.entries) < roMapArrayMin {
+		return false
+	}
+	var lo, hi constant.Value
+	for _, kv := range plan.entries {
+		k := ctx.GetTypes()[kv.Key].Value
+		if lo == nil || constant.Compare(k, token.LSS, lo) {
+			lo = k
+		}
+		if hi == nil || constant.Compare(k, token.GTR, hi) {
+			hi = k
+		}
+	}
+	span := constant.BinaryOp(hi, token.SUB, lo)
+	n, exact := constant.Int64Val(span)
+	if !exact || n+1 != int64(len(plan.entries)) {
+		return false
+	}
+	plan.min = lo
+	return true
+}
+
+// allocate names the generated functions and registers their objects, so
+// that Rename follows them
+func (p *ReadOnlyMapPass) allocate(plan *roMapPlan, ctx *astutil.TranspileContext) {
+	free := func(name string) bool {
+		return !p.used[name] && types.Universe.Lookup(name) == nil && ctx.Package.Scope().Lookup(name) == nil
+	}
+	plan.names = make(map[string]string)
+	for _, role := range []string{"Get", "Lookup", "Table"} {
+		name := plan.obj.Name() + role
+		for i := 2; !free(name); i++ {
+			name = plan.obj.Name() + role + strconv.Itoa(i)
+		}
+		p.used[name] = true
+		plan.names[role] = name
+	}
+
+	key := types.NewVar(token.NoPos, ctx.Package, "key", plan.keyType)
+	value := types.NewVar(token.NoPos, ctx.Package, "", plan.valueType)
+	ok := types.NewVar(token.NoPos, ctx.Package, "", types.Typ[types.Bool])
+	params := types.NewTuple(key)
+	if plan.needGet {
+		plan.get = types.NewFunc(plan.obj.Pos(), ctx.Package, plan.names["Get"],
+			types.NewSignatureType(nil, nil, nil, params, types.NewTuple(value), false))
+		ctx.Package.Scope().Insert(plan.get)
+	}
+	if plan.needOk {
+		plan.lookup = types.NewFunc(plan.obj.Pos(), ctx.Package, plan.names["Lookup"],
+			types.NewSignatureType(nil, nil, nil, params, types.NewTuple(value, ok), false))
+		ctx.Package.Scope().Insert(plan.lookup)
+	}
+	if plan.array {
+		plan.table = types.NewVar(plan.obj.Pos(), ctx.Package, plan.names["Table"],
+			types.NewArray(plan.valueType, int64(len(plan.entries))))
+		ctx.Package.Scope().Insert(plan.table)
+	}
+}
+
+func (p *ReadOnlyMapPass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	if len(p.plans) == 0 {
+		return nil
+	}
+	count := 0
+	stdastutil.Apply(file, nil, func(c *stdastutil.Cursor) bool {
+		switch n := c.Node().(type) {
+		case *ast.IndexExpr:
+			plan := p.planOf(n.X, ctx)
+			if plan == nil {
+				return true
+			}
+			fn := plan.get
+			switch parent := c.Parent().(type) {
+			case *ast.AssignStmt:
+				if len(parent.Lhs) == 2 && len(parent.Rhs) == 1 {
+					fn = plan.lookup
+				}
+			case *ast.ValueSpec:
+				if len(parent.Names) == 2 && len(parent.Values) == 1 {
+					fn = plan.lookup
+				}
+			}
+			id := ast.NewIdent(fn.Name())
+			ctx.GetUses()[id] = fn
+			call := &ast.CallExpr{Fun: id, Args: []ast.Expr{n.Index}}
+			if fn == plan.get {
+				ctx.GetTypes()[call] = ctx.GetTypes()[n]
+			}
+			c.Replace(call)
+			count++
+		case *ast.CallExpr:
+			if len(n.Args) != 1 || !isBuiltinCall(n, "len", ctx) {
+				return true
+			}
+			if plan := p.planOf(n.Args[0], ctx); plan != nil {
+				c.Replace(&ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(len(plan.entries))})
+				count++
+			}
+		}
+		return true
+	})
+
+	// === 3️⃣ A declaração do map vira as funções geradas ===
+	for _, plan := range p.order {
+		if plan.file != file {
+			continue
+		}
+		decls, err := p.generate(plan, file, fset, ctx)
+		if err != nil {
+			return fmt.Errorf("ReadOnlyMap: %w", err)
+		}
+		p.removeDecl(plan, file)
+		file.Decls = append(file.Decls, decls...)
+
+		how := "switch function"
+		if plan.array {
+			how = "array table " + plan.names["Table"]
+		}
+		ctx.RecordLedger(p.Name(), fset.Position(plan.obj.Pos()), plan.obj.Name(), "rewritten",
+			fmt.Sprintf("%s (%d keys, %d lookups)", how, len(plan.entries), plan.lookups))
+	}
+	if count > 0 {
+		ctx.LogVerbose(fset, "🗺️ ReadOnlyMapPass: %d map reads rewritten", count)
+	}
+	return nil
+}
+
+func (p *ReadOnlyMapPass) planOf(e ast.Expr, ctx *astutil.TranspileContext) *roMapPlan {
+	id, ok := ast.Unparen(e).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	v, _ := ctx.GetUses()[id].(*types.Var)
+	return p.plans[v]
+}
+
+// removeDecl drops the map declaration and the comments inside it
+func (p *ReadOnlyMapPass) removeDecl(plan *roMapPlan, file *ast.File) {
+	var from, to token.Pos = plan.spec.Pos(), plan.spec.End()
+	if plan.spec.Doc != nil {
+		from = plan.spec.Doc.Pos()
+	}
+	if len(plan.decl.Specs) == 1 {
+		from, to = plan.decl.Pos(), plan.decl.End()
+		if plan.decl.Doc != nil {
+			from = plan.decl.Doc.Pos()
+		}
+		for i, d := range file.Decls {
+			if d == plan.decl {
+				file.Decls = append(file.Decls[:i], file.Decls[i+1:]...)
+				break
+			}
+		}
+	} else {
+		for i, s := range plan.decl.Specs {
+			if s == plan.spec {
+				plan.decl.Specs = append(plan.decl.Specs[:i], plan.decl.Specs[i+1:]...)
+				break
+			}
+		}
+	}
+	comments := file.Comments[:0]
+	for _, cg := range file.Comments {
+		if cg.Pos() < from || cg.End() > to {
+			comments = append(comments, cg)
+		}
+	}
+	file.Comments = comments
+}
+
+// generate renders the lookup functions and binds their identifiers to the
+// original constants and types
+func (p *ReadOnlyMapPass) generate(plan *roMapPlan, file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) ([]ast.Decl, error) {
+	qualifier := func(pkg *types.Package) string {
+		if pkg == ctx.Package {
+			return ""
+		}
+		return importName(fset, file, pkg.Path())
+	}
+	keyType := types.TypeString(plan.keyType, qualifier)
+	valueType := types.TypeString(plan.valueType, qualifier)
+
+	var b strings.Builder
+	if plan.array {
+		// Entradas em ordem de chave; índice = chave - min
+		sorted := append([]*ast.KeyValueExpr(nil), plan.entries...)
+		sort.Slice(sorted, func(i, j int) bool {
+			return constant.Compare(ctx.GetTypes()[sorted[i].Key].Value, token.LSS, ctx.GetTypes()[sorted[j].Key].Value)
+		})
+		fmt.Fprintf(&b, "var %s = [%d]%s{", plan.names["Table"], len(sorted), valueType)
+		for i, kv := range sorted {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(types.ExprString(kv.Value))
+		}
+		b.WriteString("}\n\n")
+
+		// Com sinal a subtração é feita em int64: fora de min..max o
+		// resultado convertido para uint64 fica sempre >= len
+		index := "uint64(key)"
+		if plan.keyType.Underlying().(*types.Basic).Info()&types.IsUnsigned == 0 {
+			index = "uint64(int64(key))"
+			if constant.Sign(plan.min) != 0 {
+				index = "uint64(int64(key) - " + roMapParen(plan.min.ExactString()) + ")"
+			}
+		} else if constant.Sign(plan.min) != 0 {
+			index += " - " + plan.min.ExactString()
+		}
+		if plan.needGet {
+			fmt.Fprintf(&b, "func %s(key %s) (v %s) {\n\tif i := %s; i < %d {\n\t\treturn %s[i]\n\t}\n\treturn\n}\n\n",
+				plan.names["Get"], keyType, valueType, index, len(sorted), plan.names["Table"])
+		}
+		if plan.needOk {
+			fmt.Fprintf(&b, "func %s(key %s) (v %s, ok bool) {\n\tif i := %s; i < %d {\n\t\treturn %s[i], true\n\t}\n\treturn\n}\n",
+				plan.names["Lookup"], keyType, valueType, index, len(sorted), plan.names["Table"])
+		}
+	} else {
+		// Chaves com o mesmo valor dividem o case
+		var values []string
+		keys := make(map[string][]string)
+		for _, kv := range plan.entries {
+			v := types.ExprString(kv.Value)
+			if keys[v] == nil {
+				values = append(values, v)
+			}
+			keys[v] = append(keys[v], types.ExprString(kv.Key))
+		}
+		body := func(ok string) string {
+			var s strings.Builder
+			s.WriteString("\tswitch key {\n")
+			for _, v := range values {
+				fmt.Fprintf(&s, "\tcase %s:\n\t\treturn %s%s\n", strings.Join(keys[v], ", "), v, ok)
+			}
+			s.WriteString("\t}\n\treturn\n")
+			return s.String()
+		}
+		if plan.needGet {
+			fmt.Fprintf(&b, "func %s(key %s) (v %s) {\n%s}\n\n", plan.names["Get"], keyType, valueType, body(""))
+		}
+		if plan.needOk {
+			fmt.Fprintf(&b, "func %s(key %s) (v %s, ok bool) {\n%s}\n", plan.names["Lookup"], keyType, valueType, body(", true"))
+		}
+	}
+
+	decls, err := astutil.ParseDecls(fset, b.String())
+	if err != nil {
+		return nil, err
+	}
+
+	// Identificadores do pacote (constantes, tipos, as funções geradas)
+	// ligados aos objetos, para Rename e os passes seguintes
+	generated := map[string]types.Object{}
+	if plan.get != nil {
+		generated[plan.get.Name()] = plan.get
+	}
+	if plan.lookup != nil {
+		generated[plan.lookup.Name()] = plan.lookup
+	}
+	if plan.table != nil {
+		generated[plan.table.Name()] = plan.table
+	}
+	for _, d := range decls {
+		ast.Inspect(d, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.SelectorExpr:
+				return false // pkg.Nome de outro pacote
+			case *ast.Ident:
+				if obj := generated[n.Name]; obj != nil {
+					if obj.Pos() == plan.obj.Pos() && roMapDefines(d, n) {
+						ctx.GetDefs()[n] = obj
+					} else {
+						ctx.GetUses()[n] = obj
+					}
+				} else if obj := ctx.Package.Scope().Lookup(n.Name); obj != nil && n.Name != "key" {
+					ctx.GetUses()[n] = obj
+				}
+			}
+			return true
+		})
+	}
+	return decls, nil
+}
+
+// roMapDefines reports whether id is the name declared by d
+func roMapDefines(d ast.Decl, id *ast.Ident) bool {
+	switch d := d.(type) {
+	case *ast.FuncDecl:
+		return d.Name == id
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			if vs, ok := spec.(*ast.ValueSpec); ok && len(vs.Names) == 1 && vs.Names[0] == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func roMapParen(s string) string {
+	if strings.HasPrefix(s, "-") {
+		return "(" + s + ")"
+	}
+	return s
+}
@@ -0,0 +1,62 @@
+package pass
+
+import (
+	"strings"
+	"testing"
+)
+
+const readOnlyMapProbe = `package main
+
+import "fmt"
+
+type point struct{ X, Y int }
+
+// Viram lookup: chaves inteiras contíguas (array) e strings (switch)
+var codes = map[int]string{1: "one", 2: "two", 3: "three", -1: "minus"}
+var colors = map[string]int{"red": 1, "green": 2, "blue": 3}
+
+// Ficam: escrita, range, passado adiante, valor não constante
+var written = map[string]int{"a": 1}
+var ranged = map[string]int{"a": 1}
+var passed = map[string]int{"a": 1}
+var points = map[string]point{"o": {0, 0}}
+
+func size(m map[string]int) int { return len(m) }
+
+func main() {
+	for k := -2; k <= 4; k++ {
+		v, ok := codes[k]
+		fmt.Println(k, v, ok, codes[k])
+	}
+	fmt.Println(colors["red"], colors["pink"], len(colors))
+
+	written["b"] = 2
+	for k, v := range ranged {
+		fmt.Println(k, v)
+	}
+	fmt.Println(len(written), size(passed), points["o"])
+}
+`
+
+func TestReadOnlyMapPreservesBehavior(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, readOnlyMapProbe)
+	out, ctx := transpileSource(t, readOnlyMapProbe, false, NewReadOnlyMapPass())
+	if got := countLedger(ctx, "ReadOnlyMap", "rewritten"); got != 2 {
+		t.Errorf("%d maps rewritten, want 2\n%s", got, out)
+	}
+	for _, e := range ctx.Ledger {
+		if e.Target == "points" && e.Detail != "non-constant value main.point literal" {
+			t.Errorf("points rejected with %q", e.Detail)
+		}
+	}
+	if got := countLedger(ctx, "ReadOnlyMap", "rejected"); got != 4 {
+		t.Errorf("%d maps rejected, want 4\n%+v", got, ctx.Ledger)
+	}
+	if strings.Contains(out, "var codes") || strings.Contains(out, "var colors") {
+		t.Errorf("lookup maps left in place\n%s", out)
+	}
+	if got := runSource(t, out); got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+}
@@ -0,0 +1,498 @@
+package pass
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// RenamePass troca os identificadores do pacote por nomes curtos sem
+// significado, resolvendo cada uso pelo go/types: locais, funções, tipos,
+// vars e consts não exportados (e os exportados em package main), métodos
+// que não implementam nenhuma interface e campos de structs nomeados.
+//
+//	func parseHeader(buf []byte) (header, error)  →  func a(b []byte) (c, error)
+//
+// Fica com o nome original tudo o que pode ser observado por nome: structs que
+// viram interface (fmt, encoding/json e reflect leem nomes de tipo e campo),
+// structs com tags ou convertidos para outro struct, métodos com o nome de
+// algum método de interface, e nomes usados pelos testes internos do pacote.
+// Pacotes com cgo ou //go:linkname ficam inteiros. Cada troca vai para a
+// seção renames do --map.
+// Deve rodar depois dos outros passes: eles procuram structs e campos pelo
+// nome original.
+type RenamePass struct {
+	names    map[types.Object]string // objeto → novo nome
+	oldNames map[string]string       // nome antigo → novo, objetos do escopo do pacote
+	handled  map[*ast.Ident]bool
+}
+
+func NewRenamePass() *RenamePass   { return &RenamePass{} }
+func (p *RenamePass) Name() string { return "Rename" }
+
+// Prepare decides the new name of every renamable object of the package
+func (p *RenamePass) Prepare(files []*ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	p.names = make(map[types.Object]string)
+	p.oldNames = make(map[string]string)
+	if !ctx.Ofuscate || ctx.Package == nil || len(files) == 0 {
+		return nil
+	}
+	pkg := ctx.Package
+	isMain := pkg.Name() == "main"
+
+	// Nomes já usados no pacote (e nos testes): os novos nomes nunca colidem nem sombreiam
+	reserved := make(map[string]bool)
+	for _, file := range files {
+		for _, cg := range file.Comments {
+			for _, c := range cg.List {
+				if strings.HasPrefix(c.Text, "//go:linkname") || strings.HasPrefix(c.Text, "//export ") {
+					gl.Log("info", fmt.Sprintf("Rename: skipping package %s (linkname/export directives)", pkg.Name()))
+					return nil
+				}
+			}
+		}
+		for _, imp := range file.Imports {
+			if imp.Path.Value == `"C"` {
+				gl.Log("info", fmt.Sprintf("Rename: skipping package %s (cgo)", pkg.Name()))
+				return nil
+			}
+		}
+		collectIdentNames(file, reserved)
+	}
+	testNames := p.internalTestNames(filepath.Dir(fset.Position(files[0].Pos()).Filename), pkg.Name())
+	for name := range testNames {
+		reserved[name] = true
+	}
+
+	observed := observedStructs(files, fset, ctx)
+	interfaceMethods := interfaceMethodNames(ctx)
+	owners := fieldOwners(ctx)
+
+	type def struct {
+		id  *ast.Ident
+		obj types.Object
+	}
+	var defs []def // em ordem de travessia: determinístico mesmo com posições repetidas
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && id.Name != "_" {
+				if obj := ctx.GetDefs()[id]; obj != nil && obj.Pkg() == pkg {
+					defs = append(defs, def{id, obj})
+				}
+			}
+			return true
+		})
+	}
+
+	next := 0
+	fresh := func(old string) string {
+		for {
+			name := string(rune('a' + next%26))
+			if next >= 26 {
+				name += strconv.Itoa(next / 26)
+			}
+			next++
+			if token.IsExported(old) {
+				name = strings.ToUpper(name[:1]) + name[1:]
+			}
+			if !reserved[name] && types.Universe.Lookup(name) == nil {
+				reserved[name] = true
+				return name
+			}
+		}
+	}
+	fieldNames := make(map[string]string) // mesmo nome antigo → mesmo novo, em todo struct
+	var embedded []*types.Var
+
+	for _, d := range defs {
+		obj := d.obj
+		packageLevel := obj.Parent() == pkg.Scope()
+		visible := !obj.Exported() || isMain
+		kind, scope, reason := "", "", ""
+
+		switch o := obj.(type) {
+		case *types.Func:
+			sig := o.Type().(*types.Signature)
+			switch {
+			case sig.Recv() != nil:
+				kind, scope = "method", types.TypeString(sig.Recv().Type(), types.RelativeTo(pkg))
+				recv := derefNamed(sig.Recv().Type())
+				switch {
+				case !visible:
+					reason = "exported outside package main"
+				case interfaceMethods[o.Name()]:
+					reason = "name of an interface method"
+				case o.Exported() && recv != nil && observed[recv.Obj()] != "":
+					reason = "receiver " + observed[recv.Obj()]
+				}
+			case o.Name() == "main" || o.Name() == "init":
+				continue
+			default:
+				kind = "func"
+				if !visible {
+					reason = "exported outside package main"
+				}
+			}
+		case *types.TypeName:
+			kind = "type"
+			if !packageLevel {
+				kind = "local"
+			}
+			if !visible && packageLevel {
+				reason = "exported outside package main"
+			} else if r := observed[o]; r != "" {
+				reason = r
+			}
+		case *types.Var:
+			switch {
+			case o.IsField():
+				kind = "field"
+				owner := owners[o]
+				switch {
+				case o.Embedded():
+					embedded = append(embedded, o)
+					continue
+				case owner == nil:
+					continue // campo de struct sem nome: a identidade do tipo depende dele
+				case !visible:
+					reason = "exported outside package main"
+				case observed[owner] != "":
+					reason = observed[owner]
+				}
+				if owner != nil {
+					scope = owner.Name()
+				}
+			case packageLevel:
+				kind = "var"
+				if !visible {
+					reason = "exported outside package main"
+				}
+			default:
+				kind = "local"
+			}
+		case *types.Const:
+			kind = "const"
+			if !packageLevel {
+				kind = "local"
+			} else if !visible {
+				reason = "exported outside package main"
+			}
+		default:
+			continue
+		}
+		if reason == "" && testNames[obj.Name()] {
+			reason = "referenced by the package tests"
+		}
+
+		pos := fset.Position(d.id.Pos())
+		if reason != "" {
+			if kind != "local" && visible {
+				ctx.RecordLedger(p.Name(), pos, obj.Name(), "skipped", reason)
+			}
+			continue
+		}
+		if kind == "local" {
+			scope = enclosingFuncName(files, d.id.Pos())
+		}
+
+		var name string
+		if kind == "field" {
+			if fieldNames[obj.Name()] == "" {
+				fieldNames[obj.Name()] = fresh(obj.Name())
+			}
+			name = fieldNames[obj.Name()]
+		} else {
+			name = fresh(obj.Name())
+		}
+		p.names[obj] = name
+		if packageLevel {
+			p.oldNames[obj.Name()] = name
+		}
+		ctx.RecordRename(astutil.RenameEntry{
+			Package: pkg.Name(), Kind: kind, Scope: scope, Old: obj.Name(), New: name,
+			File: pos.Filename, Line: pos.Line,
+		})
+	}
+
+	// Campo embutido tem o nome do tipo: segue o tipo
+	for _, field := range embedded {
+		if named := derefNamed(field.Type()); named != nil {
+			if name, ok := p.names[named.Obj()]; ok {
+				p.names[field] = name
+			}
+		}
+	}
+	return nil
+}
+
+func (p *RenamePass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	if len(p.names) == 0 {
+		return nil
+	}
+	defs, uses := ctx.GetDefs(), ctx.GetUses()
+	p.handled = make(map[*ast.Ident]bool)
+	renamed := 0
+
+	rename := func(id *ast.Ident, obj types.Object) {
+		if name, ok := p.names[originObject(obj)]; ok && id.Name != name {
+			id.Name = name
+			renamed++
+		}
+	}
+
+	// Nomes de campo e de método em código sintetizado só são usados via seletor,
+	// que sem tipo não é resolvido: ficam como estão
+	keep := func(id *ast.Ident) {
+		if _, ok := defs[id]; !ok {
+			p.handled[id] = true
+		}
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.StructType:
+			for _, field := range node.Fields.List {
+				for _, name := range field.Names {
+					keep(name)
+				}
+			}
+		case *ast.FuncDecl:
+			if node.Recv != nil {
+				keep(node.Name)
+			}
+		case *ast.CompositeLit:
+			if _, ok := ctx.GetTypes()[node]; !ok {
+				for _, elt := range node.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						if id, ok := kv.Key.(*ast.Ident); ok {
+							keep(id)
+						}
+					}
+				}
+			}
+		case *ast.SelectorExpr:
+			// Seletor sintetizado por outro pass: resolve pelo tipo do receptor
+			if _, ok := uses[node.Sel]; ok || p.handled[node.Sel] {
+				return true
+			}
+			p.handled[node.Sel] = true
+			if tv, ok := ctx.GetTypes()[node.X]; ok && tv.Type != nil {
+				if obj, _, _ := types.LookupFieldOrMethod(tv.Type, true, ctx.Package, node.Sel.Name); obj != nil {
+					rename(node.Sel, obj)
+				}
+			}
+		case *ast.Ident:
+			if p.handled[node] {
+				return true
+			}
+			p.handled[node] = true
+			if obj, ok := defs[node]; ok {
+				if obj != nil {
+					rename(node, obj)
+				}
+				return true
+			}
+			if obj, ok := uses[node]; ok {
+				rename(node, obj)
+				return true
+			}
+			// Identificador sintetizado (ex.: tipo emitido por typeExprFor)
+			if name, ok := p.oldNames[node.Name]; ok {
+				node.Name = name
+				renamed++
+			}
+		}
+		return true
+	})
+
+	if renamed > 0 {
+		ctx.LogVerbose(fset, "🔤 RenamePass: %d identifiers renamed", renamed)
+	}
+	return nil
+}
+
+// internalTestNames returns every identifier used by the _test.go files of
+// dir that belong to the package itself (not to the external _test package)
+func (p *RenamePass) internalTestNames(dir, pkgName string) map[string]bool {
+	names := make(map[string]bool)
+	paths, _ := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, src, parser.SkipObjectResolution)
+		if err != nil || file.Name.Name != pkgName {
+			continue
+		}
+		collectIdentNames(file, names)
+	}
+	return names
+}
+
+func collectIdentNames(file *ast.File, names map[string]bool) {
+	ast.Inspect(file, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			names[id.Name] = true
+		}
+		return true
+	})
+}
+
+// observedStructs returns the named types whose type and field names can be
+// observed at run time: values converted to interfaces (the same analysis
+// StructLayout uses), structs with tags and structs converted to another struct
+func observedStructs(files []*ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) map[*types.TypeName]string {
+	observed := make(map[*types.TypeName]string)
+	layout := NewStructLayoutPass()
+	_ = layout.Prepare(files, fset, ctx)
+	for obj, reason := range layout.observed {
+		observed[obj] = reason
+	}
+
+	for _, obj := range ctx.GetDefs() {
+		tn, ok := obj.(*types.TypeName)
+		if !ok {
+			continue
+		}
+		if st, ok := tn.Type().Underlying().(*types.Struct); ok {
+			for i := 0; i < st.NumFields(); i++ {
+				if st.Tag(i) != "" {
+					observed[tn] = "struct tags"
+				}
+			}
+		}
+	}
+
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 1 {
+				return true
+			}
+			tv, ok := ctx.GetTypes()[call.Fun]
+			if !ok || !tv.IsType() || structOf(tv.Type) == nil {
+				return true
+			}
+			if arg, ok := ctx.GetTypes()[call.Args[0]]; ok && arg.Type != nil && structOf(arg.Type) != nil {
+				for _, t := range []types.Type{tv.Type, arg.Type} {
+					if named := derefNamed(t); named != nil {
+						observed[named.Obj()] = "converted between struct types"
+					}
+				}
+			}
+			return true
+		})
+	}
+	return observed
+}
+
+// interfaceMethodNames returns the method names of every interface the
+// package can see: its own, those of its imports and the predeclared error
+func interfaceMethodNames(ctx *astutil.TranspileContext) map[string]bool {
+	names := map[string]bool{"Error": true}
+	add := func(t types.Type) {
+		if t == nil {
+			return
+		}
+		if it, ok := t.Underlying().(*types.Interface); ok {
+			for i := 0; i < it.NumMethods(); i++ {
+				names[it.Method(i).Name()] = true
+			}
+		}
+	}
+	for _, tv := range ctx.GetTypes() {
+		add(tv.Type)
+	}
+	for _, obj := range ctx.GetDefs() {
+		if obj != nil {
+			add(obj.Type())
+		}
+	}
+
+	seen := make(map[*types.Package]bool)
+	var walk func(pkg *types.Package)
+	walk = func(pkg *types.Package) {
+		if pkg == nil || seen[pkg] {
+			return
+		}
+		seen[pkg] = true
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			if tn, ok := scope.Lookup(name).(*types.TypeName); ok {
+				add(tn.Type())
+			}
+		}
+		for _, imp := range pkg.Imports() {
+			walk(imp)
+		}
+	}
+	if ctx.Package != nil {
+		for _, imp := range ctx.Package.Imports() {
+			walk(imp)
+		}
+	}
+	return names
+}
+
+// fieldOwners maps each field of a named struct declared in the package to its type
+func fieldOwners(ctx *astutil.TranspileContext) map[*types.Var]*types.TypeName {
+	owners := make(map[*types.Var]*types.TypeName)
+	for _, obj := range ctx.GetDefs() {
+		tn, ok := obj.(*types.TypeName)
+		if !ok || tn.IsAlias() {
+			continue
+		}
+		if st, ok := tn.Type().Underlying().(*types.Struct); ok {
+			for i := 0; i < st.NumFields(); i++ {
+				owners[st.Field(i)] = tn
+			}
+		}
+	}
+	return owners
+}
+
+// enclosingFuncName returns the name of the function declaration containing pos
+func enclosingFuncName(files []*ast.File, pos token.Pos) string {
+	for _, file := range files {
+		if pos < file.Pos() || pos > file.End() {
+			continue
+		}
+		for _, decl := range file.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok && fd.Pos() <= pos && pos <= fd.End() {
+				return fd.Name.Name
+			}
+		}
+	}
+	return ""
+}
+
+// derefNamed returns the named type behind t or *t
+func derefNamed(t types.Type) *types.Named {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, _ := types.Unalias(t).(*types.Named)
+	return named
+}
+
+// originObject maps instantiated generic fields and methods to their declaration
+func originObject(obj types.Object) types.Object {
+	switch o := obj.(type) {
+	case *types.Var:
+		return o.Origin()
+	case *types.Func:
+		return o.Origin()
+	}
+	return obj
+}
@@ -0,0 +1,34 @@
+package pass
+
+import (
+	"strings"
+	"testing"
+)
+
+const renameObservedProbe = `package main
+
+import "fmt"
+
+type point struct {
+	X, Y int
+}
+
+func main() {
+	m := map[string]point{"a": point{1, 2}}
+	fmt.Printf("%+v\n", m)
+}
+`
+
+// TestRenameKeepsObservedFields covers a struct rejected by StructLayout for an
+// unkeyed literal before it is seen flowing into an interface
+func TestRenameKeepsObservedFields(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, renameObservedProbe)
+	out, _ := transpileSource(t, renameObservedProbe, true, NewRenamePass())
+	if !strings.Contains(out, "X, Y int") {
+		t.Errorf("fields of a struct printed by fmt were renamed\n%s", out)
+	}
+	if got := runSource(t, out); got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+}
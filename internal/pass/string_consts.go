@@ -0,0 +1,213 @@
+package pass
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+)
+
+// stringConstPlan says which string constants of a package can become vars
+// (so their literals can be encrypted) and why the others must stay constant
+type stringConstPlan struct {
+	convert  map[*ast.ValueSpec]bool   // specs que viram var
+	reasons  map[*ast.ValueSpec]string // specs que ficam const, com o motivo
+	literals map[*ast.BasicLit]bool    // literais dos specs convertidos (sem tipo → string)
+}
+
+// planStringConsts checks every use of every string constant in files. A
+// constant becomes a var only when all its uses tolerate a non-constant
+// value: none sits in another constant or in an array length, every use has
+// the var's type, moving the spec does not renumber iota for the specs after
+// it, and nothing outside the package can reach it.
+func planStringConsts(files []*ast.File, ctx *astutil.TranspileContext) *stringConstPlan {
+	plan := &stringConstPlan{
+		convert:  make(map[*ast.ValueSpec]bool),
+		reasons:  make(map[*ast.ValueSpec]string),
+		literals: make(map[*ast.BasicLit]bool),
+	}
+
+	// Identificadores em contextos que exigem constante
+	constContext := make(map[*ast.Ident]string)
+	mark := func(n ast.Node, reason string) {
+		ast.Inspect(n, func(m ast.Node) bool {
+			if id, ok := m.(*ast.Ident); ok {
+				constContext[id] = reason
+			}
+			return true
+		})
+	}
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.GenDecl:
+				if node.Tok != token.CONST {
+					return true
+				}
+				for _, spec := range node.Specs {
+					vs := spec.(*ast.ValueSpec)
+					for _, v := range vs.Values {
+						mark(v, "used in constant "+vs.Names[0].Name)
+					}
+				}
+			case *ast.ArrayType:
+				if node.Len != nil {
+					mark(node.Len, "used in an array length")
+				}
+			}
+			return true
+		})
+	}
+
+	uses := make(map[types.Object][]*ast.Ident)
+	for id, obj := range ctx.GetUses() {
+		if _, ok := obj.(*types.Const); ok {
+			uses[obj] = append(uses[obj], id)
+		}
+	}
+	for _, ids := range uses {
+		sort.Slice(ids, func(i, j int) bool { return ids[i].Pos() < ids[j].Pos() })
+	}
+
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			gd, ok := n.(*ast.GenDecl)
+			if !ok || gd.Tok != token.CONST {
+				return true
+			}
+			// Specs que dependem da posição no grupo (iota ou repetição implícita)
+			lastDep := -1
+			for i, spec := range gd.Specs {
+				if dependsOnPosition(spec.(*ast.ValueSpec), ctx) {
+					lastDep = i
+				}
+			}
+			for i, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				reason, isString := plan.constReason(vs, i < lastDep, uses, constContext, ctx)
+				if !isString {
+					continue
+				}
+				if reason == "" && (hasDirective(gd.Doc, keepDirective) || hasDirective(vs.Doc, keepDirective) || hasDirective(vs.Comment, keepDirective)) {
+					reason = "gastype:keep"
+				}
+				if reason != "" {
+					plan.reasons[vs] = reason
+					continue
+				}
+				plan.convert[vs] = true
+				for _, v := range vs.Values {
+					ast.Inspect(v, func(m ast.Node) bool {
+						if bl, ok := m.(*ast.BasicLit); ok && bl.Kind == token.STRING {
+							plan.literals[bl] = true
+						}
+						return true
+					})
+				}
+			}
+			return false
+		})
+	}
+	return plan
+}
+
+// constReason returns why vs must stay constant ("" if it can become a var),
+// and whether vs declares string constants at all
+func (plan *stringConstPlan) constReason(vs *ast.ValueSpec, beforeDep bool, uses map[types.Object][]*ast.Ident,
+	constContext map[*ast.Ident]string, ctx *astutil.TranspileContext) (string, bool) {
+	count := 0
+	for _, name := range vs.Names {
+		if obj, ok := ctx.GetDefs()[name].(*types.Const); ok && isStringType(obj.Type()) {
+			count++
+		}
+	}
+	if count == 0 {
+		return "", false
+	}
+	if count != len(vs.Names) {
+		return "declared together with non-string constants", true
+	}
+	if len(vs.Values) == 0 {
+		return "implicit repetition of the previous spec", true
+	}
+	if beforeDep {
+		return "later specs depend on its position in the group (iota or implicit repetition)", true
+	}
+
+	for _, name := range vs.Names {
+		obj := ctx.GetDefs()[name].(*types.Const)
+		packageLevel := ctx.Package != nil && obj.Parent() == ctx.Package.Scope()
+		if packageLevel && obj.Exported() && ctx.Package.Name() != "main" {
+			return "exported outside package main, uses in other packages are unknown", true
+		}
+		if !packageLevel && len(uses[obj]) == 0 {
+			return "unused local constant would become an unused var", true
+		}
+		varType := types.Default(obj.Type())
+		for _, use := range uses[obj] {
+			if reason := constContext[use]; reason != "" {
+				return reason, true
+			}
+			if tv, ok := ctx.GetTypes()[use]; ok && tv.Type != nil && !types.Identical(tv.Type, varType) {
+				return "used as " + tv.Type.String(), true
+			}
+		}
+	}
+	return "", true
+}
+
+// dependsOnPosition reports whether vs repeats the previous spec or refers to iota
+func dependsOnPosition(vs *ast.ValueSpec, ctx *astutil.TranspileContext) bool {
+	if len(vs.Values) == 0 {
+		return true
+	}
+	found := false
+	for _, v := range vs.Values {
+		ast.Inspect(v, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && id.Name == "iota" {
+				if obj := ctx.GetUses()[id]; obj == nil || obj.Parent() == types.Universe {
+					found = true
+				}
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// splitConstDecl splits gd into consecutive const/var declarations following
+// plan, preserving the source order of the specs. It returns nil when no spec
+// of gd is converted.
+func splitConstDecl(gd *ast.GenDecl, plan *stringConstPlan) []*ast.GenDecl {
+	converted := false
+	for _, spec := range gd.Specs {
+		converted = converted || plan.convert[spec.(*ast.ValueSpec)]
+	}
+	if !converted {
+		return nil
+	}
+
+	var runs []*ast.GenDecl
+	for _, spec := range gd.Specs {
+		tok := token.CONST
+		if plan.convert[spec.(*ast.ValueSpec)] {
+			tok = token.VAR
+		}
+		if n := len(runs); n > 0 && runs[n-1].Tok == tok {
+			runs[n-1].Specs = append(runs[n-1].Specs, spec)
+			continue
+		}
+		runs = append(runs, &ast.GenDecl{TokPos: spec.Pos(), Tok: tok, Specs: []ast.Spec{spec}})
+	}
+
+	runs[0].Doc, runs[0].TokPos = gd.Doc, gd.TokPos
+	if gd.Lparen.IsValid() {
+		for _, run := range runs {
+			run.Lparen, run.Rparen = run.Specs[0].Pos(), run.Specs[len(run.Specs)-1].End()
+		}
+		runs[0].Lparen, runs[len(runs)-1].Rparen = gd.Lparen, gd.Rparen
+	}
+	return runs
+}
@@ -0,0 +1,76 @@
+package pass
+
+import (
+	"strings"
+	"testing"
+)
+
+const stringConstsProbe = `package main
+
+import "fmt"
+
+type LogType string
+
+const (
+	LogTypeDebug LogType = "debug-level-secret"
+	LogTypeInfo  LogType = "info-level-secret"
+)
+
+// Vem antes de specs com iota: virar var renumeraria KindA e KindB
+const (
+	KindName = "kind-name-secret"
+	KindA    = iota
+	KindB
+)
+
+const greeting = "hello-greeting-secret"
+
+// Usadas em outra constante e num tamanho de array: ficam constantes
+const prefix = "prefix-secret"
+const full = prefix + "-suffix"
+const sizeKey = "size-key-secret"
+
+var buf [len(sizeKey)]byte
+
+func label(t LogType) string {
+	switch t {
+	case LogTypeDebug:
+		return "d"
+	case "warning-level-secret":
+		return "w"
+	}
+	return string(t)
+}
+
+func main() {
+	fmt.Println(label(LogTypeDebug), label(LogTypeInfo), label("warning-level-secret"))
+	fmt.Println(KindName, KindA, KindB, greeting, full, len(buf))
+}
+`
+
+func TestStringConstsConvertOnlyVarSafeConstants(t *testing.T) {
+	requireGo(t)
+	want, _ := buildSource(t, stringConstsProbe)
+	out, ctx := transpileSource(t, stringConstsProbe, true, NewStringObfuscatePass())
+	got, bin := buildSource(t, out)
+	if got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+	requirePlaintext(t, bin, false, "debug-level-secret", "info-level-secret", "hello-greeting-secret", "warning-level-secret")
+
+	reasons := make(map[string]string)
+	for _, e := range ctx.Ledger {
+		if e.Action == "skipped" {
+			reasons[e.Target] = e.Detail
+		}
+	}
+	for lit, want := range map[string]string{
+		`"kind-name-secret"`: "later specs depend on its position",
+		`"prefix-secret"`:    "used in constant full",
+		`"size-key-secret"`:  "used in an array length",
+	} {
+		if !strings.Contains(reasons[lit], want) {
+			t.Errorf("%s skipped with %q, want %q", lit, reasons[lit], want)
+		}
+	}
+}
@@ -1,216 +1,374 @@
 package pass
 
 import (
+	"encoding/binary"
 	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
 	"strconv"
 	"strings"
 
+	stdastutil "golang.org/x/tools/go/ast/astutil"
+
 	"github.com/kubex-ecosystem/gastype/internal/astutil"
 
 	gl "github.com/kubex-ecosystem/logz/logger"
 )
 
-type objectSpecMap struct {
-	isConst         bool
-	name            string
-	obfuscatedName  string
-	varType         ast.Expr
-	obfuscatedValue any
+// StringObfuscatePass cifra os literais string com uma chave derivada da seed
+// do build e injeta no pacote um decodificador que decifra cada literal na
+// primeira leitura (sync.Once), então o texto puro não fica no binário.
+//
+//	fmt.Println("connecting to db")  →  fmt.Println(obfStrings[0].get())
+//
+// Cada arquivo ganha uma tabela [N]obfString com o texto cifrado e um nonce
+// por literal; o tipo obfString e o método get são emitidos uma vez por pacote.
+// A chave sai da seed do build (--seed, aleatória quando omitida e gravada no
+// --map), e os textos cifrados vão para ctx.ObfuscatedStrings: o transpile
+// compila a saída e avisa sobre qualquer um que ainda apareça no binário.
+//
+// Constantes string viram var quando todos os usos toleram (veja
+// planStringConsts); as demais, tags de struct e caminhos de import ficam
+// como estão.
+type StringObfuscatePass struct {
+	key     uint64          // chave do pacote atual
+	decoder string          // nome do tipo decodificador no pacote atual
+	emitted bool            // decodificador já emitido no pacote
+	used    map[string]bool // nomes de tabela já emitidos no pacote
+	count   uint64          // literais cifrados no pacote (gera os nonces)
+	consts  *stringConstPlan
+	policy  *stringPolicy
 }
 
-type StringObfuscatePass struct{}
-
 func NewStringObfuscatePass() *StringObfuscatePass { return &StringObfuscatePass{} }
 func (p *StringObfuscatePass) Name() string        { return "StringObfuscate" }
 
-func (p *StringObfuscatePass) Apply(file *ast.File, _ *token.FileSet, ctx *astutil.TranspileContext) error {
-	transformations := 0
+// Prepare derives the package key, picks a package-unique decoder name and
+// decides which string constants can become vars
+func (p *StringObfuscatePass) Prepare(files []*ast.File, _ *token.FileSet, ctx *astutil.TranspileContext) error {
+	if !ctx.Ofuscate {
+		return nil
+	}
+	p.reset(ctx)
+	p.consts = planStringConsts(files, ctx)
+	p.policy = newStringPolicy(files, ctx)
+	return nil
+}
 
-	// Imports → ignorar
-	importSpecs := make(map[*ast.BasicLit]bool)
-	for _, decl := range file.Decls {
-		if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.IMPORT {
-			for _, spec := range gd.Specs {
-				if is, ok := spec.(*ast.ImportSpec); ok && is.Path != nil {
-					importSpecs[is.Path] = true
-				}
-			}
-		}
+func (p *StringObfuscatePass) reset(ctx *astutil.TranspileContext) {
+	scope := ""
+	if ctx.Package != nil {
+		scope = ctx.Package.Name()
 	}
+	key := ctx.DeriveKey("strings/" + scope)
+	p.key = binary.LittleEndian.Uint64(key[:8])
+	p.decoder = freshPackageName(ctx.Package, "obfString")
+	p.emitted = false
+	p.used = make(map[string]bool)
+	p.count = 0
+}
 
-	// Constantes string
-	objectsSpecMap := make(map[any]objectSpecMap)
+func (p *StringObfuscatePass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	// Etapa 1 (--no-obfuscate) gera código legível
+	if !ctx.Ofuscate {
+		return nil
+	}
+	if p.used == nil {
+		p.reset(ctx)
+	}
+	if p.consts == nil {
+		p.consts = planStringConsts([]*ast.File{file}, ctx)
+		p.policy = newStringPolicy([]*ast.File{file}, ctx)
+	}
+	p.policy.scanFile(file, fset)
+	transformations := 0
 
-	for _, decl := range file.Decls {
-		gd, ok := decl.(*ast.GenDecl)
-		if !ok {
-			continue
-		}
+	// Constantes cujos usos toleram var viram var (e seus literais são cifrados)
+	p.convertConsts(file, fset, ctx)
 
-		for _, spec := range gd.Specs {
-			vs, ok := spec.(*ast.ValueSpec)
-			if !ok || len(vs.Names) == 0 {
-				continue
+	// Literais que precisam continuar constantes (ou nem são expressões)
+	skip := make(map[*ast.BasicLit]string)
+	ast.Inspect(file, func(n ast.Node) bool {
+		var within ast.Node
+		reason := ""
+		switch node := n.(type) {
+		case *ast.ImportSpec:
+			within, reason = node.Path, "import path"
+		case *ast.Field:
+			if node.Tag != nil {
+				within, reason = node.Tag, "struct tag"
 			}
-
-			// Nome do objeto
-			name := vs.Names[0].Name
-			// Tipo do objeto
-			varType := vs.Type
-
-			// Se já tiver valor literal, guarda pra obfuscar
-			if len(vs.Values) > 0 {
-				ast.Inspect(vs.Values[0], func(n ast.Node) bool {
-					bl, ok := n.(*ast.BasicLit)
-					if !ok || bl.Kind != token.STRING {
-						return true
-					}
-					objectsSpecMap[bl] = objectSpecMap{
-						isConst:         gd.Tok == token.CONST,
-						name:            name,
-						varType:         varType,
-						obfuscatedName:  fmt.Sprintf("obfuscated_%s", name),
-						obfuscatedValue: nil,
-					}
-					return false
-				})
-			} else {
-				objectsSpecMap[vs.Names[0]] = objectSpecMap{
-					isConst:         gd.Tok == token.CONST,
-					name:            name,
-					varType:         varType,
-					obfuscatedName:  fmt.Sprintf("obfuscated_%s", name),
-					obfuscatedValue: nil,
-				}
+		case *ast.GenDecl:
+			if node.Tok == token.CONST {
+				within, reason = node, "constant declaration"
+			}
+		case *ast.ValueSpec:
+			if reason = p.consts.reasons[node]; reason != "" {
+				within = node
+			}
+		case *ast.ArrayType:
+			if node.Len != nil {
+				within, reason = node.Len, "array length"
 			}
 		}
-	}
-
-	// Tags de struct → ignorar
-	structTags := make(map[*ast.BasicLit]bool)
-	ast.Inspect(file, func(n ast.Node) bool {
-		if f, ok := n.(*ast.Field); ok && f.Tag != nil {
-			structTags[f.Tag] = true
+		if within != nil {
+			ast.Inspect(within, func(m ast.Node) bool {
+				if bl, ok := m.(*ast.BasicLit); ok && bl.Kind == token.STRING {
+					skip[bl] = reason
+				}
+				return true
+			})
 		}
 		return true
 	})
 
-	// var initStmts []ast.Stmt
-
-	// Percorre a AST e obfusca strings
-	ast.Inspect(file, func(n ast.Node) bool {
-
-		bl, ok := n.(*ast.BasicLit)
+	var entries []string
+	table := ""
 
+	stdastutil.Apply(file, func(c *stdastutil.Cursor) bool {
+		bl, ok := c.Node().(*ast.BasicLit)
 		if !ok || bl.Kind != token.STRING {
 			return true
 		}
+		pos := fset.Position(bl.Pos())
 
-		if importSpecs[bl] || structTags[bl] {
+		if reason, ok := skip[bl]; ok {
+			if reason != "import path" && reason != "struct tag" {
+				ctx.RecordLedger(p.Name(), pos, bl.Value, "skipped", "constant kept: "+reason)
+			}
 			return true
 		}
 
-		if len(bl.Value) < 2 {
+		val, err := strconv.Unquote(bl.Value)
+		if err != nil {
+			return true
+		}
+		if reason := p.policy.reason(bl, val, pos.Line); reason != "" {
+			ctx.RecordLedger(p.Name(), pos, bl.Value, "skipped", reason)
 			return true
 		}
 
-		// Ignora strings curtas demais e comuns
-		// Ex: "", "a", "ok", "id", "to", "is", "in", "on", "at", "of", "by"
-		if len(bl.Value) <= 3 {
-			simpleStrings := map[string]bool{
-				`""`: true, `"a"`: true, `"b"`: true, `"c"`: true,
-				`"d"`: true, `"e"`: true, `"f"`: true, `"g"`: true,
-				`"h"`: true, `"i"`: true, `"j"`: true, `"k"`: true,
-				`"l"`: true, `"m"`: true, `"n"`: true, `"o"`: true,
-				`"p"`: true, `"q"`: true, `"r"`: true, `"s"`: true,
-				`"t"`: true, `"u"`: true, `"v"`: true, `"w"`: true,
-				`"x"`: true, `"y"`: true, `"z"`: true,
-				`"ok"`: true, `"id"`: true, `"to"`: true,
-				`"is"`: true, `"in"`: true, `"on"`: true,
-				`"at"`: true, `"of"`: true, `"by"`: true,
-			}
-			if simpleStrings[bl.Value] {
+		// Literais sintetizados por outros passes não têm tipo; os que ficaram
+		// sem tipo concreto estão num contexto que exige constante
+		tv, ok := ctx.GetTypes()[bl]
+		if !ok || tv.Type == nil {
+			return true
+		}
+		t := tv.Type
+		if p.consts.literals[bl] {
+			t = types.Default(t) // const sem tipo que virou var string
+		}
+		basic, isBasic := types.Unalias(t).(*types.Basic)
+		if isBasic && basic.Info()&types.IsUntyped != 0 {
+			ctx.RecordLedger(p.Name(), pos, bl.Value, "skipped", "constant context")
+			return true
+		}
+		var conv ast.Expr
+		if !isBasic || basic.Kind() != types.String {
+			if conv = typeExprFor(t, file, ctx); conv == nil {
+				ctx.RecordLedger(p.Name(), pos, bl.Value, "skipped", "type "+t.String()+" not expressible here")
 				return true
 			}
 		}
 
-		if astutil.CheckConstant(
-			bl,
-			bl.Kind.String(),
-			ctx,
-		) {
-			// Se for constante, não obfusca
-			return true
+		if table == "" {
+			table = p.tableName(file, ctx)
 		}
-
-		// Valor da strings
-
-		val, err := strconv.Unquote(bl.Value)
-		if err != nil || len(val) < 4 {
-			return true
+		index := len(entries)
+		p.count++
+		nonce := splitmix64(p.key + p.count*0x9e3779b97f4a7c15)
+		entries = append(entries, fmt.Sprintf("{data: %s, nonce: %#x}", quoteBytes(obfuscateXOR([]byte(val), p.key^nonce)), nonce))
+
+		// Posições do literal original: sem elas o printer despeja comentários no meio da expressão
+		at := bl.Pos()
+		var expr ast.Expr = &ast.CallExpr{Fun: &ast.SelectorExpr{
+			X: &ast.IndexExpr{
+				X:      &ast.Ident{NamePos: at, Name: table},
+				Lbrack: at,
+				Index:  &ast.BasicLit{ValuePos: at, Kind: token.INT, Value: strconv.Itoa(index)},
+				Rbrack: at,
+			},
+			Sel: &ast.Ident{NamePos: at, Name: "get"},
+		}, Lparen: at, Rparen: at}
+		if conv != nil {
+			if id, ok := conv.(*ast.Ident); ok {
+				id.NamePos = at
+			}
+			expr = &ast.CallExpr{Fun: conv, Lparen: at, Args: []ast.Expr{expr}, Rparen: at}
 		}
+		c.Replace(expr)
+		ctx.ObfuscatedStrings = append(ctx.ObfuscatedStrings, val)
+		ctx.RecordLedger(p.Name(), pos, bl.Value, "rewritten", fmt.Sprintf("%s[%d]", table, index))
+		transformations++
+		return true
+	}, nil)
 
-		commonWords := []string{"main", "func", "package", "import", "var", "const", "if", "else", "for", "range"}
-		for _, w := range commonWords {
-			if val == w {
-				return true
+	if len(entries) == 0 {
+		return nil
+	}
+
+	src := fmt.Sprintf("var %s = [%d]%s{%s}\n", table, len(entries), p.decoder, strings.Join(entries, ", "))
+	if !p.emitted {
+		sync := ""
+		for _, imp := range file.Imports {
+			if imp.Path.Value != `"sync"` {
+				continue
+			}
+			if imp.Name == nil {
+				sync = "sync"
+			} else if imp.Name.Name != "_" && imp.Name.Name != "." {
+				sync = imp.Name.Name
 			}
 		}
+		if sync == "" {
+			stdastutil.AddImport(fset, file, "sync")
+			sync = "sync"
+		}
+		src += fmt.Sprintf(stringDecoderSource, p.decoder, sync, p.key)
+		p.emitted = true
+	}
+	decls, err := astutil.ParseDecls(fset, src)
+	if err != nil {
+		return fmt.Errorf("StringObfuscate: %w", err)
+	}
+	file.Decls = append(file.Decls, decls...)
 
-		// Obfuscação → byte array
-		byteVals := make([]string, len(val))
-		for i, b := range []byte(val) {
-			byteVals[i] = strconv.Itoa(int(b))
-		}
-
-		// Caso seja constante → converte para var + init()
-		isConst := objectsSpecMap[bl].isConst
-		isConstCheck := astutil.CheckConstant(
-			bl,
-			bl.Kind.String(),
-			ctx,
-		)
-
-		if !isConst && !isConstCheck {
-			if !astutil.CheckConstant(
-				bl,
-				bl.Kind.String(),
-				ctx,
-			) {
-				tpS := objectsSpecMap[bl].varType
-				tp := "string"
-				if tpS != nil {
-					switch t := tpS.(type) {
-					case *ast.Ident:
-						tp = t.Name
-					case *ast.SelectorExpr:
-						if x, ok := t.X.(*ast.Ident); ok {
-							tp = fmt.Sprintf("%s.%s", x.Name, t.Sel.Name)
-						}
-					}
-				}
+	if transformations > 0 {
+		gl.Log("info", fmt.Sprintf("🔄 StringObfuscatePass: %d transformations applied", transformations))
+	}
 
-				// Se for outro tipo (alias), mantém o tipo
-				// Substitui o literal pela conversão do byte array
-				// Ex: "hello" → string([]byte{104, 101, 108, 108, 111})
-				// Ex: MyStringAlias("hello") → MyStringAlias([]byte{104, 101, 108, 108, 111})
-				bl.Value = fmt.Sprintf("%s([]byte{%s})", tp, strings.Join(byteVals, ", "))
-				transformations++
+	return nil
+}
+
+// convertConsts rewrites the const declarations of file that the plan converts into vars
+func (p *StringObfuscatePass) convertConsts(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) {
+	record := func(runs []*ast.GenDecl) {
+		for _, run := range runs {
+			if run.Tok != token.VAR {
+				continue
 			}
+			for _, spec := range run.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					ctx.RecordLedger(p.Name(), fset.Position(name.Pos()), name.Name, "rewritten", "const → var")
+				}
+			}
+		}
+	}
 
-			transformations++
+	var decls []ast.Decl
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.CONST {
+			decls = append(decls, decl)
+			continue
+		}
+		runs := splitConstDecl(gd, p.consts)
+		if runs == nil {
+			decls = append(decls, decl)
+			continue
+		}
+		record(runs)
+		for _, run := range runs {
+			decls = append(decls, run)
+		}
+	}
+	file.Decls = decls
 
+	// Constantes locais
+	stdastutil.Apply(file, func(c *stdastutil.Cursor) bool {
+		ds, ok := c.Node().(*ast.DeclStmt)
+		if !ok {
 			return true
 		}
-
+		gd, ok := ds.Decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.CONST {
+			return true
+		}
+		runs := splitConstDecl(gd, p.consts)
+		if runs == nil {
+			return true
+		}
+		record(runs)
+		for i := len(runs) - 1; i > 0; i-- {
+			c.InsertAfter(&ast.DeclStmt{Decl: runs[i]})
+		}
+		c.Replace(&ast.DeclStmt{Decl: runs[0]})
 		return false
-	})
+	}, nil)
+}
 
-	if transformations > 0 {
-		gl.Log("info", fmt.Sprintf("🔄 StringObfuscatePass: %d transformations applied", transformations))
+// tableName returns a package-unique name for the literal table of a file
+func (p *StringObfuscatePass) tableName(file *ast.File, ctx *astutil.TranspileContext) string {
+	for i := 1; ; i++ {
+		name := "obfStrings"
+		if i > 1 {
+			name += strconv.Itoa(i)
+		}
+		if !p.used[name] && freshLocalName(file, name) == name && freshPackageName(ctx.Package, name) == name {
+			p.used[name] = true
+			return name
+		}
 	}
+}
 
-	return nil
+// obfuscateXOR cifra (ou decifra) b com o fluxo splitmix64 de state, igual ao decodificador gerado
+func obfuscateXOR(b []byte, state uint64) []byte {
+	out := make([]byte, len(b))
+	var k uint64
+	for i := range b {
+		if i%8 == 0 {
+			state += 0x9e3779b97f4a7c15
+			k = splitmix64(state)
+		}
+		out[i] = b[i] ^ byte(k>>(i%8*8))
+	}
+	return out
+}
+
+// splitmix64 is the finalizer of the SplitMix64 generator
+func splitmix64(x uint64) uint64 {
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// quoteBytes renders b as a Go string literal made only of \x escapes
+func quoteBytes(b []byte) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, c := range b {
+		fmt.Fprintf(&sb, `\x%02x`, c)
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+const stringDecoderSource = `
+// %[1]s guarda um literal cifrado; get decifra na primeira chamada e reaproveita o resultado
+type %[1]s struct {
+	once  %[2]s.Once
+	data  string
+	nonce uint64
+	plain string
+}
+
+func (o *%[1]s) get() string {
+	o.once.Do(func() {
+		b := []byte(o.data)
+		x := o.nonce ^ %#[3]x
+		var k uint64
+		for i := range b {
+			if i%%8 == 0 {
+				x += 0x9e3779b97f4a7c15
+				k = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+				k = (k ^ k>>27) * 0x94d049bb133111eb
+				k ^= k >> 31
+			}
+			b[i] ^= byte(k >> (i %% 8 * 8))
+		}
+		o.plain = string(b)
+	})
+	return o.plain
 }
+`
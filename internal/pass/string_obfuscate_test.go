@@ -0,0 +1,97 @@
+package pass
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// buildSource builds a single-file main package, runs the binary and returns
+// its stdout and the binary itself
+func buildSource(t *testing.T, src string) (string, []byte) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module probe\n\ngo 1.22\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	build := exec.Command("go", "build", "-o", "probe")
+	build.Dir = dir
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s\n%s", err, out, src)
+	}
+	bin := filepath.Join(dir, "probe")
+	out, err := exec.Command(bin).Output()
+	if err != nil {
+		t.Fatalf("run: %v\n%s", err, src)
+	}
+	data, err := os.ReadFile(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out), data
+}
+
+// requirePlaintext checks which secrets show up in a binary
+func requirePlaintext(t *testing.T, bin []byte, present bool, secrets ...string) {
+	t.Helper()
+	for _, s := range secrets {
+		if bytes.Contains(bin, []byte(s)) != present {
+			t.Errorf("%q in binary: %v, want %v", s, !present, present)
+		}
+	}
+}
+
+const stringObfuscateProbe = `package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Path string
+
+var banner = "gastype-banner-secret"
+
+func connect(dsn string) string { return strings.ToUpper(dsn) }
+
+func main() {
+	fmt.Println(banner)
+	fmt.Println(connect("postgres://user:hunter2@db/prod"))
+	var p Path = "/etc/gastype/secret.conf"
+	fmt.Println(p, len(p))
+	for _, w := range []string{"alpha-secret", "beta-secret"} {
+		fmt.Println(w)
+	}
+	m := map[string]int{"map-key-secret": 1}
+	fmt.Println(m["map-key-secret"], "multi\nline\tsecret")
+}
+`
+
+func TestStringObfuscateHidesPlaintext(t *testing.T) {
+	requireGo(t)
+	secrets := []string{"gastype-banner-secret", "hunter2@db/prod", "/etc/gastype/secret.conf",
+		"alpha-secret", "beta-secret", "map-key-secret", "multi\nline\tsecret"}
+
+	want, bin := buildSource(t, stringObfuscateProbe)
+	requirePlaintext(t, bin, true, secrets...)
+
+	out, ctx := transpileSource(t, stringObfuscateProbe, true, NewStringObfuscatePass())
+	if got := countLedger(ctx, "StringObfuscate", "rewritten"); got != 8 {
+		t.Errorf("%d literals encrypted, want 8\n%s", got, out)
+	}
+	got, bin := buildSource(t, out)
+	if got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+	requirePlaintext(t, bin, false, secrets...)
+
+	// Sem ofuscação (etapa 1) o código sai como está
+	if plain, _ := transpileSource(t, stringObfuscateProbe, false, NewStringObfuscatePass()); !bytes.Contains([]byte(plain), []byte(`"gastype-banner-secret"`)) {
+		t.Errorf("literals encrypted without ofuscate\n%s", plain)
+	}
+}
@@ -0,0 +1,251 @@
+package pass
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"math"
+	"strings"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+)
+
+const keepDirective = "//gastype:keep"
+
+// stringPolicy decides, literal by literal, whether StringObfuscate encrypts it.
+// A literal stays in plain text when a //gastype:keep covers it (at the end of
+// its line, alone on the line above, or in the doc comment of its
+// declaration), when it matches --strings-deny, when it is the format of a
+// printf-like call, when it is the message of a sentinel error, or when it is
+// shorter than --strings-min-len or below --strings-min-entropy.
+// --strings-allow forces encryption past the automatic rules, never past keep
+// or deny.
+type stringPolicy struct {
+	ctx       *astutil.TranspileContext
+	sentinels map[*ast.BasicLit]bool // mensagens de erros sentinela usados com errors.Is
+	keep      map[*ast.BasicLit]bool // literais sob //gastype:keep
+	keepLines map[int]bool           // linhas com //gastype:keep no arquivo atual
+	keepNext  map[int]bool           // linhas logo abaixo de um //gastype:keep sozinho na linha
+	formats   map[*ast.BasicLit]string
+}
+
+// newStringPolicy collects the package-wide facts: the messages of sentinel
+// errors, i.e. package-level vars built with errors.New/fmt.Errorf and used as
+// the target of errors.Is. Their identity matters, not their text.
+func newStringPolicy(files []*ast.File, ctx *astutil.TranspileContext) *stringPolicy {
+	policy := &stringPolicy{ctx: ctx, sentinels: make(map[*ast.BasicLit]bool)}
+
+	targets := make(map[types.Object]bool)
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 2 || !isPackageFunc(call, "errors", "Is", ctx) {
+				return true
+			}
+			var id *ast.Ident
+			switch target := ast.Unparen(call.Args[1]).(type) {
+			case *ast.Ident:
+				id = target
+			case *ast.SelectorExpr:
+				id = target.Sel
+			}
+			if id != nil {
+				if obj := ctx.GetUses()[id]; obj != nil {
+					targets[obj] = true
+				}
+			}
+			return true
+		})
+	}
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, name := range vs.Names {
+					if i >= len(vs.Values) || !targets[ctx.GetDefs()[name]] {
+						continue
+					}
+					call, ok := ast.Unparen(vs.Values[i]).(*ast.CallExpr)
+					if !ok || !(isPackageFunc(call, "errors", "New", ctx) || isPackageFunc(call, "fmt", "Errorf", ctx)) {
+						continue
+					}
+					ast.Inspect(call, func(m ast.Node) bool {
+						if bl, ok := m.(*ast.BasicLit); ok && bl.Kind == token.STRING {
+							policy.sentinels[bl] = true
+						}
+						return true
+					})
+				}
+			}
+		}
+	}
+	return policy
+}
+
+// scanFile collects the //gastype:keep opt-outs and the format strings of file
+func (policy *stringPolicy) scanFile(file *ast.File, fset *token.FileSet) {
+	policy.keep = make(map[*ast.BasicLit]bool)
+	policy.keepLines = make(map[int]bool)
+	policy.keepNext = make(map[int]bool)
+	policy.formats = make(map[*ast.BasicLit]string)
+
+	// Primeira posição de código em cada linha, para saber se um comentário
+	// está sozinho na linha ou vem depois de código
+	firstCode := make(map[int]token.Pos)
+	mark := func(pos token.Pos) {
+		if !pos.IsValid() {
+			return
+		}
+		line := fset.Position(pos).Line
+		if first, ok := firstCode[line]; !ok || pos < first {
+			firstCode[line] = pos
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n.(type) {
+		case nil, *ast.File:
+			return true
+		case *ast.CommentGroup, *ast.Comment:
+			return false
+		}
+		mark(n.Pos())
+		mark(n.End() - 1)
+		return true
+	})
+
+	// Por literal: comentário na mesma linha, ou sozinho na linha anterior.
+	// Um //gastype:keep no fim de uma linha vale só para ela.
+	for _, cg := range file.Comments {
+		for _, c := range cg.List {
+			if !strings.HasPrefix(c.Text, keepDirective) {
+				continue
+			}
+			line := fset.Position(c.Pos()).Line
+			policy.keepLines[line] = true
+			if first, ok := firstCode[line]; !ok || first > c.Pos() {
+				policy.keepNext[line+1] = true
+			}
+		}
+	}
+
+	keepAll := func(n ast.Node) {
+		ast.Inspect(n, func(m ast.Node) bool {
+			if bl, ok := m.(*ast.BasicLit); ok && bl.Kind == token.STRING {
+				policy.keep[bl] = true
+			}
+			return true
+		})
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		// Por declaração: diretiva no comentário de documentação
+		switch node := n.(type) {
+		case *ast.FuncDecl:
+			if hasDirective(node.Doc, keepDirective) {
+				keepAll(node)
+			}
+		case *ast.GenDecl:
+			if hasDirective(node.Doc, keepDirective) {
+				keepAll(node)
+			}
+		case *ast.ValueSpec:
+			if hasDirective(node.Doc, keepDirective) || hasDirective(node.Comment, keepDirective) {
+				keepAll(node)
+			}
+		case *ast.CallExpr:
+			policy.scanFormat(node)
+		}
+		return true
+	})
+}
+
+// scanFormat marks the literal passed as the `format` parameter of a printf-like call
+func (policy *stringPolicy) scanFormat(call *ast.CallExpr) {
+	tv, ok := policy.ctx.GetTypes()[call.Fun]
+	if !ok || tv.Type == nil {
+		return
+	}
+	sig, ok := tv.Type.Underlying().(*types.Signature)
+	if !ok || !sig.Variadic() {
+		return
+	}
+	for i := 0; i < sig.Params().Len()-1 && i < len(call.Args); i++ {
+		if sig.Params().At(i).Name() != "format" {
+			continue
+		}
+		if bl, ok := ast.Unparen(call.Args[i]).(*ast.BasicLit); ok && bl.Kind == token.STRING {
+			policy.formats[bl] = types.ExprString(call.Fun)
+		}
+	}
+}
+
+// reason returns why bl (with value val) must stay in plain text, or "" to encrypt it
+func (policy *stringPolicy) reason(bl *ast.BasicLit, val string, line int) string {
+	rules := policy.ctx.StringPolicy
+	if policy.keep[bl] || policy.keepLines[line] || policy.keepNext[line] {
+		return "gastype:keep"
+	}
+	if rules.Deny != nil && rules.Deny.MatchString(val) {
+		return "matches --strings-deny"
+	}
+	if rules.Allow != nil && rules.Allow.MatchString(val) {
+		return ""
+	}
+	if callee, ok := policy.formats[bl]; ok {
+		return "format string of " + callee
+	}
+	if policy.sentinels[bl] {
+		return "message of a sentinel error compared with errors.Is"
+	}
+	if len(val) < rules.MinLength {
+		return fmt.Sprintf("shorter than %d bytes", rules.MinLength)
+	}
+	if e := shannonEntropy(val); e < rules.MinEntropy {
+		return fmt.Sprintf("entropy %.2f below %.2f", e, rules.MinEntropy)
+	}
+	return ""
+}
+
+// hasDirective reports whether cg contains a //gastype: directive
+func hasDirective(cg *ast.CommentGroup, directive string) bool {
+	if cg == nil {
+		return false
+	}
+	for _, c := range cg.List {
+		if strings.HasPrefix(c.Text, directive) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPackageFunc reports whether call calls the function pkg.name
+func isPackageFunc(call *ast.CallExpr, pkg, name string, ctx *astutil.TranspileContext) bool {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	fn, ok := ctx.GetUses()[sel.Sel].(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == pkg
+}
+
+// shannonEntropy returns the entropy of s in bits per byte
+func shannonEntropy(s string) float64 {
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	entropy := 0.0
+	for _, n := range counts {
+		if n > 0 {
+			p := float64(n) / float64(len(s))
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
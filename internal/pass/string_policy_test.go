@@ -0,0 +1,95 @@
+package pass
+
+import (
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+)
+
+// obfuscateWith runs StringObfuscatePass with the --strings-* settings
+type obfuscateWith struct {
+	*StringObfuscatePass
+	policy astutil.StringPolicy
+}
+
+func (o obfuscateWith) Prepare(files []*ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	ctx.StringPolicy = o.policy
+	return o.StringObfuscatePass.Prepare(files, fset, ctx)
+}
+
+const stringPolicyProbe = `package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrNotFound = errors.New("not-found-sentinel")
+
+//gastype:keep
+var docKept = "doc-kept-plaintext"
+
+func find(k string) error {
+	if k == "" {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func main() {
+	a := "same-line-plaintext" //gastype:keep
+	c := "next-line-encrypted"
+	//gastype:keep
+	b := "line-above-plaintext"
+	fmt.Printf("format-plaintext %s %s\n", a, b)
+	fmt.Println(c, docKept, "ab", "aaaaaaaa", "zz", "deny-me-plaintext")
+	fmt.Println(errors.Is(find(""), ErrNotFound), find("x") == nil)
+}
+`
+
+func TestStringPolicyReportsEachLiteral(t *testing.T) {
+	requireGo(t)
+	want, _ := buildSource(t, stringPolicyProbe)
+	pass := obfuscateWith{NewStringObfuscatePass(), astutil.StringPolicy{
+		Allow:     regexp.MustCompile(`^zz$`),
+		Deny:      regexp.MustCompile(`^deny-`),
+		MinLength: 4, MinEntropy: 1,
+	}}
+	out, ctx := transpileSource(t, stringPolicyProbe, true, pass)
+	got, bin := buildSource(t, out)
+	if got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+
+	reasons := make(map[string]string)
+	for _, e := range ctx.Ledger {
+		if e.Action == "rewritten" {
+			reasons[e.Target] = "encrypted"
+		} else {
+			reasons[e.Target] = e.Detail
+		}
+	}
+	for lit, want := range map[string]string{
+		`"same-line-plaintext"`:      "gastype:keep",
+		`"line-above-plaintext"`:     "gastype:keep",
+		`"doc-kept-plaintext"`:       "gastype:keep",
+		`"next-line-encrypted"`:      "encrypted",
+		`"format-plaintext %s %s\n"`: "format string of fmt.Printf",
+		`"not-found-sentinel"`:       "sentinel error",
+		`"ab"`:                       "shorter than 4 bytes",
+		`"aaaaaaaa"`:                 "entropy 0.00 below 1.00",
+		`"zz"`:                       "encrypted",
+		`"deny-me-plaintext"`:        "--strings-deny",
+	} {
+		if !strings.Contains(reasons[lit], want) {
+			t.Errorf("%s: %q, want %q", lit, reasons[lit], want)
+		}
+	}
+	requirePlaintext(t, bin, true, "same-line-plaintext", "line-above-plaintext", "doc-kept-plaintext",
+		"format-plaintext", "not-found-sentinel", "deny-me-plaintext")
+	requirePlaintext(t, bin, false, "next-line-encrypted")
+}
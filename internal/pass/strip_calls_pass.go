@@ -0,0 +1,548 @@
+package pass
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	stdastutil "golang.org/x/tools/go/ast/astutil"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// defaultStripRules apply when --strip-calls is not given: the debug logs of logz
+var defaultStripRules = []astutil.StripRule{
+	{Qualifier: "github.com/kubex-ecosystem/logz/logger", Name: "Log", Levels: []string{"debug"}},
+}
+
+// StripCallsPass remove de builds de produção as chamadas de log (ou de
+// debug) configuradas em --strip-calls, junto com a montagem dos argumentos:
+//
+//	gl.Log("debug", fmt.Sprintf("cache hit %s", key))   →   (removida)
+//
+// Só some a chamada usada como statement cujos argumentos não têm efeitos
+// colaterais nem podem entrar em pânico: constantes, variáveis, leituras de
+// campos sem ponteiro, leituras de mapa, closures não chamadas, conversões,
+// len/cap/min/max, concatenação e as funções puras de strconv/strings, além
+// de fmt.Sprint* com operandos de tipo básico sem métodos (String/Error
+// chamados pelo fmt podem fazer qualquer coisa). Qualquer expressão que possa
+// entrar em pânico mantém a chamada, para o programa falhar onde falhava:
+// campo via ponteiro, índice e fatia fora de um array com limites constantes,
+// `*p`, asserção de tipo, divisão inteira por não-constante, shift por
+// contagem com sinal não constante, comparação de interfaces, conversão de
+// fatia para array, strings.Repeat sem contagem constante não negativa e
+// FormatInt/FormatUint sem base constante entre 2 e 36.
+// O qualificador de uma regra pode ser o nome de um import, o nome ou caminho
+// do pacote, ou o tipo receptor de um método; funções do próprio pacote casam
+// sem qualificador ou com o nome dele (main.Log).
+// Variáveis locais que ficariam sem uso viram `_ = x` e imports sem uso caem.
+// Cada chamada removida entra no relatório com os bytes de string eliminados;
+// as mantidas ficam no ledger com o motivo.
+type StripCallsPass struct {
+	rules []astutil.StripRule
+}
+
+// stripPureFuncs are the package functions accepted inside stripped arguments
+var stripPureFuncs = map[string]bool{
+	"strconv.Itoa": true, "strconv.FormatInt": true, "strconv.FormatUint": true,
+	"strconv.FormatFloat": true, "strconv.FormatBool": true, "strconv.Quote": true,
+	"strings.Join": true, "strings.Repeat": true, "strings.ToUpper": true,
+	"strings.ToLower": true, "strings.TrimSpace": true,
+}
+
+func NewStripCallsPass() *StripCallsPass { return &StripCallsPass{} }
+func (p *StripCallsPass) Name() string   { return "StripCalls" }
+
+// Prepare picks the rules configured on the context, or the logz default
+func (p *StripCallsPass) Prepare(_ []*ast.File, _ *token.FileSet, ctx *astutil.TranspileContext) error {
+	p.rules = ctx.StripRules
+	if len(p.rules) == 0 {
+		p.rules = defaultStripRules
+	}
+	return nil
+}
+
+func (p *StripCallsPass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	// === 1️⃣ Chamadas que casam com as regras ===
+	type strip struct {
+		stmt  *ast.ExprStmt
+		call  *ast.CallExpr
+		name  string
+		level string
+	}
+	var strips []strip
+	removed := make(map[*ast.ExprStmt]bool)
+	replaced := make(map[*ast.ExprStmt]ast.Stmt)
+	ast.Inspect(file, func(n ast.Node) bool {
+		stmt, ok := n.(*ast.ExprStmt)
+		if !ok {
+			return true
+		}
+		call, ok := ast.Unparen(stmt.X).(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		rule, name, ok := p.match(call, ctx)
+		if !ok {
+			return true
+		}
+		level, ok := stripLevel(rule, call, ctx)
+		if !ok {
+			return true
+		}
+		pos := fset.Position(call.Pos())
+		evaluated := call.Args
+		if sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok {
+			evaluated = append([]ast.Expr{sel.X}, evaluated...)
+		}
+		if reason := stripImpure(evaluated, ctx); reason != "" {
+			gl.Log("info", fmt.Sprintf("StripCalls: skipping %s at %s (%s)", name, pos, reason))
+			ctx.RecordLedger(p.Name(), pos, name, "skipped", reason)
+			return true
+		}
+		strips = append(strips, strip{stmt, call, name, level})
+		removed[stmt] = true
+		return false
+	})
+	if len(strips) == 0 {
+		return nil
+	}
+
+	// === 2️⃣ Locais que só eram lidos pelos logs ===
+	locals := stripLocals(file, ctx)
+	remaining := make(map[*types.Var]int)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if stmt, ok := n.(*ast.ExprStmt); ok && removed[stmt] {
+			return false
+		}
+		if id, ok := n.(*ast.Ident); ok {
+			if v, ok := ctx.GetUses()[id].(*types.Var); ok && locals[v] != nil {
+				remaining[locals[v]]++
+			}
+		}
+		return true
+	})
+
+	kept := make(map[*types.Var]bool)
+	imports := make(map[*types.PkgName]bool)
+	stringBytes := 0
+	for _, s := range strips {
+		// Um `_ = x` pelas variáveis que ficariam declaradas e não usadas
+		blank := &ast.AssignStmt{Tok: token.ASSIGN}
+		n := 0
+		ast.Inspect(s.call, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.BasicLit:
+				if node.Kind == token.STRING {
+					if v, err := strconv.Unquote(node.Value); err == nil {
+						n += len(v)
+					}
+				}
+			case *ast.Ident:
+				obj := ctx.GetUses()[node]
+				if pkgName, ok := obj.(*types.PkgName); ok {
+					imports[pkgName] = true
+				}
+				if v, ok := obj.(*types.Var); ok && locals[v] != nil && remaining[locals[v]] == 0 && !kept[locals[v]] {
+					kept[locals[v]] = true
+					id := ast.NewIdent(node.Name)
+					ctx.GetUses()[id] = v
+					blank.Lhs = append(blank.Lhs, ast.NewIdent("_"))
+					blank.Rhs = append(blank.Rhs, id)
+				}
+				delete(ctx.GetUses(), node)
+			}
+			return true
+		})
+		stringBytes += n
+
+		pos := fset.Position(s.call.Pos())
+		ctx.RegisterStrippedCall(astutil.StrippedCall{
+			File: pos.Filename, Line: pos.Line, Call: s.name, Level: s.level, StringBytes: n,
+		})
+		detail := fmt.Sprintf("removed (%d string bytes)", n)
+		if len(blank.Lhs) > 0 {
+			detail += fmt.Sprintf(", %d locals kept with _ =", len(blank.Lhs))
+			replaced[s.stmt] = blank
+		}
+		ctx.RecordLedger(p.Name(), pos, s.name, "rewritten", detail)
+	}
+	stdastutil.Apply(file, nil, func(c *stdastutil.Cursor) bool {
+		stmt, ok := c.Node().(*ast.ExprStmt)
+		switch {
+		case !ok || !removed[stmt]:
+		case replaced[stmt] != nil:
+			c.Replace(replaced[stmt])
+		case stripInList(c):
+			c.Delete()
+		default:
+			c.Replace(&ast.EmptyStmt{Implicit: true})
+		}
+		return true
+	})
+
+	// === 3️⃣ Imports que só os logs usavam ===
+	for pkgName := range imports {
+		path := pkgName.Imported().Path()
+		if stdastutil.UsesImport(file, path) {
+			continue
+		}
+		for _, spec := range file.Imports {
+			if p, _ := strconv.Unquote(spec.Path.Value); p == path {
+				name := ""
+				if spec.Name != nil {
+					name = spec.Name.Name
+				}
+				stdastutil.DeleteNamedImport(fset, file, name, path)
+				break
+			}
+		}
+	}
+
+	ctx.LogVerbose(fset, "🔇 StripCallsPass: %d calls removed (-%d bytes of string literals)", len(strips), stringBytes)
+	return nil
+}
+
+// match finds the rule naming the function or method called
+func (p *StripCallsPass) match(call *ast.CallExpr, ctx *astutil.TranspileContext) (astutil.StripRule, string, bool) {
+	// Sem export data (pacotes de módulo fora do GOROOT) o objeto chamado
+	// fica sem tipo; o nome do import ainda identifica a função
+	var obj types.Object
+	var name string
+	var qualifiers []string
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		obj, name = ctx.GetUses()[fun], fun.Name
+	case *ast.SelectorExpr:
+		obj, name = ctx.GetUses()[fun.Sel], fun.Sel.Name
+		if x, ok := fun.X.(*ast.Ident); ok {
+			if pkgName, ok := ctx.GetUses()[x].(*types.PkgName); ok {
+				qualifiers = append(qualifiers, x.Name, pkgName.Imported().Name(), pkgName.Imported().Path())
+			}
+		}
+	default:
+		return astutil.StripRule{}, "", false
+	}
+	if obj == nil && len(qualifiers) == 0 {
+		return astutil.StripRule{}, "", false
+	}
+	if fn, ok := obj.(*types.Func); ok {
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			if named := derefNamed(recv.Type()); named != nil {
+				qualifiers = append(qualifiers, named.Obj().Name())
+			}
+		}
+	}
+	if obj != nil && obj.Pkg() == ctx.Package && obj.Parent() == ctx.Package.Scope() {
+		// Funções do próprio pacote: sem qualificador ou com o nome dele (main.Log)
+		qualifiers = append(qualifiers, "", ctx.Package.Name())
+	}
+	for _, rule := range p.rules {
+		if rule.Name != name {
+			continue
+		}
+		for _, q := range qualifiers {
+			if q == rule.Qualifier {
+				return rule, types.ExprString(call.Fun), true
+			}
+		}
+	}
+	return astutil.StripRule{}, "", false
+}
+
+// stripLevel checks the first argument against the levels of the rule
+func stripLevel(rule astutil.StripRule, call *ast.CallExpr, ctx *astutil.TranspileContext) (string, bool) {
+	if len(rule.Levels) == 0 {
+		return "", true
+	}
+	if len(call.Args) == 0 {
+		return "", false
+	}
+	arg := call.Args[0]
+	value := ctx.GetTypes()[arg].Value
+	if value == nil {
+		return "", false
+	}
+	text := value.ExactString()
+	if value.Kind() == constant.String {
+		text = constant.StringVal(value)
+	}
+	name := types.ExprString(arg)
+	if sel, ok := arg.(*ast.SelectorExpr); ok {
+		name = sel.Sel.Name
+	}
+	for _, level := range rule.Levels {
+		if level == text || level == name || level == types.ExprString(arg) {
+			return level, true
+		}
+	}
+	return "", false
+}
+
+// stripImpure returns why evaluating args could have side effects or panic, or
+// "". Every expression that may panic counts, so the program still fails
+// where the original did.
+func stripImpure(args []ast.Expr, ctx *astutil.TranspileContext) string {
+	reason := ""
+	for _, arg := range args {
+		ast.Inspect(arg, func(n ast.Node) bool {
+			if reason != "" {
+				return false
+			}
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				return false // só cria a closure
+			case *ast.UnaryExpr:
+				if n.Op == token.ARROW {
+					reason = "channel receive"
+				}
+			case *ast.StarExpr:
+				if tv, ok := ctx.GetTypes()[n]; !ok || !tv.IsType() {
+					reason = "dereferences " + types.ExprString(n.X)
+				}
+			case *ast.TypeAssertExpr:
+				reason = "type assertion " + types.ExprString(n)
+			case *ast.SelectorExpr:
+				if sel := ctx.GetSelections()[n]; sel != nil && sel.Kind() != types.MethodExpr && sel.Indirect() {
+					reason = "reads " + types.ExprString(n) + " through a pointer"
+				}
+			case *ast.IndexExpr:
+				reason = stripIndex(n, ctx)
+			case *ast.SliceExpr:
+				reason = stripSlice(n, ctx)
+			case *ast.BinaryExpr:
+				switch n.Op {
+				case token.QUO, token.REM:
+					reason = stripDivision(n, ctx)
+				case token.SHL, token.SHR:
+					reason = stripShift(n, ctx)
+				case token.EQL, token.NEQ:
+					reason = stripCompare(n, ctx)
+				}
+			case *ast.CallExpr:
+				reason = stripImpureCall(n, ctx)
+			}
+			return true
+		})
+		if reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+// stripDivision rejects integer division by a divisor that may be zero
+func stripDivision(bin *ast.BinaryExpr, ctx *astutil.TranspileContext) string {
+	tv, ok := ctx.GetTypes()[bin]
+	if ok && tv.Value != nil {
+		return "" // constante: o compilador já checou
+	}
+	if ok && tv.Type != nil && !isIntegerType(tv.Type) {
+		return "" // float e complex não entram em pânico
+	}
+	if y, ok := ctx.GetTypes()[bin.Y]; ok && y.Value != nil && constant.Sign(y.Value) != 0 {
+		return ""
+	}
+	return "divides by " + types.ExprString(bin.Y)
+}
+
+// stripIndex rejects indexes that may be out of range, and map reads whose
+// interface key may be unhashable
+func stripIndex(ix *ast.IndexExpr, ctx *astutil.TranspileContext) string {
+	x, ok := ctx.GetTypes()[ix.X]
+	if !ok || x.IsType() || x.Type == nil {
+		return "" // instanciação genérica
+	}
+	if _, ok := x.Type.Underlying().(*types.Signature); ok {
+		return ""
+	}
+	index := ctx.GetTypes()[ix.Index]
+	switch u := x.Type.Underlying().(type) {
+	case *types.Map:
+		if !types.IsInterface(u.Key()) || index.Value != nil {
+			return ""
+		}
+		return "map key " + types.ExprString(ix.Index) + " may be unhashable"
+	case *types.Array:
+		if index.Value != nil {
+			return "" // o compilador já checou
+		}
+	case *types.Basic:
+		if x.Value != nil && index.Value != nil {
+			return ""
+		}
+	}
+	return "indexes " + types.ExprString(ix)
+}
+
+// stripSlice rejects slice expressions whose bounds may be out of range
+func stripSlice(sl *ast.SliceExpr, ctx *astutil.TranspileContext) string {
+	x := ctx.GetTypes()[sl.X]
+	if x.Type == nil {
+		return "slices " + types.ExprString(sl)
+	}
+	constBounds := true
+	for _, bound := range []ast.Expr{sl.Low, sl.High, sl.Max} {
+		if bound != nil && ctx.GetTypes()[bound].Value == nil {
+			constBounds = false
+		}
+	}
+	switch x.Type.Underlying().(type) {
+	case *types.Array:
+		if constBounds {
+			return ""
+		}
+	case *types.Slice:
+		if sl.Low == nil && sl.High == nil && sl.Max == nil {
+			return "" // s[:] nunca sai do intervalo
+		}
+	case *types.Basic:
+		if sl.Low == nil && sl.High == nil || x.Value != nil && constBounds {
+			return ""
+		}
+	}
+	return "slices " + types.ExprString(sl)
+}
+
+// stripShift rejects shifts by a signed count that may be negative
+func stripShift(bin *ast.BinaryExpr, ctx *astutil.TranspileContext) string {
+	y := ctx.GetTypes()[bin.Y]
+	if y.Value != nil || y.Type == nil {
+		return ""
+	}
+	if b, ok := y.Type.Underlying().(*types.Basic); ok && b.Info()&types.IsUnsigned != 0 {
+		return ""
+	}
+	return "shifts by " + types.ExprString(bin.Y)
+}
+
+// stripCompare rejects interface comparisons, which panic on uncomparable
+// dynamic types
+func stripCompare(bin *ast.BinaryExpr, ctx *astutil.TranspileContext) string {
+	x, y := ctx.GetTypes()[bin.X], ctx.GetTypes()[bin.Y]
+	if x.IsNil() || y.IsNil() || x.Type == nil || y.Type == nil {
+		return ""
+	}
+	if types.IsInterface(x.Type) || types.IsInterface(y.Type) {
+		return "compares interfaces in " + types.ExprString(bin)
+	}
+	return ""
+}
+
+func stripImpureCall(call *ast.CallExpr, ctx *astutil.TranspileContext) string {
+	if tv, ok := ctx.GetTypes()[call.Fun]; ok && tv.IsType() {
+		// Fatia → array (ou *array) entra em pânico se faltar elemento
+		to := tv.Type.Underlying()
+		if p, ok := to.(*types.Pointer); ok {
+			to = p.Elem().Underlying()
+		}
+		if _, ok := to.(*types.Array); ok && len(call.Args) == 1 {
+			if from := ctx.GetTypes()[call.Args[0]].Type; from != nil {
+				if _, ok := from.Underlying().(*types.Slice); ok {
+					return "converts " + types.ExprString(call.Args[0]) + " to an array"
+				}
+			}
+		}
+		return "" // conversão
+	}
+	for _, name := range []string{"len", "cap", "min", "max"} {
+		if isBuiltinCall(call, name, ctx) {
+			return ""
+		}
+	}
+	// Argumentos que fariam a função pura entrar em pânico
+	constArg := func(i int, lo, hi int64) bool {
+		if i >= len(call.Args) {
+			return false
+		}
+		v := ctx.GetTypes()[call.Args[i]].Value
+		if v == nil {
+			return false
+		}
+		n, exact := constant.Int64Val(constant.ToInt(v))
+		return exact && n >= lo && n <= hi
+	}
+	switch {
+	case isPackageFunc(call, "strings", "Repeat", ctx) && !constArg(1, 0, 1<<31-1):
+		return "strings.Repeat count may be negative"
+	case (isPackageFunc(call, "strconv", "FormatInt", ctx) || isPackageFunc(call, "strconv", "FormatUint", ctx)) && !constArg(1, 2, 36):
+		return "base of " + types.ExprString(call.Fun) + " may be invalid"
+	}
+	for name := range stripPureFuncs {
+		pkgPath, fn, _ := strings.Cut(name, ".")
+		if isPackageFunc(call, pkgPath, fn, ctx) {
+			return ""
+		}
+	}
+	for _, fn := range []string{"Sprint", "Sprintf", "Sprintln"} {
+		if !isPackageFunc(call, "fmt", fn, ctx) {
+			continue
+		}
+		for _, arg := range call.Args {
+			t := ctx.GetTypes()[arg].Type
+			if t == nil || !stripPlainBasic(t) {
+				return "fmt formats " + types.ExprString(arg) + " through its methods"
+			}
+		}
+		return ""
+	}
+	return "calls " + types.ExprString(call.Fun)
+}
+
+// stripPlainBasic reports whether t has a basic underlying type and no methods fmt would call
+func stripPlainBasic(t types.Type) bool {
+	if _, ok := t.Underlying().(*types.Basic); !ok {
+		return false
+	}
+	ms := types.NewMethodSet(t)
+	for _, name := range []string{"String", "Error", "Format", "GoString"} {
+		if ms.Lookup(nil, name) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// stripLocals maps the objects of function-local variables (including each
+// clause's variable of a type switch) to the variable that must stay used
+func stripLocals(file *ast.File, ctx *astutil.TranspileContext) map[*types.Var]*types.Var {
+	locals := make(map[*types.Var]*types.Var)
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FieldList:
+			return false // parâmetros, resultados, receptores e campos podem ficar sem uso
+		case *ast.Ident:
+			if v, ok := ctx.GetDefs()[n].(*types.Var); ok && v.Parent() != ctx.Package.Scope() {
+				locals[v] = v
+			}
+		case *ast.TypeSwitchStmt:
+			// Cada cláusula tem o seu objeto; o conjunto conta como uma variável
+			var first *types.Var
+			for _, clause := range n.Body.List {
+				if v, ok := ctx.GetImplicits()[clause].(*types.Var); ok {
+					if first == nil {
+						first = v
+					}
+					locals[v] = first
+				}
+			}
+		}
+		return true
+	})
+	return locals
+}
+
+// stripInList reports whether the cursor sits in a statement list
+func stripInList(c *stdastutil.Cursor) bool {
+	switch c.Parent().(type) {
+	case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+		return true
+	}
+	return false
+}
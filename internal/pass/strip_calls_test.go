@@ -0,0 +1,83 @@
+package pass
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+)
+
+// stripWith runs StripCallsPass with the rules of --strip-calls
+type stripWith struct {
+	*StripCallsPass
+	rules []astutil.StripRule
+}
+
+func (s stripWith) Prepare(files []*ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	ctx.StripRules = s.rules
+	return s.StripCallsPass.Prepare(files, fset, ctx)
+}
+
+const stripCallsProbe = `package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type point struct{ X int }
+
+func debug(args ...any) {}
+
+// try relata se f entrou em pânico
+func try(name string, f func()) {
+	defer func() { fmt.Println(name, recover() != nil) }()
+	f()
+}
+
+func main() {
+	var p *point
+	var s []int
+	var k any = []int{}
+	var m map[any]int
+	var a, b any = []int{}, []int{}
+	i, n, base := 3, -1, 1
+
+	// Podem entrar em pânico: a chamada fica
+	try("field", func() { debug(p.X) })
+	try("index", func() { debug(s[i]) })
+	try("slice", func() { debug(s[i:]) })
+	try("shift", func() { debug(1 << n) })
+	try("compare", func() { debug(a == b) })
+	try("array", func() { debug([2]int(s)) })
+	try("repeat", func() { debug(strings.Repeat("x", n)) })
+	try("base", func() { debug(strconv.FormatInt(5, base)) })
+	try("hash", func() { debug(m[k]) })
+
+	// Não entram em pânico: a chamada some
+	arr := [3]int{1, 2, 3}
+	v := point{X: 1}
+	names := map[string]int{"a": 1}
+	debug(arr[1], arr[0:2], "lit"[1], names["x"], v.X, s[:], 1<<uint(i))
+	debug(strings.Repeat("x", 3), strconv.FormatInt(5, 16), a == nil)
+	fmt.Println("done")
+}
+`
+
+func TestStripCallsKeepsPanics(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, stripCallsProbe)
+	pass := stripWith{NewStripCallsPass(), []astutil.StripRule{{Name: "debug"}}}
+	out, ctx := transpileSource(t, stripCallsProbe, false, pass)
+	if got := countLedger(ctx, "StripCalls", "skipped"); got != 9 {
+		t.Errorf("%d calls kept, want 9\n%+v", got, ctx.Ledger)
+	}
+	if got := countLedger(ctx, "StripCalls", "rewritten"); got != 2 {
+		t.Errorf("%d calls removed, want 2\n%s", got, out)
+	}
+	if got := runSource(t, out); got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+}
@@ -0,0 +1,495 @@
+package pass
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+
+	"github.com/kubex-ecosystem/gastype/internal/astutil"
+
+	gl "github.com/kubex-ecosystem/logz/logger"
+)
+
+// StructLayoutPass reorders struct fields into the layout with the least padding
+// for the target GOARCH and reports the bytes saved per struct.
+//
+//	type Config struct {        type Config struct {
+//		Debug bool                  Timeout int64
+//		Timeout int64      →        Name    string
+//		Verbose bool                Debug   bool
+//		Name string                 Verbose bool
+//	}                           }   // 48 → 32 bytes on amd64
+//
+// A struct is left alone when field order is observable: unkeyed composite
+// literals, unsafe/encoding/binary use, cgo, struct tags, blank padding fields,
+// values flowing into interfaces, struct literal fields included (fmt,
+// encoding/json and reflect print or encode fields in declaration order), or
+// exported structs outside package main, whose callers may rely on the order.
+type StructLayoutPass struct {
+	rejected map[*types.TypeName]string
+	observed map[*types.TypeName]string // structs que viram interface e os alcançáveis por eles
+	cgo      bool
+}
+
+func NewStructLayoutPass() *StructLayoutPass {
+	return &StructLayoutPass{}
+}
+
+func (p *StructLayoutPass) Name() string {
+	return "StructLayout"
+}
+
+// Prepare finds the structs of the package whose field order must be preserved
+func (p *StructLayoutPass) Prepare(files []*ast.File, _ *token.FileSet, ctx *astutil.TranspileContext) error {
+	p.rejected = make(map[*types.TypeName]string)
+	p.observed = make(map[*types.TypeName]string)
+	p.cgo = false
+	if ctx.Package == nil {
+		return nil
+	}
+
+	for _, file := range files {
+		for _, imp := range file.Imports {
+			if path, _ := strconv.Unquote(imp.Path.Value); path == "C" {
+				p.cgo = true
+			}
+		}
+		p.scanFile(file, ctx)
+	}
+
+	// Um struct contido em outro já rejeitado (ou observado) também é
+	propagateStructs(p.rejected)
+	propagateStructs(p.observed)
+	return nil
+}
+
+// propagateStructs extends set with the structs reachable through the fields
+// of its members, keeping the reason of the outer struct
+func propagateStructs(set map[*types.TypeName]string) {
+	changed := true
+	for changed {
+		changed = false
+		for obj, reason := range set {
+			st, ok := obj.Type().Underlying().(*types.Struct)
+			if !ok {
+				continue
+			}
+			for i := 0; i < st.NumFields(); i++ {
+				for _, inner := range namedStructsIn(st.Field(i).Type()) {
+					if _, done := set[inner]; !done {
+						set[inner] = reason + " (via " + obj.Name() + ")"
+						changed = true
+					}
+				}
+			}
+		}
+	}
+}
+
+// scanFile rejects structs used in layout-sensitive ways inside one file
+func (p *StructLayoutPass) scanFile(file *ast.File, ctx *astutil.TranspileContext) {
+	reject := func(t types.Type, reason string) {
+		for _, obj := range namedStructsIn(t) {
+			if _, done := p.rejected[obj]; !done {
+				p.rejected[obj] = reason
+			}
+		}
+	}
+	typeOf := func(e ast.Expr) types.Type {
+		if tv, ok := ctx.GetTypes()[e]; ok {
+			return tv.Type
+		}
+		return nil
+	}
+	toInterface := func(target types.Type, value ast.Expr) {
+		if target != nil && types.IsInterface(target) {
+			if t := typeOf(value); t != nil && !types.IsInterface(t) {
+				reject(t, "converted to interface")
+				for _, obj := range namedStructsIn(t) {
+					if _, done := p.observed[obj]; !done {
+						p.observed[obj] = "converted to interface"
+					}
+				}
+			}
+		}
+	}
+
+	var stack []ast.Node
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+
+		switch node := n.(type) {
+		case *ast.CompositeLit:
+			t := typeOf(node)
+			if len(node.Elts) > 0 {
+				if _, keyed := node.Elts[0].(*ast.KeyValueExpr); !keyed && structOf(t) != nil {
+					reject(t, "unkeyed composite literal")
+				}
+			}
+			if t == nil {
+				return true
+			}
+			switch u := t.Underlying().(type) {
+			case *types.Slice:
+				for _, elt := range node.Elts {
+					toInterface(u.Elem(), compositeValue(elt))
+				}
+			case *types.Array:
+				for _, elt := range node.Elts {
+					toInterface(u.Elem(), compositeValue(elt))
+				}
+			case *types.Map:
+				for _, elt := range node.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						toInterface(u.Key(), kv.Key)
+						toInterface(u.Elem(), kv.Value)
+					}
+				}
+			case *types.Struct:
+				// W{V: c} com V any guarda c numa interface
+				for i, elt := range node.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						if key, ok := kv.Key.(*ast.Ident); ok {
+							if field, ok := ctx.GetUses()[key].(*types.Var); ok {
+								toInterface(field.Type(), kv.Value)
+							}
+						}
+					} else if i < u.NumFields() {
+						toInterface(u.Field(i).Type(), elt)
+					}
+				}
+			}
+		case *ast.CallExpr:
+			if pkgPath := calleePackage(node, ctx); pkgPath == "unsafe" || pkgPath == "encoding/binary" {
+				for _, arg := range node.Args {
+					reject(typeOf(arg), pkgPath+" use")
+				}
+				return true
+			}
+			if tv, ok := ctx.GetTypes()[node.Fun]; ok && tv.IsType() {
+				if len(node.Args) == 1 {
+					toInterface(tv.Type, node.Args[0])
+				}
+				return true
+			}
+			sig, ok := typeOf(node.Fun).(*types.Signature)
+			if !ok {
+				if t := typeOf(node.Fun); t != nil {
+					sig, _ = t.Underlying().(*types.Signature)
+				}
+			}
+			if sig == nil {
+				return true
+			}
+			for i, arg := range node.Args {
+				toInterface(paramType(sig, i, node.Ellipsis.IsValid()), arg)
+			}
+		case *ast.AssignStmt:
+			if len(node.Lhs) == len(node.Rhs) && node.Tok == token.ASSIGN {
+				for i := range node.Lhs {
+					toInterface(typeOf(node.Lhs[i]), node.Rhs[i])
+				}
+			}
+		case *ast.ValueSpec:
+			if node.Type != nil && len(node.Values) == len(node.Names) {
+				for _, v := range node.Values {
+					toInterface(typeOf(node.Type), v)
+				}
+			}
+		case *ast.ReturnStmt:
+			sig := enclosingSignature(stack, ctx)
+			if sig == nil || sig.Results().Len() != len(node.Results) {
+				return true
+			}
+			for i, r := range node.Results {
+				toInterface(sig.Results().At(i).Type(), r)
+			}
+		case *ast.SendStmt:
+			if ch, ok := typeOf(node.Chan).Underlying().(*types.Chan); ok {
+				toInterface(ch.Elem(), node.Value)
+			}
+		}
+		return true
+	})
+}
+
+func (p *StructLayoutPass) Apply(file *ast.File, fset *token.FileSet, ctx *astutil.TranspileContext) error {
+	if ctx.Package == nil {
+		return nil
+	}
+	sizes := ctx.Sizes()
+	transformations := 0
+	reordered := false
+
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok || ts.TypeParams != nil || len(st.Fields.List) < 2 {
+				continue
+			}
+			layout, order := p.planLayout(ts, st, sizes, ctx)
+			if layout == nil {
+				continue
+			}
+			layout.File = fset.Position(ts.Pos()).Filename
+			ctx.RegisterStructLayout(layout)
+			if order == nil {
+				continue
+			}
+			reorderFields(st, order)
+			reordered = true
+			transformations++
+			gl.Log("info", fmt.Sprintf("📐 StructLayout: %s %d → %d bytes (%d saved, %s)",
+				layout.Struct, layout.OriginalSize, layout.OptimizedSize, layout.BytesSaved, layout.Arch))
+		}
+	}
+
+	if reordered {
+		sort.SliceStable(file.Comments, func(i, j int) bool {
+			return file.Comments[i].Pos() < file.Comments[j].Pos()
+		})
+	}
+	if transformations > 0 {
+		ctx.LogVerbose(fset, "📐 StructLayoutPass: %d structs reordered", transformations)
+	}
+	return nil
+}
+
+// planLayout computes the current and optimal sizes of a struct. It returns a
+// nil order when the struct must keep its layout or is already optimal.
+func (p *StructLayoutPass) planLayout(ts *ast.TypeSpec, st *ast.StructType, sizes types.Sizes, ctx *astutil.TranspileContext) (*astutil.StructLayout, []int) {
+	var entryTypes, expanded []types.Type
+	var names []string
+	skipped := ""
+	for _, field := range st.Fields.List {
+		t := fieldType(field.Type, ctx)
+		if t == nil {
+			return nil, nil
+		}
+		entryTypes = append(entryTypes, t)
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+			names = append(names, types.ExprString(field.Type))
+		}
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+			if name.Name == "_" {
+				skipped = "blank padding field"
+			}
+		}
+		for i := 0; i < count; i++ {
+			expanded = append(expanded, t)
+		}
+		if field.Tag != nil && skipped == "" {
+			skipped = "struct tags"
+		}
+	}
+
+	order := astutil.OptimalFieldOrder(entryTypes, sizes)
+	var optimized []types.Type
+	var newNames []string
+	for _, idx := range order {
+		field := st.Fields.List[idx]
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+			newNames = append(newNames, types.ExprString(field.Type))
+		}
+		for _, name := range field.Names {
+			newNames = append(newNames, name.Name)
+		}
+		for i := 0; i < count; i++ {
+			optimized = append(optimized, entryTypes[idx])
+		}
+	}
+
+	layout := &astutil.StructLayout{
+		Struct:        ts.Name.Name,
+		Arch:          ctx.GOARCH,
+		OriginalSize:  astutil.StructSize(expanded, sizes),
+		OptimizedSize: astutil.StructSize(optimized, sizes),
+		OriginalOrder: names,
+	}
+	if layout.OptimizedSize >= layout.OriginalSize {
+		layout.OptimizedSize = layout.OriginalSize
+		return layout, nil
+	}
+
+	if obj, ok := ctx.GetDefs()[ts.Name].(*types.TypeName); ok && skipped == "" {
+		if reason, bad := p.rejected[obj]; bad {
+			skipped = reason
+		}
+	}
+	if skipped == "" && ctx.Package != nil && ctx.Package.Name() != "main" && ast.IsExported(ts.Name.Name) {
+		skipped = "exported outside package main" // outros pacotes podem depender da ordem (literais não nomeados, unsafe)
+	}
+	if p.cgo {
+		skipped = "cgo package"
+	}
+	if skipped != "" {
+		layout.Skipped = skipped
+		layout.OptimizedSize = layout.OriginalSize
+		gl.Log("info", fmt.Sprintf("StructLayout: keeping %s layout (%s)", ts.Name.Name, skipped))
+		return layout, nil
+	}
+
+	layout.BytesSaved = layout.OriginalSize - layout.OptimizedSize
+	layout.NewOrder = newNames
+	return layout, order
+}
+
+// reorderFields applies order to the field list, shifting positions so each
+// field keeps its doc and line comments when printed.
+func reorderFields(st *ast.StructType, order []int) {
+	old := st.Fields.List
+	start := func(f *ast.Field) token.Pos {
+		if f.Doc != nil {
+			return f.Doc.Pos()
+		}
+		return f.Pos()
+	}
+
+	// Slots são os intervalos originais [início do campo, início do próximo)
+	slotLen := make(map[*ast.Field]token.Pos)
+	var positioned []*ast.Field
+	for _, f := range old {
+		if start(f).IsValid() {
+			positioned = append(positioned, f)
+		}
+	}
+	for i, f := range positioned {
+		end := st.Fields.Closing
+		if i+1 < len(positioned) {
+			end = start(positioned[i+1])
+		}
+		slotLen[f] = end - start(f)
+	}
+
+	list := make([]*ast.Field, len(old))
+	var cursor token.Pos
+	if len(positioned) > 0 {
+		cursor = start(positioned[0])
+	}
+	for i, idx := range order {
+		f := old[idx]
+		list[i] = f
+		if n, ok := slotLen[f]; ok {
+			astutil.ShiftPositions(f, int(cursor-start(f)))
+			cursor += n
+		}
+	}
+	st.Fields.List = list
+}
+
+// fieldType resolves a field type expression, including idents created by earlier passes
+func fieldType(expr ast.Expr, ctx *astutil.TranspileContext) types.Type {
+	if tv, ok := ctx.GetTypes()[expr]; ok && tv.IsType() {
+		return tv.Type
+	}
+	if id, ok := expr.(*ast.Ident); ok {
+		if tn, ok := types.Universe.Lookup(id.Name).(*types.TypeName); ok {
+			return tn.Type()
+		}
+	}
+	return nil
+}
+
+// namedStructsIn returns the package struct types reachable by value from t
+func namedStructsIn(t types.Type) []*types.TypeName {
+	switch u := t.(type) {
+	case nil:
+		return nil
+	case *types.Named:
+		if _, ok := u.Underlying().(*types.Struct); ok {
+			return []*types.TypeName{u.Obj()}
+		}
+		return namedStructsIn(u.Underlying())
+	case *types.Pointer:
+		return namedStructsIn(u.Elem())
+	case *types.Slice:
+		return namedStructsIn(u.Elem())
+	case *types.Array:
+		return namedStructsIn(u.Elem())
+	case *types.Map:
+		return append(namedStructsIn(u.Key()), namedStructsIn(u.Elem())...)
+	case *types.Chan:
+		return namedStructsIn(u.Elem())
+	}
+	return nil
+}
+
+// calleePackage returns the import path of pkg in a pkg.Func(...) call
+func calleePackage(call *ast.CallExpr, ctx *astutil.TranspileContext) string {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	id, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	if pn, ok := ctx.GetUses()[id].(*types.PkgName); ok {
+		return pn.Imported().Path()
+	}
+	return ""
+}
+
+// paramType returns the type the i-th argument is assigned to
+func paramType(sig *types.Signature, i int, ellipsis bool) types.Type {
+	params := sig.Params()
+	if sig.Variadic() && i >= params.Len()-1 {
+		last := params.At(params.Len() - 1).Type()
+		if ellipsis {
+			return last
+		}
+		if s, ok := last.(*types.Slice); ok {
+			return s.Elem()
+		}
+		return nil
+	}
+	if i < params.Len() {
+		return params.At(i).Type()
+	}
+	return nil
+}
+
+// enclosingSignature returns the signature of the innermost function in stack
+func enclosingSignature(stack []ast.Node, ctx *astutil.TranspileContext) *types.Signature {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch fn := stack[i].(type) {
+		case *ast.FuncLit:
+			if tv, ok := ctx.GetTypes()[fn]; ok {
+				sig, _ := tv.Type.(*types.Signature)
+				return sig
+			}
+			return nil
+		case *ast.FuncDecl:
+			if obj, ok := ctx.GetDefs()[fn.Name].(*types.Func); ok {
+				sig, _ := obj.Type().(*types.Signature)
+				return sig
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+func compositeValue(elt ast.Expr) ast.Expr {
+	if kv, ok := elt.(*ast.KeyValueExpr); ok {
+		return kv.Value
+	}
+	return elt
+}
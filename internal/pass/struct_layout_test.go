@@ -0,0 +1,70 @@
+package pass
+
+import (
+	"strings"
+	"testing"
+)
+
+const structLayoutProbe = `package main
+
+import "fmt"
+
+// Reordenável: só acessos por campo
+type packed struct {
+	a bool
+	n int64
+	b bool
+}
+
+// Guardado num campo interface de um literal com chave
+type keyed struct {
+	a bool
+	n int64
+	b bool
+}
+
+// Guardado num campo interface de um literal posicional
+type positional struct {
+	a bool
+	n int64
+	b bool
+}
+
+type W struct {
+	V any
+}
+
+type P struct {
+	V any
+	N int
+}
+
+func main() {
+	p := packed{a: true, n: 2, b: true}
+	fmt.Println(p.a, p.n, p.b)
+
+	k := keyed{a: true, n: 2, b: true}
+	w := W{V: k}
+	fmt.Println(w.V)
+
+	q := P{positional{a: true, n: 2, b: true}, 1}
+	fmt.Println(q.V, q.N)
+}
+`
+
+func TestStructLayoutPreservesBehavior(t *testing.T) {
+	requireGo(t)
+	want := runSource(t, structLayoutProbe)
+	out, ctx := transpileSource(t, structLayoutProbe, false, NewStructLayoutPass())
+	if layout := ctx.StructLayouts["packed"]; layout == nil || layout.BytesSaved == 0 {
+		t.Fatalf("packed not reordered\n%s", out)
+	}
+	for _, name := range []string{"keyed", "positional"} {
+		if layout := ctx.StructLayouts[name]; layout == nil || !strings.Contains(layout.Skipped, "converted to interface") {
+			t.Errorf("%s reordered although it is stored in an interface field: %+v", name, layout)
+		}
+	}
+	if got := runSource(t, out); got != want {
+		t.Errorf("output differs\n got: %q\nwant: %q\n%s", got, want, out)
+	}
+}